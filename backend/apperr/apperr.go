@@ -0,0 +1,143 @@
+// Package apperr 定义业务错误。
+//
+// 服务层返回 *Error 表示可以告知客户端的错误，错误处理中间件根据错误类型确定 HTTP 状态码，
+// 并以统一的格式返回错误码和错误信息。其他错误一律视为服务器内部错误，原始信息只记录在日志中，不返回给客户端。
+package apperr
+
+import (
+	"blue-note/i18n"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Kind 错误类型，决定 HTTP 状态码
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"   // 请求参数错误
+	KindUnauthorized Kind = "unauthorized" // 未登录或认证失败
+	KindForbidden    Kind = "forbidden"    // 无权限
+	KindNotFound     Kind = "not_found"    // 资源不存在
+	KindConflict     Kind = "conflict"     // 与当前状态冲突，如重复操作
+	KindRateLimited  Kind = "rate_limited" // 请求过于频繁
+	KindTimeout      Kind = "timeout"      // 请求超时
+	KindInternal     Kind = "internal"     // 服务器内部错误
+)
+
+var kindStatus = map[Kind]int{
+	KindValidation:   http.StatusBadRequest,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindRateLimited:  http.StatusTooManyRequests,
+	KindTimeout:      http.StatusGatewayTimeout,
+	KindInternal:     http.StatusInternalServerError,
+}
+
+// Error 业务错误。Code 是稳定的错误码，客户端应根据错误码而不是错误信息判断错误；
+// 错误信息定义在 i18n 语言包中，键为 error.<错误码>，可以包含 fmt 格式的占位符，由 WithArgs 填充
+type Error struct {
+	Kind    Kind
+	Code    int
+	Args    []any
+	Details any
+	cause   error
+}
+
+// registry 已定义的错误，用于检查错误码重复和语言包是否完整
+var registry = map[int]*Error{}
+
+// New 定义业务错误，错误码重复时 panic
+func New(kind Kind, code int) *Error {
+	if _, ok := registry[code]; ok {
+		panic(fmt.Sprintf("apperr: 错误码 %d 重复定义", code))
+	}
+	e := &Error{Kind: kind, Code: code}
+	registry[code] = e
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := e.Text()
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// MessageKey 返回错误信息在语言包中的键
+func (e *Error) MessageKey() string {
+	return fmt.Sprintf("error.%d", e.Code)
+}
+
+// Text 返回默认语言的错误信息
+func (e *Error) Text() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize 返回指定语言的错误信息
+func (e *Error) Localize(lang string) string {
+	return i18n.T(lang, e.MessageKey(), e.Args...)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同即认为是同一个错误，errors.Is 可以匹配带参数或包装了原始错误的副本
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Status 返回错误对应的 HTTP 状态码
+func (e *Error) Status() int {
+	if status, ok := kindStatus[e.Kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// WithArgs 返回填充了错误信息参数的副本
+func (e *Error) WithArgs(args ...any) *Error {
+	c := *e
+	c.Args = args
+	return &c
+}
+
+// WithDetails 返回带有详细信息的副本，详细信息会返回给客户端，实现 i18n.Localizer 时按请求的语言渲染
+func (e *Error) WithDetails(details any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Wrap 返回包装了原始错误的副本，原始错误只记录在日志中
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.cause = err
+	return &c
+}
+
+// From 将任意错误转换为业务错误：*Error 原样返回，请求超时、记录不存在和无效的 ID 转换为对应的错误，
+// 其他错误视为服务器内部错误
+func From(err error) *Error {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout.Wrap(err)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound.Wrap(err)
+	case errors.Is(err, primitive.ErrInvalidHex):
+		return ErrInvalidID.Wrap(err)
+	}
+	return ErrInternal.Wrap(err)
+}
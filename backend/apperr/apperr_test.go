@@ -0,0 +1,87 @@
+package apperr
+
+import (
+	"blue-note/i18n"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus int
+	}{
+		{name: "业务错误", err: ErrPostNotFound, wantCode: 40402, wantStatus: http.StatusNotFound},
+		{name: "包装的业务错误", err: fmt.Errorf("删除笔记: %w", ErrPostDeleteForbidden), wantCode: 40303, wantStatus: http.StatusForbidden},
+		{name: "记录不存在", err: mongo.ErrNoDocuments, wantCode: 40400, wantStatus: http.StatusNotFound},
+		{name: "超时", err: fmt.Errorf("查询: %w", context.DeadlineExceeded), wantCode: 50400, wantStatus: http.StatusGatewayTimeout},
+		{name: "未知错误", err: errors.New("connection refused"), wantCode: 50001, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+			if e.Code != tt.wantCode || e.Status() != tt.wantStatus {
+				t.Errorf("From() = %d/%d, 期望 %d/%d", e.Code, e.Status(), tt.wantCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIs(t *testing.T) {
+	cause := errors.New("原始错误")
+	err := ErrTagNameTaken.WithArgs("go", "golang").Wrap(cause)
+
+	if !errors.Is(err, ErrTagNameTaken) {
+		t.Error("附加参数后应仍匹配原错误")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("应能匹配包装的原始错误")
+	}
+	if errors.Is(err, ErrTagNotFound) {
+		t.Error("不应匹配其他错误码")
+	}
+	if ErrTagNameTaken.Args != nil {
+		t.Error("WithArgs 不应修改原错误")
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		err  *Error
+		want string
+	}{
+		{name: "中文", lang: i18n.ZhCN, err: ErrPostNotFound, want: "笔记不存在"},
+		{name: "英文", lang: i18n.EnUS, err: ErrPostNotFound, want: "Post not found"},
+		{name: "不支持的语言", lang: "ja-JP", err: ErrPostNotFound, want: "笔记不存在"},
+		{name: "带参数", lang: i18n.EnUS, err: ErrFileTooLarge.WithArgs(10), want: "File size exceeds the limit (10MB)"},
+		{name: "中文带参数", lang: i18n.ZhCN, err: ErrFileTooLarge.WithArgs(10), want: "文件大小超过限制(10MB)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Localize(tt.lang); got != tt.want {
+				t.Errorf("Localize() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessagesComplete(t *testing.T) {
+	for code, e := range registry {
+		for _, lang := range i18n.Languages() {
+			if _, ok := i18n.Lookup(lang, e.MessageKey()); !ok {
+				t.Errorf("语言包 %s 缺少错误码 %d 的错误信息", lang, code)
+			}
+		}
+	}
+}
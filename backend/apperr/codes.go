@@ -0,0 +1,92 @@
+package apperr
+
+// 错误码由 HTTP 状态码和两位序号组成，新增错误时在对应分组末尾追加，已发布的错误码不要修改含义。
+// 新增错误码时需要在 i18n 的每个语言包中添加 error.<错误码> 的翻译
+
+// 400 请求参数错误
+var (
+	ErrInvalidID             = New(KindValidation, 40001)
+	ErrInvalidParams         = New(KindValidation, 40003)
+	ErrFileTooLarge          = New(KindValidation, 40004)
+	ErrUnsupportedFormat     = New(KindValidation, 40005)
+	ErrUploadInvalid         = New(KindValidation, 40006)
+	ErrReadFile              = New(KindValidation, 40007)
+	ErrProcessFile           = New(KindValidation, 40008)
+	ErrFileMissing           = New(KindValidation, 40009)
+	ErrCaptcha               = New(KindValidation, 40010)
+	ErrInvalidCursor         = New(KindValidation, 40011)
+	ErrCursorExpired         = New(KindValidation, 40012)
+	ErrPasswordTooShort      = New(KindValidation, 40013)
+	ErrWrongPassword         = New(KindValidation, 40014)
+	ErrBlockSelf             = New(KindValidation, 40015)
+	ErrMuteSelf              = New(KindValidation, 40016)
+	ErrReportSelf            = New(KindValidation, 40017)
+	ErrMergeIntoSelf         = New(KindValidation, 40018)
+	ErrEmptyTagName          = New(KindValidation, 40019)
+	ErrInvalidSettings       = New(KindValidation, 40020)
+	ErrUnsupportedReportType = New(KindValidation, 40021)
+)
+
+// 401 未登录或认证失败
+var (
+	ErrUnauthorized = New(KindUnauthorized, 40100)
+	ErrLoginFailed  = New(KindUnauthorized, 40101)
+	ErrTokenMissing = New(KindUnauthorized, 40102)
+	ErrTokenFormat  = New(KindUnauthorized, 40103)
+	ErrTokenExpired = New(KindUnauthorized, 40104)
+	ErrTokenInvalid = New(KindUnauthorized, 40105)
+)
+
+// 403 无权限
+var (
+	ErrForbidden              = New(KindForbidden, 40300)
+	ErrAnalyticsForbidden     = New(KindForbidden, 40301)
+	ErrPostUpdateForbidden    = New(KindForbidden, 40302)
+	ErrPostDeleteForbidden    = New(KindForbidden, 40303)
+	ErrCommentDeleteForbidden = New(KindForbidden, 40304)
+	ErrBlockedByAuthor        = New(KindForbidden, 40305)
+	ErrBlockedByUser          = New(KindForbidden, 40306)
+	ErrAccountBanned          = New(KindForbidden, 40307)
+	ErrUserHidden             = New(KindForbidden, 40308)
+)
+
+// 404 资源不存在
+var (
+	ErrNotFound             = New(KindNotFound, 40400)
+	ErrUserNotFound         = New(KindNotFound, 40401)
+	ErrPostNotFound         = New(KindNotFound, 40402)
+	ErrTagNotFound          = New(KindNotFound, 40403)
+	ErrDraftNotFound        = New(KindNotFound, 40404)
+	ErrReportNotFound       = New(KindNotFound, 40405)
+	ErrReportTargetNotFound = New(KindNotFound, 40406)
+	ErrLikeNotFound         = New(KindNotFound, 40407)
+	ErrFileNotFound         = New(KindNotFound, 40408)
+	ErrCommentNotFound      = New(KindNotFound, 40409)
+)
+
+// 409 与当前状态冲突
+var (
+	ErrConflict         = New(KindConflict, 40900)
+	ErrSettingsConflict = New(KindConflict, 40901)
+	ErrReconcileRunning = New(KindConflict, 40902)
+	ErrUsernameTaken    = New(KindConflict, 40903)
+	ErrAlreadyLiked     = New(KindConflict, 40904)
+	ErrAlreadyBlocked   = New(KindConflict, 40905)
+	ErrNotBlocked       = New(KindConflict, 40906)
+	ErrAlreadyMuted     = New(KindConflict, 40907)
+	ErrNotMuted         = New(KindConflict, 40908)
+	ErrTagFollowed      = New(KindConflict, 40909)
+	ErrTagNotFollowed   = New(KindConflict, 40910)
+	ErrAlreadyReported  = New(KindConflict, 40911)
+	ErrReportHandled    = New(KindConflict, 40912)
+	ErrTagNameTaken     = New(KindConflict, 40913)
+	ErrUnblockFirst     = New(KindConflict, 40914)
+)
+
+// 429、500、504
+var (
+	ErrRateLimited = New(KindRateLimited, 42900)
+	ErrInternal    = New(KindInternal, 50001)
+	ErrReconcile   = New(KindInternal, 50002)
+	ErrTimeout     = New(KindTimeout, 50400)
+)
@@ -0,0 +1,17 @@
+// Package cache 提供缓存后端（进程内 LRU 和 Redis）以及带防击穿保护的读取
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache 缓存后端，值为编码后的字节
+type Cache interface {
+	// Get 读取缓存，不存在或已过期时 ok 为 false
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set 写入缓存，ttl 为 0 表示不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete 删除缓存，键不存在时不报错
+	Delete(ctx context.Context, keys ...string) error
+}
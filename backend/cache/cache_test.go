@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"blue-note/redis"
+	"blue-note/redis/redistest"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// backends 返回 LRU 和 Redis 两种缓存后端
+func backends(t *testing.T) map[string]Cache {
+	t.Helper()
+
+	server, err := redistest.NewServer()
+	if err != nil {
+		t.Fatalf("启动 Redis 测试服务失败: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	client, err := redis.NewClient(server.URI(), "")
+	if err != nil {
+		t.Fatalf("创建 Redis 客户端失败: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]Cache{"lru": NewLRU(100), "redis": NewRedis(client, "test:")}
+}
+
+func TestCacheBackends(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, ok, err := c.Get(ctx, "missing"); ok || err != nil {
+				t.Errorf("读取不存在的键 ok=%v err=%v", ok, err)
+			}
+
+			c.Set(ctx, "a", []byte("1"), 0)
+			c.Set(ctx, "b", []byte("2"), time.Millisecond)
+			if value, ok, _ := c.Get(ctx, "a"); !ok || string(value) != "1" {
+				t.Errorf("读取 a = %q ok=%v", value, ok)
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			if _, ok, _ := c.Get(ctx, "b"); ok {
+				t.Error("b 应已过期")
+			}
+
+			if err := c.Delete(ctx, "a", "missing"); err != nil {
+				t.Fatalf("删除失败: %v", err)
+			}
+			if _, ok, _ := c.Get(ctx, "a"); ok {
+				t.Error("a 应已删除")
+			}
+		})
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b 最久未使用，应被淘汰")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Errorf("%s 不应被淘汰", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, 期望 2", c.Len())
+	}
+}
+
+type item struct {
+	Name   string
+	Hidden bool `json:"-"`
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			g := NewGroup(c)
+			var loads atomic.Int32
+			load := func(ctx context.Context) (*item, error) {
+				loads.Add(1)
+				time.Sleep(20 * time.Millisecond)
+				return &item{Name: "post", Hidden: true}, nil
+			}
+
+			// 并发未命中只回源一次
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := Fetch(ctx, g, "post:1", time.Minute, load); err != nil {
+						t.Errorf("Fetch 失败: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+			if n := loads.Load(); n != 1 {
+				t.Errorf("回源 %d 次, 期望 1", n)
+			}
+
+			got, err := Fetch(ctx, g, "post:1", time.Minute, load)
+			if err != nil || got.Name != "post" || !got.Hidden {
+				t.Errorf("命中缓存 = %+v, err=%v", got, err)
+			}
+			if n := loads.Load(); n != 1 {
+				t.Errorf("命中缓存后回源 %d 次, 期望 1", n)
+			}
+
+			g.Delete(ctx, "post:1")
+			Fetch(ctx, g, "post:1", time.Minute, load)
+			if n := loads.Load(); n != 2 {
+				t.Errorf("删除后回源 %d 次, 期望 2", n)
+			}
+		})
+	}
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(NewLRU(10))
+	errLoad := errors.New("load failed")
+
+	calls := 0
+	load := func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errLoad
+		}
+		return 42, nil
+	}
+
+	if _, err := Fetch(ctx, g, "k", time.Minute, load); err != errLoad {
+		t.Fatalf("err = %v, 期望 %v", err, errLoad)
+	}
+	if got, err := Fetch(ctx, g, "k", time.Minute, load); err != nil || got != 42 {
+		t.Errorf("Fetch = %d, %v, 期望 42", got, err)
+	}
+
+	var nilGroup *Group
+	if got, _ := Fetch(ctx, nilGroup, "k", time.Minute, load); got != 42 || calls != 3 {
+		t.Errorf("nil Group 应直接回源, got=%d calls=%d", got, calls)
+	}
+}
+
+func TestFetchCallerCanceled(t *testing.T) {
+	g := NewGroup(NewLRU(10))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loadErr atomic.Value
+
+	load := func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		// 发起回源的请求已取消，回源的 ctx 不应随之取消
+		loadErr.Store(fmt.Sprint(ctx.Err()))
+		return 42, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := Fetch(ctx, g, "k", time.Minute, load)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan int, 1)
+	go func() {
+		got, _ := Fetch(context.Background(), g, "k", time.Minute, load)
+		second <- got
+	}()
+
+	// 取消的请求立即返回，不等待回源
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, 期望 %v", err, context.Canceled)
+	}
+
+	close(release)
+	if got := <-second; got != 42 {
+		t.Errorf("等待中的请求 Fetch = %d, 期望 42", got)
+	}
+	if err := loadErr.Load(); err != "<nil>" {
+		t.Errorf("回源的 ctx.Err() = %v, 期望 nil", err)
+	}
+}
+
+func TestDeleteDuringFetch(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(NewLRU(10))
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// 回源读到修改前的数据后，修改方删除缓存，回源才完成
+	stale := make(chan string, 1)
+	go func() {
+		got, _ := Fetch(ctx, g, "profile:alice", time.Minute, func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "未封禁", nil
+		})
+		stale <- got
+	}()
+	<-started
+	g.Delete(ctx, "profile:alice")
+	close(release)
+
+	// 等待中的请求仍拿到回源结果，但结果不写入缓存
+	if got := <-stale; got != "未封禁" {
+		t.Errorf("回源结果 = %q, 期望 %q", got, "未封禁")
+	}
+	got, err := Fetch(ctx, g, "profile:alice", time.Minute, func(ctx context.Context) (string, error) {
+		return "已封禁", nil
+	})
+	if err != nil || got != "已封禁" {
+		t.Errorf("删除后 Fetch = %q, %v, 期望重新回源得到 %q", got, err, "已封禁")
+	}
+	if len(g.loads) != 0 {
+		t.Errorf("回源结束后应清除记录, 剩余 %d", len(g.loads))
+	}
+}
+
+func TestFetchLoadPanic(t *testing.T) {
+	g := NewGroup(NewLRU(10))
+	_, err := Fetch(context.Background(), g, "k", time.Minute, func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Error("回源 panic 应返回错误")
+	}
+}
+
+func TestGeneration(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(NewLRU(10))
+
+	first := g.Generation(ctx, "feed:gen:alice", time.Hour)
+	if again := g.Generation(ctx, "feed:gen:alice", time.Hour); again != first {
+		t.Errorf("版本不应变化: %s != %s", again, first)
+	}
+	g.Delete(ctx, "feed:gen:alice")
+	if next := g.Generation(ctx, "feed:gen:alice", time.Hour); next == first {
+		t.Error("删除版本键后应生成新版本")
+	}
+}
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/sync/singleflight"
+)
+
+// jitter TTL 的随机浮动比例，避免同时写入的缓存同时过期
+const jitter = 0.1
+
+// loadTimeout 合并后的回源的超时时间。回源由多个请求共享，不随发起它的请求取消
+const loadTimeout = 10 * time.Second
+
+// Group 在 Cache 之上提供防击穿的读取：缓存未命中时，同一进程内相同键的并发请求只回源一次，
+// 其余请求等待并共享结果。Group 为 nil 时不使用缓存，直接回源
+type Group struct {
+	cache  Cache
+	flight singleflight.Group
+
+	mu    sync.Mutex
+	loads map[string]*loadState // 进行中的回源，只保存有回源的键
+}
+
+// loadState 一个键上进行中的回源。Delete 时递增 version，
+// 回源完成时版本已变化说明结果可能是删除前的旧数据，不再写入缓存
+type loadState struct {
+	count   int
+	version uint64
+}
+
+// NewGroup 创建缓存读取组
+func NewGroup(cache Cache) *Group {
+	return &Group{cache: cache, loads: make(map[string]*loadState)}
+}
+
+// wrapper 值使用 BSON 编码，保留 json:"-" 的字段；BSON 的顶层必须是文档
+type wrapper[T any] struct {
+	Value T `bson:"v"`
+}
+
+// Fetch 读取缓存，未命中时调用 load 回源并写入缓存。回源出错时不写入缓存；
+// 缓存后端出错时记录日志并直接回源，不影响请求。ttl 不大于 0 时不使用缓存。
+// 并发请求共享的回源使用独立的超时，某个请求的 ctx 取消时只有该请求提前返回，回源继续执行
+func Fetch[T any](ctx context.Context, g *Group, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	if g == nil || ttl <= 0 {
+		return load(ctx)
+	}
+
+	if data, ok, err := g.cache.Get(ctx, key); err != nil {
+		slog.WarnContext(ctx, "读取缓存失败", "key", key, "error", err)
+	} else if ok {
+		var w wrapper[T]
+		if err := bson.Unmarshal(data, &w); err == nil {
+			return w.Value, nil
+		}
+		slog.WarnContext(ctx, "解析缓存失败", "key", key, "error", err)
+	}
+
+	ch := g.flight.DoChan(key, func() (result interface{}, err error) {
+		// DoChan 在单独的 goroutine 中回源，panic 不会传递给调用方，需要转换为错误
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("缓存回源 panic: %v", r)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		version := g.beginLoad(key)
+		defer g.endLoad(key)
+
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// 回源期间键被删除时，结果可能是修改前读到的，只返回给等待的请求，不写入缓存
+		if g.invalidated(key, version) {
+			return value, nil
+		}
+		if data, err := bson.Marshal(wrapper[T]{Value: value}); err != nil {
+			slog.WarnContext(ctx, "编码缓存失败", "key", key, "error", err)
+		} else if err := g.cache.Set(ctx, key, data, withJitter(ttl)); err != nil {
+			slog.WarnContext(ctx, "写入缓存失败", "key", key, "error", err)
+		} else if g.invalidated(key, version) {
+			// 检查之后、写入完成之前发生的删除可能先于写入执行，写入后再检查一次并补删
+			if err := g.cache.Delete(ctx, key); err != nil {
+				slog.WarnContext(ctx, "删除缓存失败", "key", key, "error", err)
+			}
+		}
+		return value, nil
+	})
+
+	var zero T
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Delete 删除缓存，出错时只记录日志
+func (g *Group) Delete(ctx context.Context, keys ...string) {
+	if g == nil || len(keys) == 0 {
+		return
+	}
+	// 之后的请求不再等待删除前开始的回源，避免读到修改前的数据；
+	// 删除前开始的回源完成后也不再把结果写入缓存
+	g.mu.Lock()
+	for _, key := range keys {
+		g.flight.Forget(key)
+		if state, ok := g.loads[key]; ok {
+			state.version++
+		}
+	}
+	g.mu.Unlock()
+	if err := g.cache.Delete(ctx, keys...); err != nil {
+		slog.WarnContext(ctx, "删除缓存失败", "keys", keys, "error", err)
+	}
+}
+
+// beginLoad 登记一次回源，返回键的当前版本
+func (g *Group) beginLoad(key string) uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.loads == nil {
+		g.loads = make(map[string]*loadState)
+	}
+	state, ok := g.loads[key]
+	if !ok {
+		state = &loadState{}
+		g.loads[key] = state
+	}
+	state.count++
+	return state.version
+}
+
+// endLoad 回源结束，键上没有其他回源时移除记录
+func (g *Group) endLoad(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if state := g.loads[key]; state != nil {
+		if state.count--; state.count == 0 {
+			delete(g.loads, key)
+		}
+	}
+}
+
+// invalidated 判断回源开始后键是否被本进程的 Delete 删除过
+func (g *Group) invalidated(key string, version uint64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loads[key].version != version
+}
+
+// Generation 返回命名空间的当前版本，作为该命名空间下缓存键的一部分。
+// 通过 Delete 删除版本键即可让命名空间下的所有缓存失效，无需逐个删除
+func (g *Group) Generation(ctx context.Context, key string, ttl time.Duration) string {
+	if g == nil {
+		return ""
+	}
+	if data, ok, err := g.cache.Get(ctx, key); err == nil && ok {
+		return string(data)
+	}
+
+	generation := primitive.NewObjectID().Hex()
+	if err := g.cache.Set(ctx, key, []byte(generation), ttl); err != nil {
+		slog.WarnContext(ctx, "写入缓存失败", "key", key, "error", err)
+	}
+	return generation
+}
+
+func withJitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	delta := float64(ttl) * jitter
+	return ttl + time.Duration(delta*(2*rand.Float64()-1))
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"blue-note/redis"
+	"context"
+	"time"
+)
+
+// Redis 保存在 Redis 中的缓存，多实例共享，修改后所有实例立即读到新数据
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis 创建 Redis 缓存，prefix 为键的前缀
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.client.Do(ctx, "GET", c.prefix+key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	return value, ok, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []interface{}{"SET", c.prefix + key, value}
+	if ms := ttl.Milliseconds(); ms > 0 {
+		args = append(args, "PX", ms)
+	}
+	_, err := c.client.Do(ctx, args...)
+	return err
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, c.prefix+key)
+	}
+	_, err := c.client.Do(ctx, args...)
+	return err
+}
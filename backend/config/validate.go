@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Validate 校验配置，返回所有不合法的配置项
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key string, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.MongoDB.URI != "", "mongodb.uri", "不能为空，请通过环境变量 %s 或密钥文件设置", envName("mongodb.uri"))
+	if c.MongoDB.URI != "" {
+		check(strings.HasPrefix(c.MongoDB.URI, "mongodb://") || strings.HasPrefix(c.MongoDB.URI, "mongodb+srv://"),
+			"mongodb.uri", "必须以 mongodb:// 或 mongodb+srv:// 开头")
+	}
+	check(c.MongoDB.Database != "", "mongodb.database", "不能为空")
+
+	check(c.JWT.Secret != "", "jwt.secret", "不能为空，请通过环境变量 %s 或密钥文件设置", envName("jwt.secret"))
+	if c.JWT.Secret != "" && !c.isLocal() {
+		check(len(c.JWT.Secret) >= 16, "jwt.secret", "长度不能少于 16 个字符")
+	}
+	check(c.JWT.Expire > 0, "jwt.expire", "必须大于 0")
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "必须在 1-65535 之间")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "server.trustedproxies", "%q 不是合法的 IP 或 CIDR", proxy)
+	}
+
+	check((c.ObjectStorage.AccessKey == "") == (c.ObjectStorage.SecretKey == ""),
+		"objectstorage", "accesskey 和 secretkey 必须同时设置")
+
+	check(c.Report.HideThreshold > 0, "report.hidethreshold", "必须大于 0")
+	check(c.Feed.Strategy == "read" || c.Feed.Strategy == "write" || c.Feed.Strategy == "hybrid",
+		"feed.strategy", "必须是 read、write 或 hybrid")
+	check(c.Feed.FanoutMaxFans >= 0, "feed.fanoutmaxfans", "不能为负数")
+	check(c.Feed.BackfillLimit >= 0, "feed.backfilllimit", "不能为负数")
+	check(c.Discover.ExploreRatio >= 0 && c.Discover.ExploreRatio <= 1, "discover.exploreratio", "必须在 0-1 之间")
+	check(c.Reconcile.Interval >= 0, "reconcile.interval", "不能为负数")
+
+	check(c.Timeout.Default >= 0, "timeout.default", "不能为负数")
+	for route, seconds := range c.Timeout.Routes {
+		check(seconds > 0, "timeout.routes", "%q 的超时时间必须大于 0", route)
+		check(len(strings.Fields(route)) == 2, "timeout.routes", "%q 格式应为 \"方法 路由\"", route)
+	}
+	check(c.Shutdown.Timeout > 0, "shutdown.timeout", "必须大于 0")
+	check(c.Health.Timeout > 0, "health.timeout", "必须大于 0")
+	check(c.Health.CacheTTL >= 0, "health.cachettl", "不能为负数")
+
+	check(c.Cache.Backend == "memory" || c.Cache.Backend == "redis" || c.Cache.Backend == "none", "cache.backend", "必须是 memory、redis 或 none")
+	if c.Cache.Backend == "memory" {
+		check(c.Cache.Capacity > 0, "cache.capacity", "必须大于 0")
+	}
+	if c.Cache.Backend == "redis" {
+		check(c.Redis.URI != "", "redis.uri", "cache.backend 为 redis 时不能为空，请通过环境变量 %s 或密钥文件设置", envName("redis.uri"))
+	}
+	check(c.Cache.PostTTL >= 0 && c.Cache.ProfileTTL >= 0 && c.Cache.DimensionsTTL >= 0 && c.Cache.FeedTTL >= 0,
+		"cache", "缓存时间不能为负数")
+
+	check(c.RateLimit.Backend == "memory" || c.RateLimit.Backend == "redis", "ratelimit.backend", "必须是 memory 或 redis")
+	if c.RateLimit.Backend == "redis" {
+		check(c.Redis.URI != "", "redis.uri", "ratelimit.backend 为 redis 时不能为空，请通过环境变量 %s 或密钥文件设置", envName("redis.uri"))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		check(c.Tracing.Endpoint != "", "tracing.endpoint", "tracing.exporter 为 otlp 时不能为空")
+	default:
+		check(false, "tracing.exporter", "必须是 none、stdout 或 otlp")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleratio", "必须在 0 到 1 之间")
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level", "必须是 debug、info、warn 或 error")
+	}
+	check(c.Log.Format == "text" || c.Log.Format == "json", "log.format", "必须是 text 或 json")
+
+	return errors.Join(errs...)
+}
+
+// isLocal 是否为本地开发环境
+func (c *Config) isLocal() bool {
+	return c.Environment == "local" || c.Environment == "development"
+}
+
+// envName 返回配置项对应的环境变量名
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
@@ -1,8 +1,8 @@
 package controller
 
 import (
+	"blue-note/config"
 	"blue-note/service"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -21,20 +21,13 @@ func NewAdminController(adminService *service.AdminService, objectStorageService
 }
 
 func (c *AdminController) GetStatistics(ctx *gin.Context) {
-	stats, err := c.adminService.GetStatistics()
+	stats, err := c.adminService.GetStatistics(ctx.Request.Context())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取统计数据失败",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    stats,
-	})
+	success(ctx, "common.success", stats)
 }
 
 func (c *AdminController) GetPendingPosts(ctx *gin.Context) {
@@ -51,18 +44,16 @@ func (c *AdminController) GetPendingPosts(ctx *gin.Context) {
 		limit = 10
 	}
 
-	result, err := c.adminService.GetPendingPosts(page, limit)
+	result, err := c.adminService.GetPendingPosts(ctx.Request.Context(), page, limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取待审核帖子失败",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    result,
-	})
-} 
\ No newline at end of file
+	success(ctx, "common.success", result)
+} 
+
+// GetConfig 获取当前生效的配置，密码和密钥等敏感信息会被隐藏
+func (c *AdminController) GetConfig(ctx *gin.Context) {
+	success(ctx, "common.success", config.GetConfig().Redacted())
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+	analyticsService *service.AnalyticsService
+	creatorService   *service.CreatorService
+}
+
+func NewAnalyticsController(analyticsService *service.AnalyticsService, creatorService *service.CreatorService) *AnalyticsController {
+	return &AnalyticsController{
+		analyticsService: analyticsService,
+		creatorService:   creatorService,
+	}
+}
+
+// GetPostAnalytics 获取笔记的数据分析（作者本人或管理员）
+func (c *AnalyticsController) GetPostAnalytics(ctx *gin.Context) {
+	var query model.AnalyticsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.analyticsService.GetPostAnalytics(ctx.Request.Context(), 
+		ctx.Param("postId"),
+		ctx.GetString("userId"),
+		ctx.GetString("role"),
+		&query,
+	)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// GetCreatorDashboard 获取当前用户的创作者数据看板
+func (c *AnalyticsController) GetCreatorDashboard(ctx *gin.Context) {
+	var query model.AnalyticsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.creatorService.GetDashboard(ctx.Request.Context(), ctx.GetString("userId"), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"blue-note/apperr"
 	"blue-note/model"
 	"blue-note/service"
 	"blue-note/util"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,13 +22,11 @@ func NewAuthController(authService *service.AuthService) *AuthController {
 func (c *AuthController) GetCaptcha(ctx *gin.Context) {
 	captchaID, captchaImage, err := c.authService.GenerateCaptcha()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "生成验证码失败",
-		})
+		fail(ctx, fmt.Errorf("生成验证码失败: %w", err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
+	success(ctx, "common.success", gin.H{
 		"captcha_id":    captchaID,
 		"captcha_image": captchaImage,
 	})
@@ -36,48 +36,34 @@ func (c *AuthController) GetCaptcha(ctx *gin.Context) {
 func (c *AuthController) Login(ctx *gin.Context) {
 	var req model.LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    40003,
-			"message": "请求参数错误",
-			"error":   err.Error(),
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
-	user, token, expiresAt, isNewUser, err := c.authService.LoginOrRegister(&req)
+	user, token, expiresAt, isNewUser, err := c.authService.LoginOrRegister(ctx.Request.Context(), &req)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"code":    40101,
-			"message": "用户名或密码错误",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
 	// 根据是否为新用户确定状态码和消息
 	statusCode := http.StatusOK
-	responseCode := 200
-	message := "登录成功"
+	message := "auth.login_success"
 
 	if isNewUser {
 		statusCode = http.StatusCreated
-		responseCode = 201
-		message = "注册成功"
+		message = "auth.register_success"
 	}
 
 	// 返回用户信息和token，添加头像、昵称和过期时间
-	ctx.JSON(statusCode, gin.H{
-		"code":    responseCode,
-		"message": message,
-		"data": gin.H{
-			"token":     token,
-			"expiresAt": expiresAt,
-			"user_id":   user.ID.Hex(),
-			"username":  user.Username,
-			"role":      user.Role,
-			"avatar":    user.Avatar,
-			"nickname":  user.Nickname,
-		},
+	successWithStatus(ctx, statusCode, message, gin.H{
+		"token":     token,
+		"expiresAt": expiresAt,
+		"user_id":   user.ID.Hex(),
+		"username":  user.Username,
+		"role":      user.Role,
+		"avatar":    user.Avatar,
+		"nickname":  user.Nickname,
 	})
 }
 
@@ -100,36 +86,22 @@ func (c *AuthController) ChangePassword(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    40003,
-			"message": "请求参数错误",
-			"error":   err.Error(),
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
 	// 验证验证码
 	if !util.VerifyCaptcha(req.CaptchaID, req.CaptchaCode) {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    40003,
-			"message": "验证码错误",
-		})
+		fail(ctx, apperr.ErrCaptcha)
 		return
 	}
 
 	// 调用服务层修改密码
-	err := c.authService.ChangePassword(req.Username, req.OldPassword, req.NewPassword)
+	err := c.authService.ChangePassword(ctx.Request.Context(), req.Username, req.OldPassword, req.NewPassword)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    40003,
-			"message": err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "密码修改成功",
-		"data":    nil,
-	})
+	success(ctx, "auth.password_changed", nil)
 }
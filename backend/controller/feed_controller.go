@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedController struct {
+	feedService     *service.FeedService
+	discoverService *service.DiscoverService
+}
+
+func NewFeedController(feedService *service.FeedService, discoverService *service.DiscoverService) *FeedController {
+	return &FeedController{
+		feedService:     feedService,
+		discoverService: discoverService,
+	}
+}
+
+// GetFollowingFeed 获取关注的人发布的笔记（使用游标分页）
+func (c *FeedController) GetFollowingFeed(ctx *gin.Context) {
+	var query model.FeedQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	userID := ctx.GetString("userId")
+
+	result, err := c.feedService.GetFollowingFeed(ctx.Request.Context(), userID, &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// GetDiscoverFeed 获取发现页推荐笔记（使用游标分页，未登录也可访问）
+func (c *FeedController) GetDiscoverFeed(ctx *gin.Context) {
+	var query model.DiscoverQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	viewerID := ctx.GetString("userId")
+
+	result, err := c.discoverService.GetDiscoverFeed(ctx.Request.Context(), viewerID, &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
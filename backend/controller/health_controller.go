@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"blue-note/health"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	registry *health.Registry
+}
+
+func NewHealthController(registry *health.Registry) *HealthController {
+	return &HealthController{registry: registry}
+}
+
+// Live 存活检查，只要进程能处理请求就返回 200，不检查外部依赖，
+// 避免数据库故障时所有实例被重启
+func (c *HealthController) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": health.StatusUp,
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready 就绪检查，关键组件不可用或服务正在关闭时返回 503，
+// 部分功能降级（如对象存储使用本地存储）时仍返回 200，状态为 degraded
+func (c *HealthController) Ready(ctx *gin.Context) {
+	report := c.registry.Check(ctx.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
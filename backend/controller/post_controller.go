@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"blue-note/apperr"
 	"blue-note/model"
 	"blue-note/service"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -12,19 +15,17 @@ import (
 
 type PostController struct {
 	postService *service.PostService
+	viewService *service.ViewService
 }
 
-func NewPostController(postService *service.PostService) *PostController {
-	return &PostController{postService: postService}
+func NewPostController(postService *service.PostService, viewService *service.ViewService) *PostController {
+	return &PostController{postService: postService, viewService: viewService}
 }
 
 func (c *PostController) CreatePost(ctx *gin.Context) {
 	var req model.CreatePostRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "请求参数错误",
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
@@ -36,10 +37,7 @@ func (c *PostController) CreatePost(ctx *gin.Context) {
 
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "用户ID格式错误",
-		})
+		fail(ctx, apperr.ErrUnauthorized.Wrap(err))
 		return
 	}
 
@@ -50,20 +48,13 @@ func (c *PostController) CreatePost(ctx *gin.Context) {
 		Avatar:   avatar,
 	}
 
-	post, err := c.postService.CreatePost(user, &req)
+	post, err := c.postService.CreatePost(ctx.Request.Context(), user, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "创建帖子失败",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    post,
-	})
+	success(ctx, "common.success", post)
 }
 
 func (c *PostController) GetPostList(ctx *gin.Context) {
@@ -75,142 +66,105 @@ func (c *PostController) GetPostList(ctx *gin.Context) {
 
 	var query model.PostQuery
 	if err := ctx.ShouldBindQuery(&query); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "请求参数错误",
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
-	result, err := c.postService.GetPostList(&query)
+	result, err := c.postService.GetPostList(ctx.Request.Context(), &query, ctx.GetString("userId"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取帖子列表失败",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    result,
-	})
+	success(ctx, "common.success", result)
 }
 
 // GetPostsWithCursor 获取帖子列表（使用游标分页）
 func (c *PostController) GetPostsWithCursor(ctx *gin.Context) {
 	var query model.CursorQuery
 	if err := ctx.ShouldBindQuery(&query); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "请求参数错误",
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
-	result, err := c.postService.GetPostListWithCursor(&query)
+	result, err := c.postService.GetPostListWithCursor(ctx.Request.Context(), &query, ctx.GetString("userId"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取帖子列表失败",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    result,
-	})
+	success(ctx, "common.success", result)
 }
 
 func (c *PostController) GetPostDetail(ctx *gin.Context) {
 	postID := ctx.Param("postId")
-	post, err := c.postService.GetPostDetail(postID)
+	post, err := c.postService.GetPostDetail(ctx.Request.Context(), postID, ctx.GetString("userId"), ctx.GetString("role"))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"code":    404,
-			"message": "帖子不存在",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    post,
-	})
+	// 记录浏览
+	if c.viewService != nil {
+		viewerKey := service.ViewerFingerprint(ctx.GetString("userId"), ctx.ClientIP(), ctx.Request.UserAgent())
+		c.viewService.RecordView(post, viewerKey)
+	}
+
+	success(ctx, "common.success", post)
 }
 
 func (c *PostController) UpdatePost(ctx *gin.Context) {
 	postID := ctx.Param("postId")
 	var req model.UpdatePostRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "请求参数错误",
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
 	userID := ctx.GetString("userId")
-	post, err := c.postService.UpdatePost(postID, userID, &req)
+	post, err := c.postService.UpdatePost(ctx.Request.Context(), postID, userID, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data":    post,
-	})
+	success(ctx, "common.success", post)
 }
 
 func (c *PostController) DeletePost(ctx *gin.Context) {
 	postID := ctx.Param("postId")
 	userID := ctx.GetString("userId")
 
-	err := c.postService.DeletePost(postID, userID)
+	err := c.postService.DeletePost(ctx.Request.Context(), postID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-	})
+	success(ctx, "common.success", nil)
 }
 
 // 获取帖子评论列表
 func (c *PostController) GetPostComments(ctx *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(ctx.Param("postId"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的帖子ID"})
+		fail(ctx, err)
 		return
 	}
 
 	var query model.CommentQuery
 	if err := ctx.ShouldBindQuery(&query); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的查询参数"})
+		bindFailed(ctx, err)
 		return
 	}
 
-	comments, total, err := c.postService.GetPostComments(postID, &query)
+	comments, total, err := c.postService.GetPostComments(ctx.Request.Context(), postID, &query, ctx.GetString("userId"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
+	success(ctx, "common.success", gin.H{
 		"comments": comments,
 		"total":    total,
 		"page":     query.Page,
@@ -224,86 +178,78 @@ func (c *PostController) GetPostComments(ctx *gin.Context) {
 func (c *PostController) CreateComment(ctx *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(ctx.Param("postId"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的帖子ID"})
+		fail(ctx, err)
 		return
 	}
 
 	var req model.CreateCommentRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		bindFailed(ctx, err)
 		return
 	}
 
 	// 从上下文中获取用户ID
-	userID, exists := ctx.Get("userID")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+	userID, err := primitive.ObjectIDFromHex(ctx.GetString("userId"))
+	if err != nil {
+		fail(ctx, apperr.ErrUnauthorized.Wrap(err))
 		return
 	}
 
-	comment, err := c.postService.CreateComment(postID, userID.(primitive.ObjectID), req.Content)
+	comment, err := c.postService.CreateComment(ctx.Request.Context(), postID, userID, req.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "创建评论失败"})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, comment)
+	successWithStatus(ctx, http.StatusCreated, "comment.created", comment)
 }
 
 // 点赞评论
 func (c *PostController) LikeComment(ctx *gin.Context) {
 	commentID, err := primitive.ObjectIDFromHex(ctx.Param("commentId"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		fail(ctx, err)
 		return
 	}
 
 	// 从上下文中获取用户ID
-	userID, exists := ctx.Get("userID")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+	userID, err := primitive.ObjectIDFromHex(ctx.GetString("userId"))
+	if err != nil {
+		fail(ctx, apperr.ErrUnauthorized.Wrap(err))
 		return
 	}
 
-	err = c.postService.LikeComment(commentID, userID.(primitive.ObjectID))
+	err = c.postService.LikeComment(ctx.Request.Context(), commentID, userID)
 	if err != nil {
-		if err.Error() == "已经点赞过了" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "点赞失败"})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "点赞成功"})
+	success(ctx, "comment.liked", nil)
 }
 
 // 取消点赞评论
 func (c *PostController) UnlikeComment(ctx *gin.Context) {
 	commentID, err := primitive.ObjectIDFromHex(ctx.Param("commentId"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		fail(ctx, err)
 		return
 	}
 
 	// 从上下文中获取用户ID
-	userID, exists := ctx.Get("userID")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+	userID, err := primitive.ObjectIDFromHex(ctx.GetString("userId"))
+	if err != nil {
+		fail(ctx, apperr.ErrUnauthorized.Wrap(err))
 		return
 	}
 
-	err = c.postService.UnlikeComment(commentID, userID.(primitive.ObjectID))
+	err = c.postService.UnlikeComment(ctx.Request.Context(), commentID, userID)
 	if err != nil {
-		if err.Error() == "未找到点赞记录" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "取消点赞失败"})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "取消点赞成功"})
+	success(ctx, "comment.unliked", nil)
 }
 
 func (c *PostController) DeleteComment(ctx *gin.Context) {
@@ -311,45 +257,30 @@ func (c *PostController) DeleteComment(ctx *gin.Context) {
 	commentID := ctx.Param("commentId")
 	userID := ctx.GetString("userId")
 
-	err := c.postService.DeleteComment(postID, commentID, userID)
+	err := c.postService.DeleteComment(ctx.Request.Context(), postID, commentID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-	})
+	success(ctx, "common.success", nil)
 }
 
 func (c *PostController) ReviewPost(ctx *gin.Context) {
 	postID := ctx.Param("postId")
 	var req model.ReviewPostRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "请求参数错误",
-		})
+		bindFailed(ctx, err)
 		return
 	}
 
-	err := c.postService.ReviewPost(postID, &req)
+	err := c.postService.ReviewPost(ctx.Request.Context(), postID, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "审核帖子失败",
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-	})
+	success(ctx, "common.success", nil)
 }
 
 // 点赞帖子
@@ -357,19 +288,13 @@ func (c *PostController) LikePost(ctx *gin.Context) {
 	postID := ctx.Param("postId")
 	userID := ctx.GetString("userId")
 
-	err := c.postService.LikePost(postID, userID)
+	state, err := c.postService.LikePost(ctx.Request.Context(), postID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "点赞失败: " + err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-	})
+	success(ctx, "common.success", state)
 }
 
 // 取消点赞
@@ -377,19 +302,13 @@ func (c *PostController) UnlikePost(ctx *gin.Context) {
 	postID := ctx.Param("postId")
 	userID := ctx.GetString("userId")
 
-	err := c.postService.UnlikePost(postID, userID)
+	state, err := c.postService.UnlikePost(ctx.Request.Context(), postID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "取消点赞失败: " + err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-	})
+	success(ctx, "common.success", state)
 }
 
 // 检查是否已点赞
@@ -397,21 +316,14 @@ func (c *PostController) CheckLikeStatus(ctx *gin.Context) {
 	postID := ctx.Param("postId")
 	userID := ctx.GetString("userId")
 
-	hasLiked, err := c.postService.HasLiked(postID, userID)
+	hasLiked, err := c.postService.HasLiked(ctx.Request.Context(), postID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "检查点赞状态失败: " + err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "success",
-		"data": gin.H{
-			"hasLiked": hasLiked,
-		},
+	success(ctx, "common.success", gin.H{
+		"hasLiked": hasLiked,
 	})
 }
 
@@ -421,11 +333,7 @@ func (c *PostController) SaveDraft(ctx *gin.Context) {
 	
 	var req model.CreatePostRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    40003,
-			"message": "请求参数错误",
-			"error":   err.Error(),
-		})
+		bindFailed(ctx, err)
 		return
 	}
 	
@@ -433,21 +341,13 @@ func (c *PostController) SaveDraft(ctx *gin.Context) {
 	draftID := ctx.Query("draftId")
 	
 	// 保存草稿
-	draft, err := c.postService.SaveDraft(userID, &req, draftID)
+	draft, err := c.postService.SaveDraft(ctx.Request.Context(), userID, &req, draftID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "保存草稿失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "草稿保存成功",
-		"data":    draft,
-	})
+	success(ctx, "draft.saved", draft)
 }
 
 // GetUserDrafts 获取用户草稿列表
@@ -458,21 +358,13 @@ func (c *PostController) GetUserDrafts(ctx *gin.Context) {
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
 	
 	// 获取草稿列表
-	result, err := c.postService.GetUserDrafts(userID, page, limit)
+	result, err := c.postService.GetUserDrafts(ctx.Request.Context(), userID, page, limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取草稿列表失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "success",
-		"data":    result,
-	})
+	success(ctx, "common.success", result)
 }
 
 // GetDraftByID 获取草稿详情
@@ -481,21 +373,13 @@ func (c *PostController) GetDraftByID(ctx *gin.Context) {
 	draftID := ctx.Param("draftId")
 	
 	// 获取草稿详情
-	draft, err := c.postService.GetDraftByID(draftID, userID)
+	draft, err := c.postService.GetDraftByID(ctx.Request.Context(), draftID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取草稿详情失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "success",
-		"data":    draft,
-	})
+	success(ctx, "common.success", draft)
 }
 
 // DeleteDraft 删除草稿
@@ -504,20 +388,13 @@ func (c *PostController) DeleteDraft(ctx *gin.Context) {
 	draftID := ctx.Param("draftId")
 	
 	// 删除草稿
-	err := c.postService.DeleteDraft(draftID, userID)
+	err := c.postService.DeleteDraft(ctx.Request.Context(), draftID, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "删除草稿失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "草稿删除成功",
-	})
+	success(ctx, "draft.deleted", nil)
 }
 
 // PublishDraft 发布草稿
@@ -528,32 +405,20 @@ func (c *PostController) PublishDraft(ctx *gin.Context) {
 	var req model.UpdatePostRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		// 如果没有提供更新内容，使用原草稿内容发布
-		if err.Error() == "EOF" {
+		if errors.Is(err, io.EOF) {
 			req = model.UpdatePostRequest{}
 		} else {
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"code":    40003,
-				"message": "请求参数错误",
-				"error":   err.Error(),
-			})
+			bindFailed(ctx, err)
 			return
 		}
 	}
 	
 	// 发布草稿
-	post, err := c.postService.PublishDraft(draftID, userID, &req)
+	post, err := c.postService.PublishDraft(ctx.Request.Context(), draftID, userID, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "发布草稿失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "发布成功",
-		"data":    post,
-	})
+	success(ctx, "draft.published", post)
 }
@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"blue-note/apperr"
 	"blue-note/model"
 	"blue-note/service"
-	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -25,21 +27,13 @@ func (c *ProfileController) GetProfile(ctx *gin.Context) {
 	userID := ctx.Param("userId")
 	currentUserID := ctx.GetString("userId")
 
-	profile, err := c.profileService.GetUserProfile(userID, currentUserID)
+	profile, err := c.profileService.GetUserProfile(ctx.Request.Context(), userID, currentUserID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取用户资料失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "成功",
-		"data":    profile,
-	})
+	success(ctx, "common.success", profile)
 }
 
 // UpdateProfile 更新用户资料（包含头像上传）
@@ -55,48 +49,34 @@ func (c *ProfileController) UpdateProfile(ctx *gin.Context) {
 	userID := ctx.GetString("userId")
 	ctx.Set("userId", userID)
 	
-	// 打印到控制台
-	fmt.Println("==== UpdateProfile 被调用 ====")
-	fmt.Printf("userID: %s\n", userID)
+	// 打印到调试日志
+	reqCtx := ctx.Request.Context()
+	slog.DebugContext(reqCtx, "UpdateProfile 被调用", "user_id", userID)
 	
-	// 打印所有请求参数
-	fmt.Println("请求参数:")
-	for key, values := range ctx.Request.URL.Query() {
-		fmt.Printf("  %s: %v\n", key, values)
-	}
+	// 打印所有请求参数，敏感字段由日志统一隐藏
+	slog.DebugContext(reqCtx, "请求参数", formAttrs(ctx.Request.URL.Query())...)
 	
 	// 打印表单数据
 	if err := ctx.Request.ParseMultipartForm(10 << 20); err == nil {
-		fmt.Println("表单数据:")
-		for key, values := range ctx.Request.Form {
-			fmt.Printf("  %s: %v\n", key, values)
-		}
+		slog.DebugContext(reqCtx, "表单数据", formAttrs(ctx.Request.Form)...)
 	}
 	
 	// 处理表单数据
 	var req model.UpdateProfileRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":    40003,
-			"message": "请求参数错误",
-			"error":   err.Error(),
-		})
+		bindFailed(ctx, err)
 		return
 	}
 	
 	// 处理头像文件上传
 	file, err := ctx.FormFile("avatar")
-	fmt.Printf("头像文件: %v, 错误: %v\n", file, err)
+	slog.DebugContext(reqCtx, "头像文件", "present", file != nil, "error", err)
 	
 	var avatarFile io.Reader
 	if file != nil {
 		f, err := file.Open()
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"code":    40006,
-				"message": "上传文件处理失败",
-				"error":   err.Error(),
-			})
+			fail(ctx, apperr.ErrUploadInvalid.Wrap(err))
 			return
 		}
 		defer f.Close()
@@ -105,51 +85,33 @@ func (c *ProfileController) UpdateProfile(ctx *gin.Context) {
 		buf := make([]byte, 512)
 		n, err := f.Read(buf)
 		if err != nil && err != io.EOF {
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"code":    40007,
-				"message": "读取文件失败",
-				"error":   err.Error(),
-			})
+			fail(ctx, apperr.ErrReadFile.Wrap(err))
 			return
 		}
 		
 		// 重置文件位置
 		_, err = f.Seek(0, io.SeekStart)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"code":    40008,
-				"message": "处理文件失败",
-				"error":   err.Error(),
-			})
+			fail(ctx, apperr.ErrProcessFile.Wrap(err))
 			return
 		}
 		
-		fmt.Printf("文件内容前 %d 字节: %v\n", n, buf[:n])
+		slog.DebugContext(reqCtx, "头像文件内容", "bytes", n, "content_type", http.DetectContentType(buf[:n]))
 		avatarFile = f
 	}
 	
 	// 检查对象存储服务状态
 	isStorageAvailable := c.profileService.IsObjectStorageAvailable()
-	fmt.Printf("对象存储服务状态: %v\n", isStorageAvailable)
+	slog.DebugContext(reqCtx, "对象存储服务状态", "available", isStorageAvailable)
 	
 	// 调用服务更新资料和头像
-	profile, err := c.profileService.UpdateProfileWithAvatar(userID, &req, avatarFile)
+	profile, err := c.profileService.UpdateProfileWithAvatar(ctx.Request.Context(), userID, &req, avatarFile)
 	if err != nil {
-		// 记录错误但返回一个友好的错误信息
-		fmt.Printf("更新用户资料失败: %v\n", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "更新用户资料失败，请稍后再试",
-			"error":   "服务器内部错误",
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "个人资料更新成功",
-		"data":    profile,
-	})
+	success(ctx, "profile.updated", profile)
 }
 
 // FollowUser 关注用户
@@ -157,21 +119,13 @@ func (c *ProfileController) FollowUser(ctx *gin.Context) {
 	userID := ctx.GetString("userId")
 	followingID := ctx.Param("userId")
 	
-	result, err := c.profileService.FollowUser(userID, followingID)
+	result, err := c.profileService.FollowUser(ctx.Request.Context(), userID, followingID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "关注用户失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "关注成功",
-		"data":    result,
-	})
+	success(ctx, "user.followed", result)
 }
 
 // UnfollowUser 取消关注用户
@@ -179,21 +133,13 @@ func (c *ProfileController) UnfollowUser(ctx *gin.Context) {
 	userID := ctx.GetString("userId")
 	followingID := ctx.Param("userId")
 	
-	result, err := c.profileService.UnfollowUser(userID, followingID)
+	result, err := c.profileService.UnfollowUser(ctx.Request.Context(), userID, followingID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "取消关注失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "取消关注成功",
-		"data":    result,
-	})
+	success(ctx, "user.unfollowed", result)
 }
 
 // CheckFollowStatus 检查关注状态
@@ -201,22 +147,14 @@ func (c *ProfileController) CheckFollowStatus(ctx *gin.Context) {
 	userID := ctx.GetString("userId")
 	followingID := ctx.Param("userId")
 	
-	isFollowing, err := c.profileService.CheckFollowStatus(userID, followingID)
+	isFollowing, err := c.profileService.CheckFollowStatus(ctx.Request.Context(), userID, followingID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "检查关注状态失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "成功",
-		"data": gin.H{
-			"isFollowing": isFollowing,
-		},
+	success(ctx, "common.success", gin.H{
+		"isFollowing": isFollowing,
 	})
 }
 
@@ -228,21 +166,13 @@ func (c *ProfileController) GetFollowingList(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
 	
-	result, err := c.profileService.GetFollowingList(userID, currentUserID, page, limit)
+	result, err := c.profileService.GetFollowingList(ctx.Request.Context(), userID, currentUserID, page, limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取关注列表失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "成功",
-		"data":    result,
-	})
+	success(ctx, "common.success", result)
 }
 
 // GetFansList 获取粉丝列表
@@ -253,21 +183,13 @@ func (c *ProfileController) GetFansList(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
 	
-	result, err := c.profileService.GetFansList(userID, currentUserID, page, limit)
+	result, err := c.profileService.GetFansList(ctx.Request.Context(), userID, currentUserID, page, limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取粉丝列表失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "成功",
-		"data":    result,
-	})
+	success(ctx, "common.success", result)
 }
 
 // GetUserLikedPosts 获取用户喜欢的笔记
@@ -277,21 +199,13 @@ func (c *ProfileController) GetUserLikedPosts(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
 	
-	result, err := c.profileService.GetUserLikedPosts(userID, page, limit)
+	result, err := c.profileService.GetUserLikedPosts(ctx.Request.Context(), userID, page, limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取喜欢的笔记失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "成功",
-		"data":    result,
-	})
+	success(ctx, "common.success", result)
 }
 
 // GetUserCollectedPosts 获取用户收藏的笔记
@@ -301,19 +215,103 @@ func (c *ProfileController) GetUserCollectedPosts(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
 	
-	result, err := c.profileService.GetUserCollectedPosts(userID, page, limit)
+	result, err := c.profileService.GetUserCollectedPosts(ctx.Request.Context(), userID, page, limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code":    50001,
-			"message": "获取收藏的笔记失败",
-			"error":   err.Error(),
-		})
+		fail(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "成功",
-		"data":    result,
-	})
-} 
\ No newline at end of file
+	success(ctx, "common.success", result)
+} 
+// BlockUser 拉黑用户
+func (c *ProfileController) BlockUser(ctx *gin.Context) {
+	userID := ctx.GetString("userId")
+	blockedID := ctx.Param("userId")
+
+	if err := c.profileService.BlockUser(ctx.Request.Context(), userID, blockedID); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "user.blocked", nil)
+}
+
+// UnblockUser 取消拉黑
+func (c *ProfileController) UnblockUser(ctx *gin.Context) {
+	userID := ctx.GetString("userId")
+	blockedID := ctx.Param("userId")
+
+	if err := c.profileService.UnblockUser(ctx.Request.Context(), userID, blockedID); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "user.unblocked", nil)
+}
+
+// MuteUser 屏蔽用户
+func (c *ProfileController) MuteUser(ctx *gin.Context) {
+	userID := ctx.GetString("userId")
+	mutedID := ctx.Param("userId")
+
+	if err := c.profileService.MuteUser(ctx.Request.Context(), userID, mutedID); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "user.muted", nil)
+}
+
+// UnmuteUser 取消屏蔽
+func (c *ProfileController) UnmuteUser(ctx *gin.Context) {
+	userID := ctx.GetString("userId")
+	mutedID := ctx.Param("userId")
+
+	if err := c.profileService.UnmuteUser(ctx.Request.Context(), userID, mutedID); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "user.unmuted", nil)
+}
+
+// GetBlockedList 获取当前用户的拉黑列表
+func (c *ProfileController) GetBlockedList(ctx *gin.Context) {
+	userID := ctx.GetString("userId")
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	result, err := c.profileService.GetBlockedList(ctx.Request.Context(), userID, page, limit)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// GetMutedList 获取当前用户的屏蔽列表
+func (c *ProfileController) GetMutedList(ctx *gin.Context) {
+	userID := ctx.GetString("userId")
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	result, err := c.profileService.GetMutedList(ctx.Request.Context(), userID, page, limit)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// formAttrs 将请求参数转为日志字段
+func formAttrs(values url.Values) []any {
+	attrs := make([]any, 0, len(values))
+	for key, v := range values {
+		attrs = append(attrs, slog.Any(key, v))
+	}
+	return attrs
+}
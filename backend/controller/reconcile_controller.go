@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"blue-note/apperr"
+	"blue-note/model"
+	"blue-note/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReconcileController struct {
+	reconcileService *service.ReconcileService
+}
+
+func NewReconcileController(reconcileService *service.ReconcileService) *ReconcileController {
+	return &ReconcileController{reconcileService: reconcileService}
+}
+
+// RunReconcile 手动触发计数对账（管理员）
+func (c *ReconcileController) RunReconcile(ctx *gin.Context) {
+	var req model.ReconcileRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			bindFailed(ctx, err)
+			return
+		}
+	}
+
+	report, err := c.reconcileService.Run(ctx.Request.Context(), "manual", req.DryRun)
+	if err != nil {
+		// 对账中途出错时在 details 中返回已完成部分的报告
+		if report != nil {
+			err = apperr.ErrReconcile.Wrap(err).WithDetails(report)
+		}
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "reconcile.completed", report)
+}
+
+// GetReconcileReports 获取对账报告列表（管理员）
+func (c *ReconcileController) GetReconcileReports(ctx *gin.Context) {
+	var query model.ReconcileReportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.reconcileService.GetReports(ctx.Request.Context(), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
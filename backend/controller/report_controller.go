@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController struct {
+	reportService *service.ReportService
+}
+
+func NewReportController(reportService *service.ReportService) *ReportController {
+	return &ReportController{reportService: reportService}
+}
+
+// ReportPost 举报笔记
+func (c *ReportController) ReportPost(ctx *gin.Context) {
+	c.createReport(ctx, ctx.Param("postId"), c.reportService.ReportPost)
+}
+
+// ReportComment 举报评论
+func (c *ReportController) ReportComment(ctx *gin.Context) {
+	c.createReport(ctx, ctx.Param("commentId"), c.reportService.ReportComment)
+}
+
+// ReportUser 举报用户
+func (c *ReportController) ReportUser(ctx *gin.Context) {
+	c.createReport(ctx, ctx.Param("userId"), c.reportService.ReportUser)
+}
+
+func (c *ReportController) createReport(
+	ctx *gin.Context,
+	targetID string,
+	report func(ctx context.Context, reporterID string, targetID string, req *model.CreateReportRequest) (*model.Report, error),
+) {
+	var req model.CreateReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	userID := ctx.GetString("userId")
+
+	result, err := report(ctx.Request.Context(), userID, targetID, &req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "report.created", result)
+}
+
+// GetReports 获取举报列表（管理员）
+func (c *ReportController) GetReports(ctx *gin.Context) {
+	var query model.ReportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.reportService.GetReports(ctx.Request.Context(), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// HandleReport 处理举报（管理员）
+func (c *ReportController) HandleReport(ctx *gin.Context) {
+	var req model.HandleReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	reportID := ctx.Param("reportId")
+	handlerID := ctx.GetString("userId")
+
+	report, err := c.reportService.HandleReport(ctx.Request.Context(), reportID, handlerID, &req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "report.handled", report)
+}
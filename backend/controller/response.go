@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"blue-note/apperr"
+	"blue-note/i18n"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 所有接口使用统一的响应格式，成功时 code 为 0：
+//
+//	{"code": 0, "message": "成功", "data": ...}
+//
+// message 按请求的语言从 i18n 语言包中翻译。失败时由错误处理中间件返回错误码和错误信息，见 middleware.ErrorHandler
+
+func init() {
+	// 参数校验错误中使用 json/form 标签中的字段名，与客户端提交的字段一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName 返回字段在请求中的名称
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			break
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// success 返回 200 成功响应，messageKey 为提示信息在语言包中的键
+func success(ctx *gin.Context, messageKey string, data interface{}) {
+	successWithStatus(ctx, http.StatusOK, messageKey, data)
+}
+
+// successWithStatus 返回指定状态码的成功响应，如创建资源时返回 201
+func successWithStatus(ctx *gin.Context, status int, messageKey string, data interface{}) {
+	ctx.JSON(status, gin.H{
+		"code":    0,
+		"message": i18n.T(i18n.FromContext(ctx.Request.Context()), messageKey),
+		"data":    data,
+	})
+}
+
+// fail 记录错误，由错误处理中间件转换为错误响应
+func fail(ctx *gin.Context, err error) {
+	ctx.Error(err)
+}
+
+// bindFailed 请求参数绑定或校验失败，校验失败时在 details 中返回每个字段的错误
+func bindFailed(ctx *gin.Context, err error) {
+	e := apperr.ErrInvalidParams.Wrap(err)
+
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		details := make(fieldErrors, 0, len(errs))
+		for _, fe := range errs {
+			details = append(details, fieldError{
+				field: fe.Field(),
+				rule:  fe.Tag(),
+				param: fe.Param(),
+				kind:  fe.Kind(),
+			})
+		}
+		e = e.WithDetails(details)
+	}
+	fail(ctx, e)
+}
+
+// fieldError 单个字段的校验错误
+type fieldError struct {
+	field string
+	rule  string
+	param string
+	kind  reflect.Kind
+}
+
+// message 按语言返回校验规则对应的提示信息，min、max 作用于字符串和列表时校验的是长度，
+// 没有对应提示信息的规则返回通用的格式错误
+func (fe fieldError) message(lang string) string {
+	rule := fe.rule
+	switch fe.kind {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rule == "min" || rule == "max" {
+			rule += "_len"
+		}
+	}
+	key := "validation." + rule
+	if _, ok := i18n.Lookup(i18n.Default, key); !ok {
+		return i18n.T(lang, "validation.invalid", fe.field)
+	}
+	if fe.param == "" {
+		return i18n.T(lang, key, fe.field)
+	}
+	return i18n.T(lang, key, fe.field, fe.param)
+}
+
+// fieldErrors 参数校验错误，按请求的语言渲染提示信息
+type fieldErrors []fieldError
+
+func (errs fieldErrors) Localize(lang string) any {
+	details := make([]gin.H, len(errs))
+	for i, fe := range errs {
+		details[i] = gin.H{
+			"field":   fe.field,
+			"rule":    fe.rule,
+			"param":   fe.param,
+			"message": fe.message(lang),
+		}
+	}
+	return details
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingsController struct {
+	settingsService *service.SettingsService
+}
+
+func NewSettingsController(settingsService *service.SettingsService) *SettingsController {
+	return &SettingsController{settingsService: settingsService}
+}
+
+// GetSettings 获取运行时配置（管理员）
+func (c *SettingsController) GetSettings(ctx *gin.Context) {
+	settings, err := c.settingsService.Latest(ctx.Request.Context())
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", settings)
+}
+
+// UpdateSettings 修改运行时配置（管理员），修改后立即生效
+func (c *SettingsController) UpdateSettings(ctx *gin.Context) {
+	latest, err := c.settingsService.Latest(ctx.Request.Context())
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	// 未提交的字段保持当前值
+	req := latest.UpdateRequest()
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	settings, err := c.settingsService.Update(ctx.Request.Context(), ctx.GetString("userId"), &req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "settings.updated", settings)
+}
+
+// GetSettingsHistory 获取运行时配置修改记录（管理员）
+func (c *SettingsController) GetSettingsHistory(ctx *gin.Context) {
+	var query model.SettingsHistoryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.settingsService.GetHistory(ctx.Request.Context(), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
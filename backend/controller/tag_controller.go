@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagController struct {
+	tagService *service.TagService
+}
+
+func NewTagController(tagService *service.TagService) *TagController {
+	return &TagController{tagService: tagService}
+}
+
+// ListTags 获取标签列表
+func (c *TagController) ListTags(ctx *gin.Context) {
+	var query model.TagQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.tagService.ListTags(ctx.Request.Context(), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// GetTag 获取标签页信息
+func (c *TagController) GetTag(ctx *gin.Context) {
+	result, err := c.tagService.GetTag(ctx.Request.Context(), ctx.Param("name"), ctx.GetString("userId"))
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// FollowTag 关注标签
+func (c *TagController) FollowTag(ctx *gin.Context) {
+	if err := c.tagService.FollowTag(ctx.Request.Context(), ctx.GetString("userId"), ctx.Param("name")); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "tag.followed", nil)
+}
+
+// UnfollowTag 取消关注标签
+func (c *TagController) UnfollowTag(ctx *gin.Context) {
+	if err := c.tagService.UnfollowTag(ctx.Request.Context(), ctx.GetString("userId"), ctx.Param("name")); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "tag.unfollowed", nil)
+}
+
+// GetFollowedTags 获取当前用户关注的标签
+func (c *TagController) GetFollowedTags(ctx *gin.Context) {
+	tags, err := c.tagService.GetFollowedTags(ctx.Request.Context(), ctx.GetString("userId"))
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", tags)
+}
+
+// CreateTag 创建标签（管理员）
+func (c *TagController) CreateTag(ctx *gin.Context) {
+	var req model.CreateTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	tag, err := c.tagService.CreateTag(ctx.Request.Context(), &req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "tag.created", tag)
+}
+
+// UpdateTag 更新标签名称、同义词和描述（管理员）
+func (c *TagController) UpdateTag(ctx *gin.Context) {
+	var req model.UpdateTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	tag, err := c.tagService.UpdateTag(ctx.Request.Context(), ctx.Param("tagId"), &req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "tag.updated", tag)
+}
+
+// MergeTags 将其他标签合并到指定标签（管理员）
+func (c *TagController) MergeTags(ctx *gin.Context) {
+	var req model.MergeTagsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	tag, err := c.tagService.MergeTags(ctx.Request.Context(), ctx.Param("tagId"), &req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "tag.merged", tag)
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrendingController struct {
+	trendingService *service.TrendingService
+}
+
+func NewTrendingController(trendingService *service.TrendingService) *TrendingController {
+	return &TrendingController{trendingService: trendingService}
+}
+
+// GetTrending 获取热门标签和热门笔记
+func (c *TrendingController) GetTrending(ctx *gin.Context) {
+	var query model.TrendingQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	result, err := c.trendingService.GetTrending(ctx.Request.Context(), ctx.GetString("userId"), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", result)
+}
+
+// SuggestTags 标签联想
+func (c *TrendingController) SuggestTags(ctx *gin.Context) {
+	var query model.TagSuggestQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		bindFailed(ctx, err)
+		return
+	}
+
+	tags, err := c.trendingService.SuggestTags(ctx.Request.Context(), &query)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	success(ctx, "common.success", tags)
+}
@@ -0,0 +1,185 @@
+// Package health 管理各组件的健康检查，为存活检查和就绪检查接口提供结果。
+//
+// 组件通过 Registry.Register 注册检查函数。关键组件（如 MongoDB）不可用时服务不能处理请求，
+// 就绪检查返回 down；非关键组件（如缓存、后台任务）不可用或运行在降级模式时返回 degraded，服务仍然可以处理请求。
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status 组件或服务的健康状态
+type Status string
+
+const (
+	StatusUp       Status = "up"       // 正常
+	StatusDegraded Status = "degraded" // 可以处理请求，但部分功能降级
+	StatusDown     Status = "down"     // 不可用
+)
+
+// Result 一个组件的检查结果
+type Result struct {
+	Status    Status    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+	Latency   int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Up 返回正常的检查结果
+func Up(message string) Result {
+	return Result{Status: StatusUp, Message: message}
+}
+
+// Degraded 返回降级的检查结果
+func Degraded(message string) Result {
+	return Result{Status: StatusDegraded, Message: message}
+}
+
+// Down 返回不可用的检查结果
+func Down(message string) Result {
+	return Result{Status: StatusDown, Message: message}
+}
+
+// CheckFunc 检查一个组件，ctx 到期后应尽快返回
+type CheckFunc func(ctx context.Context) Result
+
+// Ping 将返回错误的连通性检查转换为 CheckFunc，出错时为 down
+func Ping(ping func(ctx context.Context) error) CheckFunc {
+	return func(ctx context.Context) Result {
+		if err := ping(ctx); err != nil {
+			return Down(err.Error())
+		}
+		return Up("")
+	}
+}
+
+// Report 所有组件的检查结果
+type Report struct {
+	Status     Status            `json:"status"`
+	Draining   bool              `json:"draining,omitempty"`
+	Components map[string]Result `json:"components"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
+
+// Ready 服务是否可以接收请求，degraded 时仍然可以
+func (r Report) Ready() bool {
+	return r.Status != StatusDown && !r.Draining
+}
+
+type checker struct {
+	name     string
+	check    CheckFunc
+	critical bool
+}
+
+// Registry 组件检查的注册表。检查结果会缓存一段时间，避免探针频繁访问数据库等依赖
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu       sync.Mutex
+	checkers []checker
+	report   *Report
+	draining bool
+}
+
+// NewRegistry 创建注册表，timeout 为每个检查的超时时间，cacheTTL 为结果的缓存时间（0 表示不缓存）
+func NewRegistry(timeout, cacheTTL time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &Registry{timeout: timeout, cacheTTL: cacheTTL}
+}
+
+// Register 注册组件检查，critical 为 true 的组件不可用时服务不能处理请求
+func (r *Registry) Register(name string, critical bool, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checker{name: name, check: check, critical: critical})
+	r.report = nil
+}
+
+// Drain 标记服务正在关闭，之后就绪检查失败，负载均衡不再转发新请求
+func (r *Registry) Drain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draining = true
+}
+
+// Check 返回所有组件的检查结果，缓存未过期时直接返回缓存的结果。
+// 同一时间只执行一轮检查，并发的请求等待这一轮的结果
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.report == nil || time.Since(r.report.CheckedAt) >= r.cacheTTL {
+		// 结果会共享给其他请求，不随发起检查的请求一起取消
+		report := r.run(context.WithoutCancel(ctx))
+		r.report = &report
+	}
+
+	report := *r.report
+	report.Draining = r.draining
+	return report
+}
+
+// run 并发执行所有检查并汇总状态
+func (r *Registry) run(ctx context.Context) Report {
+	results := make([]Result, len(r.checkers))
+	var wg sync.WaitGroup
+	for i, c := range r.checkers {
+		wg.Add(1)
+		go func(i int, c checker) {
+			defer wg.Done()
+			results[i] = r.runOne(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]Result, len(r.checkers)),
+		CheckedAt:  time.Now(),
+	}
+	for i, c := range r.checkers {
+		result := results[i]
+		report.Components[c.name] = result
+
+		switch {
+		case result.Status == StatusDown && c.critical:
+			report.Status = StatusDown
+		case result.Status != StatusUp && report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// runOne 在超时时间内执行一个检查，超时或 panic 时记为 down
+func (r *Registry) runOne(ctx context.Context, c checker) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				done <- Down(fmt.Sprintf("检查出错: %v", v))
+			}
+		}()
+		done <- c.check(ctx)
+	}()
+
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Down("检查超时")
+	}
+	result.Latency = time.Since(start).Milliseconds()
+	result.CheckedAt = time.Now()
+	return result
+}
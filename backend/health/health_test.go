@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func check(result Result) CheckFunc {
+	return func(ctx context.Context) Result { return result }
+}
+
+func TestRegistryStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		critical  Result
+		optional  Result
+		want      Status
+		wantReady bool
+	}{
+		{name: "全部正常", critical: Up(""), optional: Up(""), want: StatusUp, wantReady: true},
+		{name: "非关键组件降级", critical: Up(""), optional: Degraded("本地存储"), want: StatusDegraded, wantReady: true},
+		{name: "非关键组件不可用", critical: Up(""), optional: Down("连接失败"), want: StatusDegraded, wantReady: true},
+		{name: "关键组件降级", critical: Degraded("慢"), optional: Up(""), want: StatusDegraded, wantReady: true},
+		{name: "关键组件不可用", critical: Down("连接失败"), optional: Up(""), want: StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(time.Second, 0)
+			r.Register("mongodb", true, check(tt.critical))
+			r.Register("storage", false, check(tt.optional))
+
+			report := r.Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Status = %s, 期望 %s", report.Status, tt.want)
+			}
+			if report.Ready() != tt.wantReady {
+				t.Errorf("Ready() = %v, 期望 %v", report.Ready(), tt.wantReady)
+			}
+			if got := report.Components["storage"].Message; got != tt.optional.Message {
+				t.Errorf("storage.Message = %q, 期望 %q", got, tt.optional.Message)
+			}
+		})
+	}
+}
+
+func TestRegistryFailures(t *testing.T) {
+	r := NewRegistry(20*time.Millisecond, 0)
+	r.Register("slow", true, func(ctx context.Context) Result {
+		time.Sleep(time.Second)
+		return Up("")
+	})
+	r.Register("panic", false, func(ctx context.Context) Result {
+		panic("出错了")
+	})
+	r.Register("ping", false, Ping(func(ctx context.Context) error { return errors.New("拒绝连接") }))
+
+	report := r.Check(context.Background())
+	if report.Status != StatusDown {
+		t.Errorf("Status = %s, 期望 down", report.Status)
+	}
+	for name, want := range map[string]string{"slow": "检查超时", "panic": "检查出错: 出错了", "ping": "拒绝连接"} {
+		if got := report.Components[name]; got.Status != StatusDown || got.Message != want {
+			t.Errorf("%s = %+v, 期望 down %q", name, got, want)
+		}
+	}
+}
+
+func TestRegistryCache(t *testing.T) {
+	var calls atomic.Int32
+	r := NewRegistry(time.Second, time.Hour)
+	r.Register("mongodb", true, func(ctx context.Context) Result {
+		calls.Add(1)
+		return Up("")
+	})
+
+	for i := 0; i < 3; i++ {
+		r.Check(context.Background())
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("缓存有效期内检查了 %d 次, 期望 1 次", got)
+	}
+
+	// 发起检查的请求取消后，结果仍然有效
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Register("storage", false, check(Up("")))
+	if report := r.Check(ctx); report.Status != StatusUp {
+		t.Errorf("请求取消后 Status = %s, 期望 up", report.Status)
+	}
+
+	r.Drain()
+	report := r.Check(context.Background())
+	if report.Ready() || !report.Draining {
+		t.Errorf("关闭中 Ready() = %v, Draining = %v, 期望未就绪", report.Ready(), report.Draining)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var h Heartbeat
+	if got := h.Check(context.Background()); got.Status != StatusUp {
+		t.Errorf("未启动时 Status = %s, 期望 up", got.Status)
+	}
+
+	h.Start(time.Hour)
+	h.Beat(nil)
+	if got := h.Check(context.Background()); got.Status != StatusUp {
+		t.Errorf("运行正常时 Status = %s, 期望 up", got.Status)
+	}
+
+	h.Beat(errors.New("写入失败"))
+	if got := h.Check(context.Background()); got.Status != StatusDegraded || got.Message != "上次运行失败: 写入失败" {
+		t.Errorf("运行失败时 = %+v, 期望 degraded", got)
+	}
+
+	h.Start(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if got := h.Check(context.Background()); got.Status != StatusDegraded {
+		t.Errorf("长时间没有运行时 Status = %s, 期望 degraded", got.Status)
+	}
+
+	h.Stop()
+	if got := h.Check(context.Background()); got.Status != StatusDegraded || got.Message != "已停止" {
+		t.Errorf("停止后 = %+v, 期望 degraded", got)
+	}
+}
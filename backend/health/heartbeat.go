@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// staleFactor 超过多少个运行间隔没有运行时认为后台任务卡住
+const staleFactor = 3
+
+// Heartbeat 记录定时后台任务的运行情况。任务启动时调用 Start，每轮运行结束后调用 Beat，
+// 停止时调用 Stop。零值可以直接使用，未启动时检查结果为正常
+type Heartbeat struct {
+	mu       sync.Mutex
+	interval time.Duration
+	running  bool
+	stopped  bool
+	last     time.Time
+	err      error
+}
+
+// Start 记录任务已启动，interval 为运行间隔
+func (h *Heartbeat) Start(interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.interval = interval
+	h.running = true
+	h.stopped = false
+	h.last = time.Now()
+	h.err = nil
+}
+
+// Beat 记录一轮运行结束，err 为这一轮的错误
+func (h *Heartbeat) Beat(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = time.Now()
+	h.err = err
+}
+
+// Stop 记录任务已停止
+func (h *Heartbeat) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running = false
+	h.stopped = true
+}
+
+// Check 检查任务状态：已停止、长时间没有运行或上一轮失败时为 degraded
+func (h *Heartbeat) Check(ctx context.Context) Result {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case h.stopped:
+		return Degraded("已停止")
+	case !h.running:
+		return Up("未启用")
+	case time.Since(h.last) > staleFactor*h.interval:
+		return Degraded(fmt.Sprintf("已超过 %s 没有运行", time.Since(h.last).Round(time.Second)))
+	case h.err != nil:
+		return Degraded("上次运行失败: " + h.err.Error())
+	}
+	return Up(fmt.Sprintf("上次运行于 %s", h.last.Format(time.RFC3339)))
+}
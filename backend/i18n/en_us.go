@@ -0,0 +1,130 @@
+package i18n
+
+// enUS 英文语言包
+var enUS = Catalog{
+	// 接口成功提示
+	"common.success": "Success",
+
+	"auth.login_success":    "Logged in successfully",
+	"auth.register_success": "Registered successfully",
+	"auth.password_changed": "Password changed",
+
+	"profile.updated": "Profile updated",
+	"user.followed":   "Followed",
+	"user.unfollowed": "Unfollowed",
+	"user.blocked":    "User blocked",
+	"user.unblocked":  "User unblocked",
+	"user.muted":      "User muted",
+	"user.unmuted":    "User unmuted",
+
+	"comment.created": "Comment posted",
+	"comment.liked":   "Liked",
+	"comment.unliked": "Like removed",
+	"draft.saved":     "Draft saved",
+	"draft.deleted":   "Draft deleted",
+	"draft.published": "Published",
+
+	"tag.followed":   "Tag followed",
+	"tag.unfollowed": "Tag unfollowed",
+	"tag.created":    "Tag created",
+	"tag.updated":    "Tag updated",
+	"tag.merged":     "Tags merged",
+
+	"file.uploaded":       "File uploaded",
+	"file.deleted":        "File deleted",
+	"report.created":      "Report submitted",
+	"report.handled":      "Report handled",
+	"settings.updated":    "Settings updated",
+	"reconcile.completed": "Reconcile completed",
+
+	// 参数校验，第一个参数是字段名，第二个参数是校验规则的参数
+	"validation.required": "%s is required",
+	"validation.email":    "%s must be a valid email address",
+	"validation.eqfield":  "%s must match %s",
+	"validation.oneof":    "%s must be one of [%s]",
+	"validation.min":      "%s must be at least %s",
+	"validation.max":      "%s must be at most %s",
+	"validation.min_len":  "%s must be at least %s characters or items long",
+	"validation.max_len":  "%s must be at most %s characters or items long",
+	"validation.invalid":  "%s is invalid",
+
+	// 运行时配置校验，第一个参数是配置项
+	"settings.range":              "%s must be between %d and %d",
+	"settings.range_seconds":      "%s must be between %d and %d seconds",
+	"settings.one_of":             "%s must be one of %s",
+	"settings.empty":              "%s must not be empty",
+	"settings.unknown_rate_limit": "%s is not a configurable rate limit rule",
+	"settings.invalid_origin":     "%s contains an invalid origin: %s",
+	"settings.invalid_extension":  "%s contains an invalid extension: %s",
+
+	// 错误信息，键为 error.<错误码>，见 apperr 包
+	"error.40001": "Invalid ID",
+	"error.40003": "Invalid request parameters",
+	"error.40004": "File size exceeds the limit (%dMB)",
+	"error.40005": "Unsupported file format, please upload %s",
+	"error.40006": "Failed to process the uploaded file",
+	"error.40007": "Failed to read the file",
+	"error.40008": "Failed to process the file",
+	"error.40009": "No file found",
+	"error.40010": "Incorrect captcha",
+	"error.40011": "Invalid cursor",
+	"error.40012": "Cursor has expired, please refresh",
+	"error.40013": "New password must be at least 6 characters",
+	"error.40014": "Incorrect old password",
+	"error.40015": "You cannot block yourself",
+	"error.40016": "You cannot mute yourself",
+	"error.40017": "You cannot report yourself",
+	"error.40018": "A tag cannot be merged into itself",
+	"error.40019": "Tag name cannot be empty",
+	"error.40020": "Invalid settings",
+	"error.40021": "Unsupported report target type",
+
+	"error.40100": "Not logged in",
+	"error.40101": "Incorrect username or password",
+	"error.40102": "Missing authentication credentials",
+	"error.40103": "Malformed authentication header",
+	"error.40104": "Authentication has expired, please log in again",
+	"error.40105": "Invalid authentication credentials",
+
+	"error.40300": "Access denied",
+	"error.40301": "You are not allowed to view analytics for this post",
+	"error.40302": "You are not allowed to edit this post",
+	"error.40303": "You are not allowed to delete this post",
+	"error.40304": "You are not allowed to delete this comment",
+	"error.40305": "The author has blocked you",
+	"error.40306": "This user has blocked you",
+	"error.40307": "This account has been banned",
+	"error.40308": "This user is hidden",
+
+	"error.40400": "Record not found",
+	"error.40401": "User not found",
+	"error.40402": "Post not found",
+	"error.40403": "Tag not found",
+	"error.40404": "Draft not found or does not belong to you",
+	"error.40405": "Report not found",
+	"error.40406": "Report target not found",
+	"error.40407": "Like not found",
+	"error.40408": "File not found or you are not allowed to delete it",
+	"error.40409": "Comment not found",
+
+	"error.40900": "Record already exists",
+	"error.40901": "Settings were modified by another administrator, please refresh and try again",
+	"error.40902": "A reconcile job is already running",
+	"error.40903": "Username is already taken",
+	"error.40904": "Already liked",
+	"error.40905": "User is already blocked",
+	"error.40906": "User is not blocked",
+	"error.40907": "User is already muted",
+	"error.40908": "User is not muted",
+	"error.40909": "Tag is already followed",
+	"error.40910": "Tag is not followed",
+	"error.40911": "You have already reported this content",
+	"error.40912": "This report has already been handled",
+	"error.40913": "Name %s is already used by tag %s, please merge the tags instead",
+	"error.40914": "You have blocked this user, please unblock first",
+
+	"error.42900": "Too many requests, please try again later",
+	"error.50001": "Internal server error",
+	"error.50002": "Reconcile failed",
+	"error.50400": "Request timed out, please try again later",
+}
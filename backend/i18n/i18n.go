@@ -0,0 +1,166 @@
+// Package i18n 提供接口信息的多语言支持。
+//
+// 返回给客户端的信息（成功提示、错误信息、参数校验信息）都通过消息键在语言包中查找，
+// 语言包见 zh_cn.go 和 en_us.go。请求使用的语言由 middleware.Locale 根据用户的语言偏好
+// 或 Accept-Language 请求头确定，并保存在请求的 context 中。
+// 语言包中缺少的消息使用默认语言（简体中文）的翻译。
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+
+	Default = ZhCN
+)
+
+// Catalog 语言包，消息键到翻译的映射，翻译中可以包含 fmt 格式的占位符
+type Catalog map[string]string
+
+var catalogs = map[string]Catalog{
+	ZhCN: zhCN,
+	EnUS: enUS,
+}
+
+// Languages 返回支持的语言
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Supported 判断是否支持指定的语言
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Match 将语言标签匹配到支持的语言，按主语言匹配，如 en-GB 匹配 en-US、zh-Hans 匹配 zh-CN
+func Match(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, lang := range Languages() {
+		if strings.ToLower(lang) == tag {
+			return lang, true
+		}
+	}
+	for _, lang := range Languages() {
+		if p, _, _ := strings.Cut(strings.ToLower(lang), "-"); p == primary {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate 根据 Accept-Language 请求头选择语言，按权重从高到低匹配，都不支持时使用默认语言
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if tag = strings.TrimSpace(tag); tag != "" && tag != "*" && q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, c := range candidates {
+		if lang, ok := Match(c.tag); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// Lookup 查找指定语言的翻译，不回退到默认语言
+func Lookup(lang, key string) (string, bool) {
+	msg, ok := catalogs[lang][key]
+	return msg, ok
+}
+
+// T 翻译消息，语言包中缺少时使用默认语言，默认语言也缺少时返回消息键
+func T(lang, key string, args ...any) string {
+	msg, ok := Lookup(lang, key)
+	if !ok {
+		if msg, ok = Lookup(Default, key); !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Message 待翻译的消息，用于在无法确定语言的地方（如服务层）描述信息，写入响应时再翻译
+type Message struct {
+	Key  string
+	Args []any
+}
+
+// M 创建待翻译的消息
+func M(key string, args ...any) Message {
+	return Message{Key: key, Args: args}
+}
+
+// Translate 按指定语言翻译消息
+func (m Message) Translate(lang string) string {
+	return T(lang, m.Key, m.Args...)
+}
+
+func (m Message) String() string {
+	return m.Translate(Default)
+}
+
+// Localizer 可以按语言渲染的内容，错误详情实现此接口时会在写入响应前按请求的语言渲染
+type Localizer interface {
+	Localize(lang string) any
+}
+
+// Messages 待翻译的消息列表，渲染为字符串数组
+type Messages []Message
+
+func (ms Messages) Localize(lang string) any {
+	texts := make([]string, len(ms))
+	for i, m := range ms {
+		texts[i] = m.Translate(lang)
+	}
+	return texts
+}
+
+type contextKey struct{}
+
+// WithLanguage 返回携带语言的 context
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext 返回 context 中的语言，未设置时返回默认语言
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+	return Default
+}
@@ -0,0 +1,97 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "未设置", acceptLanguage: "", want: ZhCN},
+		{name: "英文", acceptLanguage: "en-US", want: EnUS},
+		{name: "按主语言匹配", acceptLanguage: "en-GB", want: EnUS},
+		{name: "繁体中文", acceptLanguage: "zh-TW", want: ZhCN},
+		{name: "大小写和下划线", acceptLanguage: "EN_us", want: EnUS},
+		{name: "按权重", acceptLanguage: "zh-CN;q=0.5, en;q=0.8", want: EnUS},
+		{name: "跳过不支持的语言", acceptLanguage: "ja-JP, en;q=0.5", want: EnUS},
+		{name: "权重为0", acceptLanguage: "en;q=0, zh;q=0.1", want: ZhCN},
+		{name: "都不支持", acceptLanguage: "fr-FR, *", want: ZhCN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q) = %s, 期望 %s", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	catalogs["test"] = Catalog{"greeting": "hi %s"}
+	defer delete(catalogs, "test")
+
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []any
+		want string
+	}{
+		{name: "带参数", lang: "test", key: "greeting", args: []any{"bob"}, want: "hi bob"},
+		{name: "回退到默认语言", lang: "test", key: "common.success", want: "成功"},
+		{name: "不支持的语言", lang: "fr-FR", key: "common.success", want: "成功"},
+		{name: "缺少翻译", lang: EnUS, key: "unknown.key", want: "unknown.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := T(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	ms := Messages{M("settings.empty", "cors.allowOrigins"), M("settings.range", "upload.maxImageSizeMB", 1, 100)}
+	got := ms.Localize(EnUS).([]string)
+	want := []string{"cors.allowOrigins must not be empty", "upload.maxImageSizeMB must be between 1 and 100"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Localize()[%d] = %q, 期望 %q", i, got[i], want[i])
+		}
+	}
+
+	ctx := WithLanguage(context.Background(), EnUS)
+	if FromContext(ctx) != EnUS || FromContext(context.Background()) != Default {
+		t.Error("context 中的语言不正确")
+	}
+}
+
+// 每个语言包的消息键和占位符必须与默认语言一致
+func TestCatalogsComplete(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for _, lang := range Languages() {
+		for key, msg := range catalogs[Default] {
+			translated, ok := catalogs[lang][key]
+			if !ok {
+				t.Errorf("语言包 %s 缺少 %s", lang, key)
+				continue
+			}
+			if a, b := verbs.FindAllString(msg, -1), verbs.FindAllString(translated, -1); len(a) != len(b) {
+				t.Errorf("语言包 %s 中 %s 的占位符 %v 与默认语言 %v 不一致", lang, key, b, a)
+			}
+		}
+		for key := range catalogs[lang] {
+			if _, ok := catalogs[Default][key]; !ok {
+				t.Errorf("语言包 %s 中的 %s 在默认语言中不存在", lang, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package i18n
+
+// zhCN 简体中文语言包，也是默认语言
+var zhCN = Catalog{
+	// 接口成功提示
+	"common.success": "成功",
+
+	"auth.login_success":    "登录成功",
+	"auth.register_success": "注册成功",
+	"auth.password_changed": "密码修改成功",
+
+	"profile.updated": "个人资料更新成功",
+	"user.followed":   "关注成功",
+	"user.unfollowed": "取消关注成功",
+	"user.blocked":    "拉黑成功",
+	"user.unblocked":  "取消拉黑成功",
+	"user.muted":      "屏蔽成功",
+	"user.unmuted":    "取消屏蔽成功",
+
+	"comment.created": "评论成功",
+	"comment.liked":   "点赞成功",
+	"comment.unliked": "取消点赞成功",
+	"draft.saved":     "草稿保存成功",
+	"draft.deleted":   "草稿删除成功",
+	"draft.published": "发布成功",
+
+	"tag.followed":   "关注成功",
+	"tag.unfollowed": "取消关注成功",
+	"tag.created":    "创建成功",
+	"tag.updated":    "更新成功",
+	"tag.merged":     "合并成功",
+
+	"file.uploaded":       "文件上传成功",
+	"file.deleted":        "删除文件成功",
+	"report.created":      "举报成功",
+	"report.handled":      "处理成功",
+	"settings.updated":    "修改成功",
+	"reconcile.completed": "对账完成",
+
+	// 参数校验，第一个参数是字段名，第二个参数是校验规则的参数
+	"validation.required": "%s 为必填字段",
+	"validation.email":    "%s 必须是有效的邮箱地址",
+	"validation.eqfield":  "%s 必须与 %s 相同",
+	"validation.oneof":    "%s 必须是 [%s] 中的一个",
+	"validation.min":      "%s 不能小于 %s",
+	"validation.max":      "%s 不能大于 %s",
+	"validation.min_len":  "%s 长度不能少于 %s",
+	"validation.max_len":  "%s 长度不能超过 %s",
+	"validation.invalid":  "%s 格式不正确",
+
+	// 运行时配置校验，第一个参数是配置项
+	"settings.range":              "%s 必须在 %d-%d 之间",
+	"settings.range_seconds":      "%s 必须在 %d-%d 秒之间",
+	"settings.one_of":             "%s 必须是 %s 之一",
+	"settings.empty":              "%s 不能为空",
+	"settings.unknown_rate_limit": "%s 不是可配置的限流规则",
+	"settings.invalid_origin":     "%s 包含无效的来源: %s",
+	"settings.invalid_extension":  "%s 包含无效的扩展名: %s",
+
+	// 错误信息，键为 error.<错误码>，见 apperr 包
+	"error.40001": "无效的ID",
+	"error.40003": "请求参数错误",
+	"error.40004": "文件大小超过限制(%dMB)",
+	"error.40005": "不支持的文件格式，请上传%s格式",
+	"error.40006": "上传文件处理失败",
+	"error.40007": "读取文件失败",
+	"error.40008": "处理文件失败",
+	"error.40009": "未找到文件",
+	"error.40010": "验证码错误",
+	"error.40011": "无效的游标值",
+	"error.40012": "游标已失效，请刷新",
+	"error.40013": "新密码长度不能少于6个字符",
+	"error.40014": "原密码错误",
+	"error.40015": "不能拉黑自己",
+	"error.40016": "不能屏蔽自己",
+	"error.40017": "不能举报自己",
+	"error.40018": "不能将标签合并到自身",
+	"error.40019": "标签名称不能为空",
+	"error.40020": "配置不合法",
+	"error.40021": "不支持的举报对象类型",
+
+	"error.40100": "未登录",
+	"error.40101": "用户名或密码错误",
+	"error.40102": "未提供认证信息",
+	"error.40103": "认证格式错误",
+	"error.40104": "认证信息已过期，请重新登录",
+	"error.40105": "无效的认证信息",
+
+	"error.40300": "无权限访问",
+	"error.40301": "无权限查看此笔记的数据",
+	"error.40302": "无权限修改此帖子",
+	"error.40303": "无权限删除此帖子",
+	"error.40304": "无权限删除此评论",
+	"error.40305": "作者已将你拉黑",
+	"error.40306": "对方已将你拉黑，无法关注",
+	"error.40307": "账号已被封禁",
+	"error.40308": "该用户已被隐藏",
+
+	"error.40400": "记录不存在",
+	"error.40401": "用户不存在",
+	"error.40402": "笔记不存在",
+	"error.40403": "标签不存在",
+	"error.40404": "草稿不存在或不属于当前用户",
+	"error.40405": "举报不存在",
+	"error.40406": "举报对象不存在",
+	"error.40407": "未找到点赞记录",
+	"error.40408": "文件不存在或无权限删除",
+	"error.40409": "评论不存在",
+
+	"error.40900": "记录已存在",
+	"error.40901": "配置已被其他管理员修改，请刷新后重试",
+	"error.40902": "对账任务正在运行",
+	"error.40903": "用户名已被占用",
+	"error.40904": "已经点赞过了",
+	"error.40905": "已经拉黑该用户",
+	"error.40906": "未拉黑该用户",
+	"error.40907": "已经屏蔽该用户",
+	"error.40908": "未屏蔽该用户",
+	"error.40909": "已经关注了该标签",
+	"error.40910": "未关注该标签",
+	"error.40911": "已经举报过该内容",
+	"error.40912": "该举报已处理",
+	"error.40913": "名称 %s 已被标签 %s 使用，请使用合并功能",
+	"error.40914": "已拉黑该用户，请先取消拉黑",
+
+	"error.42900": "请求过于频繁，请稍后再试",
+	"error.50001": "服务器内部错误",
+	"error.50002": "对账失败",
+	"error.50400": "请求超时，请稍后再试",
+}
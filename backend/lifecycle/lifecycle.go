@@ -0,0 +1,85 @@
+// Package lifecycle 管理应用关闭时各组件的停止顺序和截止时间。
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// StopFunc 停止组件，ctx 到期后应尽快返回
+type StopFunc func(ctx context.Context) error
+
+// Func 将不接收 ctx 的停止函数转换为 StopFunc
+func Func(stop func()) StopFunc {
+	return func(ctx context.Context) error {
+		stop()
+		return nil
+	}
+}
+
+type hook struct {
+	name string
+	stop StopFunc
+}
+
+// Manager 按注册的相反顺序停止组件：先注册的基础组件（如数据库连接）最后停止，
+// 后注册的 HTTP 服务最先停止，保证正在处理的请求和后台任务结束前依赖仍然可用
+type Manager struct {
+	mu    sync.Mutex
+	hooks []hook
+	once  sync.Once
+	err   error
+}
+
+// New 创建生命周期管理器
+func New() *Manager {
+	return &Manager{}
+}
+
+// OnStop 注册组件的停止函数
+func (m *Manager) OnStop(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Shutdown 依次停止所有组件，多次调用只执行一次。
+// 某个组件在 ctx 到期前没有停止时不再等待它，继续停止后面的组件，返回所有失败的原因
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		m.mu.Lock()
+		hooks := make([]hook, len(m.hooks))
+		copy(hooks, m.hooks)
+		m.mu.Unlock()
+
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			h := hooks[i]
+			slog.Info("正在停止", "component", h.name)
+			if err := runStop(ctx, h.stop); err != nil {
+				slog.Error("停止失败", "component", h.name, "error", err)
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			}
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
+
+// runStop 执行停止函数，ctx 到期时直接返回
+func runStop(ctx context.Context, stop StopFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("等待停止超时: %w", ctx.Err())
+	}
+}
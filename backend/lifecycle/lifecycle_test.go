@@ -0,0 +1,72 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	m := New()
+	var stopped []string
+	for _, name := range []string{"数据库", "后台任务", "HTTP 服务"} {
+		name := name
+		m.OnStop(name, func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown 失败: %v", err)
+	}
+	want := []string{"HTTP 服务", "后台任务", "数据库"}
+	if !reflect.DeepEqual(stopped, want) {
+		t.Errorf("停止顺序 = %v, 期望 %v", stopped, want)
+	}
+
+	// 重复调用不会再次停止组件
+	m.Shutdown(context.Background())
+	if len(stopped) != len(want) {
+		t.Errorf("重复关闭后停止次数 = %d, 期望 %d", len(stopped), len(want))
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	errFailed := errors.New("停止失败")
+	block := make(chan struct{})
+	defer close(block)
+
+	tests := []struct {
+		name    string
+		stop    StopFunc
+		wantErr error
+	}{
+		{name: "正常停止", stop: Func(func() {})},
+		{name: "停止失败", stop: func(ctx context.Context) error { return errFailed }, wantErr: errFailed},
+		{name: "停止超时", stop: Func(func() { <-block }), wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			lastStopped := false
+			m.OnStop("最后停止", Func(func() { lastStopped = true }))
+			m.OnStop(tt.name, tt.stop)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			err := m.Shutdown(ctx)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("err = %v, 期望 %v", err, tt.wantErr)
+			}
+			// 前一个组件失败或超时也会继续停止后面的组件
+			if tt.wantErr != context.DeadlineExceeded && !lastStopped {
+				t.Error("后面的组件没有被停止")
+			}
+		})
+	}
+}
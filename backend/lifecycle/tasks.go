@@ -0,0 +1,74 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Tasks 跟踪请求触发的后台任务（如关注流推送、计数更新），停止时等待进行中的任务完成，
+// 保证它们在数据库连接断开前结束。nil 的 Tasks 不跟踪任务，直接在新的 goroutine 中执行
+type Tasks struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	stopped bool
+
+	// 停止等待超时后取消仍在执行的任务
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewTasks 创建后台任务组
+func NewTasks() *Tasks {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Tasks{ctx: ctx, cancel: cancel}
+}
+
+// Go 在后台执行 fn。fn 收到的 ctx 保留调用方 ctx 中的值（如链路信息），但不随请求结束而取消；
+// 任务组停止后提交的任务会被丢弃
+func (t *Tasks) Go(ctx context.Context, fn func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	if t == nil {
+		go fn(ctx)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		slog.WarnContext(ctx, "后台任务已停止，丢弃新任务")
+		return
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(t.ctx, cancel)
+		defer stop()
+		fn(ctx)
+	}()
+}
+
+// Stop 不再接收新任务并等待进行中的任务完成，ctx 到期时取消剩余任务并返回错误
+func (t *Tasks) Stop(ctx context.Context) error {
+	t.mu.Lock()
+	t.stopped = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		t.cancel()
+		return fmt.Errorf("等待后台任务完成超时: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,59 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTasksWaitOnStop(t *testing.T) {
+	tasks := NewTasks()
+	var finished atomic.Bool
+
+	// 请求结束后任务仍继续执行，停止时等待它完成
+	reqCtx, cancel := context.WithCancel(context.Background())
+	tasks.Go(reqCtx, func(ctx context.Context) {
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(ctx.Err() == nil)
+	})
+	cancel()
+
+	if err := tasks.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop 失败: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Stop 返回前任务应已正常完成")
+	}
+
+	// 停止后提交的任务被丢弃
+	var ran atomic.Bool
+	tasks.Go(context.Background(), func(ctx context.Context) { ran.Store(true) })
+	time.Sleep(10 * time.Millisecond)
+	if ran.Load() {
+		t.Error("停止后不应再执行新任务")
+	}
+}
+
+func TestTasksStopTimeout(t *testing.T) {
+	tasks := NewTasks()
+	canceled := make(chan struct{})
+	tasks.Go(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(canceled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tasks.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, 期望 %v", err, context.DeadlineExceeded)
+	}
+
+	// 超时后取消仍在执行的任务
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("超时后任务的 ctx 应被取消")
+	}
+}
@@ -0,0 +1,114 @@
+// Package logger 基于 log/slog 的结构化日志。日志按级别过滤，以文本或 JSON 格式输出，
+// 自动附加上下文中的请求ID和链路ID，并隐藏密码、token、密钥等敏感字段。
+//
+// 初始化后 slog 和标准库 log 的输出都经过这里，业务代码使用 slog.InfoContext 等带上下文的函数记录日志。
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID 传递请求ID的请求头和响应头
+const HeaderRequestID = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID 将请求ID保存到上下文
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 返回上下文中的请求ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ParseLevel 解析日志级别：debug、info、warn 或 error
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("无效的日志级别: %s", level)
+}
+
+// New 创建输出到 w 的日志，format 为 json 时输出 JSON，否则输出 key=value 文本
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redactAttr}
+	var h slog.Handler
+	switch format {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "", "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("无效的日志格式: %s", format)
+	}
+	return slog.New(&handler{Handler: h}), nil
+}
+
+// Init 创建输出到标准错误的日志并设为默认日志
+func Init(level string, format string) error {
+	l, err := New(os.Stderr, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(l)
+	return nil
+}
+
+// Fatal 记录错误日志后退出进程
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// handler 为每条日志附加请求ID和链路ID，并隐藏消息中的敏感信息
+type handler struct {
+	slog.Handler
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, RedactString(r.Message), r.PC)
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(a)
+		return true
+	})
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{Handler: h.Handler.WithGroup(name)}
+}
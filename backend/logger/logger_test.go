@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	l.InfoContext(ctx, "处理请求", "user_id", "u1")
+	l.Info("没有请求ID")
+	l.DebugContext(ctx, "低于日志级别")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("输出 %d 行, 期望 2 行: %s", len(lines), buf.String())
+	}
+
+	var first, second map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first["request_id"] != "req-1" || first["user_id"] != "u1" || first["level"] != "INFO" {
+		t.Errorf("第一行 = %v", first)
+	}
+	if _, ok := second["request_id"]; ok {
+		t.Errorf("没有请求ID时不应输出 request_id: %v", second)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "debug", "text")
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		log     func()
+		leaked  string
+		wantOut string
+	}{
+		{
+			name:    "敏感字段",
+			log:     func() { l.Info("登录", "password", "hunter22", "username", "alice") },
+			leaked:  "hunter22",
+			wantOut: "username=alice",
+		},
+		{
+			name:   "字段名大小写和下划线",
+			log:    func() { l.Info("上传", "Access_Key", "AKIA123", "newPassword", "p@ss") },
+			leaked: "AKIA123",
+		},
+		{
+			name:   "分组中的字段",
+			log:    func() { l.WithGroup("req").Info("请求", "token", "abc.def") },
+			leaked: "abc.def",
+		},
+		{
+			name:   "消息中的 Bearer token",
+			log:    func() { l.Info("请求头 Authorization: Bearer eyJhbGciOi.x.y") },
+			leaked: "eyJhbGciOi",
+		},
+		{
+			name:    "连接串密码",
+			log:     func() { l.Info("连接数据库", "uri", "mongodb://root:s3cret@db:27017") },
+			leaked:  "s3cret",
+			wantOut: "mongodb://root:******@db:27017",
+		},
+		{
+			name:   "错误中的密码",
+			log:    func() { l.Error("连接失败", "error", errors.New("auth failed: password=s3cret")) },
+			leaked: "s3cret",
+		},
+		{
+			name:   "JSON 文本",
+			log:    func() { l.Info(`请求体 {"username":"alice","password":"hunter22"}`) },
+			leaked: "hunter22",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.log()
+			out := buf.String()
+			if strings.Contains(out, tt.leaked) {
+				t.Errorf("日志中包含敏感信息 %q: %s", tt.leaked, out)
+			}
+			if !strings.Contains(out, Mask) {
+				t.Errorf("日志中没有占位符: %s", out)
+			}
+			if tt.wantOut != "" && !strings.Contains(out, tt.wantOut) {
+				t.Errorf("日志中没有 %q: %s", tt.wantOut, out)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "verbose", "text"); err == nil {
+		t.Error("无效的日志级别应返回错误")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("无效的日志格式应返回错误")
+	}
+	if lvl, _ := ParseLevel("WARN"); lvl != slog.LevelWarn {
+		t.Errorf("ParseLevel(WARN) = %v", lvl)
+	}
+}
@@ -1,59 +1,144 @@
 package main
 
 import (
+	"blue-note/cache"
 	"blue-note/config"
 	"blue-note/controller"
+	"blue-note/health"
+	"blue-note/lifecycle"
+	"blue-note/logger"
+	"blue-note/metrics"
+	"blue-note/ratelimit"
+	"blue-note/redis"
+	"blue-note/repository"
 	"blue-note/router"
 	"blue-note/service"
+	"blue-note/tracing"
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func main() {
 	// 初始化配置
-	if err := config.Init(); err != nil {
+	loaded, err := config.Init()
+	if err != nil {
 		log.Fatalf("初始化配置失败: %v", err)
 	}
 
+	// 初始化日志，之后标准库 log 的输出也按配置的格式输出
+	if err := logger.Init(config.GetConfig().Log.Level, config.GetConfig().Log.Format); err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
+	if loaded.FileError != nil {
+		slog.Warn("读取配置文件失败，将使用默认配置", "error", loaded.FileError)
+	}
+	slog.Info("配置加载完成",
+		"environment", config.GetConfig().Environment,
+		"config_file", loaded.ConfigFile,
+		"mongodb_uri", config.RedactURI(config.GetConfig().MongoDB.URI),
+		"redis_uri", config.RedactURI(config.GetConfig().Redis.URI),
+	)
+
+	// 初始化链路追踪，需要在创建 MongoDB 客户端之前设置
+	shutdownTracing, err := tracing.Init(context.Background(), config.GetConfig())
+	if err != nil {
+		logger.Fatal("初始化链路追踪失败", "error", err)
+	}
+
 	// 在连接MongoDB之前
-	log.Printf("当前环境: %s", config.GetConfig().Environment)
-	log.Printf("正在连接MongoDB: %s", config.GetConfig().MongoDB.URI)
+	slog.Info("当前环境", "environment", config.GetConfig().Environment)
+	slog.Info("正在连接MongoDB", "uri", config.RedactURI(config.GetConfig().MongoDB.URI))
 
 	// 连接MongoDB
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 临时使用本地MongoDB连接
-	mongoURI := "mongodb://localhost:27017"
-	log.Printf("正在连接MongoDB: %s", mongoURI)
-	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	// 命令监听器记录每条 MongoDB 命令的耗时和 span
+	mongoClient, err := mongo.Connect(ctx, options.Client().
+		ApplyURI(config.GetConfig().MongoDB.URI).
+		SetMonitor(chainMonitors(metrics.MongoMonitor(), tracing.MongoMonitor())))
 	if err != nil {
-		log.Fatalf("连接MongoDB失败: %v", err)
+		logger.Fatal("连接MongoDB失败", "error", err)
 	}
 
 	// 测试连接
 	err = mongoClient.Ping(ctx, nil)
 	if err != nil {
-		log.Fatalf("MongoDB Ping失败: %v", err)
+		logger.Fatal("MongoDB Ping失败", "error", err)
 	}
-	log.Printf("MongoDB连接成功，数据库: %s", config.GetConfig().MongoDB.Database)
-	defer func() {
-		if err := mongoClient.Disconnect(ctx); err != nil {
-			log.Printf("断开MongoDB连接失败: %v", err)
-		}
-	}()
+	slog.Info("MongoDB连接成功", "database", config.GetConfig().MongoDB.Database)
+
+	// 生命周期管理：退出时按注册的相反顺序停止各组件，MongoDB 最后断开
+	app := lifecycle.New()
+	app.OnStop("链路追踪", shutdownTracing)
+	app.OnStop("MongoDB", mongoClient.Disconnect)
 
 	// 初始化服务和控制器
 	db := mongoClient.Database(config.GetConfig().MongoDB.Database)
 
+	// migrate 子命令：执行、查看或回滚数据库迁移后退出
+	if isMigrateCommand() {
+		err := runMigrateCommand(db, os.Args[2:])
+		shutdown(app)
+		if err != nil {
+			logger.Fatal("数据库迁移失败", "error", err)
+		}
+		return
+	}
+
+	// 执行数据库迁移并创建索引
+	autoMigrate(db)
+
+	// 初始化数据仓储
+	repos := repository.NewMongo(db)
+
+	// 限流或缓存使用 Redis 时共用一个客户端
+	var redisClient *redis.Client
+	if config.GetConfig().RateLimit.Backend == "redis" || config.GetConfig().Cache.Backend == "redis" {
+		redisClient, err = redis.NewClient(config.GetConfig().Redis.URI, config.GetConfig().Redis.Password)
+		if err != nil {
+			logger.Fatal("初始化Redis客户端失败", "error", err)
+		}
+		if err := redisClient.Ping(ctx); err != nil {
+			slog.Warn("Redis Ping失败，限流检查失败时将放行请求，缓存读写失败时将直接查询数据库", "error", err)
+		}
+		app.OnStop("Redis", func(ctx context.Context) error { return redisClient.Close() })
+	}
+
+	// 请求触发的后台任务（关注流推送、计数更新、缓存失效等）在 HTTP 服务停止后等待完成，
+	// 注册在 Redis 和 MongoDB 之后，停止时先于它们执行，保证任务结束前连接仍可用
+	backgroundTasks := lifecycle.NewTasks()
+	app.OnStop("后台任务", backgroundTasks.Stop)
+
+	// 初始化限流后端，多实例部署时使用 Redis 共享配额
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if config.GetConfig().RateLimit.Backend == "redis" {
+		rateLimitStore = ratelimit.NewRedisStore(redisClient, config.GetConfig().RateLimit.Prefix)
+	}
+
+	// 初始化热点数据缓存，多实例部署时使用 Redis 使修改后的失效对所有实例生效
+	var cacheGroup *cache.Group
+	switch config.GetConfig().Cache.Backend {
+	case "memory":
+		cacheGroup = cache.NewGroup(cache.NewLRU(config.GetConfig().Cache.Capacity))
+	case "redis":
+		cacheGroup = cache.NewGroup(cache.NewRedis(redisClient, config.GetConfig().Cache.Prefix))
+	}
+
 	// 初始化对象存储服务
-	fmt.Println("开始初始化对象存储服务...")
+	slog.Info("开始初始化对象存储服务")
 	objectStorageChan := make(chan *service.ObjectStorageService, 1)
 	errorChan := make(chan error, 1)
 
@@ -73,34 +158,96 @@ func main() {
 	// 等待对象存储服务初始化，最多等待5秒
 	select {
 	case err := <-errorChan:
-		log.Printf("初始化对象存储服务失败: %v，将使用降级模式", err)
+		slog.Warn("初始化对象存储服务失败，将使用降级模式", "error", err)
 		objectStorageService = service.NewDegradedObjectStorageService()
 	case objectStorageService = <-objectStorageChan:
-		log.Println("对象存储服务初始化成功")
+		slog.Info("对象存储服务初始化成功")
 	case <-time.After(5 * time.Second):
-		log.Println("初始化对象存储服务超时，将使用降级模式")
+		slog.Warn("初始化对象存储服务超时，将使用降级模式")
 		objectStorageService = service.NewDegradedObjectStorageService()
 	}
+	objectStorageService.StartHealthCheck()
+	app.OnStop("对象存储健康检查", lifecycle.Func(objectStorageService.StopHealthCheck))
+
+	// 加载运行时配置，并定时同步其他实例上的修改
+	settingsService := service.NewSettingsService(repos)
+	if err := settingsService.Load(context.Background()); err != nil {
+		slog.Warn("加载运行时配置失败，将使用默认配置", "error", err)
+	}
+	settingsService.Start()
+	app.OnStop("运行时配置同步", lifecycle.Func(settingsService.Stop))
 
 	// 初始化文件服务
-	fileService := service.NewFileService(db, objectStorageService)
+	fileService := service.NewFileService(repos, objectStorageService, cacheGroup)
+
+	// 初始化标签服务
+	tagService := service.NewTagService(db, cacheGroup)
+
+	// 初始化关注流服务
+	feedService := service.NewFeedService(db, fileService, cacheGroup)
+
+	// 初始化发现页推荐服务
+	discoverService := service.NewDiscoverService(db, fileService)
+
+	// 初始化热门榜单服务并启动定时计算
+	trendingService := service.NewTrendingService(db, fileService)
+	trendingService.Start()
+	app.OnStop("热门榜单计算", lifecycle.Func(trendingService.Stop))
 
 	// 创建 ProfileService
-	profileService := service.NewProfileService(db, objectStorageService)
+	profileService := service.NewProfileService(repos, objectStorageService, feedService, cacheGroup, backgroundTasks)
 
 	// 创建 AuthService，传入 ProfileService
-	authService := service.NewAuthService(db, profileService)
+	authService := service.NewAuthService(repos, profileService)
+
+	// 初始化浏览计数服务，定时批量写入浏览数
+	viewService := service.NewViewService(db, cacheGroup)
+	viewService.Start()
+	app.OnStop("浏览数写入", viewService.Stop)
 
 	// 其他服务
-	postService := service.NewPostService(db, fileService)
-	adminService := service.NewAdminService(db)
+	postService := service.NewPostService(repos, fileService, feedService, tagService, cacheGroup, backgroundTasks)
+	adminService := service.NewAdminService(repos, postService)
+	reportService := service.NewReportService(db, cacheGroup)
+	analyticsService := service.NewAnalyticsService(db)
+	creatorService := service.NewCreatorService(db)
+
+	// 初始化计数对账服务并启动定时对账
+	reconcileService := service.NewReconcileService(db, cacheGroup)
+	reconcileService.Start()
+	app.OnStop("计数对账", lifecycle.Func(reconcileService.Stop))
 
 	authController := controller.NewAuthController(authService)
 	profileController := controller.NewProfileController(profileService)
-	postController := controller.NewPostController(postService)
+	postController := controller.NewPostController(postService, viewService)
 	adminController := controller.NewAdminController(adminService, objectStorageService)
-	uploadController := controller.NewUploadController(objectStorageService, fileService)
+	uploadController := controller.NewUploadController(objectStorageService, fileService, settingsService)
 	fileController := controller.NewFileController(fileService)
+	reportController := controller.NewReportController(reportService)
+	feedController := controller.NewFeedController(feedService, discoverService)
+	trendingController := controller.NewTrendingController(trendingService)
+	tagController := controller.NewTagController(tagService)
+	analyticsController := controller.NewAnalyticsController(analyticsService, creatorService)
+	reconcileController := controller.NewReconcileController(reconcileService)
+	settingsController := controller.NewSettingsController(settingsService)
+
+	// 健康检查：MongoDB 不可用时服务不能处理请求，其他组件不可用时只是部分功能降级
+	healthRegistry := health.NewRegistry(
+		time.Duration(config.GetConfig().Health.Timeout)*time.Second,
+		time.Duration(config.GetConfig().Health.CacheTTL)*time.Second,
+	)
+	healthRegistry.Register("mongodb", true, health.Ping(func(ctx context.Context) error {
+		return mongoClient.Ping(ctx, nil)
+	}))
+	healthRegistry.Register("storage", false, objectStorageService.Health)
+	if redisClient != nil {
+		healthRegistry.Register("redis", false, health.Ping(redisClient.Ping))
+	}
+	healthRegistry.Register("trending", false, trendingService.Health)
+	healthRegistry.Register("views", false, viewService.Health)
+	healthRegistry.Register("reconcile", false, reconcileService.Health)
+	healthRegistry.Register("settings", false, settingsService.Health)
+	healthController := controller.NewHealthController(healthRegistry)
 
 	// 设置路由
 	r := router.SetupRouter(
@@ -110,7 +257,17 @@ func main() {
 		adminController, 
 		uploadController, 
 		fileController,
-		mongoClient,
+		reportController,
+		feedController,
+		trendingController,
+		tagController,
+		analyticsController,
+		reconcileController,
+		settingsController,
+		healthController,
+		settingsService,
+		profileService,
+		rateLimitStore,
 	)
 
 	// 设置最大并发连接数
@@ -128,8 +285,69 @@ func main() {
 	http.DefaultTransport.(*http.Transport).MaxConnsPerHost = 100
 
 	// 启动服务器
-	log.Printf("服务器启动在 %s", server.Addr)
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("服务器启动失败: %v", err)
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("服务器启动", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	// 关闭时先停止接收新连接，并等待处理中的请求完成
+	app.OnStop("HTTP 服务", server.Shutdown)
+	// 最先将就绪检查置为失败，负载均衡不再转发新请求
+	app.OnStop("就绪检查", lifecycle.Func(healthRegistry.Drain))
+
+	// 等待退出信号
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-quit:
+		slog.Info("收到信号，开始关闭服务", "signal", sig.String())
+		// 恢复默认处理，再次收到信号时直接退出
+		signal.Stop(quit)
+	case err := <-serverErr:
+		slog.Error("服务器启动失败", "error", err)
+		shutdown(app)
+		os.Exit(1)
+	}
+
+	shutdown(app)
+}
+
+// shutdown 在配置的截止时间内停止所有组件
+func shutdown(app *lifecycle.Manager) {
+	timeout := time.Duration(config.GetConfig().Shutdown.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := app.Shutdown(ctx); err != nil {
+		slog.Error("服务未能完全关闭", "error", err)
+		return
+	}
+	slog.Info("服务已关闭")
+}
+
+// chainMonitors 将多个命令监听器合并为一个，客户端只能设置一个监听器
+func chainMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				m.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				m.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				m.Failed(ctx, e)
+			}
+		},
 	}
 }
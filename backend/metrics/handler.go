@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler 返回输出所有指标的 HTTP 处理函数
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,105 @@
+// Package metrics 定义 Prometheus 指标，通过 /metrics 接口暴露。
+//
+// 指标注册在默认的 Registry 中，包括 HTTP 请求、MongoDB 命令、对象存储、限流和业务计数。
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace 指标名前缀
+const namespace = "bluenote"
+
+// 对象存储操作的后端和结果
+const (
+	BackendObjectStorage = "object_storage"
+	BackendLocal         = "local"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// 登录结果
+const (
+	LoginSuccess         = "success"
+	LoginRegistered      = "registered"
+	LoginInvalidCaptcha  = "invalid_captcha"
+	LoginInvalidPassword = "invalid_password"
+	LoginBanned          = "banned"
+	LoginError           = "error"
+)
+
+var (
+	// HTTPRequests 按路由、方法和状态码统计的请求数，未匹配的路由记为 unmatched
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration 按路由和方法统计的请求耗时
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// MongoDuration MongoDB 命令耗时，status 为 success 或 error
+	MongoDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "MongoDB 命令耗时（秒）",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"command", "collection", "status"})
+
+	// StorageOperations 文件上传和删除的结果，backend 为 object_storage 或 local
+	StorageOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operations_total",
+		Help:      "文件存储操作数",
+	}, []string{"operation", "backend", "result"})
+
+	// StorageFallbacks 对象存储上传失败后降级到本地存储的次数
+	StorageFallbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_fallback_total",
+		Help:      "对象存储上传失败后降级到本地存储的次数",
+	})
+
+	// RateLimitRejections 被限流拒绝的请求数
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "被限流拒绝的请求数",
+	}, []string{"policy"})
+
+	// RateLimitErrors 限流后端出错而放行的请求数
+	RateLimitErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_errors_total",
+		Help:      "限流后端出错而放行的请求数",
+	}, []string{"policy"})
+
+	// PostsCreated 发布的笔记数，source 为 post（直接发布）或 draft（草稿发布）
+	PostsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_created_total",
+		Help:      "发布的笔记数",
+	}, []string{"source"})
+
+	// PostReviews 审核的笔记数，status 为审核结果
+	PostReviews = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "post_reviews_total",
+		Help:      "审核的笔记数",
+	}, []string{"status"})
+
+	// Logins 登录次数，result 为 success、registered（首次登录自动注册）或失败原因
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "登录次数",
+	}, []string{"result"})
+)
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor 返回记录命令耗时的 MongoDB 命令监听器，在创建客户端时通过 SetMonitor 设置
+func MongoMonitor() *event.CommandMonitor {
+	// 命令开始时记录集合名，结束事件中没有命令内容
+	var collections sync.Map
+
+	finish := func(requestID int64, command string, status string, seconds float64) {
+		collection := ""
+		if v, ok := collections.LoadAndDelete(requestID); ok {
+			collection = v.(string)
+		}
+		MongoDuration.WithLabelValues(command, collection, status).Observe(seconds)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			// 命令文档的第一个字段为命令名，值为集合名，如 {find: "posts", ...}
+			element, err := e.Command.IndexErr(0)
+			if err != nil {
+				return
+			}
+			if value, err := element.ValueErr(); err == nil {
+				if collection, ok := value.StringValueOK(); ok {
+					collections.Store(e.RequestID, collection)
+				}
+			}
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, e.CommandName, ResultSuccess, e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.CommandName, ResultError, e.Duration.Seconds())
+		},
+	}
+}
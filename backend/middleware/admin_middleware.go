@@ -1,8 +1,9 @@
 package middleware
 
 import (
+	"blue-note/apperr"
+
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
 // AdminMiddleware 检查用户是否具有管理员权限
@@ -10,10 +11,7 @@ func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole := c.GetString("role")
 		if userRole != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "无权限访问",
-			})
+			c.Error(apperr.ErrForbidden)
 			c.Abort()
 			return
 		}
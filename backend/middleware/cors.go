@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS 跨域中间件，允许的来源可以在运行中通过 SetOrigins 调整
+type CORS struct {
+	handler atomic.Value // gin.HandlerFunc
+}
+
+func NewCORS(origins []string) *CORS {
+	c := &CORS{}
+	c.SetOrigins(origins)
+	return c
+}
+
+// SetOrigins 替换允许的来源，对之后的请求生效
+func (c *CORS) SetOrigins(origins []string) {
+	c.handler.Store(cors.New(cors.Config{
+		AllowOrigins:     append([]string(nil), origins...),
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		ExposeHeaders:    []string{"Content-Length"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+}
+
+func (c *CORS) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.handler.Load().(gin.HandlerFunc)(ctx)
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"blue-note/apperr"
+	"blue-note/i18n"
+	"blue-note/logger"
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler 将处理函数和中间件通过 c.Error 记录的错误转换为统一格式的错误响应：
+//
+//	{"code": 40402, "message": "笔记不存在", "details": ..., "request_id": "..."}
+//
+// 业务错误按错误类型确定 HTTP 状态码，错误信息按请求的语言返回；其他错误返回 500，原始错误信息只记录在日志中
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		WriteError(c, c.Errors.Last().Err)
+	}
+}
+
+// WriteError 立即写入错误响应并中止请求
+func WriteError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	e := apperr.From(err)
+	if e.Kind == apperr.KindInternal {
+		slog.ErrorContext(ctx, "请求处理失败", "error", err)
+	} else if e.Unwrap() != nil {
+		slog.DebugContext(ctx, "请求处理失败", "code", e.Code, "error", err)
+	}
+
+	lang := i18n.FromContext(ctx)
+	body := gin.H{
+		"code":       e.Code,
+		"message":    e.Localize(lang),
+		"request_id": logger.RequestID(ctx),
+	}
+	if l, ok := e.Details.(i18n.Localizer); ok {
+		body["details"] = l.Localize(lang)
+	} else if e.Details != nil {
+		body["details"] = e.Details
+	}
+	c.AbortWithStatusJSON(e.Status(), body)
+}
+
+// Recovery 处理函数 panic 时返回统一格式的 500 错误，需要放在 ErrorHandler 之后
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, v any) {
+		c.Error(fmt.Errorf("panic: %v", v))
+		c.Abort()
+	})
+}
+
+// NotFound 未匹配到路由时返回统一格式的 404 错误
+func NotFound(c *gin.Context) {
+	c.Error(apperr.ErrNotFound)
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"blue-note/apperr"
 	"blue-note/config"
 	"blue-note/model"
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
@@ -19,6 +21,7 @@ type Claims struct {
 	Role     string `json:"role"`
 	Nickname string `json:"nickname"`
 	Avatar   string `json:"avatar"`
+	Language string `json:"language,omitempty"` // 语言偏好，修改后重新登录生效
 	jwt.RegisteredClaims
 }
 
@@ -32,16 +35,15 @@ func GenerateToken(user *model.User) (string, error) {
 		return "", fmt.Errorf("无效的用户ID格式: %v", err)
 	}
 	
-	expireHours := config.GetConfig().JWT.Expire
-	
 	claims := &Claims{
 		UserID:   userIDStr,
 		Username: user.Username,
 		Role:     user.Role,
 		Nickname: user.Nickname,
 		Avatar:   user.Avatar,
+		Language: user.Language,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expireHours))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.GetConfig().TokenTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -50,25 +52,39 @@ func GenerateToken(user *model.User) (string, error) {
 	return token.SignedString([]byte(config.GetConfig().JWT.Secret))
 }
 
-// AuthMiddleware 验证用户是否登录
-func AuthMiddleware() gin.HandlerFunc {
+// AccountChecker 查询账号状态，token 未过期的账号被封禁后也不能继续访问
+type AccountChecker interface {
+	IsBanned(ctx context.Context, userID string) (bool, error)
+}
+
+// checkAccount 校验 token 中的账号仍然存在且未被封禁
+func checkAccount(ctx context.Context, accounts AccountChecker, userID string) error {
+	banned, err := accounts.IsBanned(ctx, userID)
+	if errors.Is(err, apperr.ErrUserNotFound) || errors.Is(err, primitive.ErrInvalidHex) {
+		return apperr.ErrTokenInvalid.Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	if banned {
+		return apperr.ErrAccountBanned
+	}
+	return nil
+}
+
+// AuthMiddleware 验证用户是否登录，并拒绝已被封禁的账号
+func AuthMiddleware(accounts AccountChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "未提供认证信息",
-			})
+			c.Error(apperr.ErrTokenMissing)
 			c.Abort()
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "认证格式错误",
-			})
+			c.Error(apperr.ErrTokenFormat)
 			c.Abort()
 			return
 		}
@@ -80,32 +96,21 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// 检查错误类型，区分 token 过期和其他无效情况
 		if err != nil {
-			// 检查错误消息中是否包含"expired"关键词
-			errMsg := err.Error()
-			if strings.Contains(strings.ToLower(errMsg), "expired") {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"code":    401,
-					"message": "认证信息已过期，请重新登录",
-				})
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				c.Error(apperr.ErrTokenExpired)
 				c.Abort()
 				return
 			}
 			
 			// 其他类型的错误
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "无效的认证信息",
-			})
+			c.Error(apperr.ErrTokenInvalid)
 			c.Abort()
 			return
 		}
 
 		// token 解析成功但可能无效
 		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "无效的认证信息",
-			})
+			c.Error(apperr.ErrTokenInvalid)
 			c.Abort()
 			return
 		}
@@ -113,10 +118,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 从 MapClaims 中提取字段
 		userID, ok := claims["userId"].(string)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "无效的用户ID格式",
-			})
+			c.Error(apperr.ErrTokenInvalid)
 			c.Abort()
 			return
 		}
@@ -125,10 +127,13 @@ func AuthMiddleware() gin.HandlerFunc {
 		role, _ := claims["role"].(string)
 		nickname, _ := claims["nickname"].(string)
 		avatar, _ := claims["avatar"].(string)
+		language, _ := claims["language"].(string)
 		
-		// 验证ObjectID格式
-		if _, err := primitive.ObjectIDFromHex(userID); err != nil {
-			fmt.Printf("警告: 用户ID格式无效 (%s): %v\n", userID, err)
+		// 账号不存在或已被封禁时 token 失效
+		if err := checkAccount(c.Request.Context(), accounts, userID); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
 		}
 		
 		// 将用户信息设置到上下文
@@ -137,6 +142,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("role", role)
 		c.Set("nickname", nickname)
 		c.Set("avatar", avatar)
+		applyUserLanguage(c, language)
 		c.Next()
 	}
 }
@@ -151,10 +157,37 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{
-			"code":    403,
-			"message": "无权限访问",
-		})
+		c.Error(apperr.ErrForbidden)
 		c.Abort()
 	}
 }
+
+// OptionalAuthMiddleware 可选认证：携带有效token时将用户信息设置到上下文，否则按未登录处理，
+// 已被封禁的账号也按未登录处理
+func OptionalAuthMiddleware(accounts AccountChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.Next()
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(parts[1], claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(config.GetConfig().JWT.Secret), nil
+		})
+		if err != nil || !token.Valid {
+			c.Next()
+			return
+		}
+
+		if userID, ok := claims["userId"].(string); ok && checkAccount(c.Request.Context(), accounts, userID) == nil {
+			role, _ := claims["role"].(string)
+			language, _ := claims["language"].(string)
+			c.Set("userId", userID)
+			c.Set("role", role)
+			applyUserLanguage(c, language)
+		}
+		c.Next()
+	}
+}
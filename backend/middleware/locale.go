@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"blue-note/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale 根据 Accept-Language 请求头确定响应使用的语言，保存在请求上下文中并写入 Content-Language 响应头。
+// 登录用户设置了语言偏好时，认证中间件会用语言偏好覆盖
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		setLanguage(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// setLanguage 设置请求使用的语言
+func setLanguage(c *gin.Context, lang string) {
+	c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+	c.Header("Content-Language", lang)
+}
+
+// applyUserLanguage 使用 token 中的语言偏好，未设置或不支持时保持按请求头确定的语言
+func applyUserLanguage(c *gin.Context, lang string) {
+	if i18n.Supported(lang) {
+		setLanguage(c, lang)
+	}
+}
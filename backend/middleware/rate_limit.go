@@ -1,58 +1,107 @@
 package middleware
 
 import (
-	"net/http"
+	"blue-note/apperr"
+	"blue-note/metrics"
+	"blue-note/model"
+	"blue-note/ratelimit"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// RateLimiter 按限流策略限流，计数保存在 ratelimit.Store 中，多实例部署时使用 Redis 后端共享配额
 type RateLimiter struct {
-	ips   map[string][]time.Time
-	mutex sync.Mutex
+	store ratelimit.Store
 }
 
-func NewRateLimiter() *RateLimiter {
-	return &RateLimiter{
-		ips: make(map[string][]time.Time),
-	}
+func NewRateLimiter(store ratelimit.Store) *RateLimiter {
+	return &RateLimiter{store: store}
+}
+
+// RatePolicy 限流策略，运行中可以通过 Set 调整
+type RatePolicy struct {
+	name  string
+	mutex sync.RWMutex
+	rule  ratelimit.Rule
+	key   string
+}
+
+// NewRatePolicy 创建限流策略，key 为计数维度：ip、user 或 route
+func NewRatePolicy(name string, rule ratelimit.Rule, key string) *RatePolicy {
+	return &RatePolicy{name: name, rule: rule, key: key}
 }
 
-func (rl *RateLimiter) RateLimit(maxRequests int, duration time.Duration) gin.HandlerFunc {
+// Set 调整限流规则，对之后的请求生效
+func (p *RatePolicy) Set(rule ratelimit.Rule, key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.rule = rule
+	p.key = key
+}
+
+// Get 返回当前的限流规则和计数维度
+func (p *RatePolicy) Get() (ratelimit.Rule, string) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.rule, p.key
+}
+
+func (rl *RateLimiter) RateLimit(policy *RatePolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		
-		rl.mutex.Lock()
-		defer rl.mutex.Unlock()
-		
-		// 清理过期的请求记录
-		now := time.Now()
-		cutoff := now.Add(-duration)
-		
-		if _, exists := rl.ips[ip]; exists {
-			var validRequests []time.Time
-			for _, t := range rl.ips[ip] {
-				if t.After(cutoff) {
-					validRequests = append(validRequests, t)
-				}
+		rule, keyType := policy.Get()
+
+		var key string
+		switch keyType {
+		case model.RateLimitByRoute:
+			key = "route:" + c.Request.Method + " " + c.FullPath()
+		case model.RateLimitByUser:
+			// 未登录时按 IP 计数
+			if userID := c.GetString("userId"); userID != "" {
+				key = "user:" + userID
+				break
 			}
-			rl.ips[ip] = validRequests
+			fallthrough
+		default:
+			key = "ip:" + c.ClientIP()
 		}
-		
-		// 检查请求数量
-		if len(rl.ips[ip]) >= maxRequests {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"code":    42900,
-				"message": "请求过于频繁，请稍后再试",
-			})
+
+		result, err := rl.store.Allow(c.Request.Context(), policy.name+":"+key, rule)
+		if err != nil {
+			// 限流后端不可用时放行，避免影响正常请求
+			slog.WarnContext(c.Request.Context(), "限流检查失败", "policy", policy.name, "error", err)
+			metrics.RateLimitErrors.WithLabelValues(policy.name).Inc()
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(seconds(result.ResetAfter)))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rule.Limit, seconds(rule.Window)))
+
+		if !result.Allowed {
+			metrics.RateLimitRejections.WithLabelValues(policy.name).Inc()
+			header.Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.Error(apperr.ErrRateLimited)
 			c.Abort()
 			return
 		}
-		
-		// 记录本次请求
-		rl.ips[ip] = append(rl.ips[ip], now)
-		
+
 		c.Next()
 	}
-} 
\ No newline at end of file
+}
+
+// seconds 向上取整为秒
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
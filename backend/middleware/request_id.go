@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"blue-note/logger"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength 客户端传入的请求ID的最大长度
+const maxRequestIDLength = 64
+
+// RequestID 为每个请求分配请求ID并写入响应头。客户端或网关通过 X-Request-ID 传入合法的ID时沿用，
+// 否则生成新的ID。请求ID保存在请求上下文中，服务中使用该上下文记录的日志都会带上请求ID
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(logger.HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+		c.Header(logger.HeaderRequestID, requestID)
+		c.Next()
+	}
+}
+
+// validRequestID 只接受长度有限的字母、数字和 -_.: 组成的ID，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog 请求完成后记录访问日志，5xx 记为错误，4xx 记为警告
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if route := c.FullPath(); route != "" {
+			attrs = append(attrs, slog.String("route", route))
+		}
+		if userID := c.GetString("userId"); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if errs := c.Errors.String(); errs != "" {
+			attrs = append(attrs, slog.String("error", errs))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "请求完成", attrs...)
+	}
+}
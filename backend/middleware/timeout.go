@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"blue-note/apperr"
+	"blue-note/config"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout 为每个请求设置超时时间，超时后请求上下文被取消，
+// 数据库查询、对象存储和图片尺寸获取等操作随之中断
+func Timeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := routeTimeout(c.Request.Method, c.FullPath())
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// 处理函数未写入响应时返回超时错误
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.Error(apperr.ErrTimeout)
+			c.Abort()
+		}
+	}
+}
+
+// routeTimeout 返回路由的超时时间，未单独配置的路由使用默认值
+func routeTimeout(method, path string) time.Duration {
+	cfg := config.GetConfig().Timeout
+	// viper 读取配置时会将键转为小写
+	if seconds, ok := cfg.Routes[strings.ToLower(method+" "+path)]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(cfg.Default) * time.Second
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"blue-note/config"
+	"blue-note/migrations"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const migrateUsage = `用法:
+  blue-note migrate [run] [version]   执行未执行的迁移，指定 version 时只执行到该版本
+  blue-note migrate list              查看迁移执行状态
+  blue-note migrate rollback [steps]  回滚最近执行的 steps 个迁移，默认 1 个`
+
+// runMigrateCommand 执行 migrate 子命令，args 为 migrate 之后的参数
+func runMigrateCommand(db *mongo.Database, args []string) error {
+	ctx := context.Background()
+	migrator := migrations.NewMigrator(db)
+
+	action := "run"
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+
+	switch action {
+	case "run", "up":
+		target := 0
+		if len(args) > 0 {
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version < 1 {
+				return fmt.Errorf("无效的版本号: %s", args[0])
+			}
+			target = version
+		}
+
+		done, err := migrator.Up(ctx, target)
+		for _, migration := range done {
+			fmt.Printf("已执行 %d: %s\n", migration.Version, migration.Description)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("没有需要执行的迁移")
+		}
+
+		if err := migrations.EnsureIndexes(ctx, db); err != nil {
+			return fmt.Errorf("创建索引失败: %w", err)
+		}
+		return nil
+
+	case "list", "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "未执行"
+			if status.Applied {
+				state = "已执行 " + status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-28s  %s\n", status.Version, state, status.Description)
+		}
+		return nil
+
+	case "rollback", "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("无效的回滚步数: %s", args[0])
+			}
+			steps = n
+		}
+
+		done, err := migrator.Down(ctx, steps)
+		for _, migration := range done {
+			fmt.Printf("已回滚 %d: %s\n", migration.Version, migration.Description)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("没有可以回滚的迁移")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("未知的 migrate 命令: %s\n%s", action, migrateUsage)
+	}
+}
+
+// autoMigrate 启动时执行未执行的迁移并创建索引，失败时只记录日志不阻止启动
+func autoMigrate(db *mongo.Database) {
+	ctx := context.Background()
+
+	if config.GetConfig().Migration.Auto {
+		done, err := migrations.NewMigrator(db).Up(ctx, 0)
+		if err != nil {
+			slog.Error("执行数据库迁移失败", "error", err)
+		} else if len(done) > 0 {
+			slog.Info("已执行数据库迁移", "count", len(done))
+		}
+	} else if pending, err := migrations.NewMigrator(db).Pending(ctx); err == nil && pending > 0 {
+		slog.Warn("有数据库迁移未执行，请运行 migrate 命令", "pending", pending)
+	}
+
+	if err := migrations.EnsureIndexes(ctx, db); err != nil {
+		slog.Error("创建索引失败", "error", err)
+	}
+}
+
+// isMigrateCommand 判断是否以 migrate 子命令启动
+func isMigrateCommand() bool {
+	return len(os.Args) > 1 && os.Args[1] == "migrate"
+}
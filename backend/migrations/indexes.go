@@ -0,0 +1,118 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index 索引定义，索引名使用 MongoDB 默认的命名规则（如 post_id_1_user_id_1）
+type Index struct {
+	Collection string
+	Keys       bson.D
+	Unique     bool
+	TTL        *int32 // 过期时间（秒），用于 TTL 索引
+}
+
+func asc(fields ...string) bson.D {
+	keys := bson.D{}
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: 1})
+	}
+	return keys
+}
+
+func ttl(seconds int32) *int32 {
+	return &seconds
+}
+
+// Indexes 索引注册表，启动时通过 EnsureIndexes 创建
+var Indexes = []Index{
+	// 用户
+	{Collection: "users", Keys: asc("username"), Unique: true},
+
+	// 笔记
+	{Collection: "posts", Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	{Collection: "posts", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	{Collection: "posts", Keys: asc("tags")},
+	{Collection: "posts", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "feed_distribution", Value: 1}, {Key: "_id", Value: -1}}},
+
+	// 点赞、收藏、评论
+	{Collection: "post_likes", Keys: asc("post_id", "user_id"), Unique: true},
+	{Collection: "post_likes", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	{Collection: "post_collections", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	{Collection: "post_collections", Keys: asc("post_id")},
+	{Collection: "comments", Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "score", Value: -1}}},
+	{Collection: "comments", Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	{Collection: "comment_likes", Keys: asc("comment_id", "user_id"), Unique: true},
+
+	// 关注、拉黑、屏蔽
+	{Collection: "user_follows", Keys: asc("user_id", "following_id"), Unique: true},
+	{Collection: "user_follows", Keys: bson.D{{Key: "following_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	{Collection: "user_blocks", Keys: asc("user_id", "blocked_id"), Unique: true},
+	{Collection: "user_blocks", Keys: asc("blocked_id")},
+	{Collection: "user_mutes", Keys: asc("user_id", "muted_id"), Unique: true},
+
+	// 文件
+	{Collection: "file_records", Keys: asc("file_path")},
+	{Collection: "file_records", Keys: asc("url")},
+
+	// 举报
+	{Collection: "reports", Keys: asc("target_type", "target_id", "status")},
+	{Collection: "reports", Keys: asc("reporter_id", "target_type", "target_id"), Unique: true},
+
+	// 关注流、发现页、热门榜单
+	{Collection: "feed_inbox", Keys: asc("user_id", "post_id"), Unique: true},
+	{Collection: "feed_inbox", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "author_id", Value: 1}, {Key: "post_id", Value: -1}}},
+	{Collection: "feed_inbox", Keys: asc("post_id")},
+	{Collection: "discover_sessions", Keys: asc("expire_at"), TTL: ttl(0)},
+	{Collection: "trending", Keys: asc("window", "kind"), Unique: true},
+
+	// 标签
+	{Collection: "tags", Keys: asc("name"), Unique: true},
+	{Collection: "tags", Keys: asc("aliases")},
+	{Collection: "tag_follows", Keys: asc("user_id", "tag_id"), Unique: true},
+	{Collection: "tag_follows", Keys: asc("tag_id")},
+
+	// 统计
+	{Collection: "post_daily_stats", Keys: asc("post_id", "date"), Unique: true},
+	{Collection: "post_daily_stats", Keys: asc("author_id", "date")},
+	{Collection: "reconcile_reports", Keys: bson.D{{Key: "started_at", Value: -1}}},
+
+	// 运行时配置
+	{Collection: "settings_history", Keys: bson.D{{Key: "version", Value: -1}}},
+}
+
+// EnsureIndexes 创建注册表中的所有索引，已存在的索引会被跳过。
+// 单个索引创建失败（如存在重复数据）不影响其他索引，所有错误合并返回
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	var errs []error
+	for _, index := range Indexes {
+		opts := options.Index()
+		if index.Unique {
+			opts.SetUnique(true)
+		}
+		if index.TTL != nil {
+			opts.SetExpireAfterSeconds(*index.TTL)
+		}
+
+		_, err := db.Collection(index.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    index.Keys,
+			Options: opts,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %v: %w", index.Collection, index.Keys, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	slog.Info("索引检查完成", "count", len(Indexes))
+	return nil
+}
@@ -0,0 +1,237 @@
+// Package migrations 管理数据库结构版本：按版本号顺序执行的数据迁移，以及启动时创建的索引。
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	migrationsCollection = "schema_migrations"
+	lockCollection       = "schema_migrations_lock"
+	lockTTL              = 10 * time.Minute
+)
+
+// Migration 一次数据迁移，Down 为空表示不支持回滚
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record 已执行的迁移记录
+type Record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Status 迁移状态
+type Status struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// registry 已注册的迁移，按版本号升序排列
+var registry []Migration
+
+// register 注册迁移，版本号不能重复
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("迁移版本号重复: %d", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Version < registry[j].Version
+	})
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db *mongo.Database
+}
+
+// NewMigrator 创建迁移执行器
+func NewMigrator(db *mongo.Database) *Migrator {
+	return &Migrator{db: db}
+}
+
+// applied 查询已执行的迁移
+func (m *Migrator) applied(ctx context.Context) (map[int]Record, error) {
+	cursor, err := m.db.Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %w", err)
+	}
+
+	var records []Record
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]Record, len(records))
+	for _, record := range records {
+		result[record.Version] = record
+	}
+	return result, nil
+}
+
+// Status 返回所有迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(registry))
+	for _, migration := range registry {
+		record, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     ok,
+			AppliedAt:   record.AppliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// Pending 返回尚未执行的迁移数
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// Up 按版本号顺序执行未执行的迁移，target 为 0 时执行到最新版本
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range registry {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		slog.Info("执行迁移", "version", migration.Version, "description", migration.Description)
+		if err := migration.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("执行迁移 %d 失败: %w", migration.Version, err)
+		}
+
+		_, err := m.db.Collection(migrationsCollection).InsertOne(ctx, Record{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return done, fmt.Errorf("保存迁移记录 %d 失败: %w", migration.Version, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down 按版本号倒序回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, errors.New("回滚步数必须大于 0")
+	}
+
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(registry) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := registry[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("迁移 %d 不支持回滚", migration.Version)
+		}
+
+		slog.Info("回滚迁移", "version", migration.Version, "description", migration.Description)
+		if err := migration.Down(ctx, m.db); err != nil {
+			return done, fmt.Errorf("回滚迁移 %d 失败: %w", migration.Version, err)
+		}
+
+		_, err := m.db.Collection(migrationsCollection).DeleteOne(ctx, bson.M{"_id": migration.Version})
+		if err != nil {
+			return done, fmt.Errorf("删除迁移记录 %d 失败: %w", migration.Version, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// lock 获取迁移锁，防止多个实例同时执行迁移；锁超过 lockTTL 未释放时视为失效
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	hostname, _ := os.Hostname()
+	now := time.Now()
+
+	_, err := m.db.Collection(lockCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": "lock", "expire_at": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{
+			"owner":     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+			"expire_at": now.Add(lockTTL),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("其他实例正在执行迁移")
+		}
+		return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+
+	return func() {
+		if _, err := m.db.Collection(lockCollection).DeleteOne(context.Background(), bson.M{"_id": "lock"}); err != nil {
+			slog.Error("释放迁移锁失败", "error", err)
+		}
+	}, nil
+}
@@ -0,0 +1,351 @@
+package migrations
+
+import (
+	"blue-note/model"
+	"blue-note/service"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 新增迁移时在此注册，版本号递增，已发布的迁移不要修改
+func init() {
+	register(Migration{
+		Version:     1,
+		Description: "清理重复的互动和关系记录，为唯一索引做准备",
+		Up:          dedupeRelations,
+		// 删除的重复记录无法恢复，回滚时不做处理
+		Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+	})
+
+	register(Migration{
+		Version:     2,
+		Description: "补全笔记和用户计数字段的默认值",
+		Up:          backfillCounterFields,
+		// 补全的默认值不影响旧版本，回滚时不做处理
+		Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+	})
+
+	register(Migration{
+		Version:     3,
+		Description: "根据已有笔记的标签创建标签实体并归一化笔记标签",
+		Up:          createTagsFromPosts,
+		Down:        removeMigratedTags,
+	})
+
+	register(Migration{
+		Version:     4,
+		Description: "将封禁状态从用户心情状态中迁移到独立的 banned 字段",
+		Up:          moveBanToField,
+		Down:        moveBanToStatus,
+	})
+
+	register(Migration{
+		Version:     5,
+		Description: "清理重复举报并删除旧的举报索引，为举报唯一索引做准备",
+		Up:          dedupeReports,
+		// 删除的重复举报无法恢复，旧索引只用于查询，回滚时不做处理
+		Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+	})
+
+	register(Migration{
+		Version:     6,
+		Description: "为当前使用读扩散的作者的已有笔记记录分发方式",
+		Up:          markPullPosts,
+		Down:        unmarkFeedDistribution,
+	})
+}
+
+// uniqueKeys 需要去重的集合及其唯一键
+var uniqueKeys = []struct {
+	collection string
+	keys       []string
+}{
+	{collection: "post_likes", keys: []string{"post_id", "user_id"}},
+	{collection: "comment_likes", keys: []string{"comment_id", "user_id"}},
+	{collection: "user_follows", keys: []string{"user_id", "following_id"}},
+	{collection: "user_blocks", keys: []string{"user_id", "blocked_id"}},
+	{collection: "user_mutes", keys: []string{"user_id", "muted_id"}},
+	{collection: "tag_follows", keys: []string{"user_id", "tag_id"}},
+	{collection: "feed_inbox", keys: []string{"user_id", "post_id"}},
+}
+
+// dedupeRelations 删除唯一键重复的记录，每组保留最早的一条；计数偏差由对账任务修正
+func dedupeRelations(ctx context.Context, db *mongo.Database) error {
+	for _, unique := range uniqueKeys {
+		if err := dedupeCollection(ctx, db, unique.collection, unique.keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dedupeCollection 删除集合中 keys 重复的记录，每组保留最早的一条
+func dedupeCollection(ctx context.Context, db *mongo.Database, collection string, keys []string) error {
+	groupID := bson.M{}
+	for _, key := range keys {
+		groupID[key] = "$" + key
+	}
+
+	cursor, err := db.Collection(collection).Aggregate(ctx, []bson.M{
+		{"$sort": bson.M{"_id": 1}},
+		{"$group": bson.M{
+			"_id":   groupID,
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return fmt.Errorf("查询 %s 重复记录失败: %w", collection, err)
+	}
+
+	var groups []struct {
+		IDs []interface{} `bson:"ids"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	var removed int64
+	for _, group := range groups {
+		result, err := db.Collection(collection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}})
+		if err != nil {
+			return fmt.Errorf("清理 %s 重复记录失败: %w", collection, err)
+		}
+		removed += result.DeletedCount
+	}
+	if removed > 0 {
+		slog.Info("已清理重复记录", "collection", collection, "removed", removed)
+	}
+	return nil
+}
+
+// backfillCounterFields 为缺少计数字段的旧文档补全默认值
+func backfillCounterFields(ctx context.Context, db *mongo.Database) error {
+	defaults := map[string]bson.M{
+		"posts": {
+			"likes":        0,
+			"comments":     0,
+			"collections":  0,
+			"views":        0,
+			"report_count": 0,
+			"hidden":       false,
+		},
+		"users": {
+			"follow_count":  0,
+			"fans_count":    0,
+			"like_count":    0,
+			"collect_count": 0,
+			"post_count":    0,
+			"report_count":  0,
+			"hidden":        false,
+		},
+	}
+
+	for collection, fields := range defaults {
+		for field, value := range fields {
+			_, err := db.Collection(collection).UpdateMany(
+				ctx,
+				bson.M{field: bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{field: value}},
+			)
+			if err != nil {
+				return fmt.Errorf("补全 %s.%s 失败: %w", collection, field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// createTagsFromPosts 为笔记中出现过的标签创建标签实体，并将笔记标签替换为归一化后的名称
+func createTagsFromPosts(ctx context.Context, db *mongo.Database) error {
+	values, err := db.Collection("posts").Distinct(ctx, "tags", bson.M{})
+	if err != nil {
+		return fmt.Errorf("查询笔记标签失败: %w", err)
+	}
+
+	now := time.Now()
+	for _, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		name := service.NormalizeTagName(raw)
+		if name == "" {
+			continue
+		}
+
+		_, err := db.Collection("tags").UpdateOne(
+			ctx,
+			bson.M{"$or": []bson.M{{"name": name}, {"aliases": name}}},
+			bson.M{"$setOnInsert": bson.M{
+				"name":           name,
+				"aliases":        []string{},
+				"description":    "",
+				"post_count":     0,
+				"follower_count": 0,
+				"migrated":       true, // 标记由迁移创建，回滚时删除
+				"created_at":     now,
+				"updated_at":     now,
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return fmt.Errorf("创建标签 %s 失败: %w", name, err)
+		}
+
+		if raw != name {
+			filter := bson.M{"tags": raw}
+			if _, err := db.Collection("posts").UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"tags": name}}); err != nil {
+				return fmt.Errorf("归一化笔记标签失败: %w", err)
+			}
+			if _, err := db.Collection("posts").UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"tags": raw}}); err != nil {
+				return fmt.Errorf("归一化笔记标签失败: %w", err)
+			}
+		}
+	}
+
+	// 统计每个标签的审核通过笔记数
+	cursor, err := db.Collection("posts").Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"status": "approved"}},
+		{"$unwind": "$tags"},
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("统计标签笔记数失败: %w", err)
+	}
+
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &counts); err != nil {
+		return err
+	}
+	for _, count := range counts {
+		_, err := db.Collection("tags").UpdateOne(
+			ctx,
+			bson.M{"name": count.Name},
+			bson.M{"$set": bson.M{"post_count": count.Count}},
+		)
+		if err != nil {
+			return fmt.Errorf("更新标签笔记数失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeMigratedTags 删除由迁移创建且未被编辑或关注过的标签，笔记标签的归一化不回滚
+func removeMigratedTags(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("tags").DeleteMany(ctx, bson.M{
+		"migrated":       true,
+		"aliases":        bson.M{"$size": 0},
+		"follower_count": 0,
+	})
+	if err != nil {
+		return fmt.Errorf("删除迁移创建的标签失败: %w", err)
+	}
+	return nil
+}
+
+// moveBanToField 旧版本通过 status 为 banned 表示封禁，改为独立的 banned 字段并清空 status
+func moveBanToField(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	if _, err := users.UpdateMany(ctx,
+		bson.M{"status": "banned"},
+		bson.M{"$set": bson.M{"banned": true, "status": ""}},
+	); err != nil {
+		return fmt.Errorf("迁移封禁状态失败: %w", err)
+	}
+	if _, err := users.UpdateMany(ctx,
+		bson.M{"banned": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"banned": false}},
+	); err != nil {
+		return fmt.Errorf("补全封禁字段失败: %w", err)
+	}
+	return nil
+}
+
+// moveBanToStatus 回滚时把封禁写回 status，旧版本据此禁止登录
+func moveBanToStatus(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection("users").UpdateMany(ctx,
+		bson.M{"banned": true},
+		bson.M{"$set": bson.M{"status": "banned"}},
+	); err != nil {
+		return fmt.Errorf("回滚封禁状态失败: %w", err)
+	}
+	return nil
+}
+
+// dedupeReports 同一举报人对同一对象只保留最早的举报，并删除被唯一索引取代的旧索引
+func dedupeReports(ctx context.Context, db *mongo.Database) error {
+	if err := dedupeCollection(ctx, db, "reports", []string{"reporter_id", "target_type", "target_id"}); err != nil {
+		return err
+	}
+
+	// 集合或旧索引不存在（新部署）时无需删除
+	_, err := db.Collection("reports").Indexes().DropOne(ctx, "reporter_id_1_target_id_1")
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound")) {
+		return fmt.Errorf("删除旧的举报索引失败: %w", err)
+	}
+	return nil
+}
+
+// markPullPosts 旧版本不记录笔记的分发方式，按作者当前的粉丝数推断：使用读扩散的作者的笔记没有写入收件箱，
+// 记为读扩散，作者之后改为写扩散时仍能拉取到；其余笔记视为已写入收件箱
+func markPullPosts(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection("users").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"fans_count": 1}))
+	if err != nil {
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	var users []model.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	var pullAuthors []primitive.ObjectID
+	for _, user := range users {
+		if !service.UsesFanout(user.FansCount) {
+			pullAuthors = append(pullAuthors, user.ID)
+		}
+	}
+	if len(pullAuthors) == 0 {
+		return nil
+	}
+
+	result, err := db.Collection("posts").UpdateMany(ctx,
+		bson.M{
+			"user_id":           bson.M{"$in": pullAuthors},
+			"status":            "approved",
+			"feed_distribution": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"feed_distribution": model.FeedDistributionPull}},
+	)
+	if err != nil {
+		return fmt.Errorf("记录笔记分发方式失败: %w", err)
+	}
+	slog.Info("已记录笔记分发方式", "authors", len(pullAuthors), "posts", result.ModifiedCount)
+	return nil
+}
+
+// unmarkFeedDistribution 回滚时删除笔记的分发方式，旧版本按作者当前的粉丝数选择读取方式
+func unmarkFeedDistribution(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection("posts").UpdateMany(ctx,
+		bson.M{"feed_distribution": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"feed_distribution": ""}},
+	); err != nil {
+		return fmt.Errorf("删除笔记分发方式失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// PostDailyStat 笔记每日浏览统计
+type PostDailyStat struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	PostID   primitive.ObjectID `bson:"post_id"`
+	AuthorID primitive.ObjectID `bson:"author_id"`
+	Date     string             `bson:"date"` // 格式 2006-01-02
+	Views    int                `bson:"views"`
+}
+
+// AnalyticsQuery 数据分析查询参数
+type AnalyticsQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"` // 统计最近多少天，默认 30
+}
+
+// DailyMetrics 每日数据
+type DailyMetrics struct {
+	Date         string `json:"date"`
+	Views        int    `json:"views"`
+	Likes        int    `json:"likes"`
+	Comments     int    `json:"comments"`
+	Collects     int    `json:"collects"`
+	FollowerGain int    `json:"followerGain"` // 作者当日新增粉丝
+}
+
+// PostAnalyticsTotals 笔记累计数据
+type PostAnalyticsTotals struct {
+	Views    int `json:"views"`
+	Likes    int `json:"likes"`
+	Comments int `json:"comments"`
+	Collects int `json:"collects"`
+}
+
+// PostAnalyticsResponse 笔记数据分析响应
+type PostAnalyticsResponse struct {
+	PostID string              `json:"postId"`
+	Title  string              `json:"title"`
+	Totals PostAnalyticsTotals `json:"totals"`
+	Daily  []DailyMetrics      `json:"daily"`
+}
+
+// CreatorDailyMetrics 创作者每日数据
+type CreatorDailyMetrics struct {
+	DailyMetrics
+	Posts int `json:"posts"` // 当日发布并审核通过的笔记数
+}
+
+// CreatorTotals 创作者累计数据
+type CreatorTotals struct {
+	PostCount    int `json:"postCount"`    // 审核通过的笔记数
+	LikeCount    int `json:"likeCount"`    // 所有笔记获得的点赞数
+	CollectCount int `json:"collectCount"` // 所有笔记获得的收藏数
+	FansCount    int `json:"fansCount"`
+	FollowCount  int `json:"followCount"`
+	Views        int `json:"views"` // 所有笔记的浏览数
+}
+
+// CreatorDashboardResponse 创作者数据看板响应
+type CreatorDashboardResponse struct {
+	Totals CreatorTotals         `json:"totals"`
+	Daily  []CreatorDailyMetrics `json:"daily"`
+}
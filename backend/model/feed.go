@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 关注流分发策略
+const (
+	FeedStrategyRead   = "read"   // 读扩散：读取时从关注作者的笔记中拉取
+	FeedStrategyWrite  = "write"  // 写扩散：笔记审核通过后推送到粉丝收件箱
+	FeedStrategyHybrid = "hybrid" // 混合：粉丝数不超过阈值的作者写扩散，其余读扩散
+)
+
+// 笔记审核通过时实际使用的分发方式，记录在笔记的 feed_distribution 字段。
+// 作者粉丝数跨过阈值后分发策略会变化，读取时按笔记记录的方式查找，之前的笔记不会丢失
+const (
+	FeedDistributionPush = "push" // 已写入粉丝收件箱
+	FeedDistributionPull = "pull" // 未写入收件箱，读取时从作者的笔记中拉取
+)
+
+// FeedInboxItem 关注流收件箱条目，写扩散时每个粉丝一条
+type FeedInboxItem struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"userId"`     // 收件人（粉丝）ID
+	PostID    primitive.ObjectID `bson:"post_id" json:"postId"`     // 笔记ID
+	AuthorID  primitive.ObjectID `bson:"author_id" json:"authorId"` // 笔记作者ID
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// FeedQuery 关注流查询参数
+type FeedQuery struct {
+	Cursor string `form:"cursor" binding:"omitempty"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// DiscoverQuery 发现页查询参数
+type DiscoverQuery struct {
+	Cursor string `form:"cursor" binding:"omitempty"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// DiscoverSession 发现页排序快照，保证同一次浏览中翻页的结果稳定
+type DiscoverSession struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty"`
+	UserID      primitive.ObjectID   `bson:"user_id,omitempty"` // 未登录时为空
+	PostIDs     []primitive.ObjectID `bson:"post_ids"`          // 排序后的笔记ID
+	PageSize    int                  `bson:"page_size"`         // 每页数量，多样性规则按页生效
+	PageOffsets []int                `bson:"page_offsets"`      // 每页在 PostIDs 中的起始位置，受作者限制的页可能不满 PageSize
+	CreatedAt   time.Time            `bson:"created_at"`
+	ExpireAt    time.Time            `bson:"expire_at"`
+}
@@ -21,6 +21,12 @@ type Post struct {
 	Avatar    string            `bson:"avatar" json:"avatar"`
 	Likes     int               `bson:"likes" json:"likes"`
 	Comments  int               `bson:"comments" json:"comments"`
+	Collections int             `bson:"collections" json:"collections"` // 收藏数
+	Views       int             `bson:"views" json:"views"`             // 浏览数
+	RejectReason string         `bson:"reject_reason,omitempty" json:"rejectReason,omitempty"` // 审核拒绝原因
+	Hidden      bool            `bson:"hidden" json:"hidden"`            // 是否因举报被隐藏
+	ReportCount int             `bson:"report_count" json:"reportCount"` // 待处理举报数
+	FeedDistribution string     `bson:"feed_distribution,omitempty" json:"-"` // 审核通过时的关注流分发方式：push/pull
 	CreatedAt time.Time         `bson:"created_at" json:"createdAt"`
 	UpdatedAt time.Time         `bson:"updated_at" json:"updatedAt"`
 }
@@ -73,6 +79,8 @@ type Comment struct {
 	Score     float64           `bson:"score" json:"score"` // 综合评分
 	IsAuthor  bool              `bson:"is_author" json:"is_author"` // 是否是作者评论
 	IsAdmin   bool              `bson:"is_admin" json:"is_admin"` // 是否是管理员评论
+	Hidden      bool            `bson:"hidden" json:"hidden"`             // 是否因举报被隐藏
+	ReportCount int             `bson:"report_count" json:"report_count"` // 待处理举报数
 }
 
 type CreateCommentRequest struct {
@@ -94,6 +102,12 @@ type PostLike struct {
 	CreatedAt time.Time         `bson:"created_at"`
 }
 
+// LikeState 点赞操作后的状态
+type LikeState struct {
+	Liked bool `json:"liked"` // 当前用户是否已点赞
+	Likes int  `json:"likes"` // 当前点赞数
+}
+
 // 评论点赞记录
 type CommentLike struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
@@ -123,6 +137,7 @@ type PostListItem struct {
 	Avatar       string             `json:"avatar"`
 	Likes        int                `json:"likes"`
 	Comments     int                `json:"comments"`
+	Views        int                `json:"views"`
 	LikeCount    int                `json:"likeCount"`
 	CommentCount int                `json:"commentCount"`
 	CollectCount int                `json:"collectCount"`
@@ -169,5 +184,6 @@ type PostItem struct {
 	Avatar    string    `json:"avatar"`
 	Likes     int       `json:"likes"`
 	Comments  int       `json:"comments"`
+	Views     int       `json:"views"`
 	CreatedAt time.Time `json:"createdAt"`
 }
\ No newline at end of file
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReconcileDiscrepancy 计数不一致记录
+type ReconcileDiscrepancy struct {
+	Collection string             `bson:"collection" json:"collection"` // 计数所在集合
+	DocumentID primitive.ObjectID `bson:"document_id" json:"documentId"`
+	Field      string             `bson:"field" json:"field"`   // 计数字段
+	Stored     int                `bson:"stored" json:"stored"` // 存储的值
+	Actual     int                `bson:"actual" json:"actual"` // 根据明细重新计算的值
+	Fixed      bool               `bson:"fixed" json:"fixed"`   // 是否已修正
+}
+
+// ReconcileReport 计数对账报告
+type ReconcileReport struct {
+	ID               primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Trigger          string                 `bson:"trigger" json:"trigger"` // schedule-定时任务 manual-管理员手动触发
+	DryRun           bool                   `bson:"dry_run" json:"dryRun"`  // 只检查不修正
+	Checked          int                    `bson:"checked" json:"checked"` // 检查的文档数
+	DiscrepancyCount int                    `bson:"discrepancy_count" json:"discrepancyCount"`
+	Discrepancies    []ReconcileDiscrepancy `bson:"discrepancies" json:"discrepancies"` // 最多保存前 1000 条
+	Error            string                 `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt        time.Time              `bson:"started_at" json:"startedAt"`
+	FinishedAt       time.Time              `bson:"finished_at" json:"finishedAt"`
+}
+
+// ReconcileRequest 手动触发对账请求
+type ReconcileRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+// ReconcileReportQuery 对账报告查询参数
+type ReconcileReportQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// ReconcileReportListResponse 对账报告列表响应
+type ReconcileReportListResponse struct {
+	Total int64              `json:"total"`
+	List  []*ReconcileReport `json:"list"`
+}
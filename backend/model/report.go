@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportTargetType 举报对象类型
+type ReportTargetType string
+
+const (
+	ReportTargetPost    ReportTargetType = "post"    // 笔记
+	ReportTargetComment ReportTargetType = "comment" // 评论
+	ReportTargetUser    ReportTargetType = "user"    // 用户
+)
+
+// ReportStatus 举报处理状态
+type ReportStatus string
+
+const (
+	ReportStatusPending   ReportStatus = "pending"   // 待处理
+	ReportStatusResolved  ReportStatus = "resolved"  // 举报成立，已处理
+	ReportStatusDismissed ReportStatus = "dismissed" // 举报不成立，已驳回
+)
+
+// 举报原因分类
+const (
+	ReportReasonSpam       = "spam"       // 垃圾广告
+	ReportReasonPorn       = "porn"       // 色情低俗
+	ReportReasonViolence   = "violence"   // 暴力血腥
+	ReportReasonHarassment = "harassment" // 辱骂骚扰
+	ReportReasonFraud      = "fraud"      // 诈骗
+	ReportReasonIllegal    = "illegal"    // 违法违规
+	ReportReasonPlagiarism = "plagiarism" // 抄袭侵权
+	ReportReasonOther      = "other"      // 其他
+)
+
+// Report 举报记录
+type Report struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TargetType  ReportTargetType    `bson:"target_type" json:"targetType"`                   // 举报对象类型
+	TargetID    primitive.ObjectID  `bson:"target_id" json:"targetId"`                       // 举报对象ID
+	TargetOwner primitive.ObjectID  `bson:"target_owner" json:"targetOwner"`                 // 被举报内容的作者（举报用户时为用户本身）
+	PostID      primitive.ObjectID  `bson:"post_id,omitempty" json:"postId,omitempty"`       // 评论所属笔记ID
+	ReporterID  primitive.ObjectID  `bson:"reporter_id" json:"reporterId"`                   // 举报人ID
+	Reason      string              `bson:"reason" json:"reason"`                            // 举报原因分类
+	Description string              `bson:"description" json:"description"`                  // 补充说明
+	Status      ReportStatus        `bson:"status" json:"status"`                            // 处理状态
+	HandlerID   *primitive.ObjectID `bson:"handler_id,omitempty" json:"handlerId,omitempty"` // 处理人ID
+	HandleNote  string              `bson:"handle_note,omitempty" json:"handleNote,omitempty"`
+	HandledAt   *time.Time          `bson:"handled_at,omitempty" json:"handledAt,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updatedAt"`
+}
+
+// CreateReportRequest 举报请求
+type CreateReportRequest struct {
+	Reason      string `json:"reason" binding:"required,oneof=spam porn violence harassment fraud illegal plagiarism other"`
+	Description string `json:"description" binding:"omitempty,max=200"`
+}
+
+// ReportQuery 举报列表查询参数
+type ReportQuery struct {
+	Page       int    `form:"page" binding:"omitempty,min=1"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Status     string `form:"status" binding:"omitempty,oneof=pending resolved dismissed"`
+	TargetType string `form:"targetType" binding:"omitempty,oneof=post comment user"`
+}
+
+// HandleReportRequest 处理举报请求
+type HandleReportRequest struct {
+	Action string `json:"action" binding:"required,oneof=resolve dismiss"` // resolve-举报成立 dismiss-驳回
+	Note   string `json:"note" binding:"omitempty,max=200"`
+}
+
+// ReportListResponse 举报列表响应
+type ReportListResponse struct {
+	Total int      `json:"total"`
+	List  []Report `json:"list"`
+}
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 限流规则名称
+const (
+	RateLimitLogin      = "login"       // 登录
+	RateLimitComment    = "comment"     // 发表评论
+	RateLimitLike       = "like"        // 点赞笔记和评论
+	RateLimitFollow     = "follow"      // 关注用户
+	RateLimitUpload     = "upload"      // 上传文件
+	RateLimitFileDelete = "file_delete" // 删除文件
+)
+
+// RateLimitNames 可配置的限流规则
+var RateLimitNames = []string{
+	RateLimitLogin,
+	RateLimitComment,
+	RateLimitLike,
+	RateLimitFollow,
+	RateLimitUpload,
+	RateLimitFileDelete,
+}
+
+// 限流算法
+const (
+	RateLimitTokenBucket   = "token_bucket"   // 令牌桶，允许短时突发
+	RateLimitSlidingWindow = "sliding_window" // 滑动窗口，严格限制任意时间窗口内的次数
+)
+
+// 限流计数的维度
+const (
+	RateLimitByIP    = "ip"    // 按客户端 IP
+	RateLimitByUser  = "user"  // 按登录用户，未登录时按 IP
+	RateLimitByRoute = "route" // 按路由，所有用户共享配额
+)
+
+// RuntimeSettings 运行时配置，保存在 settings 集合中，管理员修改后无需重启即可生效
+type RuntimeSettings struct {
+	Upload     UploadSettings           `bson:"upload" json:"upload"`
+	RateLimits map[string]RateLimitRule `bson:"rate_limits" json:"rateLimits"` // 规则名称 -> 限流规则
+	CORS       CORSSettings             `bson:"cors" json:"cors"`
+	Version    int                      `bson:"version" json:"version"` // 每次修改加 1，用于检测并发修改
+	UpdatedBy  string                   `bson:"updated_by,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt  time.Time                `bson:"updated_at" json:"updatedAt"`
+}
+
+// UploadSettings 上传限制
+type UploadSettings struct {
+	MaxImageSizeMB  int      `bson:"max_image_size_mb" json:"maxImageSizeMB"`
+	MaxVideoSizeMB  int      `bson:"max_video_size_mb" json:"maxVideoSizeMB"`
+	ImageExtensions []string `bson:"image_extensions" json:"imageExtensions"` // 如 .jpg
+	VideoExtensions []string `bson:"video_extensions" json:"videoExtensions"`
+}
+
+// RateLimitRule 限流规则：时间窗口内最多允许的请求数
+type RateLimitRule struct {
+	Requests  int    `bson:"requests" json:"requests"`
+	Window    int    `bson:"window" json:"window"`       // 时间窗口（秒）
+	Algorithm string `bson:"algorithm" json:"algorithm"` // token_bucket 或 sliding_window
+	Key       string `bson:"key" json:"key"`             // ip、user 或 route
+}
+
+// CORSSettings 跨域配置
+type CORSSettings struct {
+	AllowOrigins []string `bson:"allow_origins" json:"allowOrigins"`
+}
+
+// DefaultRuntimeSettings 返回默认的运行时配置，settings 集合为空时使用
+func DefaultRuntimeSettings() RuntimeSettings {
+	return RuntimeSettings{
+		Upload: UploadSettings{
+			MaxImageSizeMB:  10,
+			MaxVideoSizeMB:  100,
+			ImageExtensions: []string{".jpg", ".jpeg", ".png", ".gif", ".webp"},
+			VideoExtensions: []string{".mp4", ".webm", ".mov", ".avi"},
+		},
+		RateLimits: map[string]RateLimitRule{
+			RateLimitLogin:      {Requests: 10, Window: 60, Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP},
+			RateLimitComment:    {Requests: 20, Window: 60, Algorithm: RateLimitTokenBucket, Key: RateLimitByUser},
+			RateLimitLike:       {Requests: 60, Window: 60, Algorithm: RateLimitTokenBucket, Key: RateLimitByUser},
+			RateLimitFollow:     {Requests: 30, Window: 60, Algorithm: RateLimitTokenBucket, Key: RateLimitByUser},
+			RateLimitUpload:     {Requests: 30, Window: 300, Algorithm: RateLimitTokenBucket, Key: RateLimitByUser},
+			RateLimitFileDelete: {Requests: 10, Window: 60, Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP},
+		},
+		CORS: CORSSettings{
+			AllowOrigins: []string{
+				"http://localhost:3000", // 本地开发环境
+				"https://blue-note-v443.vercel.app",
+				"https://blue-note-v443-git-master-gary-xiongs-projects.vercel.app",
+				"https://blue-note-v443-rmmfv3wi4-gary-xiongs-projects.vercel.app",
+			},
+		},
+	}
+}
+
+// Clone 返回深拷贝，避免调用方修改共享的切片和 map
+func (s RuntimeSettings) Clone() RuntimeSettings {
+	cloned := s
+	cloned.Upload.ImageExtensions = append([]string(nil), s.Upload.ImageExtensions...)
+	cloned.Upload.VideoExtensions = append([]string(nil), s.Upload.VideoExtensions...)
+	cloned.CORS.AllowOrigins = append([]string(nil), s.CORS.AllowOrigins...)
+	cloned.RateLimits = make(map[string]RateLimitRule, len(s.RateLimits))
+	for name, rule := range s.RateLimits {
+		cloned.RateLimits[name] = rule
+	}
+	return cloned
+}
+
+// FillDefaults 补全缺少的限流规则和字段，兼容新增规则之前保存的配置
+func (s *RuntimeSettings) FillDefaults() {
+	defaults := DefaultRuntimeSettings()
+	if s.RateLimits == nil {
+		s.RateLimits = make(map[string]RateLimitRule, len(defaults.RateLimits))
+	}
+	for name, def := range defaults.RateLimits {
+		rule, ok := s.RateLimits[name]
+		if !ok {
+			s.RateLimits[name] = def
+			continue
+		}
+		if rule.Algorithm == "" {
+			rule.Algorithm = def.Algorithm
+		}
+		if rule.Key == "" {
+			rule.Key = def.Key
+		}
+		s.RateLimits[name] = rule
+	}
+}
+
+// UpdateSettingsRequest 修改运行时配置请求。
+// 以当前配置为基础解析请求体，未提供的字段保持不变，提供的数组整体替换
+type UpdateSettingsRequest struct {
+	Upload     UploadSettings           `json:"upload"`
+	RateLimits map[string]RateLimitRule `json:"rateLimits"`
+	CORS       CORSSettings             `json:"cors"`
+	Version    int                      `json:"version" binding:"required,min=1"` // 修改所基于的版本号
+	Reason     string                   `json:"reason" binding:"max=200"`         // 修改原因
+}
+
+// UpdateRequest 以当前配置创建修改请求，版本号需要由请求方提供
+func (s RuntimeSettings) UpdateRequest() UpdateSettingsRequest {
+	cloned := s.Clone()
+	return UpdateSettingsRequest{
+		Upload:     cloned.Upload,
+		RateLimits: cloned.RateLimits,
+		CORS:       cloned.CORS,
+	}
+}
+
+// SettingsFieldChange 单个配置项的变化
+type SettingsFieldChange struct {
+	Field string      `bson:"field" json:"field"` // 如 upload.maxImageSizeMB
+	Old   interface{} `bson:"old" json:"old"`
+	New   interface{} `bson:"new" json:"new"`
+}
+
+// SettingsChange 运行时配置修改记录
+type SettingsChange struct {
+	ID        primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	Version   int                   `bson:"version" json:"version"` // 修改后的版本号
+	Changes   []SettingsFieldChange `bson:"changes" json:"changes"`
+	Settings  RuntimeSettings       `bson:"settings" json:"settings"` // 修改后的完整配置
+	UpdatedBy string                `bson:"updated_by" json:"updatedBy"`
+	Reason    string                `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedAt time.Time             `bson:"created_at" json:"createdAt"`
+}
+
+// SettingsHistoryQuery 配置修改记录查询参数
+type SettingsHistoryQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// SettingsHistoryResponse 配置修改记录列表响应
+type SettingsHistoryResponse struct {
+	Total int64             `json:"total"`
+	List  []*SettingsChange `json:"list"`
+}
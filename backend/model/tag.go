@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag 标签，Name 为规范名称，Aliases 为归一化后的同义词
+type Tag struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name          string             `bson:"name" json:"name"`
+	Aliases       []string           `bson:"aliases" json:"aliases"`
+	Description   string             `bson:"description" json:"description"`
+	PostCount     int                `bson:"post_count" json:"postCount"`         // 审核通过的笔记数
+	FollowerCount int                `bson:"follower_count" json:"followerCount"` // 关注人数
+	CreatedAt     time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// TagFollow 用户关注标签记录
+type TagFollow struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	TagID     primitive.ObjectID `bson:"tag_id"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// TagQuery 标签列表查询参数
+type TagQuery struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Keyword string `form:"keyword" binding:"omitempty,max=20"`
+}
+
+// TagListResponse 标签列表响应
+type TagListResponse struct {
+	Total int64  `json:"total"`
+	List  []*Tag `json:"list"`
+}
+
+// TagDetailResponse 标签页响应
+type TagDetailResponse struct {
+	*Tag
+	IsFollowing bool `json:"isFollowing"`
+}
+
+// CreateTagRequest 创建标签请求
+type CreateTagRequest struct {
+	Name        string   `json:"name" binding:"required,max=20"`
+	Aliases     []string `json:"aliases" binding:"omitempty,dive,max=20"`
+	Description string   `json:"description" binding:"omitempty,max=200"`
+}
+
+// UpdateTagRequest 更新标签请求，Aliases 不为空时整体替换同义词
+type UpdateTagRequest struct {
+	Name        string   `json:"name" binding:"omitempty,max=20"`
+	Aliases     []string `json:"aliases" binding:"omitempty,dive,max=20"`
+	Description string   `json:"description" binding:"omitempty,max=200"`
+}
+
+// MergeTagsRequest 合并标签请求，将源标签合并到目标标签
+type MergeTagsRequest struct {
+	SourceIDs []string `json:"sourceIds" binding:"required,min=1"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TrendingWindow 热门榜单统计窗口
+type TrendingWindow string
+
+const (
+	TrendingWindowHour TrendingWindow = "hour" // 最近一小时
+	TrendingWindowDay  TrendingWindow = "day"  // 最近一天
+	TrendingWindowWeek TrendingWindow = "week" // 最近一周
+)
+
+// TrendingWindows 所有统计窗口
+var TrendingWindows = []TrendingWindow{TrendingWindowHour, TrendingWindowDay, TrendingWindowWeek}
+
+// Duration 返回窗口时长
+func (w TrendingWindow) Duration() time.Duration {
+	switch w {
+	case TrendingWindowHour:
+		return time.Hour
+	case TrendingWindowWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// TrendingKind 榜单类型
+type TrendingKind string
+
+const (
+	TrendingKindTag  TrendingKind = "tag"  // 标签榜
+	TrendingKindPost TrendingKind = "post" // 笔记榜
+)
+
+// TrendingItem 榜单条目
+type TrendingItem struct {
+	Key       string  `bson:"key" json:"key"`              // 标签名或笔记ID
+	Score     float64 `bson:"score" json:"score"`          // 热度分数
+	Count     float64 `bson:"count" json:"count"`          // 当前窗口内的加权互动量
+	PrevCount float64 `bson:"prev_count" json:"prevCount"` // 上一个窗口内的加权互动量
+	Velocity  float64 `bson:"velocity" json:"velocity"`    // 每小时互动增速
+}
+
+// TrendingSnapshot 榜单快照，每个窗口和类型一条，由定时任务覆盖更新
+type TrendingSnapshot struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Window     TrendingWindow     `bson:"window"`
+	Kind       TrendingKind       `bson:"kind"`
+	Items      []TrendingItem     `bson:"items"`
+	ComputedAt time.Time          `bson:"computed_at"`
+}
+
+// TrendingQuery 热门榜单查询参数
+type TrendingQuery struct {
+	Window string `form:"window" binding:"omitempty,oneof=hour day week"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// TagSuggestQuery 标签联想查询参数
+type TagSuggestQuery struct {
+	Keyword string `form:"keyword" binding:"omitempty,max=20"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+// TrendingTag 热门标签
+type TrendingTag struct {
+	Tag      string  `json:"tag"`
+	Score    float64 `json:"score"`
+	Velocity float64 `json:"velocity"`
+}
+
+// TrendingResponse 热门榜单响应
+type TrendingResponse struct {
+	Window     TrendingWindow `json:"window"`
+	ComputedAt *time.Time     `json:"computedAt"`
+	Tags       []TrendingTag  `json:"tags"`
+	Posts      []PostItem     `json:"posts"`
+}
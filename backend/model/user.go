@@ -12,19 +12,23 @@ type User struct {
 	Username     string             `bson:"username" json:"username"`
 	Password     string             `bson:"password" json:"-"` // 不返回密码
 	Role         string             `bson:"role" json:"role"` // "user", "admin"
-	Status       string             `bson:"status" json:"status"` // "active", "banned", "happy", "relaxed" 等
+	Status       string             `bson:"status" json:"status"` // 用户设置的心情状态，如 "happy", "relaxed"
+	Banned       bool               `bson:"banned" json:"-"`       // 账号是否被封禁，只能由举报处理修改
 	Nickname     string             `bson:"nickname" json:"nickname"`
 	Avatar       string             `bson:"avatar" json:"avatar"`
 	Bio          string             `bson:"bio" json:"bio"`
 	Gender       string             `bson:"gender" json:"gender"` // "male", "female", "other"
 	Birthday     string             `bson:"birthday" json:"birthday"`
 	Location     string             `bson:"location" json:"location"`
+	Language     string             `bson:"language" json:"language"` // 语言偏好，如 zh-CN、en-US，为空时按 Accept-Language 确定
 	IsAdmin      bool               `bson:"is_admin" json:"is_admin"`
 	FollowCount  int                `bson:"follow_count" json:"follow_count"`
 	FansCount    int                `bson:"fans_count" json:"fans_count"`
 	LikeCount    int                `bson:"like_count" json:"like_count"`
 	CollectCount int                `bson:"collect_count" json:"collect_count"`
 	PostCount    int                `bson:"post_count" json:"post_count"`
+	Hidden       bool               `bson:"hidden" json:"hidden"`             // 是否因举报被隐藏
+	ReportCount  int                `bson:"report_count" json:"report_count"` // 待处理举报数
 	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updatedAt"`
 }
@@ -46,6 +50,9 @@ type ProfileResponse struct {
 	CollectCount int    `json:"collectCount"`
 	PostCount    int    `json:"postCount"`
 	IsFollowing  bool   `json:"isFollowing"`
+	IsBlocked    bool   `json:"isBlocked"` // 当前登录用户是否拉黑了该用户
+	IsMuted      bool   `json:"isMuted"`   // 当前登录用户是否屏蔽了该用户
+	Language     string `json:"language,omitempty"` // 语言偏好，仅本人可见
 }
 
 // UpdateProfileRequest 更新用户资料请求
@@ -58,6 +65,7 @@ type UpdateProfileRequest struct {
 	Birthday string `json:"birthday" binding:"omitempty"`
 	Location string `json:"location" binding:"omitempty,max=50"`
 	Status   string `json:"status" binding:"omitempty,max=20"`
+	Language string `json:"language" binding:"omitempty,oneof=zh-CN en-US"`
 }
 
 // UserFollow 用户关注关系
@@ -68,6 +76,22 @@ type UserFollow struct {
 	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
 }
 
+// UserBlock 用户拉黑关系，被拉黑的用户不能关注、评论、点赞或查看拉黑者的笔记
+type UserBlock struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`       // 拉黑者ID
+	BlockedID primitive.ObjectID `bson:"blocked_id" json:"blocked_id"` // 被拉黑者ID
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// UserMute 用户屏蔽关系，被屏蔽用户的笔记和评论不会出现在屏蔽者的信息流和评论列表中
+type UserMute struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`   // 屏蔽者ID
+	MutedID   primitive.ObjectID `bson:"muted_id" json:"muted_id"` // 被屏蔽者ID
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
 // UserListItem 用户列表项
 type UserListItem struct {
 	UserID      string `json:"userId"`
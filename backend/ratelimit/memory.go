@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理空闲键的间隔
+const sweepInterval = time.Minute
+
+// MemoryStore 进程内的限流计数，只在单实例部署时准确
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	last    time.Time
+	hits    []time.Time
+	expires time.Time // 之后没有请求时可以删除
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b := s.buckets[key]
+	if b == nil {
+		b = &memoryBucket{tokens: float64(rule.Limit), last: now}
+		s.buckets[key] = b
+	}
+	b.expires = now.Add(rule.Window)
+
+	if rule.Algorithm == TokenBucket {
+		result, tokens := tokenBucket(rule, b.tokens, b.last, now)
+		b.tokens, b.last = tokens, now
+		return result, nil
+	}
+
+	cutoff := now.Add(-rule.Window)
+	valid := b.hits[:0]
+	for _, t := range b.hits {
+		if t.After(cutoff) {
+			valid = append(valid, t)
+		}
+	}
+	result := slidingWindow(rule, valid, now)
+	if result.Allowed {
+		valid = append(valid, now)
+	}
+	b.hits = valid
+	return result, nil
+}
+
+// sweep 删除窗口内没有请求的键，避免访问过的 IP 一直占用内存
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.After(b.expires) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Len 返回当前保存的键数量
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
@@ -0,0 +1,82 @@
+// Package ratelimit 实现令牌桶和滑动窗口限流，计数可以保存在进程内存或 Redis 中
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Algorithm 限流算法
+type Algorithm string
+
+const (
+	// TokenBucket 令牌桶：以 Limit/Window 的速率补充令牌，最多积累 Limit 个，允许短时突发
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow 滑动窗口：任意 Window 时长内最多 Limit 次请求
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Rule 限流规则
+type Rule struct {
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// Result 一次限流检查的结果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // 配额完全恢复所需的时间
+	RetryAfter time.Duration // 被拒绝时，距离下次允许请求的时间
+}
+
+// Store 保存限流计数的后端
+type Store interface {
+	// Allow 检查 key 的一次请求是否允许，并记录本次请求
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// tokenBucket 根据上次的令牌数和时间计算本次请求的结果，返回结果和新的令牌数
+func tokenBucket(rule Rule, tokens float64, last time.Time, now time.Time) (Result, float64) {
+	rate := float64(rule.Limit) / float64(rule.Window)
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens += float64(elapsed) * rate
+	}
+	if tokens > float64(rule.Limit) {
+		tokens = float64(rule.Limit)
+	}
+
+	result := Result{Limit: rule.Limit}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) / rate)
+	}
+	result.Remaining = int(tokens)
+	result.ResetAfter = time.Duration((float64(rule.Limit) - tokens) / rate)
+	return result, tokens
+}
+
+// slidingWindow 根据窗口内的请求时间（升序）计算本次请求的结果，不修改 hits
+func slidingWindow(rule Rule, hits []time.Time, now time.Time) Result {
+	result := Result{Limit: rule.Limit}
+	count := len(hits)
+	latest := now
+	if count < rule.Limit {
+		result.Allowed = true
+		count++
+	} else {
+		// 最早的请求移出窗口后才能再次请求
+		result.RetryAfter = hits[count-rule.Limit].Add(rule.Window).Sub(now)
+		latest = hits[count-1]
+	}
+	result.Remaining = rule.Limit - count
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	result.ResetAfter = latest.Add(rule.Window).Sub(now)
+	return result
+}
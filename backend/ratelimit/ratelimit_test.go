@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"blue-note/redis"
+	"blue-note/redis/redistest"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock 测试用的时钟
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// stores 返回使用同一个时钟的内存后端和 Redis 后端
+func stores(t *testing.T, c *clock) map[string]Store {
+	t.Helper()
+
+	server, err := redistest.NewServer()
+	if err != nil {
+		t.Fatalf("启动 Redis 测试服务失败: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	client, err := redis.NewClient(server.URI(), "")
+	if err != nil {
+		t.Fatalf("创建 Redis 客户端失败: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	memory := NewMemoryStore()
+	memory.now = c.Now
+	redisStore := NewRedisStore(client, "test:")
+	redisStore.now = c.Now
+	return map[string]Store{"memory": memory, "redis": redisStore}
+}
+
+func TestAlgorithms(t *testing.T) {
+	type step struct {
+		advance   time.Duration
+		allowed   bool
+		remaining int
+	}
+
+	tests := []struct {
+		name  string
+		rule  Rule
+		steps []step
+	}{
+		{
+			name: "滑动窗口",
+			rule: Rule{Algorithm: SlidingWindow, Limit: 2, Window: time.Minute},
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{advance: 30 * time.Second, allowed: true, remaining: 0},
+				{allowed: false, remaining: 0},
+				// 第一次请求移出窗口
+				{advance: 31 * time.Second, allowed: true, remaining: 0},
+				{allowed: false, remaining: 0},
+			},
+		},
+		{
+			name: "令牌桶",
+			rule: Rule{Algorithm: TokenBucket, Limit: 2, Window: time.Minute},
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{allowed: false, remaining: 0},
+				// 每 30 秒补充一个令牌
+				{advance: 30 * time.Second, allowed: true, remaining: 0},
+				{advance: 10 * time.Minute, allowed: true, remaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		c := &clock{now: time.Unix(1700000000, 0)}
+		for backend, store := range stores(t, c) {
+			t.Run(tt.name+"/"+backend, func(t *testing.T) {
+				for i, s := range tt.steps {
+					c.Advance(s.advance)
+					result, err := store.Allow(context.Background(), tt.name, tt.rule)
+					if err != nil {
+						t.Fatalf("第 %d 次请求失败: %v", i+1, err)
+					}
+					if result.Allowed != s.allowed || result.Remaining != s.remaining {
+						t.Errorf("第 %d 次请求 allowed=%v remaining=%d, 期望 allowed=%v remaining=%d",
+							i+1, result.Allowed, result.Remaining, s.allowed, s.remaining)
+					}
+					if !result.Allowed && result.RetryAfter <= 0 {
+						t.Errorf("第 %d 次请求被拒绝但 RetryAfter = %v", i+1, result.RetryAfter)
+					}
+				}
+			})
+			c.now = time.Unix(1700000000, 0)
+		}
+	}
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	rule := Rule{Algorithm: SlidingWindow, Limit: 1, Window: time.Minute}
+
+	for backend, store := range stores(t, c) {
+		t.Run(backend, func(t *testing.T) {
+			ctx := context.Background()
+			if r, _ := store.Allow(ctx, "a", rule); !r.Allowed {
+				t.Fatal("a 的第一次请求应允许")
+			}
+			if r, _ := store.Allow(ctx, "b", rule); !r.Allowed {
+				t.Error("b 不应受 a 的影响")
+			}
+			if r, _ := store.Allow(ctx, "a", rule); r.Allowed {
+				t.Error("a 的第二次请求应被拒绝")
+			}
+		})
+	}
+}
+
+func TestRedisStoreConcurrent(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	store := stores(t, c)["redis"]
+	rule := Rule{Algorithm: SlidingWindow, Limit: 10, Window: time.Minute}
+
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Allow(context.Background(), "shared", rule)
+			if err != nil && err != ErrContention {
+				t.Errorf("请求失败: %v", err)
+			}
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed > rule.Limit {
+		t.Errorf("并发请求允许了 %d 次, 超过限制 %d", allowed, rule.Limit)
+	}
+}
+
+func TestMemoryStoreSweepsIdleKeys(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	store := NewMemoryStore()
+	store.now = c.Now
+	rule := Rule{Algorithm: SlidingWindow, Limit: 5, Window: time.Second}
+
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		store.Allow(context.Background(), ip, rule)
+	}
+	c.Advance(2 * sweepInterval)
+	store.Allow(context.Background(), "4.4.4.4", rule)
+
+	if n := store.Len(); n != 1 {
+		t.Errorf("清理后剩余 %d 个键, 期望 1", n)
+	}
+}
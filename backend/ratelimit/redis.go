@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"blue-note/redis"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxRetries 并发修改同一个键导致事务失败时的最大重试次数
+const maxRetries = 5
+
+// ErrContention 重试多次仍然因为并发修改失败
+var ErrContention = errors.New("ratelimit: 并发修改冲突")
+
+// RedisStore 保存在 Redis 中的限流计数，多实例部署时共享配额。
+// 使用 WATCH/MULTI/EXEC 保证读取和更新之间没有其他请求修改计数
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	seq    atomic.Int64
+	now    func() time.Time
+}
+
+// NewRedisStore 创建 Redis 限流后端，prefix 为键的前缀
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix, now: time.Now}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	conn, err := s.client.Conn(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	for i := 0; i < maxRetries; i++ {
+		var result Result
+		var committed bool
+		if rule.Algorithm == TokenBucket {
+			result, committed, err = s.tokenBucket(ctx, conn, s.prefix+"tb:"+key, rule)
+		} else {
+			result, committed, err = s.slidingWindow(ctx, conn, s.prefix+"sw:"+key, rule)
+		}
+		if err != nil {
+			conn.Do(ctx, "UNWATCH")
+			return Result{}, err
+		}
+		if committed {
+			return result, nil
+		}
+	}
+	return Result{}, ErrContention
+}
+
+// tokenBucket 令牌桶的状态保存为 "令牌数:上次请求时间（纳秒）"
+func (s *RedisStore) tokenBucket(ctx context.Context, conn *redis.Conn, key string, rule Rule) (Result, bool, error) {
+	if _, err := conn.Do(ctx, "WATCH", key); err != nil {
+		return Result{}, false, err
+	}
+	value, ok, err := redis.String(conn.Do(ctx, "GET", key))
+	if err != nil {
+		return Result{}, false, err
+	}
+
+	now := s.now()
+	tokens, last := float64(rule.Limit), now
+	if ok {
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) == 2 {
+			t, err1 := strconv.ParseFloat(parts[0], 64)
+			n, err2 := strconv.ParseInt(parts[1], 10, 64)
+			if err1 == nil && err2 == nil {
+				tokens, last = t, time.Unix(0, n)
+			}
+		}
+	}
+
+	result, tokens := tokenBucket(rule, tokens, last, now)
+	state := strconv.FormatFloat(tokens, 'f', -1, 64) + ":" + strconv.FormatInt(now.UnixNano(), 10)
+
+	committed, err := s.exec(ctx, conn,
+		[]interface{}{"SET", key, state, "PX", milliseconds(rule.Window)},
+	)
+	return result, committed, err
+}
+
+// slidingWindow 窗口内的请求保存在有序集合中，分数为请求时间（微秒）
+func (s *RedisStore) slidingWindow(ctx context.Context, conn *redis.Conn, key string, rule Rule) (Result, bool, error) {
+	if _, err := conn.Do(ctx, "WATCH", key); err != nil {
+		return Result{}, false, err
+	}
+
+	now := s.now()
+	cutoff := now.Add(-rule.Window).UnixMicro()
+	reply, err := conn.Do(ctx, "ZRANGEBYSCORE", key, "("+strconv.FormatInt(cutoff, 10), "+inf", "WITHSCORES")
+	if err != nil {
+		return Result{}, false, err
+	}
+	items, _ := reply.([]interface{})
+	hits := make([]time.Time, 0, len(items)/2)
+	for i := 1; i < len(items); i += 2 {
+		score, _, _ := redis.String(items[i], nil)
+		micros, err := strconv.ParseFloat(score, 64)
+		if err != nil {
+			return Result{}, false, fmt.Errorf("ratelimit: 无效的分数 %q", score)
+		}
+		hits = append(hits, time.UnixMicro(int64(micros)))
+	}
+
+	result := slidingWindow(rule, hits, now)
+	commands := [][]interface{}{
+		{"ZREMRANGEBYSCORE", key, "-inf", cutoff},
+	}
+	if result.Allowed {
+		member := strconv.FormatInt(now.UnixNano(), 10) + "-" + strconv.FormatInt(s.seq.Add(1), 10)
+		commands = append(commands, []interface{}{"ZADD", key, now.UnixMicro(), member})
+	}
+	commands = append(commands, []interface{}{"PEXPIRE", key, milliseconds(rule.Window)})
+
+	committed, err := s.exec(ctx, conn, commands...)
+	return result, committed, err
+}
+
+// exec 在事务中执行命令，WATCH 的键被其他连接修改时返回 false
+func (s *RedisStore) exec(ctx context.Context, conn *redis.Conn, commands ...[]interface{}) (bool, error) {
+	if _, err := conn.Do(ctx, "MULTI"); err != nil {
+		return false, err
+	}
+	for _, args := range commands {
+		if _, err := conn.Do(ctx, args...); err != nil {
+			conn.Do(ctx, "DISCARD")
+			return false, err
+		}
+	}
+	reply, err := conn.Do(ctx, "EXEC")
+	if err != nil {
+		return false, err
+	}
+	if reply == nil {
+		return false, nil
+	}
+	for _, item := range reply.([]interface{}) {
+		if e, ok := item.(redis.Error); ok {
+			return false, e
+		}
+	}
+	return true, nil
+}
+
+func milliseconds(d time.Duration) int64 {
+	if ms := d.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
@@ -0,0 +1,304 @@
+// Package redis 实现 Redis 协议（RESP2）的精简客户端，只包含限流和缓存用到的功能
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed 客户端已关闭
+var ErrClosed = errors.New("redis: 客户端已关闭")
+
+// Error Redis 返回的错误回复
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Client Redis 客户端，内部维护连接池，可并发使用
+type Client struct {
+	addr     string
+	username string
+	password string
+	db       int
+
+	mu     sync.Mutex
+	idle   []*Conn
+	closed bool
+}
+
+// maxIdleConns 连接池中最多保留的空闲连接数
+const maxIdleConns = 16
+
+// dialTimeout 建立连接的超时时间
+const dialTimeout = 5 * time.Second
+
+// NewClient 根据 redis://[user:password@]host:port[/db] 格式的地址创建客户端。
+// password 不为空时覆盖地址中的密码。创建时不会建立连接
+func NewClient(uri, password string) (*Client, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("redis: 地址格式错误: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("redis: 不支持的协议 %q", u.Scheme)
+	}
+
+	c := &Client{addr: u.Host}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if password != "" {
+		c.password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("redis: 数据库编号错误: %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Do 执行一条命令。回复类型为 string（状态）、int64、[]byte（字符串）、[]interface{}（数组）或 nil
+func (c *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	conn, err := c.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.Do(ctx, args...)
+}
+
+// Conn 从连接池获取一个连接，用于 WATCH/MULTI/EXEC 等需要在同一连接上执行的命令。
+// 使用完毕后必须调用 Close 归还
+func (c *Client) Conn(ctx context.Context) (*Conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return conn, nil
+	}
+	c.mu.Unlock()
+
+	return c.dial(ctx)
+}
+
+func (c *Client) dial(ctx context.Context) (*Conn, error) {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Conn{
+		client: c,
+		conn:   netConn,
+		reader: bufio.NewReader(netConn),
+		writer: bufio.NewWriter(netConn),
+	}
+	if c.password != "" {
+		args := []interface{}{"AUTH", c.password}
+		if c.username != "" && c.username != "default" {
+			args = []interface{}{"AUTH", c.username, c.password}
+		}
+		if _, err := conn.Do(ctx, args...); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := conn.Do(ctx, "SELECT", c.db); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// put 归还连接，连接出错或连接池已满时直接关闭
+func (c *Client) put(conn *Conn) {
+	c.mu.Lock()
+	if conn.broken || c.closed || len(c.idle) >= maxIdleConns {
+		c.mu.Unlock()
+		conn.conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+	c.mu.Unlock()
+}
+
+// Ping 检查连接是否正常
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Close 关闭客户端和所有空闲连接
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for _, conn := range c.idle {
+		conn.conn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// Conn 单个 Redis 连接，不能并发使用
+type Conn struct {
+	client *Client
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+	broken bool
+}
+
+// Do 在该连接上执行一条命令
+func (c *Conn) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	c.conn.SetDeadline(deadline)
+
+	if err := c.write(args); err != nil {
+		c.broken = true
+		return nil, err
+	}
+	reply, err := c.read()
+	if err != nil {
+		if _, ok := err.(Error); !ok {
+			c.broken = true
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Close 将连接归还连接池
+func (c *Conn) Close() error {
+	c.client.put(c)
+	return nil
+}
+
+func (c *Conn) write(args []interface{}) error {
+	fmt.Fprintf(c.writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			s = fmt.Sprint(v)
+		}
+		fmt.Fprintf(c.writer, "$%d\r\n%s\r\n", len(s), s)
+	}
+	return c.writer.Flush()
+}
+
+func (c *Conn) read() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: 无效的回复 %q", line)
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			// 数组中的错误回复（如 EXEC 中某条命令失败）作为元素返回
+			item, err := c.read()
+			if e, ok := err.(Error); ok {
+				items[i] = e
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: 无效的回复 %q", line)
+}
+
+// String 将字符串回复转为 string，键不存在时返回 ok=false
+func String(reply interface{}, err error) (s string, ok bool, e error) {
+	if err != nil {
+		return "", false, err
+	}
+	switch v := reply.(type) {
+	case nil:
+		return "", false, nil
+	case []byte:
+		return string(v), true, nil
+	case string:
+		return v, true, nil
+	}
+	return "", false, fmt.Errorf("redis: 回复类型 %T 不是字符串", reply)
+}
+
+// Int64 将整数回复转为 int64
+func Int64(reply interface{}, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch v := reply.(type) {
+	case int64:
+		return v, nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	}
+	return 0, fmt.Errorf("redis: 回复类型 %T 不是整数", reply)
+}
@@ -0,0 +1,574 @@
+// Package redistest 提供进程内的 Redis 协议服务，用于在没有 Redis 的环境中测试
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server 进程内的 Redis 服务，只实现了限流和缓存用到的命令
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	data     map[string]*entry
+	versions map[string]int64 // 键每次修改加 1，用于 WATCH
+	commands int
+	failing  bool
+
+	wg sync.WaitGroup
+}
+
+type entry struct {
+	str      []byte
+	zset     map[string]float64
+	expireAt time.Time
+}
+
+// NewServer 在随机端口启动服务
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: listener,
+		data:     make(map[string]*entry),
+		versions: make(map[string]int64),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// URI 返回连接地址
+func (s *Server) URI() string {
+	return "redis://" + s.listener.Addr().String()
+}
+
+// Close 停止服务并断开所有连接
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// Commands 返回已执行的命令数
+func (s *Server) Commands() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands
+}
+
+// SetFailing 设为 true 后所有命令返回错误，用于测试 Redis 不可用的情况
+func (s *Server) SetFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+// FlushAll 清空所有数据
+func (s *Server) FlushAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.data {
+		s.touch(key)
+	}
+	s.data = make(map[string]*entry)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	var mu sync.Mutex
+	open := make(map[net.Conn]bool)
+	defer func() {
+		mu.Lock()
+		for conn := range open {
+			conn.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		open[conn] = true
+		mu.Unlock()
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			s.handle(conn)
+			mu.Lock()
+			delete(open, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// session 单个连接的事务状态
+type session struct {
+	inMulti bool
+	queued  [][]string
+	watched map[string]int64
+	dirty   bool // 事务排队时出现错误
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	sess := &session{}
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		reply := s.dispatch(sess, args)
+		writeReply(writer, reply)
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// errorReply 错误回复
+type errorReply string
+
+// statusReply 状态回复
+type statusReply string
+
+func (s *Server) dispatch(sess *session, args []string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands++
+	if s.failing {
+		return errorReply("ERR server is failing")
+	}
+
+	name := strings.ToUpper(args[0])
+	switch name {
+	case "MULTI":
+		if sess.inMulti {
+			return errorReply("ERR MULTI calls can not be nested")
+		}
+		sess.inMulti = true
+		sess.queued = nil
+		sess.dirty = false
+		return statusReply("OK")
+	case "EXEC":
+		if !sess.inMulti {
+			return errorReply("ERR EXEC without MULTI")
+		}
+		queued, watched, dirty := sess.queued, sess.watched, sess.dirty
+		*sess = session{}
+		if dirty {
+			return errorReply("EXECABORT Transaction discarded because of previous errors.")
+		}
+		for key, version := range watched {
+			if s.versions[key] != version {
+				return nil
+			}
+		}
+		replies := make([]interface{}, len(queued))
+		for i, cmd := range queued {
+			replies[i] = s.execute(cmd)
+		}
+		return replies
+	case "DISCARD":
+		if !sess.inMulti {
+			return errorReply("ERR DISCARD without MULTI")
+		}
+		*sess = session{}
+		return statusReply("OK")
+	case "WATCH":
+		if sess.inMulti {
+			return errorReply("ERR WATCH inside MULTI is not allowed")
+		}
+		if sess.watched == nil {
+			sess.watched = make(map[string]int64)
+		}
+		for _, key := range args[1:] {
+			s.expire(key)
+			sess.watched[key] = s.versions[key]
+		}
+		return statusReply("OK")
+	case "UNWATCH":
+		sess.watched = nil
+		return statusReply("OK")
+	}
+
+	if sess.inMulti {
+		if _, ok := commands[name]; !ok {
+			sess.dirty = true
+			return errorReply(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		}
+		sess.queued = append(sess.queued, args)
+		return statusReply("QUEUED")
+	}
+	return s.execute(args)
+}
+
+// commands 支持的命令及最少参数个数（含命令名）
+var commands = map[string]int{
+	"PING": 1, "AUTH": 2, "SELECT": 2, "FLUSHALL": 1,
+	"GET": 2, "SET": 3, "DEL": 2, "EXISTS": 2, "INCR": 2,
+	"PEXPIRE": 3, "PTTL": 2,
+	"ZADD": 4, "ZCARD": 2, "ZREMRANGEBYSCORE": 4, "ZRANGEBYSCORE": 4,
+}
+
+func (s *Server) execute(args []string) interface{} {
+	name := strings.ToUpper(args[0])
+	minArgs, ok := commands[name]
+	if !ok {
+		return errorReply(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+	if len(args) < minArgs {
+		return errorReply(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+	}
+	for _, key := range keysOf(name, args) {
+		s.expire(key)
+	}
+
+	switch name {
+	case "PING":
+		return statusReply("PONG")
+	case "AUTH", "SELECT":
+		return statusReply("OK")
+	case "FLUSHALL":
+		for key := range s.data {
+			s.touch(key)
+		}
+		s.data = make(map[string]*entry)
+		return statusReply("OK")
+	case "GET":
+		e := s.data[args[1]]
+		if e == nil {
+			return nil
+		}
+		if e.zset != nil {
+			return wrongType()
+		}
+		return e.str
+	case "SET":
+		return s.set(args)
+	case "DEL", "EXISTS":
+		var n int64
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				n++
+				if name == "DEL" {
+					delete(s.data, key)
+					s.touch(key)
+				}
+			}
+		}
+		return n
+	case "INCR":
+		e := s.data[args[1]]
+		var value int64
+		if e != nil {
+			if e.zset != nil {
+				return wrongType()
+			}
+			var err error
+			if value, err = strconv.ParseInt(string(e.str), 10, 64); err != nil {
+				return errorReply("ERR value is not an integer or out of range")
+			}
+		} else {
+			e = &entry{}
+			s.data[args[1]] = e
+		}
+		value++
+		e.str = []byte(strconv.FormatInt(value, 10))
+		s.touch(args[1])
+		return value
+	case "PEXPIRE":
+		ms, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return errorReply("ERR value is not an integer or out of range")
+		}
+		e := s.data[args[1]]
+		if e == nil {
+			return int64(0)
+		}
+		e.expireAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		s.touch(args[1])
+		return int64(1)
+	case "PTTL":
+		e := s.data[args[1]]
+		if e == nil {
+			return int64(-2)
+		}
+		if e.expireAt.IsZero() {
+			return int64(-1)
+		}
+		return time.Until(e.expireAt).Milliseconds()
+	case "ZADD":
+		return s.zadd(args)
+	case "ZCARD":
+		e := s.data[args[1]]
+		if e == nil {
+			return int64(0)
+		}
+		if e.zset == nil {
+			return wrongType()
+		}
+		return int64(len(e.zset))
+	case "ZREMRANGEBYSCORE", "ZRANGEBYSCORE":
+		return s.zrange(name, args)
+	}
+	return errorReply("ERR unknown command")
+}
+
+func (s *Server) set(args []string) interface{} {
+	key := args[1]
+	var expireAt time.Time
+	nx, xx := false, false
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "PX", "EX":
+			if i+1 >= len(args) {
+				return errorReply("ERR syntax error")
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return errorReply("ERR invalid expire time in 'set' command")
+			}
+			unit := time.Millisecond
+			if strings.ToUpper(args[i]) == "EX" {
+				unit = time.Second
+			}
+			expireAt = time.Now().Add(time.Duration(n) * unit)
+			i++
+		default:
+			return errorReply("ERR syntax error")
+		}
+	}
+
+	_, exists := s.data[key]
+	if (nx && exists) || (xx && !exists) {
+		return nil
+	}
+	s.data[key] = &entry{str: []byte(args[2]), expireAt: expireAt}
+	s.touch(key)
+	return statusReply("OK")
+}
+
+func (s *Server) zadd(args []string) interface{} {
+	if len(args)%2 != 0 {
+		return errorReply("ERR syntax error")
+	}
+	e := s.data[args[1]]
+	if e == nil {
+		e = &entry{zset: make(map[string]float64)}
+		s.data[args[1]] = e
+	}
+	if e.zset == nil {
+		return wrongType()
+	}
+
+	var added int64
+	for i := 2; i < len(args); i += 2 {
+		score, err := strconv.ParseFloat(args[i], 64)
+		if err != nil {
+			return errorReply("ERR value is not a valid float")
+		}
+		if _, ok := e.zset[args[i+1]]; !ok {
+			added++
+		}
+		e.zset[args[i+1]] = score
+	}
+	s.touch(args[1])
+	return added
+}
+
+func (s *Server) zrange(name string, args []string) interface{} {
+	min, minExclusive, err1 := parseScore(args[2])
+	max, maxExclusive, err2 := parseScore(args[3])
+	if err1 != nil || err2 != nil {
+		return errorReply("ERR min or max is not a float")
+	}
+	withScores := len(args) > 4 && strings.ToUpper(args[4]) == "WITHSCORES"
+
+	e := s.data[args[1]]
+	if e == nil {
+		if name == "ZRANGEBYSCORE" {
+			return []interface{}{}
+		}
+		return int64(0)
+	}
+	if e.zset == nil {
+		return wrongType()
+	}
+
+	type member struct {
+		name  string
+		score float64
+	}
+	var matched []member
+	for m, score := range e.zset {
+		if score < min || score > max || (minExclusive && score == min) || (maxExclusive && score == max) {
+			continue
+		}
+		matched = append(matched, member{m, score})
+	}
+
+	if name == "ZREMRANGEBYSCORE" {
+		for _, m := range matched {
+			delete(e.zset, m.name)
+		}
+		if len(e.zset) == 0 {
+			delete(s.data, args[1])
+		}
+		if len(matched) > 0 {
+			s.touch(args[1])
+		}
+		return int64(len(matched))
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].score != matched[j].score {
+			return matched[i].score < matched[j].score
+		}
+		return matched[i].name < matched[j].name
+	})
+	replies := make([]interface{}, 0, len(matched)*2)
+	for _, m := range matched {
+		replies = append(replies, []byte(m.name))
+		if withScores {
+			replies = append(replies, []byte(strconv.FormatFloat(m.score, 'f', -1, 64)))
+		}
+	}
+	return replies
+}
+
+func parseScore(s string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+	switch strings.ToLower(s) {
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	case "+inf", "inf":
+		return math.Inf(1), exclusive, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, exclusive, err
+}
+
+// keysOf 返回命令涉及的键，用于在执行前清理过期的键
+func keysOf(name string, args []string) []string {
+	switch name {
+	case "PING", "AUTH", "SELECT", "FLUSHALL":
+		return nil
+	case "DEL", "EXISTS":
+		return args[1:]
+	}
+	return args[1:2]
+}
+
+// expire 删除已过期的键
+func (s *Server) expire(key string) {
+	if e, ok := s.data[key]; ok && !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		delete(s.data, key)
+		s.touch(key)
+	}
+}
+
+func (s *Server) touch(key string) {
+	s.versions[key]++
+}
+
+func wrongType() interface{} {
+	return errorReply("WRONGTYPE Operation against a key holding the wrong kind of value")
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		// 内联命令
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("无效的请求 %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+func writeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case statusReply:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case errorReply:
+		fmt.Fprintf(w, "-%s\r\n", v)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case []byte:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []interface{}:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMemory 创建基于内存的仓储集合，数据只保存在进程内，用于测试和本地调试
+func NewMemory() *Repositories {
+	return &Repositories{
+		Users:    NewMemoryUserRepo(),
+		Posts:    NewMemoryPostRepo(),
+		Comments: NewMemoryCommentRepo(),
+		Follows:  NewMemoryFollowRepo(),
+		Files:    NewMemoryFileRepo(),
+		Likes:    NewMemoryLikeRepo(),
+		Settings: NewMemorySettingsRepo(),
+		Tx:       NewMemoryTransactor(),
+	}
+}
+
+// memoryTransactor 串行执行事务函数，不支持回滚
+type memoryTransactor struct {
+	mu sync.Mutex
+}
+
+// NewMemoryTransactor 创建内存事务执行器
+func NewMemoryTransactor() Transactor {
+	return &memoryTransactor{}
+}
+
+func (t *memoryTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(ctx)
+}
+
+// inRange 判断时间是否在范围内
+func inRange(t time.Time, r TimeRange) bool {
+	if !r.From.IsZero() && t.Before(r.From) {
+		return false
+	}
+	if !r.To.IsZero() && t.After(r.To) {
+		return false
+	}
+	return true
+}
+
+// containsID 判断 ids 中是否包含 id
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+// page 对已排序的结果分页
+func page[T any](items []T, skip, limit int) []T {
+	if skip < 0 {
+		skip = 0
+	}
+	if skip >= len(items) {
+		return []T{}
+	}
+	items = items[skip:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// relation 内存中的用户关系或互动记录
+type relation struct {
+	id        primitive.ObjectID
+	owner     primitive.ObjectID // 发起者
+	target    primitive.ObjectID // 目标用户、笔记或评论
+	createdAt time.Time
+}
+
+// relationSet 一类关系记录，owner 和 target 组合唯一
+type relationSet struct {
+	items []relation
+}
+
+func (s *relationSet) find(owner, target primitive.ObjectID) (relation, bool) {
+	for _, item := range s.items {
+		if item.owner == owner && item.target == target {
+			return item, true
+		}
+	}
+	return relation{}, false
+}
+
+func (s *relationSet) add(item relation) error {
+	if _, ok := s.find(item.owner, item.target); ok {
+		return ErrDuplicate
+	}
+	s.items = append(s.items, item)
+	return nil
+}
+
+func (s *relationSet) remove(owner, target primitive.ObjectID) bool {
+	for i, item := range s.items {
+		if item.owner == owner && item.target == target {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// filter 按创建时间倒序返回满足条件的记录
+func (s *relationSet) filter(match func(relation) bool) []relation {
+	result := []relation{}
+	for _, item := range s.items {
+		if match(item) {
+			result = append(result, item)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].createdAt.After(result[j].createdAt)
+	})
+	return result
+}
+
+// byOwner 返回 owner 发起的记录
+func (s *relationSet) byOwner(owner primitive.ObjectID) []relation {
+	return s.filter(func(item relation) bool { return item.owner == owner })
+}
+
+// byTarget 返回指向 target 的记录
+func (s *relationSet) byTarget(target primitive.ObjectID) []relation {
+	return s.filter(func(item relation) bool { return item.target == target })
+}
+
+// targets 返回记录的目标ID
+func targets(items []relation) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.target)
+	}
+	return ids
+}
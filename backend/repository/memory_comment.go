@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCommentRepo struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]*model.Comment
+}
+
+// NewMemoryCommentRepo 创建内存评论仓储
+func NewMemoryCommentRepo() CommentRepo {
+	return &memoryCommentRepo{comments: make(map[primitive.ObjectID]*model.Comment)}
+}
+
+// matchComment 判断评论是否满足查询条件
+func matchComment(comment *model.Comment, f CommentFilter) bool {
+	if !f.PostID.IsZero() && comment.PostID != f.PostID {
+		return false
+	}
+	if f.ExcludeHidden && comment.Hidden {
+		return false
+	}
+	if containsID(f.ExcludeUserIDs, comment.UserID) {
+		return false
+	}
+	return inRange(comment.CreatedAt, f.Created)
+}
+
+func (r *memoryCommentRepo) Create(ctx context.Context, comment *model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.comments[comment.ID]; ok {
+		return ErrDuplicate
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	return nil
+}
+
+func (r *memoryCommentRepo) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *comment
+	return &copied, nil
+}
+
+func (r *memoryCommentRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.comments[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.comments, id)
+	return nil
+}
+
+func (r *memoryCommentRepo) Find(ctx context.Context, filter CommentFilter, sortBy CommentSort, asc bool, skip, limit int) ([]model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []model.Comment{}
+	for _, comment := range r.comments {
+		if matchComment(comment, filter) {
+			comments = append(comments, *comment)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		a, b := comments[i], comments[j]
+		if asc {
+			a, b = b, a
+		}
+		switch sortBy {
+		case SortCommentByTime:
+			return a.CreatedAt.After(b.CreatedAt)
+		case SortCommentByLikes:
+			return a.Likes > b.Likes
+		default:
+			return a.Score > b.Score
+		}
+	})
+	return page(comments, skip, limit), nil
+}
+
+func (r *memoryCommentRepo) Count(ctx context.Context, filter CommentFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, comment := range r.comments {
+		if matchComment(comment, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryCommentRepo) IncrLikes(ctx context.Context, id primitive.ObjectID, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if comment, ok := r.comments[id]; ok {
+		comment.Likes += delta
+	}
+	return nil
+}
+
+func (r *memoryCommentRepo) UpdateScore(ctx context.Context, id primitive.ObjectID, score float64, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if comment, ok := r.comments[id]; ok {
+		comment.Score = score
+		comment.UpdatedAt = updatedAt
+	}
+	return nil
+}
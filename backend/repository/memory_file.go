@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryFileRepo struct {
+	mu      sync.RWMutex
+	records []*model.FileRecord
+}
+
+// NewMemoryFileRepo 创建内存文件记录仓储
+func NewMemoryFileRepo() FileRepo {
+	return &memoryFileRepo{}
+}
+
+func (r *memoryFileRepo) Create(ctx context.Context, record *model.FileRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	stored := *record
+	r.records = append(r.records, &stored)
+	return nil
+}
+
+func (r *memoryFileRepo) findOne(match func(*model.FileRecord) bool) (*model.FileRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.records {
+		if match(record) {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryFileRepo) FindByURL(ctx context.Context, url string) (*model.FileRecord, error) {
+	return r.findOne(func(record *model.FileRecord) bool { return record.URL == url })
+}
+
+func (r *memoryFileRepo) FindOwned(ctx context.Context, filePath string, userID primitive.ObjectID) (*model.FileRecord, error) {
+	return r.findOne(func(record *model.FileRecord) bool {
+		return record.FilePath == filePath && record.UserID == userID
+	})
+}
+
+func (r *memoryFileRepo) MarkUsed(ctx context.Context, filePaths []string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range r.records {
+		for _, path := range filePaths {
+			if record.FilePath == path {
+				usedAt := usedAt
+				record.Status = model.FileStatusUsed
+				record.UpdatedAt = usedAt
+				record.UsedAt = &usedAt
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryFileRepo) SetStatus(ctx context.Context, filePath string, status model.FileStatus, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range r.records {
+		if record.FilePath == filePath {
+			record.Status = status
+			record.UpdatedAt = updatedAt
+			return nil
+		}
+	}
+	return nil
+}
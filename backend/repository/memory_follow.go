@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryFollowRepo struct {
+	mu      sync.RWMutex
+	follows relationSet
+	blocks  relationSet
+	mutes   relationSet
+}
+
+// NewMemoryFollowRepo 创建内存用户关系仓储
+func NewMemoryFollowRepo() FollowRepo {
+	return &memoryFollowRepo{}
+}
+
+// toFollows 将关系记录转换为关注记录
+func toFollows(items []relation) []model.UserFollow {
+	follows := make([]model.UserFollow, 0, len(items))
+	for _, item := range items {
+		follows = append(follows, model.UserFollow{
+			ID:          item.id,
+			UserID:      item.owner,
+			FollowingID: item.target,
+			CreatedAt:   item.createdAt,
+		})
+	}
+	return follows
+}
+
+func (r *memoryFollowRepo) Follow(ctx context.Context, follow *model.UserFollow) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if follow.ID.IsZero() {
+		follow.ID = primitive.NewObjectID()
+	}
+	return r.follows.add(relation{id: follow.ID, owner: follow.UserID, target: follow.FollowingID, createdAt: follow.CreatedAt}) == nil, nil
+}
+
+func (r *memoryFollowRepo) Unfollow(ctx context.Context, userID, followingID primitive.ObjectID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.follows.remove(userID, followingID), nil
+}
+
+func (r *memoryFollowRepo) FindFollow(ctx context.Context, userID, followingID primitive.ObjectID) (*model.UserFollow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	item, ok := r.follows.find(userID, followingID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &toFollows([]relation{item})[0], nil
+}
+
+func (r *memoryFollowRepo) IsFollowing(ctx context.Context, userID, followingID primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.follows.find(userID, followingID)
+	return ok, nil
+}
+
+func (r *memoryFollowRepo) ListFollowing(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]model.UserFollow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return toFollows(page(r.follows.byOwner(userID), skip, limit)), nil
+}
+
+func (r *memoryFollowRepo) CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.follows.byOwner(userID))), nil
+}
+
+func (r *memoryFollowRepo) ListFollowers(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]model.UserFollow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return toFollows(page(r.follows.byTarget(userID), skip, limit)), nil
+}
+
+func (r *memoryFollowRepo) CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.follows.byTarget(userID))), nil
+}
+
+func (r *memoryFollowRepo) FollowingIDs(ctx context.Context, userID primitive.ObjectID, among []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := r.follows.filter(func(item relation) bool {
+		return item.owner == userID && (among == nil || containsID(among, item.target))
+	})
+	return targets(items), nil
+}
+
+func (r *memoryFollowRepo) Block(ctx context.Context, block *model.UserBlock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if block.ID.IsZero() {
+		block.ID = primitive.NewObjectID()
+	}
+	return r.blocks.add(relation{id: block.ID, owner: block.UserID, target: block.BlockedID, createdAt: block.CreatedAt})
+}
+
+func (r *memoryFollowRepo) Unblock(ctx context.Context, userID, blockedID primitive.ObjectID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.blocks.remove(userID, blockedID), nil
+}
+
+func (r *memoryFollowRepo) HasBlocked(ctx context.Context, userID, targetID primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.blocks.find(userID, targetID)
+	return ok, nil
+}
+
+func (r *memoryFollowRepo) ListBlocked(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return targets(page(r.blocks.byOwner(userID), skip, limit)), nil
+}
+
+func (r *memoryFollowRepo) CountBlocked(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.blocks.byOwner(userID))), nil
+}
+
+func (r *memoryFollowRepo) Mute(ctx context.Context, mute *model.UserMute) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if mute.ID.IsZero() {
+		mute.ID = primitive.NewObjectID()
+	}
+	return r.mutes.add(relation{id: mute.ID, owner: mute.UserID, target: mute.MutedID, createdAt: mute.CreatedAt})
+}
+
+func (r *memoryFollowRepo) Unmute(ctx context.Context, userID, mutedID primitive.ObjectID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mutes.remove(userID, mutedID), nil
+}
+
+func (r *memoryFollowRepo) IsMuted(ctx context.Context, userID, mutedID primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.mutes.find(userID, mutedID)
+	return ok, nil
+}
+
+func (r *memoryFollowRepo) ListMuted(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return targets(page(r.mutes.byOwner(userID), skip, limit)), nil
+}
+
+func (r *memoryFollowRepo) CountMuted(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.mutes.byOwner(userID))), nil
+}
+
+func (r *memoryFollowRepo) HiddenUserIDs(ctx context.Context, viewerID primitive.ObjectID, includeMuted bool) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []primitive.ObjectID
+	for _, item := range r.blocks.items {
+		if item.owner == viewerID {
+			ids = append(ids, item.target)
+		} else if item.target == viewerID {
+			ids = append(ids, item.owner)
+		}
+	}
+	if includeMuted {
+		ids = append(ids, targets(r.mutes.byOwner(viewerID))...)
+	}
+	return ids, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryLikeRepo 内存互动记录仓储，关系记录的 owner 为用户，target 为笔记或评论
+type memoryLikeRepo struct {
+	mu           sync.RWMutex
+	postLikes    relationSet
+	commentLikes relationSet
+	collections  relationSet
+}
+
+// NewMemoryLikeRepo 创建内存互动记录仓储
+func NewMemoryLikeRepo() LikeRepo {
+	return &memoryLikeRepo{}
+}
+
+func (r *memoryLikeRepo) LikePost(ctx context.Context, like *model.PostLike) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if like.ID.IsZero() {
+		like.ID = primitive.NewObjectID()
+	}
+	return r.postLikes.add(relation{id: like.ID, owner: like.UserID, target: like.PostID, createdAt: like.CreatedAt}) == nil, nil
+}
+
+func (r *memoryLikeRepo) UnlikePost(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.postLikes.remove(userID, postID), nil
+}
+
+func (r *memoryLikeRepo) HasLikedPost(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.postLikes.find(userID, postID)
+	return ok, nil
+}
+
+func (r *memoryLikeRepo) LikedPostIDs(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return targets(page(r.postLikes.byOwner(userID), skip, limit)), nil
+}
+
+func (r *memoryLikeRepo) CountLikedPosts(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.postLikes.byOwner(userID))), nil
+}
+
+func (r *memoryLikeRepo) LikeComment(ctx context.Context, like *model.CommentLike) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if like.ID.IsZero() {
+		like.ID = primitive.NewObjectID()
+	}
+	return r.commentLikes.add(relation{id: like.ID, owner: like.UserID, target: like.CommentID, createdAt: like.CreatedAt})
+}
+
+func (r *memoryLikeRepo) UnlikeComment(ctx context.Context, commentID, userID primitive.ObjectID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commentLikes.remove(userID, commentID), nil
+}
+
+func (r *memoryLikeRepo) CollectedPostIDs(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return targets(page(r.collections.byOwner(userID), skip, limit)), nil
+}
+
+func (r *memoryLikeRepo) CountCollectedPosts(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.collections.byOwner(userID))), nil
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPostRepo struct {
+	mu    sync.RWMutex
+	posts map[primitive.ObjectID]*model.Post
+}
+
+// NewMemoryPostRepo 创建内存笔记仓储
+func NewMemoryPostRepo() PostRepo {
+	return &memoryPostRepo{posts: make(map[primitive.ObjectID]*model.Post)}
+}
+
+// copyPost 复制笔记，避免调用方修改仓储中的数据
+func copyPost(post *model.Post) *model.Post {
+	copied := *post
+	copied.Tags = append([]string(nil), post.Tags...)
+	copied.Files = append([]string(nil), post.Files...)
+	return &copied
+}
+
+// matchPost 判断笔记是否满足查询条件
+func matchPost(post *model.Post, f PostFilter) bool {
+	if f.ExcludeHidden && post.Hidden {
+		return false
+	}
+	if f.Type != "" && post.Type != f.Type {
+		return false
+	}
+	if f.Tag != "" {
+		found := false
+		for _, tag := range post.Tags {
+			if tag == f.Tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Status != "" && post.Status != f.Status {
+		return false
+	}
+	if !f.UserID.IsZero() && post.UserID != f.UserID {
+		return false
+	}
+	if containsID(f.ExcludeUserIDs, post.UserID) {
+		return false
+	}
+	if !f.BeforeID.IsZero() && post.ID.Hex() >= f.BeforeID.Hex() {
+		return false
+	}
+	return inRange(post.CreatedAt, f.Created)
+}
+
+func (r *memoryPostRepo) Create(ctx context.Context, post *model.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if post.ID.IsZero() {
+		post.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.posts[post.ID]; ok {
+		return ErrDuplicate
+	}
+	r.posts[post.ID] = copyPost(post)
+	return nil
+}
+
+func (r *memoryPostRepo) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyPost(post), nil
+}
+
+func (r *memoryPostRepo) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := []*model.Post{}
+	for _, id := range ids {
+		if post, ok := r.posts[id]; ok {
+			posts = append(posts, copyPost(post))
+		}
+	}
+	return posts, nil
+}
+
+func (r *memoryPostRepo) Find(ctx context.Context, filter PostFilter, sortBy PostSort, skip, limit int) ([]*model.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := []*model.Post{}
+	for _, post := range r.posts {
+		if matchPost(post, filter) {
+			posts = append(posts, copyPost(post))
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		switch sortBy {
+		case SortByID:
+			return posts[i].ID.Hex() > posts[j].ID.Hex()
+		case SortByUpdatedAt:
+			return posts[i].UpdatedAt.After(posts[j].UpdatedAt)
+		default:
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+	})
+	return page(posts, skip, limit), nil
+}
+
+func (r *memoryPostRepo) Count(ctx context.Context, filter PostFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, post := range r.posts {
+		if matchPost(post, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryPostRepo) Update(ctx context.Context, id primitive.ObjectID, update PostUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	post, ok := r.posts[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	fields := map[*string]*string{
+		&post.Title:        update.Title,
+		&post.Content:      update.Content,
+		&post.Type:         update.Type,
+		&post.CoverImage:   update.CoverImage,
+		&post.Status:       update.Status,
+		&post.RejectReason: update.RejectReason,
+	}
+	for field, value := range fields {
+		if value != nil {
+			*field = *value
+		}
+	}
+	if update.Tags != nil {
+		post.Tags = append([]string(nil), (*update.Tags)...)
+	}
+	if update.Files != nil {
+		post.Files = append([]string(nil), (*update.Files)...)
+	}
+	if update.Hidden != nil {
+		post.Hidden = *update.Hidden
+	}
+	post.UpdatedAt = update.UpdatedAt
+	return nil
+}
+
+func (r *memoryPostRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.posts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.posts, id)
+	return nil
+}
+
+func (r *memoryPostRepo) IncrCounter(ctx context.Context, id primitive.ObjectID, counter PostCounter, delta int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	post, ok := r.posts[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	switch counter {
+	case PostLikes:
+		post.Likes += delta
+		return post.Likes, nil
+	case PostComments:
+		post.Comments += delta
+		return post.Comments, nil
+	}
+	return 0, nil
+}
+
+func (r *memoryPostRepo) CreatorStats(ctx context.Context, userID primitive.ObjectID) (CreatorStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stats CreatorStats
+	for _, post := range r.posts {
+		if post.UserID == userID && post.Status == "approved" {
+			stats.Posts++
+			stats.Likes += post.Likes
+			stats.Collections += post.Collections
+		}
+	}
+	return stats, nil
+}
+
+func (r *memoryPostRepo) TopTags(ctx context.Context, limit int) ([]TagCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, post := range r.posts {
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return page(tags, 0, limit), nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memorySettingsRepo struct {
+	mu       sync.RWMutex
+	settings *model.RuntimeSettings
+	history  []*model.SettingsChange
+}
+
+// NewMemorySettingsRepo 创建内存运行时配置仓储
+func NewMemorySettingsRepo() SettingsRepo {
+	return &memorySettingsRepo{}
+}
+
+func (r *memorySettingsRepo) Get(ctx context.Context) (*model.RuntimeSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.settings == nil {
+		return nil, ErrNotFound
+	}
+	copied := r.settings.Clone()
+	return &copied, nil
+}
+
+func (r *memorySettingsRepo) Save(ctx context.Context, settings *model.RuntimeSettings, expectedVersion int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := 0
+	if r.settings != nil {
+		current = r.settings.Version
+	}
+	if current != expectedVersion {
+		return ErrConflict
+	}
+	stored := settings.Clone()
+	r.settings = &stored
+	return nil
+}
+
+func (r *memorySettingsRepo) AddHistory(ctx context.Context, change *model.SettingsChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if change.ID.IsZero() {
+		change.ID = primitive.NewObjectID()
+	}
+	stored := *change
+	stored.Settings = change.Settings.Clone()
+	r.history = append(r.history, &stored)
+	return nil
+}
+
+func (r *memorySettingsRepo) ListHistory(ctx context.Context, skip, limit int) ([]*model.SettingsChange, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := make([]*model.SettingsChange, len(r.history))
+	copy(sorted, r.history)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version > sorted[j].Version })
+
+	changes := []*model.SettingsChange{}
+	for _, change := range page(sorted, skip, limit) {
+		copied := *change
+		changes = append(changes, &copied)
+	}
+	return changes, int64(len(sorted)), nil
+}
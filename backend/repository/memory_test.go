@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryUserRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepo()
+
+	alice := &model.User{Username: "alice"}
+	if err := repo.Create(ctx, alice); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	if err := repo.Create(ctx, &model.User{Username: "bob"}); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	taken := "bob"
+	renamed := "alice2"
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{name: "用户名重复", run: func() error { return repo.Create(ctx, &model.User{Username: "alice"}) }, wantErr: ErrDuplicate},
+		{name: "改为已占用的用户名", run: func() error { return repo.Update(ctx, alice.ID, UserUpdate{Username: &taken}) }, wantErr: ErrDuplicate},
+		{name: "修改用户名", run: func() error { return repo.Update(ctx, alice.ID, UserUpdate{Username: &renamed}) }},
+		{name: "更新不存在的用户", run: func() error { return repo.Update(ctx, primitive.NewObjectID(), UserUpdate{}) }, wantErr: ErrNotFound},
+		{name: "查询不存在的用户", run: func() error { _, err := repo.FindByUsername(ctx, "carol"); return err }, wantErr: ErrNotFound},
+		{name: "增加粉丝数", run: func() error { return repo.IncrCounter(ctx, alice.ID, UserFansCount, 2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err != tt.wantErr {
+				t.Errorf("err = %v, 期望 %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	user, err := repo.FindByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("查询用户失败: %v", err)
+	}
+	if user.Username != "alice2" || user.FansCount != 2 {
+		t.Errorf("用户数据不正确: %+v", user)
+	}
+
+	// 返回的是副本，修改不会影响仓储中的数据
+	user.Nickname = "changed"
+	if stored, _ := repo.FindByID(ctx, alice.ID); stored.Nickname == "changed" {
+		t.Error("修改查询结果影响了仓储中的数据")
+	}
+}
+
+func TestMemoryFollowRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFollowRepo()
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Now()
+
+	follow := func(user, following primitive.ObjectID, at time.Time) (bool, error) {
+		return repo.Follow(ctx, &model.UserFollow{UserID: user, FollowingID: following, CreatedAt: at})
+	}
+	if created, err := follow(alice, bob, now); err != nil || !created {
+		t.Fatalf("关注失败: created=%v err=%v", created, err)
+	}
+	if created, err := follow(alice, carol, now.Add(time.Second)); err != nil || !created {
+		t.Fatalf("关注失败: created=%v err=%v", created, err)
+	}
+	if created, err := follow(alice, bob, now); err != nil || created {
+		t.Errorf("重复关注 created=%v err=%v, 期望不报错且不新建", created, err)
+	}
+
+	following, _ := repo.ListFollowing(ctx, alice, 0, 10)
+	if len(following) != 2 || following[0].FollowingID != carol {
+		t.Errorf("关注列表应按时间倒序: %+v", following)
+	}
+	if ids, _ := repo.FollowingIDs(ctx, alice, []primitive.ObjectID{carol}); len(ids) != 1 || ids[0] != carol {
+		t.Errorf("FollowingIDs = %v, 期望 [carol]", ids)
+	}
+
+	removed, _ := repo.Unfollow(ctx, alice, bob)
+	again, _ := repo.Unfollow(ctx, alice, bob)
+	if !removed || again {
+		t.Errorf("取消关注 removed=%v again=%v", removed, again)
+	}
+
+	// 拉黑是双向隐藏，屏蔽只在 includeMuted 时隐藏
+	repo.Block(ctx, &model.UserBlock{UserID: bob, BlockedID: alice, CreatedAt: now})
+	repo.Mute(ctx, &model.UserMute{UserID: alice, MutedID: carol, CreatedAt: now})
+
+	tests := []struct {
+		name         string
+		viewer       primitive.ObjectID
+		includeMuted bool
+		want         []primitive.ObjectID
+	}{
+		{name: "被拉黑者", viewer: alice, want: []primitive.ObjectID{bob}},
+		{name: "被拉黑者含屏蔽", viewer: alice, includeMuted: true, want: []primitive.ObjectID{bob, carol}},
+		{name: "拉黑者", viewer: bob, want: []primitive.ObjectID{alice}},
+		{name: "无关用户", viewer: carol, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.HiddenUserIDs(ctx, tt.viewer, tt.includeMuted)
+			if err != nil {
+				t.Fatalf("HiddenUserIDs 失败: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("HiddenUserIDs = %v, 期望 %v", got, tt.want)
+			}
+			for _, id := range tt.want {
+				if !containsID(got, id) {
+					t.Errorf("HiddenUserIDs = %v, 缺少 %v", got, id)
+				}
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name        string
+		skip, limit int
+		want        []int
+	}{
+		{name: "第一页", skip: 0, limit: 2, want: []int{1, 2}},
+		{name: "最后一页", skip: 4, limit: 2, want: []int{5}},
+		{name: "超出范围", skip: 10, limit: 2, want: []int{}},
+		{name: "负数偏移", skip: -1, limit: 2, want: []int{1, 2}},
+		{name: "不限数量", skip: 1, limit: 0, want: []int{2, 3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := page(items, tt.skip, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("page = %v, 期望 %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("page = %v, 期望 %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
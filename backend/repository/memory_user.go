@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserRepo struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]*model.User
+}
+
+// NewMemoryUserRepo 创建内存用户仓储
+func NewMemoryUserRepo() UserRepo {
+	return &memoryUserRepo{users: make(map[primitive.ObjectID]*model.User)}
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username {
+			return ErrDuplicate
+		}
+	}
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *memoryUserRepo) FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *memoryUserRepo) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepo) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []model.User{}
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			users = append(users, *user)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepo) Exists(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.users[id]
+	return ok, nil
+}
+
+func (r *memoryUserRepo) UsernameTaken(ctx context.Context, username string, exceptID primitive.ObjectID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, user := range r.users {
+		if user.Username == username && id != exceptID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, id primitive.ObjectID, update UserUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if update.Username != nil {
+		for _, existing := range r.users {
+			if existing.ID != id && existing.Username == *update.Username {
+				return ErrDuplicate
+			}
+		}
+	}
+
+	fields := map[*string]*string{
+		&user.Username: update.Username,
+		&user.Password: update.Password,
+		&user.Nickname: update.Nickname,
+		&user.Avatar:   update.Avatar,
+		&user.Bio:      update.Bio,
+		&user.Gender:   update.Gender,
+		&user.Birthday: update.Birthday,
+		&user.Location: update.Location,
+		&user.Status:   update.Status,
+		&user.Language: update.Language,
+	}
+	for field, value := range fields {
+		if value != nil {
+			*field = *value
+		}
+	}
+	if update.Banned != nil {
+		user.Banned = *update.Banned
+	}
+	user.UpdatedAt = update.UpdatedAt
+	return nil
+}
+
+func (r *memoryUserRepo) IncrCounter(ctx context.Context, id primitive.ObjectID, counter UserCounter, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil
+	}
+	switch counter {
+	case UserFollowCount:
+		user.FollowCount += delta
+	case UserFansCount:
+		user.FansCount += delta
+	case UserLikeCount:
+		user.LikeCount += delta
+	case UserCollectCount:
+		user.CollectCount += delta
+	case UserPostCount:
+		user.PostCount += delta
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) SetCreatorStats(ctx context.Context, id primitive.ObjectID, stats CreatorStats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		user.PostCount = stats.Posts
+		user.LikeCount = stats.Likes
+		user.CollectCount = stats.Collections
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) Count(ctx context.Context, created TimeRange) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if inRange(user.CreatedAt, created) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewMongo 创建基于 MongoDB 的仓储集合
+func NewMongo(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Users:    NewMongoUserRepo(db),
+		Posts:    NewMongoPostRepo(db),
+		Comments: NewMongoCommentRepo(db),
+		Follows:  NewMongoFollowRepo(db),
+		Files:    NewMongoFileRepo(db),
+		Likes:    NewMongoLikeRepo(db),
+		Settings: NewMongoSettingsRepo(db),
+		Tx:       NewMongoTransactor(db),
+	}
+}
+
+// mongoError 将驱动错误转换为仓储错误
+func mongoError(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return ErrNotFound
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// upsertRelation 不存在时创建关系记录，返回是否新建。
+// 事务中插入重复记录会导致整个事务中止，因此用 upsert 代替插入：记录已存在时只匹配不写入；
+// 并发创建时事务内会产生可重试的写冲突，事务外的唯一索引冲突按已存在处理
+func upsertRelation(ctx context.Context, coll *mongo.Collection, filter bson.M, id primitive.ObjectID, createdAt time.Time) (bool, error) {
+	result, err := coll.UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$setOnInsert": bson.M{"_id": id, "created_at": createdAt}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// timeRangeFilter 将时间范围转换为查询条件，范围为空时返回 nil
+func timeRangeFilter(r TimeRange) bson.M {
+	cond := bson.M{}
+	if !r.From.IsZero() {
+		cond["$gte"] = r.From
+	}
+	if !r.To.IsZero() {
+		cond["$lte"] = r.To
+	}
+	if len(cond) == 0 {
+		return nil
+	}
+	return cond
+}
+
+// userIDFilter 构建 user_id 条件：等于 id（非零时）且不在 exclude 中
+func userIDFilter(id primitive.ObjectID, exclude []primitive.ObjectID) bson.M {
+	cond := bson.M{}
+	if !id.IsZero() {
+		cond["$eq"] = id
+	}
+	if len(exclude) > 0 {
+		cond["$nin"] = exclude
+	}
+	if len(cond) == 0 {
+		return nil
+	}
+	return cond
+}
+
+// mongoTransactor 基于会话的事务执行器
+type mongoTransactor struct {
+	db *mongo.Database
+}
+
+// NewMongoTransactor 创建 MongoDB 事务执行器
+func NewMongoTransactor(db *mongo.Database) Transactor {
+	return &mongoTransactor{db: db}
+}
+
+// transactionSupport 缓存各连接是否支持事务
+var transactionSupport sync.Map // *mongo.Client -> bool
+
+// supportsTransactions 判断 MongoDB 是否支持多文档事务（副本集或分片集群）
+func supportsTransactions(db *mongo.Database) bool {
+	if supported, ok := transactionSupport.Load(db.Client()); ok {
+		return supported.(bool)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result bson.M
+	err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&result)
+	if err != nil {
+		// 无法判断时不缓存，下次重试
+		slog.Warn("检测MongoDB部署类型失败", "error", err)
+		return false
+	}
+
+	_, isReplicaSet := result["setName"]
+	supported := isReplicaSet || result["msg"] == "isdbgrid"
+	transactionSupport.Store(db.Client(), supported)
+	if !supported {
+		slog.Info("MongoDB为单机部署，不支持事务，将按顺序执行写入")
+	}
+	return supported
+}
+
+// WithTransaction 在多文档事务中执行 fn，遇到临时错误时自动重试。
+// 单机部署不支持事务时直接按顺序执行，唯一索引保证幂等，计数偏差由对账任务修正。
+// fn 可能被多次调用，不应依赖上一次调用留下的状态。
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !supportsTransactions(t.db) {
+		return fn(ctx)
+	}
+
+	session, err := t.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoCommentRepo struct {
+	coll *mongo.Collection
+}
+
+// NewMongoCommentRepo 创建基于 MongoDB 的评论仓储
+func NewMongoCommentRepo(db *mongo.Database) CommentRepo {
+	return &mongoCommentRepo{coll: db.Collection("comments")}
+}
+
+// commentFilter 将查询条件转换为 MongoDB 过滤器
+func commentFilter(f CommentFilter) bson.M {
+	filter := bson.M{}
+	if !f.PostID.IsZero() {
+		filter["post_id"] = f.PostID
+	}
+	if f.ExcludeHidden {
+		filter["hidden"] = bson.M{"$ne": true}
+	}
+	if cond := userIDFilter(primitive.NilObjectID, f.ExcludeUserIDs); cond != nil {
+		filter["user_id"] = cond
+	}
+	if cond := timeRangeFilter(f.Created); cond != nil {
+		filter["created_at"] = cond
+	}
+	return filter
+}
+
+func (r *mongoCommentRepo) Create(ctx context.Context, comment *model.Comment) error {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	_, err := r.coll.InsertOne(ctx, comment)
+	return mongoError(err)
+}
+
+func (r *mongoCommentRepo) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&comment); err != nil {
+		return nil, mongoError(err)
+	}
+	return &comment, nil
+}
+
+func (r *mongoCommentRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoCommentRepo) Find(ctx context.Context, filter CommentFilter, sort CommentSort, asc bool, skip, limit int) ([]model.Comment, error) {
+	var sortKey string
+	switch sort {
+	case SortCommentByTime:
+		sortKey = "created_at"
+	case SortCommentByLikes:
+		sortKey = "likes"
+	default:
+		sortKey = "score"
+	}
+	order := -1
+	if asc {
+		order = 1
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: sortKey, Value: order}})
+	if skip > 0 {
+		opts.SetSkip(int64(skip))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.coll.Find(ctx, commentFilter(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []model.Comment{}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *mongoCommentRepo) Count(ctx context.Context, filter CommentFilter) (int64, error) {
+	return r.coll.CountDocuments(ctx, commentFilter(filter))
+}
+
+func (r *mongoCommentRepo) IncrLikes(ctx context.Context, id primitive.ObjectID, delta int) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"likes": delta}})
+	return err
+}
+
+func (r *mongoCommentRepo) UpdateScore(ctx context.Context, id primitive.ObjectID, score float64, updatedAt time.Time) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"score":      score,
+		"updated_at": updatedAt,
+	}})
+	return err
+}
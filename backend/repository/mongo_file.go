@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoFileRepo struct {
+	coll *mongo.Collection
+}
+
+// NewMongoFileRepo 创建基于 MongoDB 的文件记录仓储
+func NewMongoFileRepo(db *mongo.Database) FileRepo {
+	return &mongoFileRepo{coll: db.Collection("file_records")}
+}
+
+func (r *mongoFileRepo) Create(ctx context.Context, record *model.FileRecord) error {
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	_, err := r.coll.InsertOne(ctx, record)
+	return mongoError(err)
+}
+
+func (r *mongoFileRepo) findOne(ctx context.Context, filter bson.M) (*model.FileRecord, error) {
+	var record model.FileRecord
+	if err := r.coll.FindOne(ctx, filter).Decode(&record); err != nil {
+		return nil, mongoError(err)
+	}
+	return &record, nil
+}
+
+func (r *mongoFileRepo) FindByURL(ctx context.Context, url string) (*model.FileRecord, error) {
+	return r.findOne(ctx, bson.M{"url": url})
+}
+
+func (r *mongoFileRepo) FindOwned(ctx context.Context, filePath string, userID primitive.ObjectID) (*model.FileRecord, error) {
+	return r.findOne(ctx, bson.M{"file_path": filePath, "user_id": userID})
+}
+
+func (r *mongoFileRepo) MarkUsed(ctx context.Context, filePaths []string, usedAt time.Time) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	_, err := r.coll.UpdateMany(
+		ctx,
+		bson.M{"file_path": bson.M{"$in": filePaths}},
+		bson.M{"$set": bson.M{
+			"status":     model.FileStatusUsed,
+			"updated_at": usedAt,
+			"used_at":    usedAt,
+		}},
+	)
+	return err
+}
+
+func (r *mongoFileRepo) SetStatus(ctx context.Context, filePath string, status model.FileStatus, updatedAt time.Time) error {
+	_, err := r.coll.UpdateOne(
+		ctx,
+		bson.M{"file_path": filePath},
+		bson.M{"$set": bson.M{
+			"status":     status,
+			"updated_at": updatedAt,
+		}},
+	)
+	return err
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoFollowRepo struct {
+	follows *mongo.Collection
+	blocks  *mongo.Collection
+	mutes   *mongo.Collection
+}
+
+// NewMongoFollowRepo 创建基于 MongoDB 的用户关系仓储
+func NewMongoFollowRepo(db *mongo.Database) FollowRepo {
+	return &mongoFollowRepo{
+		follows: db.Collection("user_follows"),
+		blocks:  db.Collection("user_blocks"),
+		mutes:   db.Collection("user_mutes"),
+	}
+}
+
+// pageOptions 按创建时间倒序分页
+func pageOptions(skip, limit int) *options.FindOptions {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if skip > 0 {
+		opts.SetSkip(int64(skip))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	return opts
+}
+
+// exists 判断是否存在满足条件的记录
+func exists(ctx context.Context, coll *mongo.Collection, filter bson.M) (bool, error) {
+	count, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// deleteOne 删除一条记录，返回是否删除
+func deleteOne(ctx context.Context, coll *mongo.Collection, filter bson.M) (bool, error) {
+	result, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// targetIDs 分页查询关系记录，返回 field 字段的用户ID
+func targetIDs(ctx context.Context, coll *mongo.Collection, filter bson.M, field string, skip, limit int) ([]primitive.ObjectID, error) {
+	cursor, err := coll.Find(ctx, filter, pageOptions(skip, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []bson.M
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(docs))
+	for _, doc := range docs {
+		if id, ok := doc[field].(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *mongoFollowRepo) Follow(ctx context.Context, follow *model.UserFollow) (bool, error) {
+	if follow.ID.IsZero() {
+		follow.ID = primitive.NewObjectID()
+	}
+	return upsertRelation(ctx, r.follows,
+		bson.M{"user_id": follow.UserID, "following_id": follow.FollowingID},
+		follow.ID, follow.CreatedAt,
+	)
+}
+
+func (r *mongoFollowRepo) Unfollow(ctx context.Context, userID, followingID primitive.ObjectID) (bool, error) {
+	return deleteOne(ctx, r.follows, bson.M{"user_id": userID, "following_id": followingID})
+}
+
+func (r *mongoFollowRepo) FindFollow(ctx context.Context, userID, followingID primitive.ObjectID) (*model.UserFollow, error) {
+	var follow model.UserFollow
+	err := r.follows.FindOne(ctx, bson.M{"user_id": userID, "following_id": followingID}).Decode(&follow)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	return &follow, nil
+}
+
+func (r *mongoFollowRepo) IsFollowing(ctx context.Context, userID, followingID primitive.ObjectID) (bool, error) {
+	return exists(ctx, r.follows, bson.M{"user_id": userID, "following_id": followingID})
+}
+
+func (r *mongoFollowRepo) listFollows(ctx context.Context, filter bson.M, skip, limit int) ([]model.UserFollow, error) {
+	cursor, err := r.follows.Find(ctx, filter, pageOptions(skip, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	follows := []model.UserFollow{}
+	if err = cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+func (r *mongoFollowRepo) ListFollowing(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]model.UserFollow, error) {
+	return r.listFollows(ctx, bson.M{"user_id": userID}, skip, limit)
+}
+
+func (r *mongoFollowRepo) CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.follows.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoFollowRepo) ListFollowers(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]model.UserFollow, error) {
+	return r.listFollows(ctx, bson.M{"following_id": userID}, skip, limit)
+}
+
+func (r *mongoFollowRepo) CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.follows.CountDocuments(ctx, bson.M{"following_id": userID})
+}
+
+func (r *mongoFollowRepo) FollowingIDs(ctx context.Context, userID primitive.ObjectID, among []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	filter := bson.M{"user_id": userID}
+	if among != nil {
+		filter["following_id"] = bson.M{"$in": among}
+	}
+	return targetIDs(ctx, r.follows, filter, "following_id", 0, 0)
+}
+
+func (r *mongoFollowRepo) Block(ctx context.Context, block *model.UserBlock) error {
+	if block.ID.IsZero() {
+		block.ID = primitive.NewObjectID()
+	}
+	_, err := r.blocks.InsertOne(ctx, block)
+	return mongoError(err)
+}
+
+func (r *mongoFollowRepo) Unblock(ctx context.Context, userID, blockedID primitive.ObjectID) (bool, error) {
+	return deleteOne(ctx, r.blocks, bson.M{"user_id": userID, "blocked_id": blockedID})
+}
+
+func (r *mongoFollowRepo) HasBlocked(ctx context.Context, userID, targetID primitive.ObjectID) (bool, error) {
+	return exists(ctx, r.blocks, bson.M{"user_id": userID, "blocked_id": targetID})
+}
+
+func (r *mongoFollowRepo) ListBlocked(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	return targetIDs(ctx, r.blocks, bson.M{"user_id": userID}, "blocked_id", skip, limit)
+}
+
+func (r *mongoFollowRepo) CountBlocked(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.blocks.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoFollowRepo) Mute(ctx context.Context, mute *model.UserMute) error {
+	if mute.ID.IsZero() {
+		mute.ID = primitive.NewObjectID()
+	}
+	_, err := r.mutes.InsertOne(ctx, mute)
+	return mongoError(err)
+}
+
+func (r *mongoFollowRepo) Unmute(ctx context.Context, userID, mutedID primitive.ObjectID) (bool, error) {
+	return deleteOne(ctx, r.mutes, bson.M{"user_id": userID, "muted_id": mutedID})
+}
+
+func (r *mongoFollowRepo) IsMuted(ctx context.Context, userID, mutedID primitive.ObjectID) (bool, error) {
+	return exists(ctx, r.mutes, bson.M{"user_id": userID, "muted_id": mutedID})
+}
+
+func (r *mongoFollowRepo) ListMuted(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	return targetIDs(ctx, r.mutes, bson.M{"user_id": userID}, "muted_id", skip, limit)
+}
+
+func (r *mongoFollowRepo) CountMuted(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.mutes.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoFollowRepo) HiddenUserIDs(ctx context.Context, viewerID primitive.ObjectID, includeMuted bool) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+
+	// 拉黑关系对双方都生效
+	cursor, err := r.blocks.Find(ctx, bson.M{"$or": []bson.M{
+		{"user_id": viewerID},
+		{"blocked_id": viewerID},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	var blocks []model.UserBlock
+	if err = cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if block.UserID == viewerID {
+			ids = append(ids, block.BlockedID)
+		} else {
+			ids = append(ids, block.UserID)
+		}
+	}
+
+	if !includeMuted {
+		return ids, nil
+	}
+
+	muted, err := targetIDs(ctx, r.mutes, bson.M{"user_id": viewerID}, "muted_id", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return append(ids, muted...), nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoLikeRepo struct {
+	postLikes    *mongo.Collection
+	commentLikes *mongo.Collection
+	collections  *mongo.Collection
+}
+
+// NewMongoLikeRepo 创建基于 MongoDB 的互动记录仓储
+func NewMongoLikeRepo(db *mongo.Database) LikeRepo {
+	return &mongoLikeRepo{
+		postLikes:    db.Collection("post_likes"),
+		commentLikes: db.Collection("comment_likes"),
+		collections:  db.Collection("post_collections"),
+	}
+}
+
+func (r *mongoLikeRepo) LikePost(ctx context.Context, like *model.PostLike) (bool, error) {
+	if like.ID.IsZero() {
+		like.ID = primitive.NewObjectID()
+	}
+	return upsertRelation(ctx, r.postLikes,
+		bson.M{"post_id": like.PostID, "user_id": like.UserID},
+		like.ID, like.CreatedAt,
+	)
+}
+
+func (r *mongoLikeRepo) UnlikePost(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	return deleteOne(ctx, r.postLikes, bson.M{"post_id": postID, "user_id": userID})
+}
+
+func (r *mongoLikeRepo) HasLikedPost(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	return exists(ctx, r.postLikes, bson.M{"post_id": postID, "user_id": userID})
+}
+
+func (r *mongoLikeRepo) LikedPostIDs(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	return targetIDs(ctx, r.postLikes, bson.M{"user_id": userID}, "post_id", skip, limit)
+}
+
+func (r *mongoLikeRepo) CountLikedPosts(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.postLikes.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoLikeRepo) LikeComment(ctx context.Context, like *model.CommentLike) error {
+	if like.ID.IsZero() {
+		like.ID = primitive.NewObjectID()
+	}
+	_, err := r.commentLikes.InsertOne(ctx, like)
+	return mongoError(err)
+}
+
+func (r *mongoLikeRepo) UnlikeComment(ctx context.Context, commentID, userID primitive.ObjectID) (bool, error) {
+	return deleteOne(ctx, r.commentLikes, bson.M{"comment_id": commentID, "user_id": userID})
+}
+
+func (r *mongoLikeRepo) CollectedPostIDs(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error) {
+	return targetIDs(ctx, r.collections, bson.M{"user_id": userID}, "post_id", skip, limit)
+}
+
+func (r *mongoLikeRepo) CountCollectedPosts(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.collections.CountDocuments(ctx, bson.M{"user_id": userID})
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPostRepo struct {
+	coll *mongo.Collection
+}
+
+// NewMongoPostRepo 创建基于 MongoDB 的笔记仓储
+func NewMongoPostRepo(db *mongo.Database) PostRepo {
+	return &mongoPostRepo{coll: db.Collection("posts")}
+}
+
+// postFilter 将查询条件转换为 MongoDB 过滤器
+func postFilter(f PostFilter) bson.M {
+	filter := bson.M{}
+	if f.ExcludeHidden {
+		filter["hidden"] = bson.M{"$ne": true}
+	}
+	if f.Type != "" {
+		filter["type"] = f.Type
+	}
+	if f.Tag != "" {
+		filter["tags"] = f.Tag
+	}
+	if f.Status != "" {
+		filter["status"] = f.Status
+	}
+	if cond := userIDFilter(f.UserID, f.ExcludeUserIDs); cond != nil {
+		filter["user_id"] = cond
+	}
+	if !f.BeforeID.IsZero() {
+		filter["_id"] = bson.M{"$lt": f.BeforeID}
+	}
+	if cond := timeRangeFilter(f.Created); cond != nil {
+		filter["created_at"] = cond
+	}
+	return filter
+}
+
+func (r *mongoPostRepo) Create(ctx context.Context, post *model.Post) error {
+	if post.ID.IsZero() {
+		post.ID = primitive.NewObjectID()
+	}
+	_, err := r.coll.InsertOne(ctx, post)
+	return mongoError(err)
+}
+
+func (r *mongoPostRepo) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Post, error) {
+	var post model.Post
+	if err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&post); err != nil {
+		return nil, mongoError(err)
+	}
+	return &post, nil
+}
+
+func (r *mongoPostRepo) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Post, error) {
+	posts := []*model.Post{}
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	cursor, err := r.coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *mongoPostRepo) Find(ctx context.Context, filter PostFilter, sort PostSort, skip, limit int) ([]*model.Post, error) {
+	var sortKey string
+	switch sort {
+	case SortByID:
+		sortKey = "_id"
+	case SortByUpdatedAt:
+		sortKey = "updated_at"
+	default:
+		sortKey = "created_at"
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: sortKey, Value: -1}})
+	if skip > 0 {
+		opts.SetSkip(int64(skip))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.coll.Find(ctx, postFilter(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []*model.Post{}
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *mongoPostRepo) Count(ctx context.Context, filter PostFilter) (int64, error) {
+	return r.coll.CountDocuments(ctx, postFilter(filter))
+}
+
+func (r *mongoPostRepo) Update(ctx context.Context, id primitive.ObjectID, update PostUpdate) error {
+	set := bson.M{"updated_at": update.UpdatedAt}
+	fields := map[string]*string{
+		"title":         update.Title,
+		"content":       update.Content,
+		"type":          update.Type,
+		"cover_image":   update.CoverImage,
+		"status":        update.Status,
+		"reject_reason": update.RejectReason,
+	}
+	for field, value := range fields {
+		if value != nil {
+			set[field] = *value
+		}
+	}
+	if update.Tags != nil {
+		set["tags"] = *update.Tags
+	}
+	if update.Files != nil {
+		set["files"] = *update.Files
+	}
+	if update.Hidden != nil {
+		set["hidden"] = *update.Hidden
+	}
+
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPostRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPostRepo) IncrCounter(ctx context.Context, id primitive.ObjectID, counter PostCounter, delta int) (int, error) {
+	var updated bson.M
+	err := r.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{string(counter): delta}},
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{string(counter): 1}),
+	).Decode(&updated)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	return toInt(updated[string(counter)]), nil
+}
+
+func (r *mongoPostRepo) CreatorStats(ctx context.Context, userID primitive.ObjectID) (CreatorStats, error) {
+	cursor, err := r.coll.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"user_id": userID, "status": "approved"}},
+		{"$group": bson.M{
+			"_id":         nil,
+			"posts":       bson.M{"$sum": 1},
+			"likes":       bson.M{"$sum": "$likes"},
+			"collections": bson.M{"$sum": "$collections"},
+		}},
+	})
+	if err != nil {
+		return CreatorStats{}, err
+	}
+
+	var rows []struct {
+		Posts       int `bson:"posts"`
+		Likes       int `bson:"likes"`
+		Collections int `bson:"collections"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return CreatorStats{}, err
+	}
+	if len(rows) == 0 {
+		return CreatorStats{}, nil
+	}
+	return CreatorStats{Posts: rows[0].Posts, Likes: rows[0].Likes, Collections: rows[0].Collections}, nil
+}
+
+func (r *mongoPostRepo) TopTags(ctx context.Context, limit int) ([]TagCount, error) {
+	cursor, err := r.coll.Aggregate(ctx, []bson.M{
+		{"$unwind": "$tags"},
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.M{"count": -1}},
+		{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Tag   string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	tags := make([]TagCount, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, TagCount{Tag: row.Tag, Count: row.Count})
+	}
+	return tags, nil
+}
+
+// toInt 将 BSON 数值转换为 int
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
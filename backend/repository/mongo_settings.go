@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// settingsID 运行时配置文档的ID，集合中只有这一份文档
+const settingsID = "runtime"
+
+// settingsDocument settings 集合中保存的文档
+type settingsDocument struct {
+	ID                    string `bson:"_id"`
+	model.RuntimeSettings `bson:",inline"`
+}
+
+type mongoSettingsRepo struct {
+	settings *mongo.Collection
+	history  *mongo.Collection
+}
+
+// NewMongoSettingsRepo 创建基于 MongoDB 的运行时配置仓储
+func NewMongoSettingsRepo(db *mongo.Database) SettingsRepo {
+	return &mongoSettingsRepo{
+		settings: db.Collection("settings"),
+		history:  db.Collection("settings_history"),
+	}
+}
+
+func (r *mongoSettingsRepo) Get(ctx context.Context) (*model.RuntimeSettings, error) {
+	var doc settingsDocument
+	if err := r.settings.FindOne(ctx, bson.M{"_id": settingsID}).Decode(&doc); err != nil {
+		return nil, mongoError(err)
+	}
+	return &doc.RuntimeSettings, nil
+}
+
+func (r *mongoSettingsRepo) Save(ctx context.Context, settings *model.RuntimeSettings, expectedVersion int) error {
+	doc := settingsDocument{ID: settingsID, RuntimeSettings: *settings}
+
+	if expectedVersion == 0 {
+		_, err := r.settings.InsertOne(ctx, doc)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrConflict
+		}
+		return err
+	}
+
+	result, err := r.settings.ReplaceOne(ctx, bson.M{"_id": settingsID, "version": expectedVersion}, doc)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoSettingsRepo) AddHistory(ctx context.Context, change *model.SettingsChange) error {
+	_, err := r.history.InsertOne(ctx, change)
+	return err
+}
+
+func (r *mongoSettingsRepo) ListHistory(ctx context.Context, skip, limit int) ([]*model.SettingsChange, int64, error) {
+	total, err := r.history.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	if skip > 0 {
+		opts.SetSkip(int64(skip))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.history.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	changes := []*model.SettingsChange{}
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, 0, err
+	}
+	return changes, total, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"blue-note/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoUserRepo struct {
+	coll *mongo.Collection
+}
+
+// NewMongoUserRepo 创建基于 MongoDB 的用户仓储
+func NewMongoUserRepo(db *mongo.Database) UserRepo {
+	return &mongoUserRepo{coll: db.Collection("users")}
+}
+
+func (r *mongoUserRepo) Create(ctx context.Context, user *model.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	_, err := r.coll.InsertOne(ctx, user)
+	return mongoError(err)
+}
+
+func (r *mongoUserRepo) findOne(ctx context.Context, filter bson.M) (*model.User, error) {
+	var user model.User
+	if err := r.coll.FindOne(ctx, filter).Decode(&user); err != nil {
+		return nil, mongoError(err)
+	}
+	return &user, nil
+}
+
+func (r *mongoUserRepo) FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoUserRepo) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.findOne(ctx, bson.M{"username": username})
+}
+
+func (r *mongoUserRepo) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model.User, error) {
+	users := []model.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	cursor, err := r.coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *mongoUserRepo) Exists(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	count, err := r.coll.CountDocuments(ctx, bson.M{"_id": id})
+	return count > 0, err
+}
+
+func (r *mongoUserRepo) UsernameTaken(ctx context.Context, username string, exceptID primitive.ObjectID) (bool, error) {
+	count, err := r.coll.CountDocuments(ctx, bson.M{
+		"username": username,
+		"_id":      bson.M{"$ne": exceptID},
+	})
+	return count > 0, err
+}
+
+func (r *mongoUserRepo) Update(ctx context.Context, id primitive.ObjectID, update UserUpdate) error {
+	set := bson.M{"updated_at": update.UpdatedAt}
+	fields := map[string]*string{
+		"username": update.Username,
+		"password": update.Password,
+		"nickname": update.Nickname,
+		"avatar":   update.Avatar,
+		"bio":      update.Bio,
+		"gender":   update.Gender,
+		"birthday": update.Birthday,
+		"location": update.Location,
+		"status":   update.Status,
+		"language": update.Language,
+	}
+	for field, value := range fields {
+		if value != nil {
+			set[field] = *value
+		}
+	}
+	if update.Banned != nil {
+		set["banned"] = *update.Banned
+	}
+
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUserRepo) IncrCounter(ctx context.Context, id primitive.ObjectID, counter UserCounter, delta int) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{string(counter): delta}})
+	return err
+}
+
+func (r *mongoUserRepo) SetCreatorStats(ctx context.Context, id primitive.ObjectID, stats CreatorStats) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"post_count":    stats.Posts,
+		"like_count":    stats.Likes,
+		"collect_count": stats.Collections,
+	}})
+	return err
+}
+
+func (r *mongoUserRepo) Count(ctx context.Context, created TimeRange) (int64, error) {
+	filter := bson.M{}
+	if cond := timeRangeFilter(created); cond != nil {
+		filter["created_at"] = cond
+	}
+	return r.coll.CountDocuments(ctx, filter)
+}
@@ -0,0 +1,262 @@
+// Package repository 定义各聚合的数据访问接口，并提供 MongoDB 和内存两种实现。
+// 服务层只依赖这里的接口，内存实现用于测试和本地调试。
+package repository
+
+import (
+	"blue-note/apperr"
+	"blue-note/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrNotFound 记录不存在，服务层未转换时返回 404
+	ErrNotFound = apperr.ErrNotFound
+	// ErrDuplicate 违反唯一约束，服务层未转换时返回 409
+	ErrDuplicate = apperr.ErrConflict
+	// ErrConflict 记录已被其他请求修改
+	ErrConflict = errors.New("记录已被修改")
+)
+
+// Repositories 所有仓储的集合，便于统一创建和注入
+type Repositories struct {
+	Users    UserRepo
+	Posts    PostRepo
+	Comments CommentRepo
+	Follows  FollowRepo
+	Files    FileRepo
+	Likes    LikeRepo
+	Settings SettingsRepo
+	Tx       Transactor
+}
+
+// Transactor 在事务中执行 fn，fn 中的仓储调用需要使用传入的 ctx
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TimeRange 创建时间范围，零值表示不限制
+type TimeRange struct {
+	From time.Time // 包含
+	To   time.Time // 包含
+}
+
+// UserUpdate 用户资料更新，nil 字段不更新
+type UserUpdate struct {
+	Username  *string
+	Password  *string
+	Nickname  *string
+	Avatar    *string
+	Bio       *string
+	Gender    *string
+	Birthday  *string
+	Location  *string
+	Status    *string
+	Language  *string
+	Banned    *bool // 只由举报处理修改，不通过资料更新接口写入
+	UpdatedAt time.Time
+}
+
+// UserCounter 用户的冗余计数字段
+type UserCounter string
+
+const (
+	UserFollowCount  UserCounter = "follow_count"
+	UserFansCount    UserCounter = "fans_count"
+	UserLikeCount    UserCounter = "like_count"
+	UserCollectCount UserCounter = "collect_count"
+	UserPostCount    UserCounter = "post_count"
+)
+
+// CreatorStats 创作者的笔记数、获赞数和被收藏数
+type CreatorStats struct {
+	Posts       int
+	Likes       int
+	Collections int
+}
+
+// UserRepo 用户仓储
+type UserRepo interface {
+	Create(ctx context.Context, user *model.User) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error)
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model.User, error)
+	Exists(ctx context.Context, id primitive.ObjectID) (bool, error)
+	// UsernameTaken 判断用户名是否被 exceptID 以外的用户占用
+	UsernameTaken(ctx context.Context, username string, exceptID primitive.ObjectID) (bool, error)
+	Update(ctx context.Context, id primitive.ObjectID, update UserUpdate) error
+	IncrCounter(ctx context.Context, id primitive.ObjectID, counter UserCounter, delta int) error
+	SetCreatorStats(ctx context.Context, id primitive.ObjectID, stats CreatorStats) error
+	Count(ctx context.Context, created TimeRange) (int64, error)
+}
+
+// PostSort 笔记列表排序方式，均为降序
+type PostSort int
+
+const (
+	SortByCreatedAt PostSort = iota
+	SortByID
+	SortByUpdatedAt
+)
+
+// PostFilter 笔记查询条件，零值字段不参与过滤
+type PostFilter struct {
+	Type           string
+	Tag            string
+	Status         string
+	UserID         primitive.ObjectID
+	ExcludeUserIDs []primitive.ObjectID
+	ExcludeHidden  bool               // 排除因举报被隐藏的笔记
+	BeforeID       primitive.ObjectID // 游标分页：只返回 ID 小于该值的笔记
+	Created        TimeRange
+}
+
+// PostUpdate 笔记更新，nil 字段不更新
+type PostUpdate struct {
+	Title        *string
+	Content      *string
+	Type         *string
+	Tags         *[]string
+	Files        *[]string
+	CoverImage   *string
+	Status       *string
+	RejectReason *string
+	Hidden       *bool // 只由举报处理修改
+	UpdatedAt    time.Time
+}
+
+// PostCounter 笔记的冗余计数字段
+type PostCounter string
+
+const (
+	PostLikes    PostCounter = "likes"
+	PostComments PostCounter = "comments"
+)
+
+// TagCount 标签及其使用次数
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// PostRepo 笔记仓储
+type PostRepo interface {
+	Create(ctx context.Context, post *model.Post) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*model.Post, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Post, error)
+	Find(ctx context.Context, filter PostFilter, sort PostSort, skip, limit int) ([]*model.Post, error)
+	Count(ctx context.Context, filter PostFilter) (int64, error)
+	Update(ctx context.Context, id primitive.ObjectID, update PostUpdate) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// IncrCounter 更新计数并返回更新后的值
+	IncrCounter(ctx context.Context, id primitive.ObjectID, counter PostCounter, delta int) (int, error)
+	// CreatorStats 统计作者审核通过的笔记数、获赞数和被收藏数
+	CreatorStats(ctx context.Context, userID primitive.ObjectID) (CreatorStats, error)
+	// TopTags 统计使用次数最多的标签
+	TopTags(ctx context.Context, limit int) ([]TagCount, error)
+}
+
+// CommentSort 评论排序方式
+type CommentSort int
+
+const (
+	SortCommentByScore CommentSort = iota
+	SortCommentByTime
+	SortCommentByLikes
+)
+
+// CommentFilter 评论查询条件，零值字段不参与过滤
+type CommentFilter struct {
+	PostID         primitive.ObjectID
+	ExcludeUserIDs []primitive.ObjectID
+	ExcludeHidden  bool
+	Created        TimeRange
+}
+
+// CommentRepo 评论仓储
+type CommentRepo interface {
+	Create(ctx context.Context, comment *model.Comment) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*model.Comment, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	Find(ctx context.Context, filter CommentFilter, sort CommentSort, asc bool, skip, limit int) ([]model.Comment, error)
+	Count(ctx context.Context, filter CommentFilter) (int64, error)
+	IncrLikes(ctx context.Context, id primitive.ObjectID, delta int) error
+	UpdateScore(ctx context.Context, id primitive.ObjectID, score float64, updatedAt time.Time) error
+}
+
+// FollowRepo 用户关系仓储：关注、拉黑和屏蔽
+type FollowRepo interface {
+	// Follow 创建关注关系，返回是否新建了记录；已关注时返回 false 而不是写入错误，可在事务中安全重复调用
+	Follow(ctx context.Context, follow *model.UserFollow) (bool, error)
+	// Unfollow 删除关注关系，返回是否删除了记录
+	Unfollow(ctx context.Context, userID, followingID primitive.ObjectID) (bool, error)
+	FindFollow(ctx context.Context, userID, followingID primitive.ObjectID) (*model.UserFollow, error)
+	IsFollowing(ctx context.Context, userID, followingID primitive.ObjectID) (bool, error)
+	ListFollowing(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]model.UserFollow, error)
+	CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	ListFollowers(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]model.UserFollow, error)
+	CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	// FollowingIDs 返回 userID 关注的用户ID，among 不为空时只在其中查找
+	FollowingIDs(ctx context.Context, userID primitive.ObjectID, among []primitive.ObjectID) ([]primitive.ObjectID, error)
+
+	// Block 创建拉黑关系，已拉黑时返回 ErrDuplicate
+	Block(ctx context.Context, block *model.UserBlock) error
+	Unblock(ctx context.Context, userID, blockedID primitive.ObjectID) (bool, error)
+	HasBlocked(ctx context.Context, userID, targetID primitive.ObjectID) (bool, error)
+	ListBlocked(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error)
+	CountBlocked(ctx context.Context, userID primitive.ObjectID) (int64, error)
+
+	// Mute 创建屏蔽关系，已屏蔽时返回 ErrDuplicate
+	Mute(ctx context.Context, mute *model.UserMute) error
+	Unmute(ctx context.Context, userID, mutedID primitive.ObjectID) (bool, error)
+	IsMuted(ctx context.Context, userID, mutedID primitive.ObjectID) (bool, error)
+	ListMuted(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error)
+	CountMuted(ctx context.Context, userID primitive.ObjectID) (int64, error)
+
+	// HiddenUserIDs 返回对 viewerID 不可见的用户：双向拉黑的用户，以及 includeMuted 为 true 时被屏蔽的用户
+	HiddenUserIDs(ctx context.Context, viewerID primitive.ObjectID, includeMuted bool) ([]primitive.ObjectID, error)
+}
+
+// LikeRepo 互动记录仓储：笔记点赞、评论点赞和收藏
+type LikeRepo interface {
+	// LikePost 创建点赞记录，返回是否新建了记录；已点赞时返回 false 而不是写入错误，可在事务中安全重复调用
+	LikePost(ctx context.Context, like *model.PostLike) (bool, error)
+	UnlikePost(ctx context.Context, postID, userID primitive.ObjectID) (bool, error)
+	HasLikedPost(ctx context.Context, postID, userID primitive.ObjectID) (bool, error)
+	// LikedPostIDs 按点赞时间倒序返回用户点赞的笔记ID
+	LikedPostIDs(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error)
+	CountLikedPosts(ctx context.Context, userID primitive.ObjectID) (int64, error)
+
+	// LikeComment 创建评论点赞记录，已点赞时返回 ErrDuplicate
+	LikeComment(ctx context.Context, like *model.CommentLike) error
+	UnlikeComment(ctx context.Context, commentID, userID primitive.ObjectID) (bool, error)
+
+	// CollectedPostIDs 按收藏时间倒序返回用户收藏的笔记ID
+	CollectedPostIDs(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error)
+	CountCollectedPosts(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// FileRepo 文件记录仓储
+type FileRepo interface {
+	Create(ctx context.Context, record *model.FileRecord) error
+	FindByURL(ctx context.Context, url string) (*model.FileRecord, error)
+	// FindOwned 查找用户上传的文件
+	FindOwned(ctx context.Context, filePath string, userID primitive.ObjectID) (*model.FileRecord, error)
+	// MarkUsed 将文件标记为已使用
+	MarkUsed(ctx context.Context, filePaths []string, usedAt time.Time) error
+	SetStatus(ctx context.Context, filePath string, status model.FileStatus, updatedAt time.Time) error
+}
+
+// SettingsRepo 运行时配置仓储，配置只有一份，按版本号保存修改
+type SettingsRepo interface {
+	// Get 获取当前配置，尚未保存过时返回 ErrNotFound
+	Get(ctx context.Context) (*model.RuntimeSettings, error)
+	// Save 保存配置，expectedVersion 为修改前的版本号（首次保存为 0），与当前版本不一致时返回 ErrConflict
+	Save(ctx context.Context, settings *model.RuntimeSettings, expectedVersion int) error
+	AddHistory(ctx context.Context, change *model.SettingsChange) error
+	// ListHistory 按版本号倒序返回修改记录
+	ListHistory(ctx context.Context, skip, limit int) ([]*model.SettingsChange, int64, error)
+}
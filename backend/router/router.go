@@ -1,17 +1,18 @@
 package router
 
 import (
+	"blue-note/config"
 	"blue-note/controller"
+	"blue-note/metrics"
 	"blue-note/middleware"
-	"context"
-	"fmt"
-	"net/http"
+	"blue-note/model"
+	"blue-note/ratelimit"
+	"blue-note/service"
+	"log/slog"
 	"os"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func SetupRouter(
@@ -21,24 +22,60 @@ func SetupRouter(
 	adminController *controller.AdminController,
 	uploadController *controller.UploadController,
 	fileController *controller.FileController,
-	mongoClient *mongo.Client,
+	reportController *controller.ReportController,
+	feedController *controller.FeedController,
+	trendingController *controller.TrendingController,
+	tagController *controller.TagController,
+	analyticsController *controller.AnalyticsController,
+	reconcileController *controller.ReconcileController,
+	settingsController *controller.SettingsController,
+	healthController *controller.HealthController,
+	settingsService *service.SettingsService,
+	accounts middleware.AccountChecker,
+	rateLimitStore ratelimit.Store,
 ) *gin.Engine {
-	r := gin.Default()
-
-	// 配置 CORS 中间件
-	r.Use(cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000", // 本地开发环境
-			"https://blue-note-v443.vercel.app",
-			"https://blue-note-v443-git-master-gary-xiongs-projects.vercel.app",
-			"https://blue-note-v443-rmmfv3wi4-gary-xiongs-projects.vercel.app",
-		},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	r := gin.New()
+
+	// 默认不信任任何代理，ClientIP 直接使用连接地址，避免客户端伪造 X-Forwarded-For 绕过按 IP 的限流和浏览去重
+	if err := r.SetTrustedProxies(config.GetConfig().Server.TrustedProxies); err != nil {
+		slog.Error("设置信任的代理地址失败", "error", err)
+	}
+
+	// 请求ID、链路追踪和访问日志放在最外层，其他中间件和处理函数的日志都能带上请求ID和链路ID；
+	// 错误处理放在其他中间件之前，认证、限流、超时和 panic 产生的错误都以统一格式、按请求的语言返回
+	r.Use(middleware.RequestID(), middleware.Tracing(), middleware.AccessLog(), middleware.Metrics(), middleware.Locale(), middleware.ErrorHandler(), middleware.Recovery())
+	r.NoRoute(middleware.NotFound)
+
+	// 配置 CORS 中间件，允许的来源由管理员在运行时配置中设置
+	corsMiddleware := middleware.NewCORS(model.DefaultRuntimeSettings().CORS.AllowOrigins)
+	r.Use(corsMiddleware.Handler())
+
+	// 限流策略，规则由管理员在运行时配置中设置
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore)
+	policies := make(map[string]*middleware.RatePolicy, len(model.RateLimitNames))
+	for _, name := range model.RateLimitNames {
+		policies[name] = middleware.NewRatePolicy(name, ratelimit.Rule{}, "")
+	}
+	limit := func(name string) gin.HandlerFunc {
+		return rateLimiter.RateLimit(policies[name])
+	}
+
+	// 运行时配置修改后立即应用到中间件
+	settingsService.Subscribe(func(settings model.RuntimeSettings) {
+		corsMiddleware.SetOrigins(settings.CORS.AllowOrigins)
+		for name, policy := range policies {
+			if rule, ok := settings.RateLimits[name]; ok {
+				policy.Set(ratelimit.Rule{
+					Algorithm: ratelimit.Algorithm(rule.Algorithm),
+					Limit:     rule.Requests,
+					Window:    time.Duration(rule.Window) * time.Second,
+				}, rule.Key)
+			}
+		}
+	})
+
+	// 请求超时控制，超时后取消请求上下文
+	r.Use(middleware.Timeout())
 
 	// 配置静态文件服务
 	// 确保上传目录存在
@@ -46,7 +83,7 @@ func SetupRouter(
 	r.Static("/uploads", "./uploads")
 	
 	// 添加日志，帮助调试静态文件服务
-	fmt.Println("已配置静态文件服务: /uploads -> ./uploads")
+	slog.Debug("已配置静态文件服务", "path", "/uploads", "dir", "./uploads")
 
 	// 公开路由
 	public := r.Group("/api/v1")
@@ -55,21 +92,45 @@ func SetupRouter(
 		auth := public.Group("/auth")
 		{
 			auth.GET("/captcha", authController.GetCaptcha)
-			auth.POST("/login", authController.Login)
+			auth.POST("/login", limit(model.RateLimitLogin), authController.Login)
 			auth.POST("/change-password", authController.ChangePassword)
 		}
 
 		// 帖子相关（公开）
 		posts := public.Group("/posts")
+		posts.Use(middleware.OptionalAuthMiddleware(accounts))
 		{
 			posts.GET("", postController.GetPostList)
 			posts.GET("/:postId", postController.GetPostDetail)
 		}
+
+		// 发现页（公开，登录后按兴趣推荐）
+		discover := public.Group("/feed")
+		discover.Use(middleware.OptionalAuthMiddleware(accounts))
+		{
+			discover.GET("/discover", feedController.GetDiscoverFeed)
+		}
+
+		// 热门榜单（公开）
+		trending := public.Group("/trending")
+		trending.Use(middleware.OptionalAuthMiddleware(accounts))
+		{
+			trending.GET("", trendingController.GetTrending)
+			trending.GET("/tags/suggest", trendingController.SuggestTags)
+		}
+
+		// 标签（公开）
+		tags := public.Group("/tags")
+		tags.Use(middleware.OptionalAuthMiddleware(accounts))
+		{
+			tags.GET("", tagController.ListTags)
+			tags.GET("/:name", tagController.GetTag)
+		}
 	}
 
 	// 需要认证的路由
 	authorized := r.Group("/api/v1")
-	authorized.Use(middleware.AuthMiddleware())
+	authorized.Use(middleware.AuthMiddleware(accounts))
 	{
 		// 用户相关
 		userGroup := authorized.Group("/users")
@@ -84,13 +145,32 @@ func SetupRouter(
 				authUserGroup.PUT("/profile", profileController.UpdateProfile)
 				
 				// 关注用户
-				authUserGroup.POST("/follow/:userId", profileController.FollowUser)
+				authUserGroup.POST("/follow/:userId", limit(model.RateLimitFollow), profileController.FollowUser)
 				
 				// 取消关注
 				authUserGroup.DELETE("/follow/:userId", profileController.UnfollowUser)
 				
 				// 检查关注状态
 				authUserGroup.GET("/follow/check/:userId", profileController.CheckFollowStatus)
+
+				// 举报用户
+				authUserGroup.POST("/:userId/report", reportController.ReportUser)
+
+				// 拉黑/取消拉黑
+				authUserGroup.POST("/block/:userId", profileController.BlockUser)
+				authUserGroup.DELETE("/block/:userId", profileController.UnblockUser)
+				authUserGroup.GET("/blocks", profileController.GetBlockedList)
+
+				// 屏蔽/取消屏蔽
+				authUserGroup.POST("/mute/:userId", profileController.MuteUser)
+				authUserGroup.DELETE("/mute/:userId", profileController.UnmuteUser)
+				authUserGroup.GET("/mutes", profileController.GetMutedList)
+
+				// 关注的标签
+				authUserGroup.GET("/tags", tagController.GetFollowedTags)
+
+				// 创作者数据看板
+				authUserGroup.GET("/dashboard", analyticsController.GetCreatorDashboard)
 			}
 			
 			// 获取用户关注列表
@@ -115,16 +195,23 @@ func SetupRouter(
 
 			// 评论相关
 			posts.GET("/:postId/comments", postController.GetPostComments)
-			posts.POST("/:postId/comments", postController.CreateComment)
+			posts.POST("/:postId/comments", limit(model.RateLimitComment), postController.CreateComment)
 			posts.DELETE("/:postId/comments/:commentId", postController.DeleteComment)
-			posts.POST("/:postId/comments/:commentId/like", postController.LikeComment)
+			posts.POST("/:postId/comments/:commentId/like", limit(model.RateLimitLike), postController.LikeComment)
 			posts.DELETE("/:postId/comments/:commentId/like", postController.UnlikeComment)
+			posts.POST("/:postId/comments/:commentId/report", reportController.ReportComment)
 
 			// 点赞相关
-			posts.POST("/:postId/like", postController.LikePost)
+			posts.POST("/:postId/like", limit(model.RateLimitLike), postController.LikePost)
 			posts.DELETE("/:postId/like", postController.UnlikePost)
 			posts.GET("/:postId/like", postController.CheckLikeStatus)
 
+			// 举报相关
+			posts.POST("/:postId/report", reportController.ReportPost)
+
+			// 笔记数据分析（作者本人或管理员）
+			posts.GET("/:postId/analytics", analyticsController.GetPostAnalytics)
+
 			// 草稿相关
 			posts.POST("/draft", postController.SaveDraft)
 			posts.GET("/drafts", postController.GetUserDrafts)
@@ -133,53 +220,53 @@ func SetupRouter(
 			posts.POST("/draft/:draftId/publish", postController.PublishDraft)
 		}
 
+		// 关注流
+		authorized.GET("/feed/following", feedController.GetFollowingFeed)
+
+		// 关注/取消关注标签
+		authorized.POST("/tags/:name/follow", tagController.FollowTag)
+		authorized.DELETE("/tags/:name/follow", tagController.UnfollowTag)
+
 		// 文件上传
-		authorized.POST("/upload", uploadController.UploadFile)
+		authorized.POST("/upload", limit(model.RateLimitUpload), uploadController.UploadFile)
 
 		// 管理员相关
 		admin := authorized.Group("/admin")
 		admin.Use(middleware.AdminMiddleware())
 		{
 			admin.GET("/stats", adminController.GetStatistics)
+			admin.GET("/config", adminController.GetConfig)
 			admin.GET("/posts/pending", adminController.GetPendingPosts)
 			admin.PUT("/posts/:postId/review", postController.ReviewPost)
+			admin.GET("/reports", reportController.GetReports)
+			admin.PUT("/reports/:reportId", reportController.HandleReport)
+			admin.POST("/tags", tagController.CreateTag)
+			admin.PUT("/tags/:tagId", tagController.UpdateTag)
+			admin.POST("/tags/:tagId/merge", tagController.MergeTags)
+			admin.POST("/reconcile", reconcileController.RunReconcile)
+			admin.GET("/reconcile/reports", reconcileController.GetReconcileReports)
+			admin.GET("/settings", settingsController.GetSettings)
+			admin.PUT("/settings", settingsController.UpdateSettings)
+			admin.GET("/settings/history", settingsController.GetSettingsHistory)
 		}
 
-		// 创建速率限制器
-		rateLimiter := middleware.NewRateLimiter()
-
 		// 文件管理相关路由
 		files := authorized.Group("/file")
 		{
 			// 保留删除文件的路由
-			files.POST("/delete", rateLimiter.RateLimit(10, time.Minute), fileController.DeleteFile)
+			files.POST("/delete", limit(model.RateLimitFileDelete), fileController.DeleteFile)
 		}
 	}
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		// 检查MongoDB连接
-		mongoStatus := "ok"
-		if err := mongoClient.Ping(context.Background(), nil); err != nil {
-			mongoStatus = "error: " + err.Error()
-		}
-		
-		// 检查Redis连接（如果您使用了Redis客户端）
-		redisStatus := "ok"
-		// 假设您有一个redisClient
-		// if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
-		//     redisStatus = "error: " + err.Error()
-		// }
-		
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-			"time":   time.Now().Format(time.RFC3339),
-			"database": gin.H{
-				"mongodb": mongoStatus,
-				"redis":   redisStatus,
-			},
-		})
-	})
+	// 健康检查：/health/live 为存活检查，/health/ready 为就绪检查，/health 与就绪检查相同
+	r.GET("/health/live", healthController.Live)
+	r.GET("/health/ready", healthController.Ready)
+	r.GET("/health", healthController.Ready)
+
+	// Prometheus 指标，设置了 metrics.token 时需要在 Authorization 头中携带该 token
+	if cfg := config.GetConfig().Metrics; cfg.Enabled {
+		r.GET("/metrics", middleware.MetricsAuth(cfg.Token), gin.WrapH(metrics.Handler()))
+	}
 
 	return r
 }
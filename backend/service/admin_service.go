@@ -2,18 +2,25 @@ package service
 
 import (
 	"blue-note/model"
+	"blue-note/repository"
 	"context"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"time"
 )
 
 type AdminService struct {
-	db *mongo.Database
+	users       repository.UserRepo
+	posts       repository.PostRepo
+	comments    repository.CommentRepo
+	postService *PostService
 }
 
-func NewAdminService(db *mongo.Database) *AdminService {
-	return &AdminService{db: db}
+func NewAdminService(repos *repository.Repositories, postService *PostService) *AdminService {
+	return &AdminService{
+		users:       repos.Users,
+		posts:       repos.Posts,
+		comments:    repos.Comments,
+		postService: postService,
+	}
 }
 
 type StatisticsResponse struct {
@@ -37,45 +44,45 @@ type TagStat struct {
 	Count int    `json:"count"`
 }
 
-func (s *AdminService) GetStatistics() (*StatisticsResponse, error) {
+func (s *AdminService) GetStatistics(ctx context.Context) (*StatisticsResponse, error) {
 	stats := &StatisticsResponse{}
 
 	// 获取总用户数
-	totalUsers, err := s.db.Collection("users").CountDocuments(context.Background(), bson.M{})
+	totalUsers, err := s.users.Count(ctx, repository.TimeRange{})
 	if err != nil {
 		return nil, err
 	}
 	stats.TotalUsers = int(totalUsers)
 
 	// 获取总帖子数
-	totalPosts, err := s.db.Collection("posts").CountDocuments(context.Background(), bson.M{})
+	totalPosts, err := s.posts.Count(ctx, repository.PostFilter{})
 	if err != nil {
 		return nil, err
 	}
 	stats.TotalPosts = int(totalPosts)
 
 	// 获取待审核帖子数
-	pendingPosts, err := s.db.Collection("posts").CountDocuments(context.Background(), bson.M{"status": "pending"})
+	pendingPosts, err := s.posts.Count(ctx, repository.PostFilter{Status: "pending"})
 	if err != nil {
 		return nil, err
 	}
 	stats.PendingPosts = int(pendingPosts)
 
 	// 获取总评论数
-	totalComments, err := s.db.Collection("comments").CountDocuments(context.Background(), bson.M{})
+	totalComments, err := s.comments.Count(ctx, repository.CommentFilter{})
 	if err != nil {
 		return nil, err
 	}
 	stats.TotalComments = int(totalComments)
 
 	// 获取最近7天的每日统计
-	stats.DailyStats, err = s.getDailyStats()
+	stats.DailyStats, err = s.getDailyStats(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// 获取标签统计
-	stats.TagStats, err = s.getTagStats()
+	stats.TagStats, err = s.getTagStats(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +90,7 @@ func (s *AdminService) GetStatistics() (*StatisticsResponse, error) {
 	return stats, nil
 }
 
-func (s *AdminService) getDailyStats() ([]*DailyStat, error) {
+func (s *AdminService) getDailyStats(ctx context.Context) ([]*DailyStat, error) {
 	// 计算最近7天的日期
 	now := time.Now()
 	var results []*DailyStat
@@ -92,36 +99,22 @@ func (s *AdminService) getDailyStats() ([]*DailyStat, error) {
 		date := now.AddDate(0, 0, -i)
 		startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 		endOfDay := time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 999999999, date.Location())
+		day := repository.TimeRange{From: startOfDay, To: endOfDay}
 
 		// 当日新增用户
-		newUsers, err := s.db.Collection("users").CountDocuments(context.Background(), bson.M{
-			"created_at": bson.M{
-				"$gte": startOfDay,
-				"$lte": endOfDay,
-			},
-		})
+		newUsers, err := s.users.Count(ctx, day)
 		if err != nil {
 			return nil, err
 		}
 
 		// 当日新增帖子
-		newPosts, err := s.db.Collection("posts").CountDocuments(context.Background(), bson.M{
-			"created_at": bson.M{
-				"$gte": startOfDay,
-				"$lte": endOfDay,
-			},
-		})
+		newPosts, err := s.posts.Count(ctx, repository.PostFilter{Created: day})
 		if err != nil {
 			return nil, err
 		}
 
 		// 当日新增评论
-		newComments, err := s.db.Collection("comments").CountDocuments(context.Background(), bson.M{
-			"created_at": bson.M{
-				"$gte": startOfDay,
-				"$lte": endOfDay,
-			},
-		})
+		newComments, err := s.comments.Count(ctx, repository.CommentFilter{Created: day})
 		if err != nil {
 			return nil, err
 		}
@@ -137,43 +130,25 @@ func (s *AdminService) getDailyStats() ([]*DailyStat, error) {
 	return results, nil
 }
 
-func (s *AdminService) getTagStats() ([]*TagStat, error) {
-	// 获取所有标签及其使用次数
-	pipeline := []bson.M{
-		{"$unwind": "$tags"},
-		{"$group": bson.M{
-			"_id":   "$tags",
-			"count": bson.M{"$sum": 1},
-		}},
-		{"$sort": bson.M{"count": -1}},
-		{"$limit": 10},
-	}
-
-	cursor, err := s.db.Collection("posts").Aggregate(context.Background(), pipeline)
+func (s *AdminService) getTagStats(ctx context.Context) ([]*TagStat, error) {
+	// 获取使用次数最多的10个标签
+	tags, err := s.posts.TopTags(ctx, 10)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
 
 	var results []*TagStat
-	for cursor.Next(context.Background()) {
-		var result struct {
-			ID    string `bson:"_id"`
-			Count int    `bson:"count"`
-		}
-		if err := cursor.Decode(&result); err != nil {
-			return nil, err
-		}
+	for _, tag := range tags {
 		results = append(results, &TagStat{
-			Tag:   result.ID,
-			Count: result.Count,
+			Tag:   tag.Tag,
+			Count: tag.Count,
 		})
 	}
 
 	return results, nil
 }
 
-func (s *AdminService) GetPendingPosts(page, limit int) (*model.PostListResponse, error) {
+func (s *AdminService) GetPendingPosts(ctx context.Context, page, limit int) (*model.PostListResponse, error) {
 	query := &model.PostQuery{
 		Page:   page,
 		Limit:  limit,
@@ -181,6 +156,5 @@ func (s *AdminService) GetPendingPosts(page, limit int) (*model.PostListResponse
 	}
 
 	// 使用PostService获取待审核帖子列表
-	postService := NewPostService(s.db, nil)
-	return postService.GetPostList(query)
+	return s.postService.GetPostList(ctx, query, "")
 } 
\ No newline at end of file
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/model"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AnalyticsService 创作者数据分析服务
+type AnalyticsService struct {
+	db *mongo.Database
+}
+
+// NewAnalyticsService 创建数据分析服务实例
+func NewAnalyticsService(db *mongo.Database) *AnalyticsService {
+	return &AnalyticsService{db: db}
+}
+
+// GetPostAnalytics 获取笔记的数据分析，仅作者本人和管理员可查看
+func (s *AnalyticsService) GetPostAnalytics(ctx context.Context, postID string, userID string, role string, query *model.AnalyticsQuery) (*model.PostAnalyticsResponse, error) {
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的笔记ID: %w", err)
+	}
+
+	var post model.Post
+	err = s.db.Collection("posts").FindOne(ctx, bson.M{"_id": postObjectID}).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, apperr.ErrPostNotFound
+		}
+		return nil, err
+	}
+
+	if post.UserID.Hex() != userID && role != "admin" {
+		return nil, apperr.ErrAnalyticsForbidden
+	}
+
+	days := query.Days
+	if days < 1 {
+		days = 30
+	}
+	start, dates := analyticsDates(days)
+
+	views, err := sumByDay(ctx, s.db, "post_daily_stats", bson.M{"post_id": postObjectID, "date": bson.M{"$gte": dates[0]}}, "views")
+	if err != nil {
+		return nil, err
+	}
+	likes, err := countByDay(ctx, s.db, "post_likes", bson.M{"post_id": postObjectID}, start)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := countByDay(ctx, s.db, "comments", bson.M{"post_id": postObjectID}, start)
+	if err != nil {
+		return nil, err
+	}
+	collects, err := countByDay(ctx, s.db, "post_collections", bson.M{"post_id": postObjectID}, start)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := countByDay(ctx, s.db, "user_follows", bson.M{"following_id": post.UserID}, start)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := make([]model.DailyMetrics, 0, len(dates))
+	for _, date := range dates {
+		daily = append(daily, model.DailyMetrics{
+			Date:         date,
+			Views:        views[date],
+			Likes:        likes[date],
+			Comments:     comments[date],
+			Collects:     collects[date],
+			FollowerGain: followers[date],
+		})
+	}
+
+	return &model.PostAnalyticsResponse{
+		PostID: post.ID.Hex(),
+		Title:  post.Title,
+		Totals: model.PostAnalyticsTotals{
+			Views:    post.Views,
+			Likes:    post.Likes,
+			Comments: post.Comments,
+			Collects: post.Collections,
+		},
+		Daily: daily,
+	}, nil
+}
+
+// analyticsDates 返回最近 days 天（含今天）的起始时间和日期列表
+func analyticsDates(days int) (time.Time, []string) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -(days - 1))
+
+	dates := make([]string, 0, days)
+	for d := start; !d.After(today); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return start, dates
+}
+
+// countByDay 按 created_at 所在日期（服务器时区）统计记录数
+func countByDay(ctx context.Context, db *mongo.Database, collection string, filter bson.M, start time.Time) (map[string]int, error) {
+	match := bson.M{"created_at": bson.M{"$gte": start}}
+	for k, v := range filter {
+		match[k] = v
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id": bson.M{"$dateToString": bson.M{
+				"format":   "%Y-%m-%d",
+				"date":     "$created_at",
+				"timezone": start.Format("-07:00"),
+			}},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	return aggregateByDay(ctx, db, collection, pipeline)
+}
+
+// sumByDay 对按日期存储的统计记录求和
+func sumByDay(ctx context.Context, db *mongo.Database, collection string, filter bson.M, field string) (map[string]int, error) {
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$group": bson.M{
+			"_id":   "$date",
+			"count": bson.M{"$sum": "$" + field},
+		}},
+	}
+
+	return aggregateByDay(ctx, db, collection, pipeline)
+}
+
+func aggregateByDay(ctx context.Context, db *mongo.Database, collection string, pipeline []bson.M) (map[string]int, error) {
+	cursor, err := db.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("统计%s失败: %w", collection, err)
+	}
+
+	var rows []struct {
+		Date  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int, len(rows))
+	for _, row := range rows {
+		result[row.Date] = row.Count
+	}
+	return result, nil
+}
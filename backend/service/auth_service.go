@@ -1,29 +1,29 @@
 package service
 
 import (
+	"blue-note/apperr"
+	"blue-note/config"
+	"blue-note/metrics"
 	"blue-note/middleware"
 	"blue-note/model"
+	"blue-note/repository"
 	"blue-note/util"
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/spf13/viper"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	db             *mongo.Database
+	users          repository.UserRepo
 	profileService *ProfileService
 }
 
-func NewAuthService(db *mongo.Database, profileService *ProfileService) *AuthService {
+func NewAuthService(repos *repository.Repositories, profileService *ProfileService) *AuthService {
 	return &AuthService{
-		db:             db,
+		users:          repos.Users,
 		profileService: profileService,
 	}
 }
@@ -37,21 +37,25 @@ func (s *AuthService) GenerateCaptcha() (string, string, error) {
 }
 
 // LoginOrRegister 处理登录或注册逻辑，返回用户信息、token和是否为新用户
-func (s *AuthService) LoginOrRegister(req *model.LoginRequest) (*model.User, string, time.Time, bool, error) {
+func (s *AuthService) LoginOrRegister(ctx context.Context, req *model.LoginRequest) (*model.User, string, time.Time, bool, error) {
+	// 统计登录结果，未单独设置结果的错误记为 error
+	result := metrics.LoginError
+	defer func() { metrics.Logins.WithLabelValues(result).Inc() }()
+
 	// 验证验证码
 	if req.CaptchaID != "" && req.CaptchaCode != "" {
 		if !util.VerifyCaptcha(req.CaptchaID, req.CaptchaCode) {
-			return nil, "", time.Time{}, false, errors.New("验证码错误")
+			result = metrics.LoginInvalidCaptcha
+			return nil, "", time.Time{}, false, apperr.ErrLoginFailed.Wrap(apperr.ErrCaptcha)
 		}
 	}
 
 	// 查找用户
-	var user model.User
-	err := s.db.Collection("users").FindOne(context.Background(), bson.M{"username": req.Username}).Decode(&user)
+	user, err := s.users.FindByUsername(ctx, req.Username)
 	
 	// 如果用户不存在，则创建新用户（注册）
 	isNewUser := false
-	if err == mongo.ErrNoDocuments {
+	if err == repository.ErrNotFound {
 		// 加密密码
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
@@ -71,12 +75,9 @@ func (s *AuthService) LoginOrRegister(req *model.LoginRequest) (*model.User, str
 			UpdatedAt: time.Now(),
 		}
 		
-		result, err := s.db.Collection("users").InsertOne(context.Background(), newUser)
-		if err != nil {
+		if err := s.users.Create(ctx, newUser); err != nil {
 			return nil, "", time.Time{}, false, err
 		}
-		
-		newUser.ID = result.InsertedID.(primitive.ObjectID)
 
 		// 生成JWT token
 		token, err := middleware.GenerateToken(newUser)
@@ -85,19 +86,26 @@ func (s *AuthService) LoginOrRegister(req *model.LoginRequest) (*model.User, str
 		}
 		
 		// 计算过期时间
-		expireHours := viper.GetInt("jwt.expire")
-		expiresAt := time.Now().Add(time.Hour * time.Duration(expireHours))
+		expiresAt := time.Now().Add(config.GetConfig().TokenTTL())
 		
 		isNewUser = true
+		result = metrics.LoginRegistered
 		return newUser, token, expiresAt, isNewUser, nil
 	} else if err != nil {
-		return nil, "", time.Time{}, false, errors.New("用户查询失败")
+		return nil, "", time.Time{}, false, fmt.Errorf("用户查询失败: %w", err)
 	}
 	
 	// 用户存在，验证密码（登录）
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, "", time.Time{}, false, errors.New("用户名或密码错误")
+		result = metrics.LoginInvalidPassword
+		return nil, "", time.Time{}, false, apperr.ErrLoginFailed
+	}
+	
+	// 被封禁的用户不能登录，和密码错误返回相同的错误，不透露账号状态
+	if user.Banned {
+		result = metrics.LoginBanned
+		return nil, "", time.Time{}, false, apperr.ErrLoginFailed.Wrap(apperr.ErrAccountBanned)
 	}
 	
 	// 验证用户ID格式
@@ -107,64 +115,56 @@ func (s *AuthService) LoginOrRegister(req *model.LoginRequest) (*model.User, str
 	}
 	
 	// 生成JWT token
-	token, err := middleware.GenerateToken(&user)
+	token, err := middleware.GenerateToken(user)
 	if err != nil {
 		return nil, "", time.Time{}, false, err
 	}
 	
 	// 计算过期时间
-	expireHours := viper.GetInt("jwt.expire")
-	expiresAt := time.Now().Add(time.Hour * time.Duration(expireHours))
+	expiresAt := time.Now().Add(config.GetConfig().TokenTTL())
 	
-	return &user, token, expiresAt, false, nil
+	result = metrics.LoginSuccess
+	return user, token, expiresAt, false, nil
 }
 
 // ChangePassword 修改用户密码
-func (s *AuthService) ChangePassword(username, oldPassword, newPassword string) error {
+func (s *AuthService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
 	// 查找用户
-	var user model.User
-	err := s.db.Collection("users").FindOne(context.Background(), bson.M{"username": username}).Decode(&user)
-	if err == mongo.ErrNoDocuments {
-		return errors.New("用户不存在")
+	user, err := s.users.FindByUsername(ctx, username)
+	if err == repository.ErrNotFound {
+		return apperr.ErrUserNotFound
 	} else if err != nil {
-		return errors.New("用户查询失败")
+		return fmt.Errorf("用户查询失败: %w", err)
 	}
 	
 	// 验证旧密码
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
 	if err != nil {
-		return errors.New("原密码错误")
+		return apperr.ErrWrongPassword
 	}
 	
 	// 验证新密码长度
 	if len(newPassword) < 6 {
-		return errors.New("新密码长度不能少于6个字符")
+		return apperr.ErrPasswordTooShort
 	}
 	
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return errors.New("密码加密失败")
+		return fmt.Errorf("密码加密失败: %w", err)
 	}
 	
 	// 更新密码
-	updateResult, err := s.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": user.ID},
-		bson.M{
-			"$set": bson.M{
-				"password":   string(hashedPassword),
-				"updated_at": time.Now(),
-			},
-		},
-	)
-	
-	if err != nil {
-		return errors.New("密码更新失败")
+	password := string(hashedPassword)
+	err = s.users.Update(ctx, user.ID, repository.UserUpdate{
+		Password:  &password,
+		UpdatedAt: time.Now(),
+	})
+	if err == repository.ErrNotFound {
+		return apperr.ErrUserNotFound
 	}
-	
-	if updateResult.ModifiedCount == 0 {
-		return errors.New("密码未更新")
+	if err != nil {
+		return fmt.Errorf("密码更新失败: %w", err)
 	}
 	
 	return nil
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 缓存键。缓存过期前的修改通过删除对应的键生效，修改数据时需要同步删除
+
+// postCacheKey 笔记详情
+func postCacheKey(postID primitive.ObjectID) string {
+	return "post:" + postID.Hex()
+}
+
+// profileCacheKey 用户资料（不含当前用户的关注、拉黑、屏蔽状态）
+func profileCacheKey(userID primitive.ObjectID) string {
+	return "profile:" + userID.Hex()
+}
+
+// dimensionsCacheKey 文件尺寸
+func dimensionsCacheKey(fileURL string) string {
+	return "dimensions:" + fileURL
+}
+
+// feedGenerationKey 用户关注流缓存的版本，删除后该用户所有分页缓存失效
+func feedGenerationKey(userID primitive.ObjectID) string {
+	return "feed:generation:" + userID.Hex()
+}
+
+// feedPageCacheKey 关注流分页
+func feedPageCacheKey(userID primitive.ObjectID, generation string, cursor string, limit int) string {
+	return "feed:" + userID.Hex() + ":" + generation + ":" + cursor + ":" + strconv.Itoa(limit)
+}
+
+// cacheTTL 将配置中的秒数转为缓存时间
+func cacheTTL(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
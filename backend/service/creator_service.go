@@ -0,0 +1,159 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/model"
+	"blue-note/repository"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreatorService 创作者数据服务
+type CreatorService struct {
+	db *mongo.Database
+}
+
+// NewCreatorService 创建创作者数据服务实例
+func NewCreatorService(db *mongo.Database) *CreatorService {
+	return &CreatorService{db: db}
+}
+
+// recomputeCreatorStats 根据用户审核通过的笔记重新计算笔记数、获赞数和被收藏数
+func recomputeCreatorStats(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) error {
+	return updateCreatorStats(ctx, repository.NewMongoPostRepo(db), repository.NewMongoUserRepo(db), userID)
+}
+
+// updateCreatorStats 同 recomputeCreatorStats，通过仓储读写
+func updateCreatorStats(ctx context.Context, posts repository.PostRepo, users repository.UserRepo, userID primitive.ObjectID) error {
+	stats, err := posts.CreatorStats(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("统计创作者数据失败: %w", err)
+	}
+	return users.SetCreatorStats(ctx, userID, stats)
+}
+
+// GetDashboard 获取创作者数据看板：累计数据和最近每天的数据
+func (s *CreatorService) GetDashboard(ctx context.Context, userID string, query *model.AnalyticsQuery) (*model.CreatorDashboardResponse, error) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	var user model.User
+	err = s.db.Collection("users").FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, apperr.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	days := query.Days
+	if days < 1 {
+		days = 30
+	}
+	start, dates := analyticsDates(days)
+
+	// 用户的所有笔记，用于统计收到的互动
+	cursor, err := s.db.Collection("posts").Find(
+		ctx,
+		bson.M{"user_id": userObjectID},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询笔记失败: %w", err)
+	}
+	var posts []model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	postIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	byPost := bson.M{"post_id": bson.M{"$in": postIDs}}
+
+	views, err := sumByDay(ctx, s.db, "post_daily_stats", bson.M{"author_id": userObjectID, "date": bson.M{"$gte": dates[0]}}, "views")
+	if err != nil {
+		return nil, err
+	}
+	likes, err := countByDay(ctx, s.db, "post_likes", byPost, start)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := countByDay(ctx, s.db, "comments", byPost, start)
+	if err != nil {
+		return nil, err
+	}
+	collects, err := countByDay(ctx, s.db, "post_collections", byPost, start)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := countByDay(ctx, s.db, "user_follows", bson.M{"following_id": userObjectID}, start)
+	if err != nil {
+		return nil, err
+	}
+	published, err := countByDay(ctx, s.db, "posts", bson.M{"user_id": userObjectID, "status": "approved"}, start)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := make([]model.CreatorDailyMetrics, 0, len(dates))
+	for _, date := range dates {
+		daily = append(daily, model.CreatorDailyMetrics{
+			DailyMetrics: model.DailyMetrics{
+				Date:         date,
+				Views:        views[date],
+				Likes:        likes[date],
+				Comments:     comments[date],
+				Collects:     collects[date],
+				FollowerGain: followers[date],
+			},
+			Posts: published[date],
+		})
+	}
+
+	totalViews, err := s.sumPostViews(ctx, userObjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatorDashboardResponse{
+		Totals: model.CreatorTotals{
+			PostCount:    user.PostCount,
+			LikeCount:    user.LikeCount,
+			CollectCount: user.CollectCount,
+			FansCount:    user.FansCount,
+			FollowCount:  user.FollowCount,
+			Views:        totalViews,
+		},
+		Daily: daily,
+	}, nil
+}
+
+// sumPostViews 统计用户所有笔记的浏览数
+func (s *CreatorService) sumPostViews(ctx context.Context, userID primitive.ObjectID) (int, error) {
+	cursor, err := s.db.Collection("posts").Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"user_id": userID}},
+		{"$group": bson.M{"_id": nil, "views": bson.M{"$sum": "$views"}}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("统计浏览数失败: %w", err)
+	}
+
+	var rows []struct {
+		Views int `bson:"views"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Views, nil
+}
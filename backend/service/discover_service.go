@@ -0,0 +1,506 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/config"
+	"blue-note/model"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// affinityHistoryLimit 计算标签偏好时读取的最近点赞/收藏记录数
+const affinityHistoryLimit = 200
+
+// DiscoverService 发现页推荐服务
+type DiscoverService struct {
+	db          *mongo.Database
+	fileService *FileService
+
+	// 未登录用户的排序结果相同，共享快照，按每页数量区分，避免每次请求都保存新快照
+	mu        sync.Mutex
+	anonymous map[int]*model.DiscoverSession
+}
+
+// NewDiscoverService 创建发现页推荐服务实例
+func NewDiscoverService(db *mongo.Database, fileService *FileService) *DiscoverService {
+	return &DiscoverService{
+		db:          db,
+		fileService: fileService,
+		anonymous:   make(map[int]*model.DiscoverSession),
+	}
+}
+
+// scoredPost 带推荐分数的笔记
+type scoredPost struct {
+	post  *model.Post
+	score float64
+}
+
+// GetDiscoverFeed 获取发现页（基于游标的分页）
+// 首次请求时对候选笔记打分排序并保存快照，后续翻页从快照中读取，保证结果稳定
+func (s *DiscoverService) GetDiscoverFeed(ctx context.Context, viewerID string, query *model.DiscoverQuery) (*model.CursorBasedPostResponse, error) {
+	if query.Limit < 1 {
+		query.Limit = 10
+	}
+
+	var session *model.DiscoverSession
+	offset := 0
+
+	if query.Cursor == "" {
+		var err error
+		if viewerObjectID(viewerID).IsZero() {
+			session, err = s.anonymousSession(ctx, query.Limit)
+		} else {
+			session, err = s.createSession(ctx, viewerID, query.Limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sessionID, cursorOffset, err := parseDiscoverCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		session = &model.DiscoverSession{}
+		err = s.db.Collection("discover_sessions").FindOne(ctx, bson.M{"_id": sessionID}).Decode(session)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, apperr.ErrCursorExpired
+			}
+			return nil, fmt.Errorf("查询推荐快照失败: %w", err)
+		}
+		if time.Now().After(session.ExpireAt) || session.UserID.Hex() != viewerObjectID(viewerID).Hex() {
+			return nil, apperr.ErrCursorExpired
+		}
+		offset = cursorOffset
+	}
+
+	end := pageEnd(session, offset)
+	if offset > end {
+		offset = end
+	}
+	pageIDs := session.PostIDs[offset:end]
+
+	// 快照创建后新增的拉黑/屏蔽关系也要生效，每页重新计算
+	hiddenIDs, err := hiddenUserIDs(ctx, s.db, viewerID, true)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := findVisiblePostsInOrder(ctx, s.db, pageIDs, hiddenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	hasMore := end < len(session.PostIDs)
+	if hasMore {
+		nextCursor = fmt.Sprintf("%s:%d", session.ID.Hex(), end)
+	}
+
+	return &model.CursorBasedPostResponse{
+		Posts:      buildPostItems(ctx, s.fileService, posts),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// pageEnd 返回从 offset 开始的一页在快照中的结束位置
+func pageEnd(session *model.DiscoverSession, offset int) int {
+	end := offset + session.PageSize
+	if len(session.PageOffsets) > 0 {
+		// 第一个大于 offset 的起始位置即为本页结束位置
+		end = len(session.PostIDs)
+		if i := sort.SearchInts(session.PageOffsets, offset+1); i < len(session.PageOffsets) {
+			end = session.PageOffsets[i]
+		}
+	}
+	if end > len(session.PostIDs) {
+		end = len(session.PostIDs)
+	}
+	return end
+}
+
+// parseDiscoverCursor 解析游标，格式为 <快照ID>:<偏移量>
+func parseDiscoverCursor(cursor string) (primitive.ObjectID, int, error) {
+	parts := strings.SplitN(cursor, ":", 2)
+	if len(parts) != 2 {
+		return primitive.NilObjectID, 0, apperr.ErrInvalidCursor
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return primitive.NilObjectID, 0, apperr.ErrInvalidCursor.Wrap(err)
+	}
+
+	offset, err := strconv.Atoi(parts[1])
+	if err != nil || offset < 0 {
+		return primitive.NilObjectID, 0, apperr.ErrInvalidCursor
+	}
+
+	return sessionID, offset, nil
+}
+
+// viewerObjectID 解析查看者ID，未登录或格式错误时返回空ID
+func viewerObjectID(viewerID string) primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		return primitive.NilObjectID
+	}
+	return id
+}
+
+// sessionTTL 返回排序快照的有效期
+func sessionTTL() time.Duration {
+	ttl := config.GetConfig().Discover.SessionTTL
+	if ttl <= 0 {
+		ttl = 30
+	}
+	return time.Duration(ttl) * time.Minute
+}
+
+// anonymousSession 返回未登录用户共享的快照，剩余有效期不足一半时重新创建，
+// 保证拿到快照的用户至少还能翻页半个有效期
+func (s *DiscoverService) anonymousSession(ctx context.Context, pageSize int) (*model.DiscoverSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session := s.anonymous[pageSize]; reusableSession(session, time.Now()) {
+		return session, nil
+	}
+	session, err := s.createSession(ctx, "", pageSize)
+	if err != nil {
+		return nil, err
+	}
+	s.anonymous[pageSize] = session
+	return session, nil
+}
+
+// reusableSession 判断共享快照是否还能继续使用
+func reusableSession(session *model.DiscoverSession, now time.Time) bool {
+	return session != nil && session.ExpireAt.Sub(now) > sessionTTL()/2
+}
+
+// createSession 对候选笔记打分排序，应用多样性和探索规则后保存为快照
+func (s *DiscoverService) createSession(ctx context.Context, viewerID string, pageSize int) (*model.DiscoverSession, error) {
+	cfg := config.GetConfig().Discover
+
+	candidates, err := s.findCandidates(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	affinity, err := s.getTagAffinity(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]scoredPost, 0, len(candidates))
+	var explorePool []scoredPost
+	for _, post := range candidates {
+		sp := scoredPost{post: post, score: s.calculatePostScore(post, affinity)}
+		ranked = append(ranked, sp)
+		if isExploreCandidate(post) {
+			explorePool = append(explorePool, sp)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return bytes.Compare(ranked[i].post.ID[:], ranked[j].post.ID[:]) > 0
+	})
+
+	// 探索池随机打乱，让新笔记有机会获得曝光
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rng.Shuffle(len(explorePool), func(i, j int) {
+		explorePool[i], explorePool[j] = explorePool[j], explorePool[i]
+	})
+
+	now := time.Now()
+	postIDs, pageOffsets := arrangePages(ranked, explorePool, pageSize, cfg.MaxPerAuthor, cfg.ExploreRatio)
+	session := &model.DiscoverSession{
+		ID:          primitive.NewObjectID(),
+		UserID:      viewerObjectID(viewerID),
+		PostIDs:     postIDs,
+		PageSize:    pageSize,
+		PageOffsets: pageOffsets,
+		CreatedAt:   now,
+		ExpireAt:    now.Add(sessionTTL()),
+	}
+
+	if _, err := s.db.Collection("discover_sessions").InsertOne(ctx, session); err != nil {
+		return nil, fmt.Errorf("保存推荐快照失败: %w", err)
+	}
+
+	return session, nil
+}
+
+// findCandidates 查询时间范围内已审核通过的候选笔记，排除与查看者存在拉黑/屏蔽关系的作者
+func (s *DiscoverService) findCandidates(ctx context.Context, viewerID string) ([]*model.Post, error) {
+	cfg := config.GetConfig().Discover
+
+	filter := bson.M{
+		"status": "approved",
+		"hidden": bson.M{"$ne": true},
+	}
+	if cfg.WindowDays > 0 {
+		filter["created_at"] = bson.M{"$gte": time.Now().AddDate(0, 0, -cfg.WindowDays)}
+	}
+
+	hiddenIDs, err := hiddenUserIDs(ctx, s.db, viewerID, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(hiddenIDs) > 0 {
+		filter["user_id"] = bson.M{"$nin": hiddenIDs}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if cfg.CandidateLimit > 0 {
+		opts.SetLimit(int64(cfg.CandidateLimit))
+	}
+
+	cursor, err := s.db.Collection("posts").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("查询候选笔记失败: %w", err)
+	}
+
+	var posts []*model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// getTagAffinity 根据用户最近点赞和收藏的笔记计算标签偏好，取值范围 [0, 1]
+func (s *DiscoverService) getTagAffinity(ctx context.Context, viewerID string) (map[string]float64, error) {
+	userID := viewerObjectID(viewerID)
+	if userID.IsZero() {
+		return nil, nil
+	}
+
+	// 收藏比点赞体现更强的兴趣
+	weights := map[string]float64{
+		"post_likes":       1.0,
+		"post_collections": 2.0,
+	}
+
+	postWeights := make(map[primitive.ObjectID]float64)
+	for collection, weight := range weights {
+		cursor, err := s.db.Collection(collection).Find(
+			ctx,
+			bson.M{"user_id": userID},
+			options.Find().
+				SetSort(bson.D{{Key: "created_at", Value: -1}}).
+				SetLimit(affinityHistoryLimit).
+				SetProjection(bson.M{"post_id": 1}),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var records []struct {
+			PostID primitive.ObjectID `bson:"post_id"`
+		}
+		if err = cursor.All(ctx, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			postWeights[record.PostID] += weight
+		}
+	}
+
+	if len(postWeights) == 0 {
+		return nil, nil
+	}
+
+	postIDs := make([]primitive.ObjectID, 0, len(postWeights))
+	for id := range postWeights {
+		postIDs = append(postIDs, id)
+	}
+
+	cursor, err := s.db.Collection("posts").Find(
+		ctx,
+		bson.M{"_id": bson.M{"$in": postIDs}},
+		options.Find().SetProjection(bson.M{"tags": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	affinity := make(map[string]float64)
+	maxWeight := 0.0
+	for _, post := range posts {
+		for _, tag := range post.Tags {
+			affinity[tag] += postWeights[post.ID]
+			maxWeight = math.Max(maxWeight, affinity[tag])
+		}
+	}
+
+	// 归一化
+	for tag := range affinity {
+		affinity[tag] /= maxWeight
+	}
+
+	return affinity, nil
+}
+
+// calculatePostScore 计算笔记的推荐分数
+func (s *DiscoverService) calculatePostScore(post *model.Post, affinity map[string]float64) float64 {
+	// 时间衰减因子，与评论评分一致使用 24 小时作为基准
+	hours := time.Since(post.CreatedAt).Hours()
+	timeDecay := 1.0 / (1.0 + math.Log1p(hours/24.0))
+
+	// 互动得分，使用对数函数避免热门笔记权重过大；收藏和评论比点赞代表更强的互动
+	engagement := math.Log1p(float64(post.Likes))*0.3 +
+		math.Log1p(float64(post.Comments))*0.4 +
+		math.Log1p(float64(post.Collections))*0.5
+
+	// 兴趣得分，取笔记标签中偏好最高的一个
+	interest := 0.0
+	for _, tag := range post.Tags {
+		interest = math.Max(interest, affinity[tag])
+	}
+	interestWeight := 1.5
+
+	return (1.0+engagement)*timeDecay + interest*interestWeight
+}
+
+// isExploreCandidate 判断笔记是否进入探索池：最近 48 小时发布且互动较少
+func isExploreCandidate(post *model.Post) bool {
+	return time.Since(post.CreatedAt) < 48*time.Hour &&
+		post.Likes+post.Comments+post.Collections < 5
+}
+
+// arrangePages 按页编排推荐结果：每页同一作者最多 maxPerAuthor 篇，
+// 并按 exploreRatio 预留探索位，探索位均匀穿插在页面中。
+// 受作者限制时部分页会不满 pageSize，因此同时返回每页的起始位置
+func arrangePages(ranked []scoredPost, explorePool []scoredPost, pageSize int, maxPerAuthor int, exploreRatio float64) ([]primitive.ObjectID, []int) {
+	used := make(map[primitive.ObjectID]bool, len(ranked))
+	result := make([]primitive.ObjectID, 0, len(ranked))
+	var offsets []int
+
+	exploreSlots := int(float64(pageSize) * exploreRatio)
+	if exploreSlots >= pageSize {
+		exploreSlots = pageSize - 1
+	}
+
+	for len(used) < len(ranked) {
+		authorCount := make(map[primitive.ObjectID]int)
+
+		take := func(list []scoredPost, n int, capped bool) []primitive.ObjectID {
+			var picked []primitive.ObjectID
+			for _, sp := range list {
+				if len(picked) >= n {
+					break
+				}
+				if used[sp.post.ID] {
+					continue
+				}
+				if capped && maxPerAuthor > 0 && authorCount[sp.post.UserID] >= maxPerAuthor {
+					continue
+				}
+				used[sp.post.ID] = true
+				authorCount[sp.post.UserID]++
+				picked = append(picked, sp.post.ID)
+			}
+			return picked
+		}
+
+		main := take(ranked, pageSize-exploreSlots, true)
+		explore := take(explorePool, exploreSlots, true)
+		if rest := pageSize - len(main) - len(explore); rest > 0 {
+			main = append(main, take(ranked, rest, true)...)
+		}
+		// 剩余笔记都来自少数作者时放宽限制，避免无法继续编排
+		if len(main)+len(explore) == 0 {
+			main = take(ranked, pageSize, false)
+		}
+
+		offsets = append(offsets, len(result))
+		result = append(result, interleave(main, explore)...)
+	}
+
+	return result, offsets
+}
+
+// interleave 将探索位均匀穿插到常规结果中
+func interleave(main []primitive.ObjectID, explore []primitive.ObjectID) []primitive.ObjectID {
+	if len(explore) == 0 {
+		return main
+	}
+
+	total := len(main) + len(explore)
+	step := total / len(explore)
+	merged := make([]primitive.ObjectID, 0, total)
+	mi, ei := 0, 0
+	for i := 0; i < total; i++ {
+		if ei < len(explore) && (i%step == step-1 || mi >= len(main)) {
+			merged = append(merged, explore[ei])
+			ei++
+		} else {
+			merged = append(merged, main[mi])
+			mi++
+		}
+	}
+	return merged
+}
+
+// findVisiblePostsInOrder 按给定ID顺序查询笔记，已删除、不再可见或作者被排除的笔记会被跳过
+func findVisiblePostsInOrder(ctx context.Context, db *mongo.Database, postIDs []primitive.ObjectID, excludeUserIDs []primitive.ObjectID) ([]*model.Post, error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"_id":    bson.M{"$in": postIDs},
+		"status": "approved",
+		"hidden": bson.M{"$ne": true},
+	}
+	if len(excludeUserIDs) > 0 {
+		filter["user_id"] = bson.M{"$nin": excludeUserIDs}
+	}
+
+	cursor, err := db.Collection("posts").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []*model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*model.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	ordered := make([]*model.Post, 0, len(posts))
+	for _, id := range postIDs {
+		if post, ok := byID[id]; ok {
+			ordered = append(ordered, post)
+		}
+	}
+	return ordered, nil
+}
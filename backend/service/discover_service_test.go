@@ -0,0 +1,93 @@
+package service
+
+import (
+	"blue-note/model"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArrangePagesAuthorCap(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	// alice 的笔记分数都更高，限制每页每个作者 2 篇后第一页只能放 alice 2 篇和 bob 1 篇
+	var ranked []scoredPost
+	authors := map[primitive.ObjectID]primitive.ObjectID{}
+	for _, author := range []primitive.ObjectID{alice, alice, alice, alice, bob} {
+		post := &model.Post{ID: primitive.NewObjectID(), UserID: author}
+		authors[post.ID] = author
+		ranked = append(ranked, scoredPost{post: post})
+	}
+
+	postIDs, offsets := arrangePages(ranked, nil, 4, 2, 0)
+	if len(postIDs) != len(ranked) {
+		t.Fatalf("编排结果 %d 篇, 期望 %d 篇", len(postIDs), len(ranked))
+	}
+
+	session := &model.DiscoverSession{PostIDs: postIDs, PageSize: 4, PageOffsets: offsets}
+	pages := 0
+	for offset := 0; offset < len(postIDs); pages++ {
+		end := pageEnd(session, offset)
+		if end <= offset {
+			t.Fatalf("第 %d 页结束位置 %d 不大于起始位置 %d", pages+1, end, offset)
+		}
+
+		count := map[primitive.ObjectID]int{}
+		for _, id := range postIDs[offset:end] {
+			count[authors[id]]++
+		}
+		for author, n := range count {
+			if n > 2 {
+				t.Errorf("第 %d 页作者 %s 有 %d 篇, 超过限制", pages+1, author.Hex(), n)
+			}
+		}
+		offset = end
+	}
+	if pages != 2 {
+		t.Errorf("页数 = %d, 期望 2", pages)
+	}
+}
+
+func TestPageEndWithoutOffsets(t *testing.T) {
+	session := &model.DiscoverSession{PostIDs: make([]primitive.ObjectID, 5), PageSize: 2}
+
+	tests := []struct {
+		offset int
+		want   int
+	}{
+		{offset: 0, want: 2},
+		{offset: 4, want: 5},
+		{offset: 6, want: 5},
+	}
+
+	for _, tt := range tests {
+		if got := pageEnd(session, tt.offset); got != tt.want {
+			t.Errorf("pageEnd(%d) = %d, 期望 %d", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestReusableSession(t *testing.T) {
+	now := time.Now()
+	ttl := sessionTTL()
+
+	tests := []struct {
+		name    string
+		session *model.DiscoverSession
+		want    bool
+	}{
+		{name: "没有快照", session: nil, want: false},
+		{name: "刚创建", session: &model.DiscoverSession{ExpireAt: now.Add(ttl)}, want: true},
+		{name: "剩余不足一半", session: &model.DiscoverSession{ExpireAt: now.Add(ttl/2 - time.Second)}, want: false},
+		{name: "已过期", session: &model.DiscoverSession{ExpireAt: now.Add(-time.Second)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reusableSession(tt.session, now); got != tt.want {
+				t.Errorf("reusableSession() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
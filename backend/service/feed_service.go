@@ -0,0 +1,563 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/model"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fanoutBatchSize 写扩散时每批写入收件箱的条目数
+const fanoutBatchSize = 500
+
+// FeedService 关注流服务
+// 粉丝数较少的作者在笔记审核通过时写扩散到粉丝收件箱（feed_inbox 集合，按 user_id 分区），
+// 粉丝数较多的作者在读取时从 posts 集合拉取，两路结果按笔记ID合并分页。
+// 笔记记录审核通过时使用的分发方式，作者改为写扩散后，之前未写入收件箱的笔记仍从 posts 集合拉取
+type FeedService struct {
+	db          *mongo.Database
+	fileService *FileService
+	cache       *cache.Group
+}
+
+// NewFeedService 创建关注流服务实例，cache 为 nil 时不缓存关注流分页
+func NewFeedService(db *mongo.Database, fileService *FileService, cache *cache.Group) *FeedService {
+	return &FeedService{
+		db:          db,
+		fileService: fileService,
+		cache:       cache,
+	}
+}
+
+// InvalidateFeed 使用户的关注流缓存失效
+func (s *FeedService) InvalidateFeed(ctx context.Context, userIDs ...primitive.ObjectID) {
+	if s == nil {
+		return
+	}
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, feedGenerationKey(userID))
+	}
+	s.cache.Delete(ctx, keys...)
+}
+
+// UsesFanout 判断作者当前是否使用写扩散
+func UsesFanout(fansCount int) bool {
+	cfg := config.GetConfig().Feed
+	switch cfg.Strategy {
+	case model.FeedStrategyWrite:
+		return true
+	case model.FeedStrategyRead:
+		return false
+	default:
+		return fansCount <= cfg.FanoutMaxFans
+	}
+}
+
+// getFansCount 获取作者的粉丝数
+func (s *FeedService) getFansCount(ctx context.Context, authorID primitive.ObjectID) (int, error) {
+	var author struct {
+		FansCount int `bson:"fans_count"`
+	}
+	err := s.db.Collection("users").FindOne(
+		ctx,
+		bson.M{"_id": authorID},
+		options.FindOne().SetProjection(bson.M{"fans_count": 1}),
+	).Decode(&author)
+	if err != nil {
+		return 0, err
+	}
+	return author.FansCount, nil
+}
+
+// upsertInbox 批量写入收件箱，同一用户的同一笔记只保留一条
+func (s *FeedService) upsertInbox(ctx context.Context, items []model.FeedInboxItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(items))
+	for _, item := range items {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": item.UserID, "post_id": item.PostID}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{
+				"author_id":  item.AuthorID,
+				"created_at": item.CreatedAt,
+			}}).
+			SetUpsert(true))
+	}
+
+	_, err := s.db.Collection("feed_inbox").BulkWrite(
+		ctx,
+		models,
+		options.BulkWrite().SetOrdered(false),
+	)
+	return err
+}
+
+// setDistribution 记录笔记的分发方式
+func (s *FeedService) setDistribution(ctx context.Context, postID primitive.ObjectID, distribution string) error {
+	_, err := s.db.Collection("posts").UpdateOne(
+		ctx,
+		bson.M{"_id": postID},
+		bson.M{"$set": bson.M{"feed_distribution": distribution}},
+	)
+	if err != nil {
+		return fmt.Errorf("记录笔记分发方式失败: %w", err)
+	}
+	return nil
+}
+
+// FanOutPost 将审核通过的笔记推送到作者粉丝的收件箱，并记录笔记的分发方式。
+// 作者使用读扩散或推送失败时记为读扩散，读取时从 posts 集合拉取
+func (s *FeedService) FanOutPost(ctx context.Context, post *model.Post) error {
+	fansCount, err := s.getFansCount(ctx, post.UserID)
+	if err != nil {
+		return fmt.Errorf("获取作者粉丝数失败: %w", err)
+	}
+	if !UsesFanout(fansCount) {
+		return s.setDistribution(ctx, post.ID, model.FeedDistributionPull)
+	}
+
+	if err := s.fanOut(ctx, post); err != nil {
+		if markErr := s.setDistribution(ctx, post.ID, model.FeedDistributionPull); markErr != nil {
+			return errors.Join(err, markErr)
+		}
+		return err
+	}
+	return s.setDistribution(ctx, post.ID, model.FeedDistributionPush)
+}
+
+// fanOut 将笔记写入作者所有粉丝的收件箱
+func (s *FeedService) fanOut(ctx context.Context, post *model.Post) error {
+
+	cursor, err := s.db.Collection("user_follows").Find(
+		ctx,
+		bson.M{"following_id": post.UserID},
+		options.Find().SetProjection(bson.M{"user_id": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("查询粉丝列表失败: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	now := time.Now()
+	batch := make([]model.FeedInboxItem, 0, fanoutBatchSize)
+	for cursor.Next(ctx) {
+		var follow model.UserFollow
+		if err := cursor.Decode(&follow); err != nil {
+			return err
+		}
+
+		batch = append(batch, model.FeedInboxItem{
+			UserID:    follow.UserID,
+			PostID:    post.ID,
+			AuthorID:  post.UserID,
+			CreatedAt: now,
+		})
+
+		if len(batch) >= fanoutBatchSize {
+			if err := s.upsertInbox(ctx, batch); err != nil {
+				return fmt.Errorf("写入收件箱失败: %w", err)
+			}
+			s.invalidateInbox(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if err := s.upsertInbox(ctx, batch); err != nil {
+		return fmt.Errorf("写入收件箱失败: %w", err)
+	}
+	s.invalidateInbox(ctx, batch)
+	return nil
+}
+
+// invalidateInbox 使收件箱有新条目的用户的关注流缓存失效
+func (s *FeedService) invalidateInbox(ctx context.Context, items []model.FeedInboxItem) {
+	userIDs := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		userIDs = append(userIDs, item.UserID)
+	}
+	s.InvalidateFeed(ctx, userIDs...)
+}
+
+// RemovePost 从所有收件箱中删除笔记
+func (s *FeedService) RemovePost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := s.db.Collection("feed_inbox").DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
+
+// Backfill 关注新作者时，将作者最近写扩散的笔记回填到关注者的收件箱。
+// 不论作者当前的分发策略都回填，作者之后改为写扩散时这些笔记仍在收件箱中；记为读扩散的笔记读取时拉取，不需要回填
+func (s *FeedService) Backfill(ctx context.Context, userID primitive.ObjectID, authorID primitive.ObjectID) error {
+	limit := config.GetConfig().Feed.BackfillLimit
+	if limit <= 0 {
+		return nil
+	}
+
+	cursor, err := s.db.Collection("posts").Find(
+		ctx,
+		bson.M{
+			"user_id":           authorID,
+			"status":            "approved",
+			"hidden":            bson.M{"$ne": true},
+			"feed_distribution": bson.M{"$ne": model.FeedDistributionPull},
+		},
+		options.Find().
+			SetSort(bson.D{{Key: "_id", Value: -1}}).
+			SetLimit(int64(limit)).
+			SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("查询作者笔记失败: %w", err)
+	}
+
+	var posts []model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	items := make([]model.FeedInboxItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, model.FeedInboxItem{
+			UserID:    userID,
+			PostID:    post.ID,
+			AuthorID:  authorID,
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.upsertInbox(ctx, items); err != nil {
+		return err
+	}
+	s.InvalidateFeed(ctx, userID)
+	return nil
+}
+
+// RemoveAuthor 取消关注时，从关注者收件箱中移除该作者的笔记
+func (s *FeedService) RemoveAuthor(ctx context.Context, userID primitive.ObjectID, authorID primitive.ObjectID) error {
+	_, err := s.db.Collection("feed_inbox").DeleteMany(
+		ctx,
+		bson.M{
+			"user_id":   userID,
+			"author_id": authorID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.InvalidateFeed(ctx, userID)
+	return nil
+}
+
+// GetFollowingFeed 获取关注流（基于游标的分页），包含关注的作者和关注的标签下的笔记，按笔记ID降序排列
+func (s *FeedService) GetFollowingFeed(ctx context.Context, userID string, query *model.FeedQuery) (*model.CursorBasedPostResponse, error) {
+	if query.Limit < 1 {
+		query.Limit = 10
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	var cursorID primitive.ObjectID
+	if query.Cursor != "" {
+		cursorID, err = primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			return nil, apperr.ErrInvalidCursor.Wrap(err)
+		}
+	}
+
+	// 分页缓存键中带有用户关注流的版本，关注关系变化或收件箱写入时删除版本即可让所有分页失效
+	ttl := cacheTTL(config.GetConfig().Cache.FeedTTL)
+	generation := s.cache.Generation(ctx, feedGenerationKey(userObjectID), 10*ttl)
+	key := feedPageCacheKey(userObjectID, generation, query.Cursor, query.Limit)
+	return cache.Fetch(ctx, s.cache, key, ttl, func(ctx context.Context) (*model.CursorBasedPostResponse, error) {
+		return s.loadFollowingFeed(ctx, userID, userObjectID, cursorID, query.Limit)
+	})
+}
+
+// loadFollowingFeed 从数据库读取关注流的一页
+func (s *FeedService) loadFollowingFeed(ctx context.Context, userID string, userObjectID primitive.ObjectID, cursorID primitive.ObjectID, limit int) (*model.CursorBasedPostResponse, error) {
+	pushAuthors, pullAuthors, err := s.partitionFollowing(ctx, userObjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	postFilter := func(filter bson.M) bson.M {
+		filter["status"] = "approved"
+		filter["hidden"] = bson.M{"$ne": true}
+		if !cursorID.IsZero() {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+		return filter
+	}
+
+	fetchLimit := int64(limit + 1) // 多查询一条数据，用于判断是否还有更多
+	var candidates []*model.Post
+	hasMore := false
+	// 收件箱还有更多条目时，本页只能包含不早于已读取的最早条目的笔记，
+	// 否则被过滤的收件箱条目会让更早的读扩散笔记填满本页，中间的收件箱条目就会被跳过
+	var floorID primitive.ObjectID
+
+	// 写扩散部分：从收件箱读取
+	if len(pushAuthors) > 0 {
+		inboxFilter := bson.M{
+			"user_id":   userObjectID,
+			"author_id": bson.M{"$in": pushAuthors},
+		}
+		if !cursorID.IsZero() {
+			inboxFilter["post_id"] = bson.M{"$lt": cursorID}
+		}
+
+		cursor, err := s.db.Collection("feed_inbox").Find(
+			ctx,
+			inboxFilter,
+			options.Find().
+				SetSort(bson.D{{Key: "post_id", Value: -1}}).
+				SetLimit(fetchLimit),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询收件箱失败: %w", err)
+		}
+
+		var items []model.FeedInboxItem
+		if err = cursor.All(ctx, &items); err != nil {
+			return nil, err
+		}
+
+		if len(items) > limit {
+			hasMore = true
+			floorID = items[len(items)-1].PostID
+		}
+
+		if len(items) > 0 {
+			postIDs := make([]primitive.ObjectID, 0, len(items))
+			for _, item := range items {
+				postIDs = append(postIDs, item.PostID)
+			}
+
+			posts, err := s.findPosts(ctx, postFilter(bson.M{"_id": bson.M{"$in": postIDs}}), 0)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, posts...)
+		}
+	}
+
+	// 读扩散部分：直接从作者的笔记中拉取，包括写扩散作者之前未写入收件箱的笔记
+	if pullFilter := feedPullFilter(pushAuthors, pullAuthors); pullFilter != nil {
+		posts, err := s.findPosts(ctx, postFilter(pullFilter), fetchLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(posts) > limit {
+			hasMore = true
+		}
+		candidates = append(candidates, posts...)
+	}
+
+	// 关注的标签：直接拉取带有这些标签的笔记（不包括自己的笔记）
+	tagNames, err := s.followedTagNames(ctx, userObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tagNames) > 0 {
+		excludeIDs, err := hiddenUserIDs(ctx, s.db, userID, true)
+		if err != nil {
+			return nil, err
+		}
+		excludeIDs = append(excludeIDs, userObjectID)
+
+		posts, err := s.findPosts(ctx, postFilter(bson.M{
+			"tags":    bson.M{"$in": tagNames},
+			"user_id": bson.M{"$nin": excludeIDs},
+		}), fetchLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(posts) > limit {
+			hasMore = true
+		}
+		candidates = append(candidates, posts...)
+	}
+
+	posts, nextCursor, hasMore := mergeFeedPage(candidates, limit, floorID, hasMore)
+
+	return &model.CursorBasedPostResponse{
+		Posts:      buildPostItems(ctx, s.fileService, posts),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// mergeFeedPage 合并多路结果，按ID降序去重后截取一页。floorID 不为空时只保留ID不小于它的笔记，
+// 不足一页时从 floorID 继续，保证收件箱中 floorID 之前的条目不会被跳过
+func mergeFeedPage(candidates []*model.Post, limit int, floorID primitive.ObjectID, hasMore bool) ([]*model.Post, string, bool) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return bytes.Compare(candidates[i].ID[:], candidates[j].ID[:]) > 0
+	})
+	posts := make([]*model.Post, 0, len(candidates))
+	for i, post := range candidates {
+		if i > 0 && post.ID == candidates[i-1].ID {
+			continue
+		}
+		if !floorID.IsZero() && bytes.Compare(post.ID[:], floorID[:]) < 0 {
+			break
+		}
+		posts = append(posts, post)
+	}
+	if len(posts) > limit {
+		hasMore = true
+		posts = posts[:limit]
+	}
+
+	nextCursor := ""
+	if hasMore {
+		if len(posts) < limit && !floorID.IsZero() {
+			// 本页被收件箱截断，从收件箱已读取的最早条目继续
+			nextCursor = floorID.Hex()
+		} else if len(posts) > 0 {
+			nextCursor = posts[len(posts)-1].ID.Hex()
+		}
+	}
+	return posts, nextCursor, hasMore
+}
+
+// partitionFollowing 将用户关注的作者按分发策略分为写扩散和读扩散两组，并排除拉黑和屏蔽的作者
+func (s *FeedService) partitionFollowing(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, []primitive.ObjectID, error) {
+	cursor, err := s.db.Collection("user_follows").Find(
+		ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetProjection(bson.M{"following_id": 1}),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询关注列表失败: %w", err)
+	}
+
+	var follows []model.UserFollow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return nil, nil, err
+	}
+	if len(follows) == 0 {
+		return nil, nil, nil
+	}
+
+	hiddenIDs, err := hiddenUserIDs(ctx, s.db, userID.Hex(), true)
+	if err != nil {
+		return nil, nil, err
+	}
+	hidden := make(map[primitive.ObjectID]bool, len(hiddenIDs))
+	for _, id := range hiddenIDs {
+		hidden[id] = true
+	}
+
+	followingIDs := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		if !hidden[follow.FollowingID] {
+			followingIDs = append(followingIDs, follow.FollowingID)
+		}
+	}
+
+	userCursor, err := s.db.Collection("users").Find(
+		ctx,
+		bson.M{"_id": bson.M{"$in": followingIDs}},
+		options.Find().SetProjection(bson.M{"fans_count": 1}),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询关注用户失败: %w", err)
+	}
+
+	var authors []model.User
+	if err = userCursor.All(ctx, &authors); err != nil {
+		return nil, nil, err
+	}
+
+	pushAuthors, pullAuthors := partitionAuthors(authors)
+	return pushAuthors, pullAuthors, nil
+}
+
+// partitionAuthors 按作者当前的粉丝数分为写扩散和读扩散两组
+func partitionAuthors(authors []model.User) ([]primitive.ObjectID, []primitive.ObjectID) {
+	var pushAuthors, pullAuthors []primitive.ObjectID
+	for _, author := range authors {
+		if UsesFanout(author.FansCount) {
+			pushAuthors = append(pushAuthors, author.ID)
+		} else {
+			pullAuthors = append(pullAuthors, author.ID)
+		}
+	}
+	return pushAuthors, pullAuthors
+}
+
+// feedPullFilter 返回需要从 posts 集合拉取的笔记条件：读扩散作者的所有笔记，
+// 以及写扩散作者审核通过时记为读扩散的笔记（作者当时粉丝数超过阈值或推送失败）。没有需要拉取的作者时返回 nil
+func feedPullFilter(pushAuthors, pullAuthors []primitive.ObjectID) bson.M {
+	var clauses []bson.M
+	if len(pullAuthors) > 0 {
+		clauses = append(clauses, bson.M{"user_id": bson.M{"$in": pullAuthors}})
+	}
+	if len(pushAuthors) > 0 {
+		clauses = append(clauses, bson.M{
+			"user_id":           bson.M{"$in": pushAuthors},
+			"feed_distribution": model.FeedDistributionPull,
+		})
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil
+	case 1:
+		return clauses[0]
+	}
+	return bson.M{"$or": clauses}
+}
+
+// followedTagNames 获取用户关注的标签名称
+func (s *FeedService) followedTagNames(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	tags, err := followedTags(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names, nil
+}
+
+// findPosts 按条件查询笔记，按ID降序排列，limit 为 0 表示不限制
+func (s *FeedService) findPosts(ctx context.Context, filter bson.M, limit int64) ([]*model.Post, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := s.db.Collection("posts").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []*model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
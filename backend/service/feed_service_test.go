@@ -0,0 +1,172 @@
+package service
+
+import (
+	"blue-note/config"
+	"blue-note/model"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMergeFeedPage(t *testing.T) {
+	ids := make(map[int]primitive.ObjectID)
+	id := func(n int) primitive.ObjectID {
+		if _, ok := ids[n]; !ok {
+			ids[n] = primitive.NewObjectIDFromTimestamp(time.Unix(int64(n), 0))
+		}
+		return ids[n]
+	}
+	posts := func(ns ...int) []*model.Post {
+		result := make([]*model.Post, 0, len(ns))
+		for _, n := range ns {
+			result = append(result, &model.Post{ID: id(n)})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		candidates []*model.Post
+		limit      int
+		floor      int
+		hasMore    bool
+		wantPosts  []int
+		wantCursor int
+	}{
+		{
+			// 收件箱读取了 10、9、8 且还有更多，9 和 8 已失效；读扩散作者的 5、4、3 不能跳过收件箱中的 7、6
+			name:       "收件箱条目被过滤",
+			candidates: append(posts(10), posts(5, 4, 3)...),
+			limit:      2, floor: 8, hasMore: true,
+			wantPosts: []int{10}, wantCursor: 8,
+		},
+		{
+			name:       "收件箱条目全部被过滤",
+			candidates: posts(5, 4, 3),
+			limit:      2, floor: 8, hasMore: true,
+			wantPosts: []int{}, wantCursor: 8,
+		},
+		{
+			name:       "收件箱范围内已满一页",
+			candidates: append(posts(10, 8), posts(9, 5, 4)...),
+			limit:      2, floor: 8, hasMore: true,
+			wantPosts: []int{10, 9}, wantCursor: 9,
+		},
+		{
+			name:       "只有读扩散",
+			candidates: append(posts(6, 3), posts(5, 3, 1)...),
+			limit:      3, hasMore: true,
+			wantPosts: []int{6, 5, 3}, wantCursor: 3,
+		},
+		{
+			name:       "没有更多",
+			candidates: posts(6, 5),
+			limit:      3,
+			wantPosts:  []int{6, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var floor primitive.ObjectID
+			if tt.floor > 0 {
+				floor = id(tt.floor)
+			}
+			got, cursor, _ := mergeFeedPage(tt.candidates, tt.limit, floor, tt.hasMore)
+
+			if len(got) != len(tt.wantPosts) {
+				t.Fatalf("笔记数 = %d, 期望 %d", len(got), len(tt.wantPosts))
+			}
+			for i, n := range tt.wantPosts {
+				if got[i].ID != id(n) {
+					t.Errorf("第 %d 条 = %s, 期望 %d", i, got[i].ID.Timestamp().Format(time.RFC3339), n)
+				}
+			}
+			wantCursor := ""
+			if tt.wantCursor > 0 {
+				wantCursor = id(tt.wantCursor).Hex()
+			}
+			if cursor != wantCursor {
+				t.Errorf("nextCursor = %s, 期望 %s", cursor, wantCursor)
+			}
+		})
+	}
+}
+
+// pulledBy 判断笔记是否满足 feedPullFilter 返回的条件
+func pulledBy(filter bson.M, post *model.Post) bool {
+	if filter == nil {
+		return false
+	}
+	if clauses, ok := filter["$or"].([]bson.M); ok {
+		for _, clause := range clauses {
+			if pulledBy(clause, post) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if distribution, ok := filter["feed_distribution"]; ok && distribution != post.FeedDistribution {
+		return false
+	}
+	for _, id := range filter["user_id"].(bson.M)["$in"].([]primitive.ObjectID) {
+		if id == post.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFeedAuthorCrossesFanoutThreshold(t *testing.T) {
+	cfg := config.GetConfig()
+	strategy, maxFans := cfg.Feed.Strategy, cfg.Feed.FanoutMaxFans
+	cfg.Feed.Strategy, cfg.Feed.FanoutMaxFans = model.FeedStrategyHybrid, 2
+	t.Cleanup(func() { cfg.Feed.Strategy, cfg.Feed.FanoutMaxFans = strategy, maxFans })
+
+	author := primitive.NewObjectID()
+	distribution := func(fansCount int) string {
+		if UsesFanout(fansCount) {
+			return model.FeedDistributionPush
+		}
+		return model.FeedDistributionPull
+	}
+
+	// 粉丝多时发布的笔记没有写入收件箱，粉丝少时发布的笔记已写入收件箱
+	pullPost := &model.Post{ID: primitive.NewObjectID(), UserID: author, FeedDistribution: distribution(5)}
+	pushPost := &model.Post{ID: primitive.NewObjectID(), UserID: author, FeedDistribution: distribution(1)}
+
+	tests := []struct {
+		name      string
+		fansCount int
+		wantPush  bool
+		wantPull  []*model.Post
+	}{
+		// 粉丝数降到阈值以下后改为读收件箱，之前未写入收件箱的笔记仍需拉取
+		{name: "改为写扩散", fansCount: 1, wantPush: true, wantPull: []*model.Post{pullPost}},
+		// 粉丝数超过阈值后改为拉取，作者的所有笔记都从 posts 集合读取
+		{name: "改为读扩散", fansCount: 5, wantPush: false, wantPull: []*model.Post{pullPost, pushPost}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushAuthors, pullAuthors := partitionAuthors([]model.User{{ID: author, FansCount: tt.fansCount}})
+			if got := len(pushAuthors) == 1; got != tt.wantPush {
+				t.Fatalf("写扩散 = %v, 期望 %v (pull=%v)", got, tt.wantPush, pullAuthors)
+			}
+
+			filter := feedPullFilter(pushAuthors, pullAuthors)
+			for _, post := range []*model.Post{pullPost, pushPost} {
+				want := false
+				for _, p := range tt.wantPull {
+					want = want || p == post
+				}
+				if got := pulledBy(filter, post); got != want {
+					t.Errorf("分发方式为 %s 的笔记拉取 = %v, 期望 %v", post.FeedDistribution, got, want)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package service
 
 import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/config"
 	"blue-note/model"
+	"blue-note/repository"
+	"blue-note/tracing"
 	"bytes"
 	"context"
 	"fmt"
@@ -11,34 +16,42 @@ import (
 	_ "image/png"  // 支持PNG格式
 	"io"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // FileService 文件服务
 type FileService struct {
-	db                  *mongo.Database
+	files                repository.FileRepo
 	objectStorageService *ObjectStorageService
+	cache                *cache.Group
 }
 
-// NewFileService 创建文件服务实例
-func NewFileService(db *mongo.Database, objectStorageService *ObjectStorageService) *FileService {
+// NewFileService 创建文件服务实例，cache 为 nil 时不缓存文件尺寸
+func NewFileService(repos *repository.Repositories, objectStorageService *ObjectStorageService, cache *cache.Group) *FileService {
 	return &FileService{
-		db:                  db,
+		files:                repos.Files,
 		objectStorageService: objectStorageService,
+		cache:                cache,
 	}
 }
 
+// fileDimensions 缓存的文件尺寸
+type fileDimensions struct {
+	Width  int
+	Height int
+}
+
 // MarkTemporary 标记文件为临时状态
-func (s *FileService) MarkTemporary(userID string, filePath string, fileSize int64, fileTypeHint string) error {
+func (s *FileService) MarkTemporary(ctx context.Context, userID string, filePath string, fileSize int64, fileTypeHint string) error {
 	// 实现标记临时文件的逻辑
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
@@ -68,14 +81,15 @@ func (s *FileService) MarkTemporary(userID string, filePath string, fileSize int
 		}
 	}
 	
-	// 获取文件宽高
+	// 获取文件宽高，同名文件重新上传时不能使用缓存的旧尺寸
 	width, height := 0, 0
+	s.cache.Delete(ctx, dimensionsCacheKey(fileURL))
 	if fileType == "image" || fileType == "video" {
-		w, h, err := s.GetFileDimensions(fileURL)
+		w, h, err := s.GetFileDimensions(ctx, fileURL)
 		if err == nil {
 			width, height = w, h
 		} else {
-			log.Printf("获取文件尺寸失败: %v", err)
+			slog.WarnContext(ctx, "获取文件尺寸失败", "error", err)
 		}
 	}
 	
@@ -92,7 +106,7 @@ func (s *FileService) MarkTemporary(userID string, filePath string, fileSize int
 		UpdatedAt: now,
 	}
 	
-	_, err = s.db.Collection("file_records").InsertOne(context.Background(), fileRecord)
+	err = s.files.Create(ctx, &fileRecord)
 	if err != nil {
 		return fmt.Errorf("创建文件记录失败: %w", err)
 	}
@@ -100,14 +114,23 @@ func (s *FileService) MarkTemporary(userID string, filePath string, fileSize int
 	return nil
 }
 
-// GetFileDimensions 获取文件尺寸（图片或视频）
-func (s *FileService) GetFileDimensions(fileURL string) (int, int, error) {
+// GetFileDimensions 获取文件尺寸（图片或视频），结果会被缓存
+func (s *FileService) GetFileDimensions(ctx context.Context, fileURL string) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "file.dimensions", trace.WithAttributes(attribute.String("file.url", fileURL)))
+
+	ttl := cacheTTL(config.GetConfig().Cache.DimensionsTTL)
+	dims, err := cache.Fetch(ctx, s.cache, dimensionsCacheKey(fileURL), ttl, func(ctx context.Context) (fileDimensions, error) {
+		width, height, err := s.loadFileDimensions(ctx, fileURL)
+		return fileDimensions{Width: width, Height: height}, err
+	})
+	tracing.End(span, err)
+	return dims.Width, dims.Height, err
+}
+
+// loadFileDimensions 从文件记录或文件内容获取尺寸
+func (s *FileService) loadFileDimensions(ctx context.Context, fileURL string) (int, int, error) {
 	// 检查文件是否在数据库中有记录
-	var fileRecord model.FileRecord
-	err := s.db.Collection("file_records").FindOne(
-		context.Background(),
-		bson.M{"url": fileURL},
-	).Decode(&fileRecord)
+	fileRecord, err := s.files.FindByURL(ctx, fileURL)
 	
 	if err == nil && fileRecord.Width > 0 && fileRecord.Height > 0 {
 		// 如果数据库中有记录并且包含尺寸信息，直接返回
@@ -116,17 +139,16 @@ func (s *FileService) GetFileDimensions(fileURL string) (int, int, error) {
 	
 	// 尝试从本地文件获取尺寸
 	if strings.HasPrefix(fileURL, "/uploads/") {
-		localPath := "." + fileURL
-		return s.getLocalFileDimensions(localPath)
+		return s.getLocalFileDimensions(strings.TrimPrefix(fileURL, "/uploads/"))
 	}
 	
 	// 尝试从远程URL获取尺寸
-	return s.getRemoteFileDimensions(fileURL)
+	return s.getRemoteFileDimensions(ctx, fileURL)
 }
 
 // 获取本地文件尺寸
 func (s *FileService) getLocalFileDimensions(filePath string) (int, int, error) {
-	file, err := os.Open(filePath)
+	file, err := s.objectStorageService.OpenLocalFile(filePath)
 	if err != nil {
 		return 0, 0, fmt.Errorf("打开文件失败: %w", err)
 	}
@@ -154,13 +176,25 @@ func (s *FileService) getLocalFileDimensions(filePath string) (int, int, error)
 }
 
 // 获取远程文件尺寸
-func (s *FileService) getRemoteFileDimensions(fileURL string) (int, int, error) {
-	// 下载文件头部
-	resp, err := http.Get(fileURL)
+func (s *FileService) getRemoteFileDimensions(ctx context.Context, fileURL string) (width int, height int, err error) {
+	ctx, span := tracing.Start(ctx, "file.fetch_remote", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(http.MethodGet),
+		semconv.URLFull(fileURL),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 下载文件头部，请求取消或超时时随之中断
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return 0, 0, fmt.Errorf("请求文件失败: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 	
 	// 读取头部数据以检测类型
 	buffer := make([]byte, 512)
@@ -201,25 +235,13 @@ func (s *FileService) getRemoteFileDimensions(fileURL string) (int, int, error)
 }
 
 // MarkUsed 标记文件为已使用状态
-func (s *FileService) MarkUsed(filePaths []string) error {
+func (s *FileService) MarkUsed(ctx context.Context, filePaths []string) error {
 	// 实现标记已使用文件的逻辑
 	if len(filePaths) == 0 {
 		return nil
 	}
 	
-	now := time.Now()
-	_, err := s.db.Collection("file_records").UpdateMany(
-		context.Background(),
-		bson.M{"file_path": bson.M{"$in": filePaths}},
-		bson.M{
-			"$set": bson.M{
-				"status":     model.FileStatusUsed,
-				"updated_at": now,
-				"used_at":    now,
-			},
-		},
-	)
-	
+	err := s.files.MarkUsed(ctx, filePaths, time.Now())
 	if err != nil {
 		return fmt.Errorf("更新文件状态失败: %w", err)
 	}
@@ -228,7 +250,7 @@ func (s *FileService) MarkUsed(filePaths []string) error {
 }
 
 // DeleteFile 删除文件
-func (s *FileService) DeleteFile(userID string, filePath string) error {
+func (s *FileService) DeleteFile(ctx context.Context, userID string, filePath string) error {
 	// 实现删除文件的逻辑
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
@@ -236,49 +258,32 @@ func (s *FileService) DeleteFile(userID string, filePath string) error {
 	}
 	
 	// 检查文件所有权
-	var fileRecord model.FileRecord
-	err = s.db.Collection("file_records").FindOne(
-		context.Background(),
-		bson.M{
-			"file_path": filePath,
-			"user_id":   userObjID,
-		},
-	).Decode(&fileRecord)
-	
+	_, err = s.files.FindOwned(ctx, filePath, userObjID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return fmt.Errorf("文件不存在或无权限删除")
+		if err == repository.ErrNotFound {
+			return apperr.ErrFileNotFound
 		}
 		return fmt.Errorf("查询文件记录失败: %w", err)
 	}
 	
 	// 从对象存储中删除文件
-	err = s.objectStorageService.DeleteFile(filePath)
+	err = s.objectStorageService.DeleteFile(ctx, filePath)
 	if err != nil {
-		log.Printf("从对象存储删除文件失败: %v", err)
+		slog.ErrorContext(ctx, "从对象存储删除文件失败", "path", filePath, "error", err)
 	}
 	
 	// 更新文件状态为已删除
-	_, err = s.db.Collection("file_records").UpdateOne(
-		context.Background(),
-		bson.M{"file_path": filePath},
-		bson.M{
-			"$set": bson.M{
-				"status":     model.FileStatusDeleted,
-				"updated_at": time.Now(),
-			},
-		},
-	)
-	
+	err = s.files.SetStatus(ctx, filePath, model.FileStatusDeleted, time.Now())
 	if err != nil {
 		return fmt.Errorf("更新文件状态失败: %w", err)
 	}
+	s.cache.Delete(ctx, dimensionsCacheKey(filePath), dimensionsCacheKey(s.objectStorageService.GetFileURL(filePath)))
 	
 	return nil
 }
 
 // GetImageDimensions 获取图片尺寸（为兼容旧代码保留的方法）
-func (s *FileService) GetImageDimensions(filePath string) (int, int, error) {
+func (s *FileService) GetImageDimensions(ctx context.Context, filePath string) (int, int, error) {
 	// 调用新的通用方法
-	return s.GetFileDimensions(s.objectStorageService.GetFileURL(filePath))
+	return s.GetFileDimensions(ctx, s.objectStorageService.GetFileURL(filePath))
 } 
\ No newline at end of file
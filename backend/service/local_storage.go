@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage 对象存储不可用时使用的本地文件存储
+type LocalStorage interface {
+	// Save 保存文件内容
+	Save(objectName string, r io.Reader) error
+	// Open 打开已保存的文件
+	Open(objectName string) (io.ReadCloser, error)
+}
+
+// DiskStorage 将文件保存在本地目录，由 /uploads 静态路由对外提供访问
+type DiskStorage struct {
+	Dir string
+}
+
+// NewDiskStorage 创建本地目录存储
+func NewDiskStorage(dir string) *DiskStorage {
+	return &DiskStorage{Dir: dir}
+}
+
+func (s *DiskStorage) Save(objectName string, r io.Reader) error {
+	fullPath := filepath.Join(s.Dir, objectName)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	out, err := os.Create(fullPath)
+	if err != nil {
+		return fmt.Errorf("创建本地文件失败: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("写入本地文件失败: %w", err)
+	}
+
+	slog.Debug("文件已保存到本地", "path", fullPath)
+	return nil
+}
+
+func (s *DiskStorage) Open(objectName string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.Dir, objectName))
+}
@@ -2,19 +2,23 @@ package service
 
 import (
 	"blue-note/config"
+	"blue-note/health"
+	"blue-note/metrics"
+	"blue-note/tracing"
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ObjectStorageService struct {
@@ -22,16 +26,24 @@ type ObjectStorageService struct {
 	bucketName     string
 	internalEndpoint string
 	externalEndpoint string
+	local            LocalStorage // 对象存储不可用时的本地存储
+
+	healthCancel context.CancelFunc
+	healthDone   chan struct{}
+	healthMu     sync.Mutex
+	healthErr    error     // 最近一次健康检查的错误
+	healthAt     time.Time // 最近一次健康检查的时间
 }
 
 func NewObjectStorageService(cfg *config.Config) (*ObjectStorageService, error) {
-	fmt.Println("==== 对象存储配置 ====")
-	fmt.Printf("Endpoint: %s\n", cfg.ObjectStorage.Endpoint)
-	fmt.Printf("InternalEndpoint: %s\n", cfg.ObjectStorage.InternalEndpoint)
-	fmt.Printf("ExternalEndpoint: %s\n", cfg.ObjectStorage.ExternalEndpoint)
-	fmt.Printf("BucketName: %s\n", cfg.ObjectStorage.BucketName)
-	fmt.Printf("AccessKey: %s\n", cfg.ObjectStorage.AccessKey)
-	fmt.Printf("UseSSL: %v\n", cfg.ObjectStorage.UseSSL)
+	slog.Info("对象存储配置",
+		"endpoint", cfg.ObjectStorage.Endpoint,
+		"internal_endpoint", cfg.ObjectStorage.InternalEndpoint,
+		"external_endpoint", cfg.ObjectStorage.ExternalEndpoint,
+		"bucket", cfg.ObjectStorage.BucketName,
+		"access_key_set", cfg.ObjectStorage.AccessKey != "",
+		"use_ssl", cfg.ObjectStorage.UseSSL,
+	)
 	
 	// 在本地环境使用外部端点，在生产环境使用内部端点
 	var endpoint string
@@ -41,7 +53,7 @@ func NewObjectStorageService(cfg *config.Config) (*ObjectStorageService, error)
 		endpoint = cfg.ObjectStorage.InternalEndpoint // 生产环境使用内部端点
 	}
 	
-	fmt.Printf("使用端点: %s\n", endpoint)
+	slog.Info("使用对象存储端点", "endpoint", endpoint)
 	
 	// 创建自定义传输配置
 	transport := &http.Transport{
@@ -63,16 +75,16 @@ func NewObjectStorageService(cfg *config.Config) (*ObjectStorageService, error)
 	})
 	
 	if err != nil {
-		fmt.Printf("创建MinIO客户端失败: %v\n", err)
+		slog.Error("创建MinIO客户端失败", "error", err)
 		// 在开发环境中，返回降级服务而不是错误
 		if cfg.Environment == "local" || cfg.Environment == "development" {
-			fmt.Println("在本地环境中使用降级模式")
+			slog.Warn("在本地环境中使用降级模式")
 			return NewDegradedObjectStorageService(), nil
 		}
 		return nil, fmt.Errorf("创建MinIO客户端失败: %w", err)
 	}
 	
-	fmt.Printf("MinIO客户端创建成功\n")
+	slog.Info("MinIO客户端创建成功")
 	
 	// 创建服务实例，使用配置中的桶名
 	service := &ObjectStorageService{
@@ -80,6 +92,7 @@ func NewObjectStorageService(cfg *config.Config) (*ObjectStorageService, error)
 		bucketName:       cfg.ObjectStorage.BucketName,
 		internalEndpoint: cfg.ObjectStorage.InternalEndpoint,
 		externalEndpoint: cfg.ObjectStorage.ExternalEndpoint,
+		local:            NewDiskStorage("./uploads"),
 	}
 	
 	// 在后台完成初始化
@@ -90,29 +103,29 @@ func NewObjectStorageService(cfg *config.Config) (*ObjectStorageService, error)
 		// 测试连接 - 列出所有存储桶
 		buckets, err := minioClient.ListBuckets(ctx)
 		if err != nil {
-			fmt.Printf("列出存储桶失败: %v\n", err)
+			slog.Error("列出存储桶失败", "error", err)
 			return
 		}
 		
-		fmt.Printf("成功列出存储桶，共 %d 个\n", len(buckets))
+		slog.Info("成功列出存储桶", "count", len(buckets))
 		
 		// 检查存储桶是否存在
 		exists, err := minioClient.BucketExists(ctx, cfg.ObjectStorage.BucketName)
 		if err != nil {
-			fmt.Printf("检查存储桶是否存在失败: %v\n", err)
+			slog.Error("检查存储桶是否存在失败", "error", err)
 			return
 		}
 		
 		if !exists {
-			fmt.Printf("存储桶 %s 不存在，尝试创建...\n", cfg.ObjectStorage.BucketName)
+			slog.Info("存储桶不存在，尝试创建", "bucket", cfg.ObjectStorage.BucketName)
 			err = minioClient.MakeBucket(ctx, cfg.ObjectStorage.BucketName, minio.MakeBucketOptions{})
 			if err != nil {
-				fmt.Printf("创建存储桶失败: %v\n", err)
+				slog.Error("创建存储桶失败", "bucket", cfg.ObjectStorage.BucketName, "error", err)
 				return
 			}
-			fmt.Printf("存储桶 %s 创建成功\n", cfg.ObjectStorage.BucketName)
+			slog.Info("存储桶创建成功", "bucket", cfg.ObjectStorage.BucketName)
 		} else {
-			fmt.Printf("存储桶 %s 已存在\n", cfg.ObjectStorage.BucketName)
+			slog.Info("存储桶已存在", "bucket", cfg.ObjectStorage.BucketName)
 		}
 	}()
 	
@@ -120,28 +133,43 @@ func NewObjectStorageService(cfg *config.Config) (*ObjectStorageService, error)
 }
 
 // UploadFile 上传文件，带有故障转移机制
-func (s *ObjectStorageService) UploadFile(fileReader io.Reader, objectName, contentType string) (string, error) {
+func (s *ObjectStorageService) UploadFile(ctx context.Context, fileReader io.Reader, objectName, contentType string) (fileURL string, err error) {
+	ctx, span := tracing.Start(ctx, "storage.upload", trace.WithAttributes(
+		attribute.String("storage.object", objectName),
+		attribute.String("storage.content_type", contentType),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	// 如果客户端为空或在本地环境中，直接使用本地存储
 	if s.client == nil || config.GetConfig().Environment == "local" {
-		fmt.Printf("使用本地存储上传文件: %s\n", objectName)
-		return s.uploadToLocalStorage(fileReader, objectName, contentType)
+		slog.InfoContext(ctx, "使用本地存储上传文件", "object", objectName)
+		return s.uploadToLocalStorage(ctx, fileReader, objectName, contentType)
 	}
 	
 	// 尝试上传到对象存储
 	for retry := 0; retry < 3; retry++ {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		uploadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 		defer cancel()
 		
-		_, err := s.client.PutObject(ctx, s.bucketName, objectName, fileReader, -1, minio.PutObjectOptions{
+		_, err := s.client.PutObject(uploadCtx, s.bucketName, objectName, fileReader, -1, minio.PutObjectOptions{
 			ContentType: contentType,
 		})
 		
 		if err == nil {
 			// 上传成功
+			span.SetAttributes(attribute.String("storage.backend", metrics.BackendObjectStorage))
+			metrics.StorageOperations.WithLabelValues("upload", metrics.BackendObjectStorage, metrics.ResultSuccess).Inc()
 			return fmt.Sprintf("https://%s/%s/%s", s.externalEndpoint, s.bucketName, objectName), nil
 		}
 		
-		log.Printf("上传到对象存储失败 (尝试 %d/3): %v", retry+1, err)
+		slog.WarnContext(ctx, "上传到对象存储失败", "object", objectName, "attempt", retry+1, "error", err)
+		span.RecordError(err, trace.WithAttributes(attribute.Int("storage.attempt", retry+1)))
+
+		// 请求已取消或超时，不再重试也不再降级到本地存储
+		if ctx.Err() != nil {
+			metrics.StorageOperations.WithLabelValues("upload", metrics.BackendObjectStorage, metrics.ResultError).Inc()
+			return "", ctx.Err()
+		}
 		
 		// 如果不是最后一次重试，重置reader（如果可能）
 		if retry < 2 {
@@ -151,73 +179,77 @@ func (s *ObjectStorageService) UploadFile(fileReader io.Reader, objectName, cont
 				// 如果reader不支持seek，无法重试
 				break
 			}
-			time.Sleep(time.Duration(retry+1) * time.Second) // 指数退避
+			// 指数退避
+			select {
+			case <-time.After(time.Duration(retry+1) * time.Second):
+			case <-ctx.Done():
+				metrics.StorageOperations.WithLabelValues("upload", metrics.BackendObjectStorage, metrics.ResultError).Inc()
+				return "", ctx.Err()
+			}
 		}
 	}
 	
 	// 所有重试都失败，使用本地存储作为备用
-	return s.uploadToLocalStorage(fileReader, objectName, contentType)
+	metrics.StorageOperations.WithLabelValues("upload", metrics.BackendObjectStorage, metrics.ResultError).Inc()
+	metrics.StorageFallbacks.Inc()
+	slog.WarnContext(ctx, "对象存储上传失败，降级到本地存储", "object", objectName)
+	span.SetAttributes(attribute.Bool("storage.fallback", true))
+	return s.uploadToLocalStorage(ctx, fileReader, objectName, contentType)
 }
 
 // 本地存储备用方案
-func (s *ObjectStorageService) uploadToLocalStorage(fileReader io.Reader, objectName, contentType string) (string, error) {
-	fmt.Printf("使用本地存储上传文件: %s\n", objectName)
-	
-	// 确保本地存储目录存在
-	uploadDir := "./uploads"
-	fullPath := filepath.Join(uploadDir, objectName)
-	dirPath := filepath.Dir(fullPath)
-	
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return "", fmt.Errorf("创建目录失败: %w", err)
-	}
-	
-	// 创建本地文件
-	out, err := os.Create(fullPath)
-	if err != nil {
-		return "", fmt.Errorf("创建本地文件失败: %w", err)
-	}
-	defer out.Close()
+func (s *ObjectStorageService) uploadToLocalStorage(ctx context.Context, fileReader io.Reader, objectName, contentType string) (string, error) {
+	slog.DebugContext(ctx, "写入本地存储", "object", objectName)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("storage.backend", metrics.BackendLocal))
 	
-	// 复制数据到本地文件
-	_, err = io.Copy(out, fileReader)
-	if err != nil {
-		return "", fmt.Errorf("写入本地文件失败: %w", err)
+	if err := s.local.Save(objectName, fileReader); err != nil {
+		metrics.StorageOperations.WithLabelValues("upload", metrics.BackendLocal, metrics.ResultError).Inc()
+		return "", err
 	}
-	
-	fmt.Printf("文件已保存到本地: %s\n", fullPath)
+	metrics.StorageOperations.WithLabelValues("upload", metrics.BackendLocal, metrics.ResultSuccess).Inc()
 	
 	// 返回本地文件URL - 使用绝对路径，确保前端可以访问
 	// 注意：这里不需要服务器域名，因为它是相对于当前域名的路径
 	return fmt.Sprintf("/uploads/%s", objectName), nil
 }
 
-func (s *ObjectStorageService) DeleteFile(fileURL string) error {
+// OpenLocalFile 打开保存在本地存储中的文件
+func (s *ObjectStorageService) OpenLocalFile(objectName string) (io.ReadCloser, error) {
+	return s.local.Open(objectName)
+}
+
+func (s *ObjectStorageService) DeleteFile(ctx context.Context, fileURL string) (err error) {
 	// 在开发环境中，如果客户端为空，直接返回
 	if s.client == nil {
 		return nil
 	}
 
+	ctx, span := tracing.Start(ctx, "storage.delete", trace.WithAttributes(attribute.String("storage.url", fileURL)))
+	defer func() { tracing.End(span, err) }()
+
 	// 从URL中提取对象名称
 	// URL格式: https://externalEndpoint/bucketName/objectName
 	parts := strings.Split(fileURL, "/")
 	if len(parts) < 4 {
+		metrics.StorageOperations.WithLabelValues("delete", metrics.BackendObjectStorage, metrics.ResultError).Inc()
 		return fmt.Errorf("无效的文件URL: %s", fileURL)
 	}
 
 	objectName := strings.Join(parts[4:], "/")
 	
 	// 删除对象
-	err := s.client.RemoveObject(
-		context.Background(),
+	err = s.client.RemoveObject(
+		ctx,
 		s.bucketName,
 		objectName,
 		minio.RemoveObjectOptions{},
 	)
 	if err != nil {
+		metrics.StorageOperations.WithLabelValues("delete", metrics.BackendObjectStorage, metrics.ResultError).Inc()
 		return fmt.Errorf("删除对象失败: %w", err)
 	}
 
+	metrics.StorageOperations.WithLabelValues("delete", metrics.BackendObjectStorage, metrics.ResultSuccess).Inc()
 	return nil
 }
 
@@ -238,38 +270,44 @@ func GenerateAdImagePath(adType string, adID string) string {
 }
 
 // 上传用户头像
-func (s *ObjectStorageService) UploadAvatar(userID string, file io.Reader) (string, error) {
+func (s *ObjectStorageService) UploadAvatar(ctx context.Context, userID string, file io.Reader) (string, error) {
 	if file == nil {
 		return "", fmt.Errorf("文件为空")
 	}
 	
 	path := fmt.Sprintf("avatars/%s.jpg", userID)
-	fmt.Printf("上传头像: userID=%s, path=%s\n", userID, path)
+	slog.InfoContext(ctx, "上传头像", "user_id", userID, "path", path)
 	
-	return s.UploadFile(file, path, "image/jpeg")
+	return s.UploadFile(ctx, file, path, "image/jpeg")
 }
 
 // 上传帖子图片
-func (s *ObjectStorageService) UploadPostImage(postID string, imageIndex int, file io.Reader) (string, error) {
+func (s *ObjectStorageService) UploadPostImage(ctx context.Context, postID string, imageIndex int, file io.Reader) (string, error) {
 	path := GeneratePostImagePath(postID, imageIndex)
-	return s.UploadFile(file, path, "image/jpeg")
+	return s.UploadFile(ctx, file, path, "image/jpeg")
 }
 
 // 上传广告图片
-func (s *ObjectStorageService) UploadAdImage(adType string, adID string, file io.Reader) (string, error) {
+func (s *ObjectStorageService) UploadAdImage(ctx context.Context, adType string, adID string, file io.Reader) (string, error) {
 	path := GenerateAdImagePath(adType, adID)
-	return s.UploadFile(file, path, "image/jpeg")
+	return s.UploadFile(ctx, file, path, "image/jpeg")
 }
 
 // NewDegradedObjectStorageService 创建一个降级模式的对象存储服务
 func NewDegradedObjectStorageService() *ObjectStorageService {
+	slog.Warn("创建降级模式的对象存储服务")
+	return NewLocalObjectStorageService(NewDiskStorage("./uploads"))
+}
+
+// NewLocalObjectStorageService 创建只使用指定本地存储的对象存储服务
+func NewLocalObjectStorageService(local LocalStorage) *ObjectStorageService {
 	cfg := config.GetConfig().ObjectStorage
-	fmt.Println("创建降级模式的对象存储服务")
 	return &ObjectStorageService{
 		client:           nil,
 		bucketName:       cfg.BucketName,
 		internalEndpoint: cfg.InternalEndpoint,
 		externalEndpoint: cfg.ExternalEndpoint,
+		local:            local,
 	}
 }
 
@@ -288,25 +326,75 @@ func (s *ObjectStorageService) GetBucketName() string {
 	return s.bucketName
 }
 
-// 启动健康检查
+// StartHealthCheck 定时检查对象存储是否可用，结果通过 Health 返回
 func (s *ObjectStorageService) StartHealthCheck() {
+	// 降级模式下没有对象存储客户端，无需检查
+	if s.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.healthCancel = cancel
+	s.healthDone = make(chan struct{})
+
 	go func() {
-		ticker := time.NewTicker(5 * time.Minute)
+		defer close(s.healthDone)
+
+		ticker := time.NewTicker(time.Minute)
 		defer ticker.Stop()
 		
-		for range ticker.C {
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			_, err := s.client.ListBuckets(ctx)
-			cancel()
+		for {
+			checkCtx, checkCancel := context.WithTimeout(ctx, 10*time.Second)
+			_, err := s.client.ListBuckets(checkCtx)
+			checkCancel()
+			if ctx.Err() != nil {
+				return
+			}
 			
 			if err != nil {
-				log.Printf("对象存储健康检查失败: %v", err)
-				// 可以在这里添加告警逻辑
+				slog.Warn("对象存储健康检查失败", "error", err)
+			}
+			s.healthMu.Lock()
+			s.healthErr = err
+			s.healthAt = time.Now()
+			s.healthMu.Unlock()
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
 }
 
+// Health 返回对象存储的状态。没有对象存储客户端或最近一次健康检查失败时为降级，
+// 此时上传的文件会保存到本地存储
+func (s *ObjectStorageService) Health(ctx context.Context) health.Result {
+	if s.client == nil {
+		return health.Degraded("对象存储不可用，使用本地存储")
+	}
+
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	if s.healthAt.IsZero() {
+		return health.Up("尚未完成健康检查")
+	}
+	if s.healthErr != nil {
+		return health.Degraded("对象存储健康检查失败，上传将降级到本地存储: " + s.healthErr.Error())
+	}
+	return health.Up("")
+}
+
+// StopHealthCheck 停止健康检查并等待其退出
+func (s *ObjectStorageService) StopHealthCheck() {
+	if s.healthCancel == nil {
+		return
+	}
+	s.healthCancel()
+	<-s.healthDone
+}
+
 // GetFileURL 获取文件URL
 func (s *ObjectStorageService) GetFileURL(filePath string) string {
 	// 如果是本地环境或客户端为空，返回本地URL
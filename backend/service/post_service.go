@@ -1,35 +1,106 @@
 package service
 
 import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/lifecycle"
+	"blue-note/metrics"
 	"blue-note/model"
+	"blue-note/repository"
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type PostService struct {
-	db *mongo.Database
+	posts       repository.PostRepo
+	comments    repository.CommentRepo
+	likes       repository.LikeRepo
+	users       repository.UserRepo
+	follows     repository.FollowRepo
+	tx          repository.Transactor
 	fileService *FileService
+	feedService *FeedService
+	tagService  *TagService
+	cache       *cache.Group
+	tasks       *lifecycle.Tasks // 关注流推送、计数更新等后台任务
 }
 
-func NewPostService(db *mongo.Database, fileService *FileService) *PostService {
-	return &PostService{db: db, fileService: fileService}
+func NewPostService(repos *repository.Repositories, fileService *FileService, feedService *FeedService, tagService *TagService, cache *cache.Group, tasks *lifecycle.Tasks) *PostService {
+	return &PostService{
+		posts:       repos.Posts,
+		comments:    repos.Comments,
+		likes:       repos.Likes,
+		users:       repos.Users,
+		follows:     repos.Follows,
+		tx:          repos.Tx,
+		fileService: fileService,
+		feedService: feedService,
+		tagService:  tagService,
+		cache:       cache,
+		tasks:       tasks,
+	}
+}
+
+// resolveTags 将标签转换为规范名称
+func (s *PostService) resolveTags(ctx context.Context, tags []string) ([]string, error) {
+	if s.tagService == nil {
+		return tags, nil
+	}
+	resolved, err := s.tagService.ResolveTags(ctx, tags)
+	if err != nil {
+		return nil, fmt.Errorf("处理标签失败: %w", err)
+	}
+	return resolved, nil
+}
+
+// canonicalTag 将查询的标签转换为规范名称，以便通过同义词筛选
+func (s *PostService) canonicalTag(ctx context.Context, tag string) (string, error) {
+	if s.tagService == nil {
+		return tag, nil
+	}
+	return s.tagService.CanonicalName(ctx, tag)
+}
+
+// recountTags 异步更新标签的笔记数
+func (s *PostService) recountTags(ctx context.Context, tags []string) {
+	if s.tagService == nil || len(tags) == 0 {
+		return
+	}
+	s.tasks.Go(ctx, func(ctx context.Context) {
+		if err := s.tagService.RecountTags(ctx, tags); err != nil {
+			slog.ErrorContext(ctx, "更新标签笔记数失败", "error", err)
+		}
+	})
+}
+
+// recomputeCreatorStats 异步重新计算作者的笔记数、获赞数和被收藏数
+func (s *PostService) recomputeCreatorStats(ctx context.Context, userID primitive.ObjectID) {
+	s.tasks.Go(ctx, func(ctx context.Context) {
+		if err := updateCreatorStats(ctx, s.posts, s.users, userID); err != nil {
+			slog.ErrorContext(ctx, "更新创作者数据失败", "error", err)
+		}
+		s.cache.Delete(ctx, profileCacheKey(userID))
+	})
 }
 
-func (s *PostService) CreatePost(user *model.User, req *model.CreatePostRequest) (*model.Post, error) {
+func (s *PostService) CreatePost(ctx context.Context, user *model.User, req *model.CreatePostRequest) (*model.Post, error) {
+	tags, err := s.resolveTags(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &model.Post{
 		Title:      req.Title,
 		Content:    req.Content,
 		Type:       req.Type,
-		Tags:       req.Tags,
+		Tags:       tags,
 		Files:      req.Files,
 		CoverImage: req.CoverImage,
 		Status:     "pending",
@@ -52,27 +123,28 @@ func (s *PostService) CreatePost(user *model.User, req *model.CreatePostRequest)
 		post.Status = "draft"
 	}
 
-	result, err := s.db.Collection("posts").InsertOne(context.Background(), post)
-	if err != nil {
+	if err := s.posts.Create(ctx, post); err != nil {
 		return nil, err
 	}
-
-	post.ID = result.InsertedID.(primitive.ObjectID)
+	if !req.IsDraft {
+		metrics.PostsCreated.WithLabelValues("post").Inc()
+	}
 
 	// 标记文件为已使用状态
 	if s.fileService != nil {
-		go func() {
-			err := s.fileService.MarkUsed(req.Files)
+		s.tasks.Go(ctx, func(ctx context.Context) {
+			err := s.fileService.MarkUsed(ctx, req.Files)
 			if err != nil {
-				log.Printf("标记文件为已使用状态失败: %v", err)
+				slog.ErrorContext(ctx, "标记文件为已使用状态失败", "error", err)
 			}
-		}()
+		})
 	}
 
 	return post, nil
 }
 
-func (s *PostService) GetPostList(query *model.PostQuery) (*model.PostListResponse, error) {
+// GetPostList 获取帖子列表，viewerID 为空表示未登录
+func (s *PostService) GetPostList(ctx context.Context, query *model.PostQuery, viewerID string) (*model.PostListResponse, error) {
 	// 设置默认值
 	if query.Page < 1 {
 		query.Page = 1
@@ -81,79 +153,67 @@ func (s *PostService) GetPostList(query *model.PostQuery) (*model.PostListRespon
 		query.Limit = 10
 	}
 
-	// 构建查询条件
-	filter := bson.M{}
-	if query.Type != "" {
-		filter["type"] = query.Type
+	// 构建查询条件（排除因举报被隐藏的帖子）
+	filter := repository.PostFilter{
+		ExcludeHidden: true,
+		Type:          query.Type,
+		Status:        query.Status,
 	}
 	if query.Tag != "" {
-		filter["tags"] = query.Tag
-	}
-	if query.Status != "" {
-		filter["status"] = query.Status
-	}
-	if query.UserID != "" {
-		userID, err := primitive.ObjectIDFromHex(query.UserID)
+		tag, err := s.canonicalTag(ctx, query.Tag)
 		if err != nil {
 			return nil, err
 		}
-		filter["user_id"] = userID
+		filter.Tag = tag
+	}
+	if err := s.applyAuthorFilter(ctx, &filter, query.UserID, viewerID); err != nil {
+		return nil, err
 	}
 
 	// 获取总数
-	total, err := s.db.Collection("posts").CountDocuments(context.Background(), filter)
+	total, err := s.posts.Count(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	// 查询数据
-	opts := options.Find().
-		SetSkip(int64((query.Page - 1) * query.Limit)).
-		SetLimit(int64(query.Limit)).
-		SetSort(bson.D{{"created_at", -1}})
-
-	cursor, err := s.db.Collection("posts").Find(context.Background(), filter, opts)
+	posts, err := s.posts.Find(ctx, filter, repository.SortByCreatedAt, (query.Page-1)*query.Limit, query.Limit)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-
-	var posts []*model.Post
-	if err = cursor.All(context.Background(), &posts); err != nil {
-		return nil, err
-	}
 
 	var postItems []model.PostListItem
 	for _, post := range posts {
 		item := model.PostListItem{
-			ID:           post.ID,
-			PostID:       post.ID.Hex(),
-			Title:        post.Title,
-			Content:      post.Content,
-			Type:         post.Type,
-			Tags:         post.Tags,
-			Files:        post.Files,
-			CoverImage:   post.CoverImage,
-			UserID:       post.UserID,
-			Username:     post.Username,
-			Nickname:     post.Nickname,
-			Avatar:       post.Avatar,
-			Likes:        post.Likes,
-			Comments:     post.Comments,
-			CreatedAt:    post.CreatedAt,
-			UpdatedAt:    post.UpdatedAt,
+			ID:         post.ID,
+			PostID:     post.ID.Hex(),
+			Title:      post.Title,
+			Content:    post.Content,
+			Type:       post.Type,
+			Tags:       post.Tags,
+			Files:      post.Files,
+			CoverImage: post.CoverImage,
+			UserID:     post.UserID,
+			Username:   post.Username,
+			Nickname:   post.Nickname,
+			Avatar:     post.Avatar,
+			Likes:      post.Likes,
+			Comments:   post.Comments,
+			Views:      post.Views,
+			CreatedAt:  post.CreatedAt,
+			UpdatedAt:  post.UpdatedAt,
 		}
-		
+
 		// 如果 CoverImage 为空，则使用第一张图片作为封面
 		if item.CoverImage == "" && len(post.Files) > 0 {
 			item.CoverImage = post.Files[0]
 		}
-		
+
 		// 设置用户信息
 		item.User.UserID = post.UserID.Hex()
 		item.User.Nickname = post.Nickname
 		item.User.Avatar = post.Avatar
-		
+
 		postItems = append(postItems, item)
 	}
 
@@ -163,127 +223,221 @@ func (s *PostService) GetPostList(query *model.PostQuery) (*model.PostListRespon
 	}, nil
 }
 
-func (s *PostService) GetPostDetail(postID string) (*model.Post, error) {
+// GetPostDetail 获取帖子详情。隐藏或未审核通过的笔记只有作者和管理员可见，其他人按笔记不存在处理；
+// viewerID 不为空时检查查看者是否被作者拉黑
+func (s *PostService) GetPostDetail(ctx context.Context, postID string, viewerID string, role string) (*model.Post, error) {
 	objectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return nil, err
 	}
 
-	var post model.Post
-	err = s.db.Collection("posts").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
+	post, err := s.getPost(ctx, objectID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &post, nil
+	isAuthor := viewerID != "" && post.UserID.Hex() == viewerID
+	if (post.Hidden || post.Status != "approved") && !isAuthor && role != "admin" {
+		return nil, apperr.ErrPostNotFound
+	}
+
+	if viewerID != "" {
+		if err := s.checkNotBlocked(ctx, post.UserID, viewerID); err != nil {
+			// 被作者拉黑时按笔记不存在处理，不透露拉黑关系
+			if errors.Is(err, apperr.ErrBlockedByAuthor) {
+				return nil, apperr.ErrPostNotFound.Wrap(err)
+			}
+			return nil, err
+		}
+	}
+
+	return post, nil
+}
+
+// getPost 通过缓存查询笔记，不做可见性检查，供内部使用
+func (s *PostService) getPost(ctx context.Context, postID primitive.ObjectID) (*model.Post, error) {
+	ttl := cacheTTL(config.GetConfig().Cache.PostTTL)
+	return cache.Fetch(ctx, s.cache, postCacheKey(postID), ttl, func(ctx context.Context) (*model.Post, error) {
+		return s.findPost(ctx, postID)
+	})
+}
+
+// findPost 查询笔记，不存在时返回 apperr.ErrPostNotFound
+func (s *PostService) findPost(ctx context.Context, postID primitive.ObjectID) (*model.Post, error) {
+	post, err := s.posts.FindByID(ctx, postID)
+	if err == repository.ErrNotFound {
+		return nil, apperr.ErrPostNotFound
+	}
+	return post, err
+}
+
+// checkNotBlocked 检查用户是否被作者拉黑
+func (s *PostService) checkNotBlocked(ctx context.Context, authorID primitive.ObjectID, userID string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	blocked, err := s.follows.HasBlocked(ctx, authorID, userObjectID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return apperr.ErrBlockedByAuthor
+	}
+	return nil
+}
+
+// checkInteractable 检查用户能否点赞、评论笔记：只有审核通过且未隐藏的笔记可以互动，
+// 其他笔记与详情页一致按不存在处理；被作者拉黑的用户不能互动
+func (s *PostService) checkInteractable(ctx context.Context, post *model.Post, userID string) error {
+	if post.Hidden || post.Status != "approved" {
+		return apperr.ErrPostNotFound
+	}
+	return s.checkNotBlocked(ctx, post.UserID, userID)
+}
+
+// applyAuthorFilter 设置作者过滤条件：按指定作者筛选，并排除与查看者存在拉黑关系的作者；
+// 未指定作者时（信息流）还会排除查看者屏蔽的作者
+func (s *PostService) applyAuthorFilter(ctx context.Context, filter *repository.PostFilter, authorID string, viewerID string) error {
+	if authorID != "" {
+		userID, err := primitive.ObjectIDFromHex(authorID)
+		if err != nil {
+			return err
+		}
+		filter.UserID = userID
+	}
+
+	hiddenIDs, err := hiddenUserIDsFrom(ctx, s.follows, viewerID, authorID == "")
+	if err != nil {
+		return err
+	}
+	filter.ExcludeUserIDs = hiddenIDs
+	return nil
 }
 
-func (s *PostService) UpdatePost(postID string, userID string, req *model.UpdatePostRequest) (*model.Post, error) {
+func (s *PostService) UpdatePost(ctx context.Context, postID string, userID string, req *model.UpdatePostRequest) (*model.Post, error) {
 	objectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return nil, err
 	}
 
 	// 检查帖子是否存在且属于当前用户
-	var post model.Post
-	err = s.db.Collection("posts").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
+	post, err := s.findPost(ctx, objectID)
 	if err != nil {
 		return nil, err
 	}
 
 	if post.UserID.Hex() != userID {
-		return nil, errors.New("无权限修改此帖子")
+		return nil, apperr.ErrPostUpdateForbidden
 	}
 
 	// 构建更新内容
-	update := bson.M{
-		"updated_at": time.Now(),
+	update := repository.PostUpdate{
+		UpdatedAt: time.Now(),
 	}
 
 	if req.Title != "" {
-		update["title"] = req.Title
+		update.Title = &req.Title
 	}
 	if req.Content != "" {
-		update["content"] = req.Content
+		update.Content = &req.Content
 	}
 	if req.Tags != nil {
-		update["tags"] = req.Tags
+		tags, err := s.resolveTags(ctx, req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		update.Tags = &tags
 	}
 	if req.Files != nil {
-		update["files"] = req.Files
+		update.Files = &req.Files
 	}
 	if req.CoverImage != "" {
-		update["cover_image"] = req.CoverImage
+		update.CoverImage = &req.CoverImage
 	}
+	status := "pending"
 	if req.IsDraft {
-		update["status"] = "draft"
-	} else {
-		update["status"] = "pending"
+		status = "draft"
 	}
+	update.Status = &status
 
 	// 标记文件为已使用状态
 	if s.fileService != nil && len(req.Files) > 0 {
-		go func() {
-			err := s.fileService.MarkUsed(req.Files)
+		s.tasks.Go(ctx, func(ctx context.Context) {
+			err := s.fileService.MarkUsed(ctx, req.Files)
 			if err != nil {
-				log.Printf("标记文件为已使用状态失败: %v", err)
+				slog.ErrorContext(ctx, "标记文件为已使用状态失败", "error", err)
 			}
-		}()
+		})
 	}
 
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		bson.M{"$set": update},
-	)
-	if err != nil {
+	if err := s.posts.Update(ctx, objectID, update); err != nil {
 		return nil, err
 	}
+	s.cache.Delete(ctx, postCacheKey(objectID))
 
-	return s.GetPostDetail(postID)
+	// 修改后需要重新审核，作者和原标签的笔记数随之变化
+	s.recountTags(ctx, post.Tags)
+	s.recomputeCreatorStats(ctx, post.UserID)
+
+	return s.getPost(ctx, objectID)
 }
 
-func (s *PostService) DeletePost(postID string, userID string) error {
+func (s *PostService) DeletePost(ctx context.Context, postID string, userID string) error {
 	objectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return err
 	}
 
 	// 检查帖子是否存在且属于当前用户
-	var post model.Post
-	err = s.db.Collection("posts").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
+	post, err := s.findPost(ctx, objectID)
 	if err != nil {
 		return err
 	}
 
 	if post.UserID.Hex() != userID {
-		return errors.New("无权限删除此帖子")
+		return apperr.ErrPostDeleteForbidden
 	}
 
-	_, err = s.db.Collection("posts").DeleteOne(context.Background(), bson.M{"_id": objectID})
-	return err
+	if err := s.posts.Delete(ctx, objectID); err != nil {
+		return err
+	}
+	s.cache.Delete(ctx, postCacheKey(objectID))
+
+	s.recountTags(ctx, post.Tags)
+	s.recomputeCreatorStats(ctx, post.UserID)
+
+	// 从关注流收件箱中移除
+	if s.feedService != nil {
+		s.tasks.Go(ctx, func(ctx context.Context) {
+			if err := s.feedService.RemovePost(ctx, objectID); err != nil {
+				slog.ErrorContext(ctx, "从收件箱移除帖子失败", "post_id", objectID.Hex(), "error", err)
+			}
+		})
+	}
+
+	return nil
 }
 
-func (s *PostService) DeleteComment(postID string, commentID string, userID string) error {
+func (s *PostService) DeleteComment(ctx context.Context, postID string, commentID string, userID string) error {
 	commentObjectID, err := primitive.ObjectIDFromHex(commentID)
 	if err != nil {
 		return err
 	}
 
 	// 检查评论是否存在且属于当前用户
-	var comment model.Comment
-	err = s.db.Collection("comments").FindOne(context.Background(), bson.M{"_id": commentObjectID}).Decode(&comment)
+	comment, err := s.comments.FindByID(ctx, commentObjectID)
 	if err != nil {
 		return err
 	}
 
 	if comment.UserID.Hex() != userID {
-		return errors.New("无权限删除此评论")
+		return apperr.ErrCommentDeleteForbidden
 	}
 
 	// 删除评论
-	_, err = s.db.Collection("comments").DeleteOne(context.Background(), bson.M{"_id": commentObjectID})
-	if err != nil {
+	if err := s.comments.Delete(ctx, commentObjectID); err != nil {
 		return err
 	}
 
@@ -293,166 +447,217 @@ func (s *PostService) DeleteComment(postID string, commentID string, userID stri
 		return err
 	}
 
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": postObjectID},
-		bson.M{"$inc": bson.M{"comments": -1}},
-	)
+	_, err = s.posts.IncrCounter(ctx, postObjectID, repository.PostComments, -1)
+	s.cache.Delete(ctx, postCacheKey(postObjectID))
 	return err
 }
 
-func (s *PostService) ReviewPost(postID string, req *model.ReviewPostRequest) error {
+func (s *PostService) ReviewPost(ctx context.Context, postID string, req *model.ReviewPostRequest) error {
 	objectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return err
 	}
 
-	update := bson.M{
-		"status":     req.Status,
-		"updated_at": time.Now(),
+	update := repository.PostUpdate{
+		Status:    &req.Status,
+		UpdatedAt: time.Now(),
 	}
 
 	if req.Reason != "" {
-		update["reject_reason"] = req.Reason
+		update.RejectReason = &req.Reason
 	}
 
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		bson.M{"$set": update},
-	)
-	return err
+	if err := s.posts.Update(ctx, objectID, update); err != nil {
+		return err
+	}
+	s.cache.Delete(ctx, postCacheKey(objectID))
+	metrics.PostReviews.WithLabelValues(req.Status).Inc()
+
+	// 审核结果影响作者的笔记数和标签的笔记数
+	s.tasks.Go(ctx, func(ctx context.Context) {
+		post, err := s.posts.FindByID(ctx, objectID)
+		if err != nil {
+			slog.ErrorContext(ctx, "获取帖子失败", "post_id", postID, "error", err)
+			return
+		}
+		if err := updateCreatorStats(ctx, s.posts, s.users, post.UserID); err != nil {
+			slog.ErrorContext(ctx, "更新创作者数据失败", "error", err)
+		}
+		s.cache.Delete(ctx, profileCacheKey(post.UserID))
+		if s.tagService != nil {
+			if err := s.tagService.RecountTags(ctx, post.Tags); err != nil {
+				slog.ErrorContext(ctx, "更新标签笔记数失败", "error", err)
+			}
+		}
+	})
+
+	// 审核通过后推送到粉丝的关注流，拒绝则从关注流中移除
+	if s.feedService != nil {
+		s.tasks.Go(ctx, func(ctx context.Context) {
+			if req.Status != "approved" {
+				if err := s.feedService.RemovePost(ctx, objectID); err != nil {
+					slog.ErrorContext(ctx, "从收件箱移除帖子失败", "post_id", objectID.Hex(), "error", err)
+				}
+				return
+			}
+
+			post, err := s.getPost(ctx, objectID)
+			if err != nil {
+				slog.ErrorContext(ctx, "获取帖子失败", "post_id", postID, "error", err)
+				return
+			}
+			if err := s.feedService.FanOutPost(ctx, post); err != nil {
+				slog.ErrorContext(ctx, "推送帖子到关注流失败", "post_id", postID, "error", err)
+			}
+		})
+	}
+
+	return nil
 }
 
-// 点赞帖子
-func (s *PostService) LikePost(postID string, userID string) error {
+// 点赞帖子，重复点赞不会报错，返回当前点赞状态和点赞数
+func (s *PostService) LikePost(ctx context.Context, postID string, userID string) (*model.LikeState, error) {
 	postObjectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 检查帖子是否存在
-	var post model.Post
-	err = s.db.Collection("posts").FindOne(context.Background(), bson.M{"_id": postObjectID}).Decode(&post)
+	post, err := s.findPost(ctx, postObjectID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 检查是否已经点赞
-	count, err := s.db.Collection("post_likes").CountDocuments(
-		context.Background(),
-		bson.M{
-			"post_id": postObjectID,
-			"user_id": userObjectID,
-		},
-	)
-	if err != nil {
-		return err
+	// 隐藏、未审核通过的笔记不能点赞，被作者拉黑的用户不能点赞
+	if err := s.checkInteractable(ctx, post, userID); err != nil {
+		return nil, err
 	}
 
-	if count > 0 {
-		return errors.New("已经点赞过此帖子")
-	}
+	// 点赞记录、帖子点赞数和作者获赞数在同一事务中更新，已点赞时不写入也不更新计数
+	var state *model.LikeState
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		state = nil
 
-	// 创建点赞记录
-	like := &model.PostLike{
-		PostID:    postObjectID,
-		UserID:    userObjectID,
-		CreatedAt: time.Now(),
-	}
+		like := &model.PostLike{
+			PostID:    postObjectID,
+			UserID:    userObjectID,
+			CreatedAt: time.Now(),
+		}
+		created, err := s.likes.LikePost(ctx, like)
+		if err != nil || !created {
+			return err
+		}
+
+		likes, err := s.posts.IncrCounter(ctx, postObjectID, repository.PostLikes, 1)
+		if err != nil {
+			return err
+		}
 
-	_, err = s.db.Collection("post_likes").InsertOne(context.Background(), like)
+		// 作者获赞数只统计审核通过的笔记，笔记状态变化时会重新计算
+		if post.Status == "approved" {
+			if err := s.users.IncrCounter(ctx, post.UserID, repository.UserLikeCount, 1); err != nil {
+				return err
+			}
+		}
+
+		state = &model.LikeState{Liked: true, Likes: likes}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 更新帖子点赞数
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": postObjectID},
-		bson.M{"$inc": bson.M{"likes": 1}},
-	)
-	return err
+	// 已经点赞过，返回当前状态
+	if state == nil {
+		return s.getLikeState(ctx, postObjectID, true)
+	}
+	s.cache.Delete(ctx, postCacheKey(postObjectID), profileCacheKey(post.UserID))
+	return state, nil
 }
 
-// 取消点赞
-func (s *PostService) UnlikePost(postID string, userID string) error {
+// 取消点赞，未点赞时不会报错，返回当前点赞状态和点赞数
+func (s *PostService) UnlikePost(ctx context.Context, postID string, userID string) (*model.LikeState, error) {
 	postObjectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 检查是否已经点赞
-	count, err := s.db.Collection("post_likes").CountDocuments(
-		context.Background(),
-		bson.M{
-			"post_id": postObjectID,
-			"user_id": userObjectID,
-		},
-	)
+	// 检查帖子是否存在
+	post, err := s.findPost(ctx, postObjectID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if count == 0 {
-		return errors.New("尚未点赞此帖子")
-	}
+	var state *model.LikeState
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		state = nil
 
-	// 删除点赞记录
-	_, err = s.db.Collection("post_likes").DeleteOne(
-		context.Background(),
-		bson.M{
-			"post_id": postObjectID,
-			"user_id": userObjectID,
-		},
-	)
+		removed, err := s.likes.UnlikePost(ctx, postObjectID, userObjectID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return nil
+		}
+
+		likes, err := s.posts.IncrCounter(ctx, postObjectID, repository.PostLikes, -1)
+		if err != nil {
+			return err
+		}
+
+		if post.Status == "approved" {
+			if err := s.users.IncrCounter(ctx, post.UserID, repository.UserLikeCount, -1); err != nil {
+				return err
+			}
+		}
+
+		state = &model.LikeState{Liked: false, Likes: likes}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 更新帖子点赞数
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": postObjectID},
-		bson.M{"$inc": bson.M{"likes": -1}},
-	)
-	return err
+	// 本来就没有点赞，返回当前状态
+	if state == nil {
+		return s.getLikeState(ctx, postObjectID, false)
+	}
+	s.cache.Delete(ctx, postCacheKey(postObjectID), profileCacheKey(post.UserID))
+	return state, nil
 }
 
-// 检查用户是否已点赞
-func (s *PostService) HasLiked(postID string, userID string) (bool, error) {
-	postObjectID, err := primitive.ObjectIDFromHex(postID)
+// getLikeState 读取帖子当前点赞数
+func (s *PostService) getLikeState(ctx context.Context, postID primitive.ObjectID, liked bool) (*model.LikeState, error) {
+	post, err := s.posts.FindByID(ctx, postID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	return &model.LikeState{Liked: liked, Likes: post.Likes}, nil
+}
 
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
+// 检查用户是否已点赞
+func (s *PostService) HasLiked(ctx context.Context, postID string, userID string) (bool, error) {
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return false, err
 	}
 
-	count, err := s.db.Collection("post_likes").CountDocuments(
-		context.Background(),
-		bson.M{
-			"post_id": postObjectID,
-			"user_id": userObjectID,
-		},
-	)
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return false, err
 	}
 
-	return count > 0, nil
+	return s.likes.HasLikedPost(ctx, postObjectID, userObjectID)
 }
 
 // 计算评论的综合评分
@@ -493,25 +698,15 @@ func (s *PostService) calculateCommentScore(comment *model.Comment) float64 {
 }
 
 // 更新评论评分
-func (s *PostService) updateCommentScore(comment *model.Comment) error {
+func (s *PostService) updateCommentScore(ctx context.Context, comment *model.Comment) error {
 	comment.Score = s.calculateCommentScore(comment)
 	comment.UpdatedAt = time.Now()
 
-	_, err := s.db.Collection("comments").UpdateOne(
-		context.Background(),
-		bson.M{"_id": comment.ID},
-		bson.M{
-			"$set": bson.M{
-				"score":      comment.Score,
-				"updated_at": comment.UpdatedAt,
-			},
-		},
-	)
-	return err
+	return s.comments.UpdateScore(ctx, comment.ID, comment.Score, comment.UpdatedAt)
 }
 
-// 获取帖子评论列表（带排序）
-func (s *PostService) GetPostComments(postID primitive.ObjectID, query *model.CommentQuery) ([]model.Comment, int64, error) {
+// 获取帖子评论列表（带排序），不包含与查看者存在拉黑关系或被查看者屏蔽的用户的评论
+func (s *PostService) GetPostComments(ctx context.Context, postID primitive.ObjectID, query *model.CommentQuery, viewerID string) ([]model.Comment, int64, error) {
 	// 设置默认值
 	if query.Page < 1 {
 		query.Page = 1
@@ -526,64 +721,56 @@ func (s *PostService) GetPostComments(postID primitive.ObjectID, query *model.Co
 		query.Order = "desc"
 	}
 
-	skip := int64((query.Page - 1) * query.PageSize)
+	skip := (query.Page - 1) * query.PageSize
 
-	// 构建查询条件
-	filter := bson.M{"post_id": postID}
+	// 构建查询条件（排除因举报被隐藏的评论）
+	filter := repository.CommentFilter{PostID: postID, ExcludeHidden: true}
+
+	hiddenIDs, err := hiddenUserIDsFrom(ctx, s.follows, viewerID, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.ExcludeUserIDs = hiddenIDs
 
 	// 获取总数
-	total, err := s.db.Collection("comments").CountDocuments(context.Background(), filter)
+	total, err := s.comments.Count(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// 构建排序条件
-	var sort bson.D
+	var sort repository.CommentSort
 	switch query.SortBy {
 	case "time":
-		sort = bson.D{{"created_at", getSortOrder(query.Order)}}
+		sort = repository.SortCommentByTime
 	case "likes":
-		sort = bson.D{{"likes", getSortOrder(query.Order)}}
+		sort = repository.SortCommentByLikes
 	default: // score
-		sort = bson.D{{"score", getSortOrder(query.Order)}}
+		sort = repository.SortCommentByScore
 	}
 
 	// 查询数据
-	opts := options.Find().
-		SetSort(sort).
-		SetSkip(skip).
-		SetLimit(int64(query.PageSize))
-
-	cursor, err := s.db.Collection("comments").Find(context.Background(), filter, opts)
+	comments, err := s.comments.Find(ctx, filter, sort, query.Order == "asc", skip, query.PageSize)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer cursor.Close(context.Background())
-
-	var comments []model.Comment
-	if err = cursor.All(context.Background(), &comments); err != nil {
-		return nil, 0, err
-	}
 
 	return comments, total, nil
 }
 
-// 获取排序顺序
-func getSortOrder(order string) int {
-	if order == "asc" {
-		return 1
-	}
-	return -1
-}
-
 // 创建评论
-func (s *PostService) CreateComment(postID primitive.ObjectID, userID primitive.ObjectID, content string) (*model.Comment, error) {
+func (s *PostService) CreateComment(ctx context.Context, postID primitive.ObjectID, userID primitive.ObjectID, content string) (*model.Comment, error) {
 	// 获取帖子信息
-	post, err := s.GetPostDetail(postID.Hex())
+	post, err := s.getPost(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
 
+	// 隐藏、未审核通过的笔记不能评论，被作者拉黑的用户不能评论
+	if err := s.checkInteractable(ctx, post, userID.Hex()); err != nil {
+		return nil, err
+	}
+
 	// 创建评论
 	comment := &model.Comment{
 		PostID:    postID,
@@ -600,203 +787,180 @@ func (s *PostService) CreateComment(postID primitive.ObjectID, userID primitive.
 	comment.Score = s.calculateCommentScore(comment)
 
 	// 插入评论
-	result, err := s.db.Collection("comments").InsertOne(context.Background(), comment)
-	if err != nil {
+	if err := s.comments.Create(ctx, comment); err != nil {
 		return nil, err
 	}
 
-	comment.ID = result.InsertedID.(primitive.ObjectID)
-
 	// 更新帖子评论数
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": postID},
-		bson.M{"$inc": bson.M{"comments": 1}},
-	)
-	if err != nil {
+	if _, err := s.posts.IncrCounter(ctx, postID, repository.PostComments, 1); err != nil {
 		return nil, err
 	}
+	s.cache.Delete(ctx, postCacheKey(postID))
 
 	return comment, nil
 }
 
 // 点赞评论
-func (s *PostService) LikeComment(commentID primitive.ObjectID, userID primitive.ObjectID) error {
-	// 检查是否已经点赞
-	exists, err := s.db.Collection("comment_likes").CountDocuments(
-		context.Background(),
-		bson.M{
-			"comment_id": commentID,
-			"user_id":    userID,
-		},
-	)
+func (s *PostService) LikeComment(ctx context.Context, commentID primitive.ObjectID, userID primitive.ObjectID) error {
+	// 隐藏的评论和不能互动的笔记下的评论不能点赞，被笔记作者拉黑的用户不能点赞
+	comment, err := s.comments.FindByID(ctx, commentID)
+	if err == repository.ErrNotFound || (err == nil && comment.Hidden) {
+		return apperr.ErrCommentNotFound
+	}
 	if err != nil {
 		return err
 	}
-
-	if exists > 0 {
-		return fmt.Errorf("已经点赞过了")
+	post, err := s.findPost(ctx, comment.PostID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkInteractable(ctx, post, userID.Hex()); err != nil {
+		return err
 	}
 
-	// 创建点赞记录
+	// 创建点赞记录，唯一索引保证不会重复点赞
 	like := &model.CommentLike{
 		CommentID: commentID,
 		UserID:    userID,
 		CreatedAt: time.Now(),
 	}
 
-	_, err = s.db.Collection("comment_likes").InsertOne(context.Background(), like)
+	err = s.likes.LikeComment(ctx, like)
+	if err == repository.ErrDuplicate {
+		return apperr.ErrAlreadyLiked
+	}
 	if err != nil {
 		return err
 	}
 
 	// 更新评论点赞数
-	_, err = s.db.Collection("comments").UpdateOne(
-		context.Background(),
-		bson.M{"_id": commentID},
-		bson.M{"$inc": bson.M{"likes": 1}},
-	)
-	if err != nil {
+	if err := s.comments.IncrLikes(ctx, commentID, 1); err != nil {
 		return err
 	}
 
 	// 更新评论评分
-	comment := &model.Comment{}
-	err = s.db.Collection("comments").FindOne(context.Background(), bson.M{"_id": commentID}).Decode(comment)
+	comment, err = s.comments.FindByID(ctx, commentID)
 	if err != nil {
 		return err
 	}
 
-	return s.updateCommentScore(comment)
+	return s.updateCommentScore(ctx, comment)
 }
 
 // 取消点赞评论
-func (s *PostService) UnlikeComment(commentID primitive.ObjectID, userID primitive.ObjectID) error {
+func (s *PostService) UnlikeComment(ctx context.Context, commentID primitive.ObjectID, userID primitive.ObjectID) error {
 	// 删除点赞记录
-	result, err := s.db.Collection("comment_likes").DeleteOne(
-		context.Background(),
-		bson.M{
-			"comment_id": commentID,
-			"user_id":    userID,
-		},
-	)
+	removed, err := s.likes.UnlikeComment(ctx, commentID, userID)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return fmt.Errorf("未找到点赞记录")
+	if !removed {
+		return apperr.ErrLikeNotFound
 	}
 
 	// 更新评论点赞数
-	_, err = s.db.Collection("comments").UpdateOne(
-		context.Background(),
-		bson.M{"_id": commentID},
-		bson.M{"$inc": bson.M{"likes": -1}},
-	)
-	if err != nil {
+	if err := s.comments.IncrLikes(ctx, commentID, -1); err != nil {
 		return err
 	}
 
 	// 更新评论评分
-	comment := &model.Comment{}
-	err = s.db.Collection("comments").FindOne(context.Background(), bson.M{"_id": commentID}).Decode(comment)
+	comment, err := s.comments.FindByID(ctx, commentID)
 	if err != nil {
 		return err
 	}
 
-	return s.updateCommentScore(comment)
+	return s.updateCommentScore(ctx, comment)
+}
+
+// findDraft 查询属于用户的草稿
+func (s *PostService) findDraft(ctx context.Context, draftID primitive.ObjectID, userID primitive.ObjectID) (*model.Post, error) {
+	draft, err := s.posts.FindByID(ctx, draftID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, apperr.ErrDraftNotFound
+		}
+		return nil, fmt.Errorf("查询草稿失败: %w", err)
+	}
+
+	if draft.UserID != userID || draft.Status != "draft" {
+		return nil, apperr.ErrDraftNotFound
+	}
+	return draft, nil
 }
 
 // SaveDraft 保存草稿
-func (s *PostService) SaveDraft(userID string, req *model.CreatePostRequest, draftID string) (*model.Post, error) {
+func (s *PostService) SaveDraft(ctx context.Context, userID string, req *model.CreatePostRequest, draftID string) (*model.Post, error) {
 	// 验证用户ID
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, fmt.Errorf("无效的用户ID: %w", err)
 	}
-	
+
 	// 获取用户信息
-	var user model.User
-	err = s.db.Collection("users").FindOne(context.Background(), bson.M{"_id": userObjID}).Decode(&user)
+	user, err := s.users.FindByID(ctx, userObjID)
 	if err != nil {
 		return nil, fmt.Errorf("获取用户信息失败: %w", err)
 	}
-	
+
 	now := time.Now()
-	
+
+	tags, err := s.resolveTags(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	// 如果提供了draftID，则更新现有草稿
 	if draftID != "" {
 		draftObjID, err := primitive.ObjectIDFromHex(draftID)
 		if err != nil {
 			return nil, fmt.Errorf("无效的草稿ID: %w", err)
 		}
-		
+
 		// 确保草稿属于当前用户
-		var existingDraft model.Post
-		err = s.db.Collection("posts").FindOne(
-			context.Background(),
-			bson.M{
-				"_id": draftObjID,
-				"user_id": userObjID,
-				"status": "draft",
-			},
-		).Decode(&existingDraft)
-		
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return nil, fmt.Errorf("草稿不存在或不属于当前用户")
-			}
-			return nil, fmt.Errorf("查询草稿失败: %w", err)
-		}
-		
-		// 构建更新内容
-		update := bson.M{
-			"title":       req.Title,
-			"content":     req.Content,
-			"type":        req.Type,
-			"tags":        req.Tags,
-			"files":       req.Files,
-			"cover_image": req.CoverImage,
-			"updated_at":  now,
+		if _, err := s.findDraft(ctx, draftObjID, userObjID); err != nil {
+			return nil, err
 		}
-		
+
 		// 只为图文帖子自动设置封面图片
+		coverImage := req.CoverImage
 		if req.Type == "image" && req.CoverImage == "" && len(req.Files) > 0 {
-			update["cover_image"] = req.Files[0]
+			coverImage = req.Files[0]
 		}
-		
-		_, err = s.db.Collection("posts").UpdateOne(
-			context.Background(),
-			bson.M{"_id": draftObjID},
-			update,
-		)
-		
-		if err != nil {
+
+		// 构建更新内容
+		files := req.Files
+		update := repository.PostUpdate{
+			Title:      &req.Title,
+			Content:    &req.Content,
+			Type:       &req.Type,
+			Tags:       &tags,
+			Files:      &files,
+			CoverImage: &coverImage,
+			UpdatedAt:  now,
+		}
+
+		if err := s.posts.Update(ctx, draftObjID, update); err != nil {
 			return nil, fmt.Errorf("更新草稿失败: %w", err)
 		}
-		
+		s.cache.Delete(ctx, postCacheKey(draftObjID))
+
 		// 获取更新后的草稿
-		var updatedDraft model.Post
-		err = s.db.Collection("posts").FindOne(
-			context.Background(),
-			bson.M{"_id": draftObjID},
-		).Decode(&updatedDraft)
-		
+		updatedDraft, err := s.posts.FindByID(ctx, draftObjID)
 		if err != nil {
 			return nil, fmt.Errorf("获取更新后的草稿失败: %w", err)
 		}
-		
-		return &updatedDraft, nil
+
+		return updatedDraft, nil
 	}
-	
+
 	// 创建新草稿
 	draft := model.Post{
 		ID:         primitive.NewObjectID(),
 		Title:      req.Title,
 		Content:    req.Content,
 		Type:       req.Type,
-		Tags:       req.Tags,
+		Tags:       tags,
 		Files:      req.Files,
 		CoverImage: req.CoverImage,
 		Status:     "draft",
@@ -809,93 +973,79 @@ func (s *PostService) SaveDraft(userID string, req *model.CreatePostRequest, dra
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
-	
+
 	// 只为图文帖子自动设置封面图片
 	if draft.Type == "image" && draft.CoverImage == "" && len(draft.Files) > 0 {
 		draft.CoverImage = draft.Files[0]
 	}
-	
-	_, err = s.db.Collection("posts").InsertOne(context.Background(), draft)
-	if err != nil {
+
+	if err := s.posts.Create(ctx, &draft); err != nil {
 		return nil, fmt.Errorf("创建草稿失败: %w", err)
 	}
-	
+
 	return &draft, nil
 }
 
 // GetUserDrafts 获取用户草稿列表
-func (s *PostService) GetUserDrafts(userID string, page, limit int) (*model.PostListResponse, error) {
+func (s *PostService) GetUserDrafts(ctx context.Context, userID string, page, limit int) (*model.PostListResponse, error) {
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, fmt.Errorf("无效的用户ID: %w", err)
 	}
-	
+
 	skip := (page - 1) * limit
-	
+
 	// 查询条件
-	filter := bson.M{
-		"user_id": userObjID,
-		"status": "draft",
+	filter := repository.PostFilter{
+		UserID: userObjID,
+		Status: "draft",
 	}
-	
+
 	// 获取总数
-	total, err := s.db.Collection("posts").CountDocuments(context.Background(), filter)
+	total, err := s.posts.Count(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("获取草稿总数失败: %w", err)
 	}
-	
+
 	// 获取草稿列表
-	cursor, err := s.db.Collection("posts").Find(
-		context.Background(),
-		filter,
-		options.Find().
-			SetSort(bson.M{"updated_at": -1}).
-			SetSkip(int64(skip)).
-			SetLimit(int64(limit)),
-	)
-	
+	drafts, err := s.posts.Find(ctx, filter, repository.SortByUpdatedAt, skip, limit)
 	if err != nil {
 		return nil, fmt.Errorf("查询草稿列表失败: %w", err)
 	}
-	
-	var drafts []model.Post
-	if err = cursor.All(context.Background(), &drafts); err != nil {
-		return nil, fmt.Errorf("解析草稿列表失败: %w", err)
-	}
-	
+
 	// 转换为响应格式
 	draftItems := make([]model.PostListItem, 0, len(drafts))
 	for _, draft := range drafts {
 		item := model.PostListItem{
-			ID:           draft.ID,
-			PostID:       draft.ID.Hex(),
-			Title:        draft.Title,
-			Content:      draft.Content,
-			Type:         draft.Type,
-			Tags:         draft.Tags,
-			Files:        draft.Files,
-			CoverImage:   draft.CoverImage,
-			UserID:       draft.UserID,
-			Username:     draft.Username,
-			Nickname:     draft.Nickname,
-			Avatar:       draft.Avatar,
-			CreatedAt:    draft.CreatedAt,
-			UpdatedAt:    draft.UpdatedAt,
+			ID:         draft.ID,
+			PostID:     draft.ID.Hex(),
+			Title:      draft.Title,
+			Content:    draft.Content,
+			Type:       draft.Type,
+			Tags:       draft.Tags,
+			Files:      draft.Files,
+			CoverImage: draft.CoverImage,
+			UserID:     draft.UserID,
+			Username:   draft.Username,
+			Nickname:   draft.Nickname,
+			Avatar:     draft.Avatar,
+			CreatedAt:  draft.CreatedAt,
+			UpdatedAt:  draft.UpdatedAt,
 		}
-		
+
 		// 如果 CoverImage 为空，则使用第一张图片作为封面
 		if item.CoverImage == "" && len(draft.Files) > 0 {
 			item.CoverImage = draft.Files[0]
 		}
-		
+
 		// 设置用户信息
 		item.User.UserID = draft.UserID.Hex()
 		item.User.Nickname = draft.Nickname
 		item.User.Avatar = draft.Avatar
-		
+
 		draftItems = append(draftItems, item)
 	}
-	
+
 	return &model.PostListResponse{
 		Total: int(total),
 		List:  draftItems,
@@ -903,146 +1053,107 @@ func (s *PostService) GetUserDrafts(userID string, page, limit int) (*model.Post
 }
 
 // GetDraftByID 获取草稿详情
-func (s *PostService) GetDraftByID(draftID, userID string) (*model.Post, error) {
+func (s *PostService) GetDraftByID(ctx context.Context, draftID, userID string) (*model.Post, error) {
 	draftObjID, err := primitive.ObjectIDFromHex(draftID)
 	if err != nil {
 		return nil, fmt.Errorf("无效的草稿ID: %w", err)
 	}
-	
+
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, fmt.Errorf("无效的用户ID: %w", err)
 	}
-	
+
 	// 查询草稿
-	var draft model.Post
-	err = s.db.Collection("posts").FindOne(
-		context.Background(),
-		bson.M{
-			"_id": draftObjID,
-			"user_id": userObjID,
-			"status": "draft",
-		},
-	).Decode(&draft)
-	
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("草稿不存在或不属于当前用户")
-		}
-		return nil, fmt.Errorf("查询草稿失败: %w", err)
-	}
-	
-	return &draft, nil
+	return s.findDraft(ctx, draftObjID, userObjID)
 }
 
 // DeleteDraft 删除草稿
-func (s *PostService) DeleteDraft(draftID, userID string) error {
+func (s *PostService) DeleteDraft(ctx context.Context, draftID, userID string) error {
 	draftObjID, err := primitive.ObjectIDFromHex(draftID)
 	if err != nil {
 		return fmt.Errorf("无效的草稿ID: %w", err)
 	}
-	
+
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return fmt.Errorf("无效的用户ID: %w", err)
 	}
-	
+
+	// 确保草稿属于当前用户
+	if _, err := s.findDraft(ctx, draftObjID, userObjID); err != nil {
+		return err
+	}
+
 	// 删除草稿
-	result, err := s.db.Collection("posts").DeleteOne(
-		context.Background(),
-		bson.M{
-			"_id": draftObjID,
-			"user_id": userObjID,
-			"status": "draft",
-		},
-	)
-	
+	err = s.posts.Delete(ctx, draftObjID)
+	if err == repository.ErrNotFound {
+		return apperr.ErrDraftNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("删除草稿失败: %w", err)
 	}
-	
-	if result.DeletedCount == 0 {
-		return fmt.Errorf("草稿不存在或不属于当前用户")
-	}
-	
+	s.cache.Delete(ctx, postCacheKey(draftObjID))
+
 	return nil
 }
 
 // PublishDraft 发布草稿
-func (s *PostService) PublishDraft(draftID, userID string, updateReq *model.UpdatePostRequest) (*model.Post, error) {
+func (s *PostService) PublishDraft(ctx context.Context, draftID, userID string, updateReq *model.UpdatePostRequest) (*model.Post, error) {
 	draftObjID, err := primitive.ObjectIDFromHex(draftID)
 	if err != nil {
 		return nil, fmt.Errorf("无效的草稿ID: %w", err)
 	}
-	
+
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, fmt.Errorf("无效的用户ID: %w", err)
 	}
-	
+
 	// 查询草稿
-	var draft model.Post
-	err = s.db.Collection("posts").FindOne(
-		context.Background(),
-		bson.M{
-			"_id": draftObjID,
-			"user_id": userObjID,
-			"status": "draft",
-		},
-	).Decode(&draft)
-	
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("草稿不存在或不属于当前用户")
-		}
-		return nil, fmt.Errorf("查询草稿失败: %w", err)
+	if _, err := s.findDraft(ctx, draftObjID, userObjID); err != nil {
+		return nil, err
 	}
-	
+
 	// 更新字段
-	update := bson.M{
-		"$set": bson.M{
-			"status":     "pending",
-			"updated_at": time.Now(),
-		},
+	status := "pending"
+	update := repository.PostUpdate{
+		Status:    &status,
+		UpdatedAt: time.Now(),
 	}
-	
+
 	// 如果提供了更新字段，则更新
 	if updateReq != nil {
 		if updateReq.Title != "" {
-			update["$set"].(bson.M)["title"] = updateReq.Title
+			update.Title = &updateReq.Title
 		}
 		if updateReq.Content != "" {
-			update["$set"].(bson.M)["content"] = updateReq.Content
+			update.Content = &updateReq.Content
 		}
 		if len(updateReq.Tags) > 0 {
-			update["$set"].(bson.M)["tags"] = updateReq.Tags
+			tags, err := s.resolveTags(ctx, updateReq.Tags)
+			if err != nil {
+				return nil, err
+			}
+			update.Tags = &tags
 		}
 		if len(updateReq.Files) > 0 {
-			update["$set"].(bson.M)["files"] = updateReq.Files
+			update.Files = &updateReq.Files
 		}
 		if updateReq.CoverImage != "" {
-			update["$set"].(bson.M)["cover_image"] = updateReq.CoverImage
+			update.CoverImage = &updateReq.CoverImage
 		}
 	}
-	
+
 	// 更新草稿状态为pending
-	_, err = s.db.Collection("posts").UpdateOne(
-		context.Background(),
-		bson.M{"_id": draftObjID},
-		update,
-	)
-	
-	if err != nil {
+	if err := s.posts.Update(ctx, draftObjID, update); err != nil {
 		return nil, fmt.Errorf("发布草稿失败: %w", err)
 	}
-	
+	s.cache.Delete(ctx, postCacheKey(draftObjID))
+	metrics.PostsCreated.WithLabelValues("draft").Inc()
+
 	// 获取更新后的帖子
-	var publishedPost model.Post
-	err = s.db.Collection("posts").FindOne(
-		context.Background(),
-		bson.M{"_id": draftObjID},
-	).Decode(&publishedPost)
-	
+	publishedPost, err := s.posts.FindByID(ctx, draftObjID)
 	if err != nil {
 		return nil, fmt.Errorf("获取发布后的帖子失败: %w", err)
 	}
@@ -1050,69 +1161,60 @@ func (s *PostService) PublishDraft(draftID, userID string, updateReq *model.Upda
 	// 标记文件为已使用状态
 	if s.fileService != nil {
 		files := publishedPost.Files
-		go func() {
-			err := s.fileService.MarkUsed(files)
+		s.tasks.Go(ctx, func(ctx context.Context) {
+			err := s.fileService.MarkUsed(ctx, files)
 			if err != nil {
-				log.Printf("标记文件为已使用状态失败: %v", err)
+				slog.ErrorContext(ctx, "标记文件为已使用状态失败", "error", err)
 			}
-		}()
+		})
 	}
 
-	return &publishedPost, nil
+	return publishedPost, nil
 }
 
-// GetPostListWithCursor 获取帖子列表（基于游标的分页）
-func (s *PostService) GetPostListWithCursor(query *model.CursorQuery) (*model.CursorBasedPostResponse, error) {
+// GetPostListWithCursor 获取帖子列表（基于游标的分页），viewerID 为空表示未登录
+func (s *PostService) GetPostListWithCursor(ctx context.Context, query *model.CursorQuery, viewerID string) (*model.CursorBasedPostResponse, error) {
 	// 设置默认值
 	if query.Limit < 1 {
 		query.Limit = 10
 	}
 
 	// 构建查询条件
-	filter := bson.M{"status": "approved"} // 默认只查询已审核通过的帖子
-	
-	if query.Type != "" {
-		filter["type"] = query.Type
+	filter := repository.PostFilter{
+		Status:        "approved", // 默认只查询已审核通过的帖子
+		ExcludeHidden: true,       // 排除因举报被隐藏的帖子
+		Type:          query.Type,
 	}
+
 	if query.Tag != "" {
-		filter["tags"] = query.Tag
-	}
-	if query.Status != "" {
-		filter["status"] = query.Status
-	}
-	if query.UserID != "" {
-		userID, err := primitive.ObjectIDFromHex(query.UserID)
+		tag, err := s.canonicalTag(ctx, query.Tag)
 		if err != nil {
 			return nil, err
 		}
-		filter["user_id"] = userID
+		filter.Tag = tag
+	}
+	if query.Status != "" {
+		filter.Status = query.Status
+	}
+	if err := s.applyAuthorFilter(ctx, &filter, query.UserID, viewerID); err != nil {
+		return nil, err
 	}
 
 	// 使用游标进行分页
 	if query.Cursor != "" {
 		cursorID, err := primitive.ObjectIDFromHex(query.Cursor)
 		if err != nil {
-			return nil, fmt.Errorf("无效的游标值: %w", err)
+			return nil, apperr.ErrInvalidCursor.Wrap(err)
 		}
 		// 查询比当前游标ID更早的数据（按创建时间降序排序）
-		filter["_id"] = bson.M{"$lt": cursorID}
+		filter.BeforeID = cursorID
 	}
 
-	// 查询数据
-	opts := options.Find().
-		SetLimit(int64(query.Limit + 1)). // 多查询一条数据，用于判断是否还有更多
-		SetSort(bson.D{{"_id", -1}})      // 按ID降序排序，等同于按创建时间降序
-
-	cursor, err := s.db.Collection("posts").Find(context.Background(), filter, opts)
+	// 查询数据，多查询一条数据用于判断是否还有更多；按ID降序排序，等同于按创建时间降序
+	posts, err := s.posts.Find(ctx, filter, repository.SortByID, 0, query.Limit+1)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-
-	var posts []*model.Post
-	if err = cursor.All(context.Background(), &posts); err != nil {
-		return nil, err
-	}
 
 	// 判断是否还有更多数据
 	hasMore := false
@@ -1123,6 +1225,22 @@ func (s *PostService) GetPostListWithCursor(query *model.CursorQuery) (*model.Cu
 	}
 
 	// 准备响应数据
+	postItems := buildPostItems(ctx, s.fileService, posts)
+
+	// 设置下一页游标
+	if hasMore && len(posts) > 0 {
+		nextCursor = posts[len(posts)-1].ID.Hex()
+	}
+
+	return &model.CursorBasedPostResponse{
+		Posts:      postItems,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// buildPostItems 将帖子转换为瀑布流列表项，并补充封面图宽高
+func buildPostItems(ctx context.Context, fileService *FileService, posts []*model.Post) []model.PostItem {
 	var postItems []model.PostItem
 	for _, post := range posts {
 		// 获取封面图片的宽高信息
@@ -1130,16 +1248,16 @@ func (s *PostService) GetPostListWithCursor(query *model.CursorQuery) (*model.Cu
 		if post.CoverImage != "" {
 			// 这里可以添加获取图片宽高的逻辑
 			// 可以从文件元数据服务获取，或者用其他方式计算
-			if s.fileService != nil {
-				w, h, err := s.fileService.GetImageDimensions(post.CoverImage)
+			if fileService != nil {
+				w, h, err := fileService.GetImageDimensions(ctx, post.CoverImage)
 				if err == nil {
 					width, height = w, h
 				}
 			}
 		} else if len(post.Files) > 0 {
 			// 如果没有设置封面图，使用第一张图片
-			if s.fileService != nil {
-				w, h, err := s.fileService.GetImageDimensions(post.Files[0])
+			if fileService != nil {
+				w, h, err := fileService.GetImageDimensions(ctx, post.Files[0])
 				if err == nil {
 					width, height = w, h
 				}
@@ -1162,6 +1280,7 @@ func (s *PostService) GetPostListWithCursor(query *model.CursorQuery) (*model.Cu
 			Avatar:    post.Avatar,
 			Likes:     post.Likes,
 			Comments:  post.Comments,
+			Views:     post.Views,
 			CreatedAt: post.CreatedAt,
 		}
 		
@@ -1173,14 +1292,5 @@ func (s *PostService) GetPostListWithCursor(query *model.CursorQuery) (*model.Cu
 		postItems = append(postItems, item)
 	}
 
-	// 设置下一页游标
-	if hasMore && len(posts) > 0 {
-		nextCursor = posts[len(posts)-1].ID.Hex()
-	}
-
-	return &model.CursorBasedPostResponse{
-		Posts:      postItems,
-		NextCursor: nextCursor,
-		HasMore:    hasMore,
-	}, nil
-} 
\ No newline at end of file
+	return postItems
+}
@@ -1,179 +1,240 @@
 package service
 
 import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/lifecycle"
 	"blue-note/model"
+	"blue-note/repository"
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProfileService struct {
-	db                  *mongo.Database
+	users                repository.UserRepo
+	posts                repository.PostRepo
+	follows              repository.FollowRepo
+	likes                repository.LikeRepo
+	tx                   repository.Transactor
 	objectStorageService *ObjectStorageService
+	feedService          *FeedService
+	cache                *cache.Group
+	tasks                *lifecycle.Tasks // 关注流回填等后台任务
 }
 
-func NewProfileService(db *mongo.Database, objectStorageService *ObjectStorageService) *ProfileService {
+func NewProfileService(repos *repository.Repositories, objectStorageService *ObjectStorageService, feedService *FeedService, cache *cache.Group, tasks *lifecycle.Tasks) *ProfileService {
 	return &ProfileService{
-		db:                  db,
+		users:                repos.Users,
+		posts:                repos.Posts,
+		follows:              repos.Follows,
+		likes:                repos.Likes,
+		tx:                   repos.Tx,
 		objectStorageService: objectStorageService,
+		feedService:          feedService,
+		cache:                cache,
+		tasks:                tasks,
 	}
 }
 
+// cachedProfile 缓存的用户资料，不含当前用户的关注、拉黑、屏蔽状态
+type cachedProfile struct {
+	Profile  model.ProfileResponse
+	Hidden   bool
+	Banned   bool
+	Language string
+}
+
 // GetDefaultAvatarURL 获取默认头像URL
 func (s *ProfileService) GetDefaultAvatarURL() string {
-	return fmt.Sprintf("https://%s/%s/static/default-avatar.jpg", 
-					  s.objectStorageService.GetExternalEndpoint(), 
-					  s.objectStorageService.GetBucketName())
+	return fmt.Sprintf("https://%s/%s/static/default-avatar.jpg",
+		s.objectStorageService.GetExternalEndpoint(),
+		s.objectStorageService.GetBucketName())
 }
 
 // GetUserProfile 获取用户资料
-func (s *ProfileService) GetUserProfile(userID string, currentUserID string) (*model.ProfileResponse, error) {
+func (s *ProfileService) GetUserProfile(ctx context.Context, userID string, currentUserID string) (*model.ProfileResponse, error) {
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	var user model.User
-	err = s.db.Collection("users").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&user)
+	cached, err := s.loadProfile(ctx, objectID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果用户没有头像，设置默认头像
-	if user.Avatar == "" {
-		user.Avatar = s.GetDefaultAvatarURL()
+	// 因举报被隐藏的用户仅本人可见
+	if cached.Hidden && currentUserID != userID {
+		return nil, apperr.ErrUserHidden
 	}
 
-	// 检查当前用户是否关注了该用户
-	isFollowing := false
+	// 检查当前用户是否关注、拉黑、屏蔽了该用户
+	isFollowing, isBlocked, isMuted := false, false, false
 	if currentUserID != "" && currentUserID != userID {
 		currentUserObjectID, err := primitive.ObjectIDFromHex(currentUserID)
 		if err == nil {
-			count, err := s.db.Collection("user_follows").CountDocuments(
-				context.Background(),
-				bson.M{
-					"user_id":      currentUserObjectID,
-					"following_id": objectID,
-				},
-			)
-			if err == nil && count > 0 {
-				isFollowing = true
-			}
+			isFollowing, _ = s.follows.IsFollowing(ctx, currentUserObjectID, objectID)
+			isBlocked, _ = s.follows.HasBlocked(ctx, currentUserObjectID, objectID)
+			isMuted, _ = s.follows.IsMuted(ctx, currentUserObjectID, objectID)
 		}
 	}
 
-	return &model.ProfileResponse{
-		UserID:       user.ID.Hex(),
-		Username:     user.Username,
-		Nickname:     user.Nickname,
-		Avatar:       user.Avatar,
-		Bio:          user.Bio,
-		Gender:       user.Gender,
-		Birthday:     user.Birthday,
-		Location:     user.Location,
-		Status:       user.Status,
-		FollowCount:  user.FollowCount,
-		FansCount:    user.FansCount,
-		LikeCount:    user.LikeCount,
-		CollectCount: user.CollectCount,
-		PostCount:    user.PostCount,
-		IsFollowing:  isFollowing,
-	}, nil
+	profile := cached.Profile
+	profile.IsFollowing = isFollowing
+	profile.IsBlocked = isBlocked
+	profile.IsMuted = isMuted
+	if currentUserID == userID {
+		profile.Language = cached.Language
+	}
+	return &profile, nil
+}
+
+// IsBanned 检查账号是否被封禁，供认证中间件在每次请求时校验
+func (s *ProfileService) IsBanned(ctx context.Context, userID string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, err
+	}
+	cached, err := s.loadProfile(ctx, objectID)
+	if err != nil {
+		return false, err
+	}
+	return cached.Banned, nil
+}
+
+// loadProfile 读取缓存的用户资料，缓存未命中时查询数据库
+func (s *ProfileService) loadProfile(ctx context.Context, objectID primitive.ObjectID) (*cachedProfile, error) {
+	ttl := cacheTTL(config.GetConfig().Cache.ProfileTTL)
+	return cache.Fetch(ctx, s.cache, profileCacheKey(objectID), ttl, func(ctx context.Context) (*cachedProfile, error) {
+		user, err := s.users.FindByID(ctx, objectID)
+		if err == repository.ErrNotFound {
+			return nil, apperr.ErrUserNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// 如果用户没有头像，设置默认头像
+		if user.Avatar == "" {
+			user.Avatar = s.GetDefaultAvatarURL()
+		}
+
+		return &cachedProfile{
+			Profile: model.ProfileResponse{
+				UserID:       user.ID.Hex(),
+				Username:     user.Username,
+				Nickname:     user.Nickname,
+				Avatar:       user.Avatar,
+				Bio:          user.Bio,
+				Gender:       user.Gender,
+				Birthday:     user.Birthday,
+				Location:     user.Location,
+				Status:       user.Status,
+				FollowCount:  user.FollowCount,
+				FansCount:    user.FansCount,
+				LikeCount:    user.LikeCount,
+				CollectCount: user.CollectCount,
+				PostCount:    user.PostCount,
+			},
+			Hidden:   user.Hidden,
+			Banned:   user.Banned,
+			Language: user.Language,
+		}, nil
+	})
 }
 
 // UpdateProfileWithAvatar 更新用户资料和头像
-func (s *ProfileService) UpdateProfileWithAvatar(userID string, req *model.UpdateProfileRequest, avatarFile io.Reader) (*model.ProfileResponse, error) {
+func (s *ProfileService) UpdateProfileWithAvatar(ctx context.Context, userID string, req *model.UpdateProfileRequest, avatarFile io.Reader) (*model.ProfileResponse, error) {
 	// 验证用户ID格式
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, fmt.Errorf("无效的用户ID格式3: %w", err)
+		return nil, err
 	}
 
-	update := bson.M{
-		"updated_at": time.Now(),
+	update := repository.UserUpdate{
+		UpdatedAt: time.Now(),
 	}
 
 	// 处理基本资料更新
 	if req.Username != "" {
 		// 检查用户名是否已被占用
-		count, err := s.db.Collection("users").CountDocuments(
-			context.Background(),
-			bson.M{
-				"username": req.Username,
-				"_id":      bson.M{"$ne": objectID},
-			},
-		)
+		taken, err := s.users.UsernameTaken(ctx, req.Username, objectID)
 		if err != nil {
 			return nil, err
 		}
-		if count > 0 {
-			return nil, fmt.Errorf("用户名已被占用")
+		if taken {
+			return nil, apperr.ErrUsernameTaken
 		}
-		update["username"] = req.Username
+		update.Username = &req.Username
 	}
 
 	if req.Nickname != "" {
-		update["nickname"] = req.Nickname
+		update.Nickname = &req.Nickname
 	}
-	
+
 	if req.Bio != "" {
-		update["bio"] = req.Bio
+		update.Bio = &req.Bio
 	}
-	
+
 	if req.Gender != "" {
-		update["gender"] = req.Gender
+		update.Gender = &req.Gender
 	}
-	
+
 	if req.Birthday != "" {
-		update["birthday"] = req.Birthday
+		update.Birthday = &req.Birthday
 	}
-	
+
 	if req.Location != "" {
-		update["location"] = req.Location
+		update.Location = &req.Location
 	}
-	
+
 	if req.Status != "" {
-		update["status"] = req.Status
+		update.Status = &req.Status
 	}
-	
+
+	if req.Language != "" {
+		update.Language = &req.Language
+	}
+
 	// 处理头像上传
 	if avatarFile != nil {
 		// 尝试上传到对象存储
-		avatarURL, err := s.objectStorageService.UploadAvatar(userID, avatarFile)
+		avatarURL, err := s.objectStorageService.UploadAvatar(ctx, userID, avatarFile)
 		if err != nil {
 			// 如果上传失败，记录错误但不中断流程
-			fmt.Printf("头像上传失败: %v，将使用默认头像\n", err)
-			update["avatar"] = s.GetDefaultAvatarURL()
+			slog.WarnContext(ctx, "头像上传失败，将使用默认头像", "user_id", userID, "error", err)
+			avatarURL = s.GetDefaultAvatarURL()
 		} else {
-			update["avatar"] = avatarURL
-			fmt.Printf("头像上传成功，URL: %s\n", avatarURL)
+			slog.InfoContext(ctx, "头像上传成功", "user_id", userID, "url", avatarURL)
 		}
+		update.Avatar = &avatarURL
 	} else if req.Avatar != "" {
-		update["avatar"] = req.Avatar
+		update.Avatar = &req.Avatar
 	}
 
 	// 更新数据库
-	_, err = s.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		bson.M{"$set": update},
-	)
+	err = s.users.Update(ctx, objectID, update)
+	if err == repository.ErrDuplicate {
+		return nil, apperr.ErrUsernameTaken
+	}
 	if err != nil {
 		return nil, err
 	}
+	s.cache.Delete(ctx, profileCacheKey(objectID))
 
 	// 获取更新后的用户信息
-	return s.GetUserProfile(userID, userID)
+	return s.GetUserProfile(ctx, userID, userID)
 }
 
 // FollowUser 关注用户
-func (s *ProfileService) FollowUser(userID string, followingID string) (map[string]interface{}, error) {
+func (s *ProfileService) FollowUser(ctx context.Context, userID string, followingID string) (map[string]interface{}, error) {
 	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
@@ -184,75 +245,47 @@ func (s *ProfileService) FollowUser(userID string, followingID string) (map[stri
 		return nil, err
 	}
 
-	// 检查是否已经关注
-	count, err := s.db.Collection("user_follows").CountDocuments(
-		context.Background(),
-		bson.M{
-			"user_id":      userObjectID,
-			"following_id": followingObjectID,
-		},
-	)
+	// 存在拉黑关系时不能关注
+	blocked, err := s.follows.HasBlocked(ctx, followingObjectID, userObjectID)
 	if err != nil {
 		return nil, err
 	}
-	if count > 0 {
-		return nil, fmt.Errorf("已经关注该用户")
-	}
-
-	// 创建关注关系
-	follow := model.UserFollow{
-		UserID:      userObjectID,
-		FollowingID: followingObjectID,
-		CreatedAt:   time.Now(),
+	if blocked {
+		return nil, apperr.ErrBlockedByUser
 	}
-
-	result, err := s.db.Collection("user_follows").InsertOne(context.Background(), follow)
+	blocked, err = s.follows.HasBlocked(ctx, userObjectID, followingObjectID)
 	if err != nil {
 		return nil, err
 	}
-
-	// 更新关注数和粉丝数
-	_, err = s.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": userObjectID},
-		bson.M{"$inc": bson.M{"follow_count": 1}},
-	)
-	if err != nil {
-		return nil, err
+	if blocked {
+		return nil, apperr.ErrUnblockFirst
 	}
 
-	_, err = s.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": followingObjectID},
-		bson.M{"$inc": bson.M{"fans_count": 1}},
-	)
+	followID, created, err := s.addFollow(ctx, userObjectID, followingObjectID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取更新后的关注数和粉丝数
-	var user model.User
-	err = s.db.Collection("users").FindOne(context.Background(), bson.M{"_id": userObjectID}).Decode(&user)
-	if err != nil {
-		return nil, err
+	// 将被关注者最近的笔记回填到关注流
+	if created && s.feedService != nil {
+		s.tasks.Go(ctx, func(ctx context.Context) {
+			if err := s.feedService.Backfill(ctx, userObjectID, followingObjectID); err != nil {
+				slog.ErrorContext(ctx, "回填关注流失败", "user_id", userObjectID.Hex(), "author_id", followingObjectID.Hex(), "error", err)
+			}
+		})
 	}
 
-	var followingUser model.User
-	err = s.db.Collection("users").FindOne(context.Background(), bson.M{"_id": followingObjectID}).Decode(&followingUser)
+	result, err := s.followState(ctx, userObjectID, followingObjectID, true)
 	if err != nil {
 		return nil, err
 	}
-
-	return map[string]interface{}{
-		"followId":       result.InsertedID.(primitive.ObjectID).Hex(),
-		"followingUserId": followingID,
-		"followCount":    user.FollowCount,
-		"fansCount":      followingUser.FansCount,
-	}, nil
+	result["followId"] = followID.Hex()
+	result["followingUserId"] = followingID
+	return result, nil
 }
 
 // UnfollowUser 取消关注用户
-func (s *ProfileService) UnfollowUser(userID string, followingID string) (map[string]interface{}, error) {
+func (s *ProfileService) UnfollowUser(ctx context.Context, userID string, followingID string) (map[string]interface{}, error) {
 	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
@@ -263,88 +296,73 @@ func (s *ProfileService) UnfollowUser(userID string, followingID string) (map[st
 		return nil, err
 	}
 
-	// 删除关注关系
-	result, err := s.db.Collection("user_follows").DeleteOne(
-		context.Background(),
-		bson.M{
-			"user_id":      userObjectID,
-			"following_id": followingObjectID,
-		},
-	)
-	if err != nil {
+	if _, err := s.removeFollow(ctx, userObjectID, followingObjectID); err != nil {
 		return nil, err
 	}
 
-	if result.DeletedCount == 0 {
-		return nil, fmt.Errorf("未关注该用户")
-	}
+	return s.followState(ctx, userObjectID, followingObjectID, false)
+}
 
-	// 更新关注数和粉丝数
-	_, err = s.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": userObjectID},
-		bson.M{"$inc": bson.M{"follow_count": -1}},
-	)
+// CheckFollowStatus 检查关注状态
+func (s *ProfileService) CheckFollowStatus(ctx context.Context, userID string, followingID string) (bool, error) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	_, err = s.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": followingObjectID},
-		bson.M{"$inc": bson.M{"fans_count": -1}},
-	)
+	followingObjectID, err := primitive.ObjectIDFromHex(followingID)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	// 获取更新后的关注数和粉丝数
-	var user model.User
-	err = s.db.Collection("users").FindOne(context.Background(), bson.M{"_id": userObjectID}).Decode(&user)
-	if err != nil {
-		return nil, err
-	}
+	return s.follows.IsFollowing(ctx, userObjectID, followingObjectID)
+}
 
-	var followingUser model.User
-	err = s.db.Collection("users").FindOne(context.Background(), bson.M{"_id": followingObjectID}).Decode(&followingUser)
+// followingSet 返回 currentUserID 关注的用户集合，among 不为空时只在其中查找；未登录时返回 nil
+func (s *ProfileService) followingSet(ctx context.Context, currentUserID string, among []primitive.ObjectID) map[primitive.ObjectID]bool {
+	if currentUserID == "" {
+		return nil
+	}
+	currentUserObjectID, err := primitive.ObjectIDFromHex(currentUserID)
 	if err != nil {
-		return nil, err
+		return nil
 	}
 
-	return map[string]interface{}{
-		"followCount": user.FollowCount,
-		"fansCount":   followingUser.FansCount,
-	}, nil
-}
-
-// CheckFollowStatus 检查关注状态
-func (s *ProfileService) CheckFollowStatus(userID string, followingID string) (bool, error) {
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	ids, err := s.follows.FollowingIDs(ctx, currentUserObjectID, among)
 	if err != nil {
-		return false, err
+		return nil
 	}
 
-	followingObjectID, err := primitive.ObjectIDFromHex(followingID)
-	if err != nil {
-		return false, err
+	set := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
 	}
+	return set
+}
 
-	count, err := s.db.Collection("user_follows").CountDocuments(
-		context.Background(),
-		bson.M{
-			"user_id":      userObjectID,
-			"following_id": followingObjectID,
-		},
-	)
+// toUserList 按 ids 的顺序查询用户并构建用户列表，following 为当前用户关注的用户集合
+func (s *ProfileService) toUserList(ctx context.Context, ids []primitive.ObjectID, following map[primitive.ObjectID]bool, allFollowing bool) ([]model.UserListItem, error) {
+	users, err := s.users.FindByIDs(ctx, ids)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return count > 0, nil
+	list := make([]model.UserListItem, 0, len(users))
+	for _, user := range users {
+		list = append(list, model.UserListItem{
+			UserID:      user.ID.Hex(),
+			Username:    user.Username,
+			Nickname:    user.Nickname,
+			Avatar:      user.Avatar,
+			Bio:         user.Bio,
+			IsFollowing: allFollowing || following[user.ID],
+		})
+	}
+	return list, nil
 }
 
 // GetFollowingList 获取关注列表
-func (s *ProfileService) GetFollowingList(userID string, currentUserID string, page, limit int) (*model.UserListResponse, error) {
+func (s *ProfileService) GetFollowingList(ctx context.Context, userID string, currentUserID string, page, limit int) (*model.UserListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -358,31 +376,13 @@ func (s *ProfileService) GetFollowingList(userID string, currentUserID string, p
 	}
 
 	// 查询用户关注的用户ID列表
-	opts := options.Find().
-		SetSkip(int64((page - 1) * limit)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	cursor, err := s.db.Collection("user_follows").Find(
-		context.Background(),
-		bson.M{"user_id": userObjectID},
-		opts,
-	)
+	follows, err := s.follows.ListFollowing(ctx, userObjectID, (page-1)*limit, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-
-	var follows []model.UserFollow
-	if err = cursor.All(context.Background(), &follows); err != nil {
-		return nil, err
-	}
 
 	// 获取关注用户总数
-	total, err := s.db.Collection("user_follows").CountDocuments(
-		context.Background(),
-		bson.M{"user_id": userObjectID},
-	)
+	total, err := s.follows.CountFollowing(ctx, userObjectID)
 	if err != nil {
 		return nil, err
 	}
@@ -401,69 +401,17 @@ func (s *ProfileService) GetFollowingList(userID string, currentUserID string, p
 		followingIDs = append(followingIDs, follow.FollowingID)
 	}
 
-	// 查询被关注用户信息
-	userCursor, err := s.db.Collection("users").Find(
-		context.Background(),
-		bson.M{"_id": bson.M{"$in": followingIDs}},
-	)
-	if err != nil {
-		return nil, err
+	// 如果是查询自己的关注列表，则所有用户都是已关注的；否则检查当前用户是否关注了这些用户
+	var following map[primitive.ObjectID]bool
+	if currentUserID != userID {
+		following = s.followingSet(ctx, currentUserID, followingIDs)
 	}
-	defer userCursor.Close(context.Background())
 
-	var users []model.User
-	if err = userCursor.All(context.Background(), &users); err != nil {
+	list, err := s.toUserList(ctx, followingIDs, following, currentUserID == userID)
+	if err != nil {
 		return nil, err
 	}
 
-	// 检查当前用户是否关注了这些用户
-	var currentUserFollowingIDs []primitive.ObjectID
-	if currentUserID != "" && currentUserID != userID {
-		currentUserObjectID, err := primitive.ObjectIDFromHex(currentUserID)
-		if err == nil {
-			followCursor, err := s.db.Collection("user_follows").Find(
-				context.Background(),
-				bson.M{"user_id": currentUserObjectID},
-			)
-			if err == nil {
-				defer followCursor.Close(context.Background())
-				var currentUserFollows []model.UserFollow
-				if err = followCursor.All(context.Background(), &currentUserFollows); err == nil {
-					for _, follow := range currentUserFollows {
-						currentUserFollowingIDs = append(currentUserFollowingIDs, follow.FollowingID)
-					}
-				}
-			}
-		}
-	}
-
-	// 构建响应
-	var list []model.UserListItem
-	for _, user := range users {
-		isFollowing := false
-		if currentUserID == userID {
-			// 如果是查询自己的关注列表，则所有用户都是已关注的
-			isFollowing = true
-		} else if len(currentUserFollowingIDs) > 0 {
-			// 检查当前用户是否关注了该用户
-			for _, id := range currentUserFollowingIDs {
-				if id == user.ID {
-					isFollowing = true
-					break
-				}
-			}
-		}
-
-		list = append(list, model.UserListItem{
-			UserID:      user.ID.Hex(),
-			Username:    user.Username,
-			Nickname:    user.Nickname,
-			Avatar:      user.Avatar,
-			Bio:         user.Bio,
-			IsFollowing: isFollowing,
-		})
-	}
-
 	return &model.UserListResponse{
 		Total: int(total),
 		List:  list,
@@ -471,7 +419,12 @@ func (s *ProfileService) GetFollowingList(userID string, currentUserID string, p
 }
 
 // GetFollowersList 获取粉丝列表
-func (s *ProfileService) GetFollowersList(userID string, currentUserID string, page, limit int) (*model.UserListResponse, error) {
+func (s *ProfileService) GetFollowersList(ctx context.Context, userID string, currentUserID string, page, limit int) (*model.UserListResponse, error) {
+	return s.GetFansList(ctx, userID, currentUserID, page, limit)
+}
+
+// GetUserLikedPosts 获取用户喜欢的笔记
+func (s *ProfileService) GetUserLikedPosts(ctx context.Context, userID string, page, limit int) (*model.PostListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -484,118 +437,23 @@ func (s *ProfileService) GetFollowersList(userID string, currentUserID string, p
 		return nil, err
 	}
 
-	// 查询关注该用户的用户ID列表
-	opts := options.Find().
-		SetSkip(int64((page - 1) * limit)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	cursor, err := s.db.Collection("user_follows").Find(
-		context.Background(),
-		bson.M{"following_id": userObjectID},
-		opts,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.Background())
-
-	var follows []model.UserFollow
-	if err = cursor.All(context.Background(), &follows); err != nil {
-		return nil, err
-	}
-
-	// 获取粉丝总数
-	total, err := s.db.Collection("user_follows").CountDocuments(
-		context.Background(),
-		bson.M{"following_id": userObjectID},
-	)
+	// 查询用户喜欢的笔记ID
+	postIDs, err := s.likes.LikedPostIDs(ctx, userObjectID, (page-1)*limit, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果没有粉丝，返回空列表
-	if len(follows) == 0 {
-		return &model.UserListResponse{
-			Total: int(total),
-			List:  []model.UserListItem{},
-		}, nil
-	}
-
-	// 提取粉丝用户ID
-	var followerIDs []primitive.ObjectID
-	for _, follow := range follows {
-		followerIDs = append(followerIDs, follow.UserID)
-	}
-
-	// 查询粉丝用户信息
-	userCursor, err := s.db.Collection("users").Find(
-		context.Background(),
-		bson.M{"_id": bson.M{"$in": followerIDs}},
-	)
+	// 获取喜欢的笔记总数
+	total, err := s.likes.CountLikedPosts(ctx, userObjectID)
 	if err != nil {
 		return nil, err
 	}
-	defer userCursor.Close(context.Background())
 
-	var users []model.User
-	if err = userCursor.All(context.Background(), &users); err != nil {
-		return nil, err
-	}
-
-	// 检查当前用户是否关注了这些粉丝
-	var currentUserFollowingIDs []primitive.ObjectID
-	if currentUserID != "" {
-		currentUserObjectID, err := primitive.ObjectIDFromHex(currentUserID)
-		if err == nil {
-			followCursor, err := s.db.Collection("user_follows").Find(
-				context.Background(),
-				bson.M{"user_id": currentUserObjectID},
-			)
-			if err == nil {
-				defer followCursor.Close(context.Background())
-				var currentUserFollows []model.UserFollow
-				if err = followCursor.All(context.Background(), &currentUserFollows); err == nil {
-					for _, follow := range currentUserFollows {
-						currentUserFollowingIDs = append(currentUserFollowingIDs, follow.FollowingID)
-					}
-				}
-			}
-		}
-	}
-
-	// 构建响应
-	var list []model.UserListItem
-	for _, user := range users {
-		isFollowing := false
-		if len(currentUserFollowingIDs) > 0 {
-			// 检查当前用户是否关注了该粉丝
-			for _, id := range currentUserFollowingIDs {
-				if id == user.ID {
-					isFollowing = true
-					break
-				}
-			}
-		}
-
-		list = append(list, model.UserListItem{
-			UserID:      user.ID.Hex(),
-			Username:    user.Username,
-			Nickname:    user.Nickname,
-			Avatar:      user.Avatar,
-			Bio:         user.Bio,
-			IsFollowing: isFollowing,
-		})
-	}
-
-	return &model.UserListResponse{
-		Total: int(total),
-		List:  list,
-	}, nil
+	return s.toPostList(ctx, postIDs, total)
 }
 
-// GetUserLikedPosts 获取用户喜欢的笔记
-func (s *ProfileService) GetUserLikedPosts(userID string, page, limit int) (*model.PostListResponse, error) {
+// GetUserCollectedPosts 获取用户收藏的笔记
+func (s *ProfileService) GetUserCollectedPosts(ctx context.Context, userID string, page, limit int) (*model.PostListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -608,79 +466,36 @@ func (s *ProfileService) GetUserLikedPosts(userID string, page, limit int) (*mod
 		return nil, err
 	}
 
-	// 查询用户喜欢的笔记ID
-	opts := options.Find().
-		SetSkip(int64((page - 1) * limit)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	cursor, err := s.db.Collection("post_likes").Find(
-		context.Background(),
-		bson.M{"user_id": userObjectID},
-		opts,
-	)
+	// 查询用户收藏的笔记ID
+	postIDs, err := s.likes.CollectedPostIDs(ctx, userObjectID, (page-1)*limit, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-
-	var likes []struct {
-		PostID    primitive.ObjectID `bson:"post_id"`
-		CreatedAt time.Time          `bson:"created_at"`
-	}
-	if err = cursor.All(context.Background(), &likes); err != nil {
-		return nil, err
-	}
 
-	// 获取喜欢的笔记总数
-	total, err := s.db.Collection("post_likes").CountDocuments(
-		context.Background(),
-		bson.M{"user_id": userObjectID},
-	)
+	// 获取收藏的笔记总数
+	total, err := s.likes.CountCollectedPosts(ctx, userObjectID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果没有喜欢的笔记，返回空列表
-	if len(likes) == 0 {
+	return s.toPostList(ctx, postIDs, total)
+}
+
+// toPostList 查询笔记信息并构建点赞、收藏列表
+func (s *ProfileService) toPostList(ctx context.Context, postIDs []primitive.ObjectID, total int64) (*model.PostListResponse, error) {
+	// 如果没有笔记，返回空列表
+	if len(postIDs) == 0 {
 		return &model.PostListResponse{
 			Total: int(total),
 			List:  []model.PostListItem{},
 		}, nil
 	}
 
-	// 提取笔记ID
-	var postIDs []primitive.ObjectID
-	for _, like := range likes {
-		postIDs = append(postIDs, like.PostID)
-	}
-
 	// 查询笔记信息
-	postCursor, err := s.db.Collection("posts").Find(
-		context.Background(),
-		bson.M{"_id": bson.M{"$in": postIDs}},
-	)
+	posts, err := s.posts.FindByIDs(ctx, postIDs)
 	if err != nil {
 		return nil, err
 	}
-	defer postCursor.Close(context.Background())
-
-	var posts []struct {
-		ID           primitive.ObjectID `bson:"_id"`
-		Title        string             `bson:"title"`
-		Files        []string           `bson:"files"`
-		UserID       primitive.ObjectID `bson:"user_id"`
-		Username     string             `bson:"username"`
-		Nickname     string             `bson:"nickname"`
-		Avatar       string             `bson:"avatar"`
-		Likes        int                `bson:"likes"`
-		Comments     int                `bson:"comments"`
-		Collections  int                `bson:"collections"`
-		CreatedAt    time.Time          `bson:"created_at"`
-	}
-	if err = postCursor.All(context.Background(), &posts); err != nil {
-		return nil, err
-	}
 
 	// 构建响应
 	var list []model.PostListItem
@@ -690,7 +505,7 @@ func (s *ProfileService) GetUserLikedPosts(userID string, page, limit int) (*mod
 			coverImage = post.Files[0]
 		}
 
-		list = append(list, model.PostListItem{
+		item := model.PostListItem{
 			PostID:       post.ID.Hex(),
 			Title:        post.Title,
 			CoverImage:   coverImage,
@@ -698,16 +513,12 @@ func (s *ProfileService) GetUserLikedPosts(userID string, page, limit int) (*mod
 			CommentCount: post.Comments,
 			CollectCount: post.Collections,
 			CreatedAt:    post.CreatedAt,
-			User: struct {
-				UserID   string `json:"userId"`
-				Nickname string `json:"nickname"`
-				Avatar   string `json:"avatar"`
-			}{
-				UserID:   post.UserID.Hex(),
-				Nickname: post.Nickname,
-				Avatar:   post.Avatar,
-			},
-		})
+		}
+		item.User.UserID = post.UserID.Hex()
+		item.User.Nickname = post.Nickname
+		item.User.Avatar = post.Avatar
+
+		list = append(list, item)
 	}
 
 	return &model.PostListResponse{
@@ -716,8 +527,8 @@ func (s *ProfileService) GetUserLikedPosts(userID string, page, limit int) (*mod
 	}, nil
 }
 
-// GetUserCollectedPosts 获取用户收藏的笔记
-func (s *ProfileService) GetUserCollectedPosts(userID string, page, limit int) (*model.PostListResponse, error) {
+// GetFansList 获取粉丝列表
+func (s *ProfileService) GetFansList(ctx context.Context, userID string, currentUserID string, page, limit int) (*model.UserListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -730,116 +541,206 @@ func (s *ProfileService) GetUserCollectedPosts(userID string, page, limit int) (
 		return nil, err
 	}
 
-	// 查询用户收藏的笔记ID
-	opts := options.Find().
-		SetSkip(int64((page - 1) * limit)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
+	// 查询关注该用户的用户ID列表
+	follows, err := s.follows.ListFollowers(ctx, userObjectID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
 
-	cursor, err := s.db.Collection("post_collections").Find(
-		context.Background(),
-		bson.M{"user_id": userObjectID},
-		opts,
-	)
+	// 获取粉丝总数
+	total, err := s.follows.CountFollowers(ctx, userObjectID)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
 
-	var collections []struct {
-		PostID    primitive.ObjectID `bson:"post_id"`
-		CreatedAt time.Time          `bson:"created_at"`
+	// 如果没有粉丝，返回空列表
+	if len(follows) == 0 {
+		return &model.UserListResponse{
+			Total: int(total),
+			List:  []model.UserListItem{},
+		}, nil
 	}
-	if err = cursor.All(context.Background(), &collections); err != nil {
-		return nil, err
+
+	// 提取粉丝用户ID
+	var fanIDs []primitive.ObjectID
+	for _, follow := range follows {
+		fanIDs = append(fanIDs, follow.UserID)
 	}
 
-	// 获取收藏的笔记总数
-	total, err := s.db.Collection("post_collections").CountDocuments(
-		context.Background(),
-		bson.M{"user_id": userObjectID},
-	)
+	// 检查当前用户是否关注了这些粉丝
+	list, err := s.toUserList(ctx, fanIDs, s.followingSet(ctx, currentUserID, fanIDs), false)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果没有收藏的笔记，返回空列表
-	if len(collections) == 0 {
-		return &model.PostListResponse{
-			Total: int(total),
-			List:  []model.PostListItem{},
-		}, nil
+	return &model.UserListResponse{
+		Total: int(total),
+		List:  list,
+	}, nil
+}
+
+// IsObjectStorageAvailable 检查对象存储服务是否可用
+func (s *ProfileService) IsObjectStorageAvailable() bool {
+	return s.objectStorageService != nil && s.objectStorageService.IsAvailable()
+}
+
+// BlockUser 拉黑用户，同时解除双方的关注关系
+func (s *ProfileService) BlockUser(ctx context.Context, userID string, blockedID string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
 	}
 
-	// 提取笔记ID
-	var postIDs []primitive.ObjectID
-	for _, collection := range collections {
-		postIDs = append(postIDs, collection.PostID)
+	blockedObjectID, err := primitive.ObjectIDFromHex(blockedID)
+	if err != nil {
+		return err
 	}
 
-	// 查询笔记信息
-	postCursor, err := s.db.Collection("posts").Find(
-		context.Background(),
-		bson.M{"_id": bson.M{"$in": postIDs}},
-	)
+	if userObjectID == blockedObjectID {
+		return apperr.ErrBlockSelf
+	}
+
+	// 检查被拉黑用户是否存在
+	exists, err := s.users.Exists(ctx, blockedObjectID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if !exists {
+		return apperr.ErrUserNotFound
 	}
-	defer postCursor.Close(context.Background())
 
-	var posts []struct {
-		ID           primitive.ObjectID `bson:"_id"`
-		Title        string             `bson:"title"`
-		Files        []string           `bson:"files"`
-		UserID       primitive.ObjectID `bson:"user_id"`
-		Username     string             `bson:"username"`
-		Nickname     string             `bson:"nickname"`
-		Avatar       string             `bson:"avatar"`
-		Likes        int                `bson:"likes"`
-		Comments     int                `bson:"comments"`
-		Collections  int                `bson:"collections"`
-		CreatedAt    time.Time          `bson:"created_at"`
+	block := &model.UserBlock{
+		UserID:    userObjectID,
+		BlockedID: blockedObjectID,
+		CreatedAt: time.Now(),
 	}
-	if err = postCursor.All(context.Background(), &posts); err != nil {
-		return nil, err
+	err = s.follows.Block(ctx, block)
+	if err == repository.ErrDuplicate {
+		return apperr.ErrAlreadyBlocked
+	}
+	if err != nil {
+		return err
 	}
 
-	// 构建响应
-	var list []model.PostListItem
-	for _, post := range posts {
-		coverImage := ""
-		if len(post.Files) > 0 {
-			coverImage = post.Files[0]
-		}
+	// 拉黑是双向隐藏，双方的关注流都需要重新生成
+	s.cache.Delete(ctx, feedGenerationKey(userObjectID), feedGenerationKey(blockedObjectID))
 
-		list = append(list, model.PostListItem{
-			PostID:       post.ID.Hex(),
-			Title:        post.Title,
-			CoverImage:   coverImage,
-			LikeCount:    post.Likes,
-			CommentCount: post.Comments,
-			CollectCount: post.Collections,
-			CreatedAt:    post.CreatedAt,
-			User: struct {
-				UserID   string `json:"userId"`
-				Nickname string `json:"nickname"`
-				Avatar   string `json:"avatar"`
-			}{
-				UserID:   post.UserID.Hex(),
-				Nickname: post.Nickname,
-				Avatar:   post.Avatar,
-			},
-		})
+	// 解除双方的关注关系
+	if _, err := s.removeFollow(ctx, userObjectID, blockedObjectID); err != nil {
+		return err
 	}
+	_, err = s.removeFollow(ctx, blockedObjectID, userObjectID)
+	return err
+}
 
-	return &model.PostListResponse{
-		Total: int(total),
-		List:  list,
-	}, nil
+// UnblockUser 取消拉黑
+func (s *ProfileService) UnblockUser(ctx context.Context, userID string, blockedID string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	blockedObjectID, err := primitive.ObjectIDFromHex(blockedID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.follows.Unblock(ctx, userObjectID, blockedObjectID)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return apperr.ErrNotBlocked
+	}
+	s.cache.Delete(ctx, feedGenerationKey(userObjectID), feedGenerationKey(blockedObjectID))
+
+	return nil
 }
 
-// GetFansList 获取粉丝列表
-func (s *ProfileService) GetFansList(userID string, currentUserID string, page, limit int) (*model.UserListResponse, error) {
+// MuteUser 屏蔽用户
+func (s *ProfileService) MuteUser(ctx context.Context, userID string, mutedID string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	mutedObjectID, err := primitive.ObjectIDFromHex(mutedID)
+	if err != nil {
+		return err
+	}
+
+	if userObjectID == mutedObjectID {
+		return apperr.ErrMuteSelf
+	}
+
+	// 检查被屏蔽用户是否存在
+	exists, err := s.users.Exists(ctx, mutedObjectID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apperr.ErrUserNotFound
+	}
+
+	mute := &model.UserMute{
+		UserID:    userObjectID,
+		MutedID:   mutedObjectID,
+		CreatedAt: time.Now(),
+	}
+	err = s.follows.Mute(ctx, mute)
+	if err == repository.ErrDuplicate {
+		return apperr.ErrAlreadyMuted
+	}
+	if err != nil {
+		return err
+	}
+	s.cache.Delete(ctx, feedGenerationKey(userObjectID))
+	return nil
+}
+
+// UnmuteUser 取消屏蔽
+func (s *ProfileService) UnmuteUser(ctx context.Context, userID string, mutedID string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	mutedObjectID, err := primitive.ObjectIDFromHex(mutedID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.follows.Unmute(ctx, userObjectID, mutedObjectID)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return apperr.ErrNotMuted
+	}
+	s.cache.Delete(ctx, feedGenerationKey(userObjectID))
+
+	return nil
+}
+
+// GetBlockedList 获取拉黑列表
+func (s *ProfileService) GetBlockedList(ctx context.Context, userID string, page, limit int) (*model.UserListResponse, error) {
+	return s.getRelationList(ctx, s.follows.ListBlocked, s.follows.CountBlocked, userID, page, limit)
+}
+
+// GetMutedList 获取屏蔽列表
+func (s *ProfileService) GetMutedList(ctx context.Context, userID string, page, limit int) (*model.UserListResponse, error) {
+	return s.getRelationList(ctx, s.follows.ListMuted, s.follows.CountMuted, userID, page, limit)
+}
+
+// getRelationList 查询拉黑/屏蔽列表中的用户
+func (s *ProfileService) getRelationList(
+	ctx context.Context,
+	list func(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]primitive.ObjectID, error),
+	count func(ctx context.Context, userID primitive.ObjectID) (int64, error),
+	userID string, page, limit int,
+) (*model.UserListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -852,112 +753,168 @@ func (s *ProfileService) GetFansList(userID string, currentUserID string, page,
 		return nil, err
 	}
 
-	// 查询关注该用户的用户ID列表
-	opts := options.Find().
-		SetSkip(int64((page - 1) * limit)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	cursor, err := s.db.Collection("user_follows").Find(
-		context.Background(),
-		bson.M{"following_id": userObjectID},
-		opts,
-	)
+	total, err := count(ctx, userObjectID)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-
-	var follows []model.UserFollow
-	if err = cursor.All(context.Background(), &follows); err != nil {
-		return nil, err
-	}
 
-	// 获取粉丝总数
-	total, err := s.db.Collection("user_follows").CountDocuments(
-		context.Background(),
-		bson.M{"following_id": userObjectID},
-	)
+	targetIDs, err := list(ctx, userObjectID, (page-1)*limit, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果没有粉丝，返回空列表
-	if len(follows) == 0 {
+	if len(targetIDs) == 0 {
 		return &model.UserListResponse{
 			Total: int(total),
 			List:  []model.UserListItem{},
 		}, nil
 	}
 
-	// 提取粉丝用户ID
-	var fanIDs []primitive.ObjectID
-	for _, follow := range follows {
-		fanIDs = append(fanIDs, follow.UserID)
+	users, err := s.toUserList(ctx, targetIDs, nil, false)
+	if err != nil {
+		return nil, err
 	}
 
-	// 查询粉丝用户信息
-	userCursor, err := s.db.Collection("users").Find(
-		context.Background(),
-		bson.M{"_id": bson.M{"$in": fanIDs}},
-	)
+	return &model.UserListResponse{
+		Total: int(total),
+		List:  users,
+	}, nil
+}
+
+// removeFollow 在事务中删除关注关系并更新关注数和粉丝数，返回是否删除了关注关系
+func (s *ProfileService) removeFollow(ctx context.Context, userID primitive.ObjectID, followingID primitive.ObjectID) (bool, error) {
+	removed := false
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		removed = false
+
+		deleted, err := s.follows.Unfollow(ctx, userID, followingID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return nil
+		}
+
+		if err := s.incrFollowCounts(ctx, userID, followingID, -1); err != nil {
+			return err
+		}
+
+		removed = true
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer userCursor.Close(context.Background())
 
-	var users []model.User
-	if err = userCursor.All(context.Background(), &users); err != nil {
-		return nil, err
+	if removed {
+		s.invalidateFollow(ctx, userID, followingID)
+		s.removeFromFeed(ctx, userID, followingID)
 	}
+	return removed, nil
+}
 
-	// 检查当前用户是否关注了这些粉丝
-	var followingMap map[string]bool
-	if currentUserID != "" {
-		followingMap = make(map[string]bool)
-		currentUserObjectID, err := primitive.ObjectIDFromHex(currentUserID)
-		if err == nil {
-			followCursor, err := s.db.Collection("user_follows").Find(
-				context.Background(),
-				bson.M{"user_id": currentUserObjectID, "following_id": bson.M{"$in": fanIDs}},
-			)
-			if err == nil {
-				var currentUserFollows []model.UserFollow
-				if err = followCursor.All(context.Background(), &currentUserFollows); err == nil {
-					for _, follow := range currentUserFollows {
-						followingMap[follow.FollowingID.Hex()] = true
-					}
-				}
-				followCursor.Close(context.Background())
-			}
+// addFollow 在事务中创建关注关系并更新关注数和粉丝数，已关注时不写入也不更新计数。
+// 已关注时返回已有的关注记录ID，created 为 false
+func (s *ProfileService) addFollow(ctx context.Context, userID primitive.ObjectID, followingID primitive.ObjectID) (primitive.ObjectID, bool, error) {
+	var followID primitive.ObjectID
+	created := false
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		created = false
+
+		follow := &model.UserFollow{
+			ID:          primitive.NewObjectID(),
+			UserID:      userID,
+			FollowingID: followingID,
+			CreatedAt:   time.Now(),
 		}
+		inserted, err := s.follows.Follow(ctx, follow)
+		if err != nil || !inserted {
+			return err
+		}
+
+		if err := s.incrFollowCounts(ctx, userID, followingID, 1); err != nil {
+			return err
+		}
+
+		followID = follow.ID
+		created = true
+		return nil
+	})
+	if err != nil {
+		return primitive.NilObjectID, false, err
 	}
 
-	// 构建响应
-	var list []model.UserListItem
-	for _, user := range users {
-		isFollowing := false
-		if followingMap != nil {
-			isFollowing = followingMap[user.ID.Hex()]
+	if created {
+		s.invalidateFollow(ctx, userID, followingID)
+	} else {
+		existing, err := s.follows.FindFollow(ctx, userID, followingID)
+		if err != nil {
+			return primitive.NilObjectID, false, err
 		}
+		followID = existing.ID
+	}
+	return followID, created, nil
+}
 
-		list = append(list, model.UserListItem{
-			UserID:      user.ID.Hex(),
-			Username:    user.Username,
-			Nickname:    user.Nickname,
-			Avatar:      user.Avatar,
-			Bio:         user.Bio,
-			IsFollowing: isFollowing,
-		})
+// incrFollowCounts 更新关注者的关注数和被关注者的粉丝数
+func (s *ProfileService) incrFollowCounts(ctx context.Context, userID primitive.ObjectID, followingID primitive.ObjectID, delta int) error {
+	if err := s.users.IncrCounter(ctx, userID, repository.UserFollowCount, delta); err != nil {
+		return err
 	}
+	return s.users.IncrCounter(ctx, followingID, repository.UserFansCount, delta)
+}
 
-	return &model.UserListResponse{
-		Total: int(total),
-		List:  list,
+// invalidateFollow 关注关系变化后删除双方的资料缓存和关注者的关注流缓存
+func (s *ProfileService) invalidateFollow(ctx context.Context, userID primitive.ObjectID, followingID primitive.ObjectID) {
+	s.cache.Delete(ctx, profileCacheKey(userID), profileCacheKey(followingID), feedGenerationKey(userID))
+}
+
+// followState 返回关注操作后的状态：是否关注、关注者的关注数、被关注者的粉丝数
+func (s *ProfileService) followState(ctx context.Context, userID primitive.ObjectID, followingID primitive.ObjectID, following bool) (map[string]interface{}, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	followingUser, err := s.users.FindByID(ctx, followingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"isFollowing": following,
+		"followCount": user.FollowCount,
+		"fansCount":   followingUser.FansCount,
 	}, nil
 }
 
-// IsObjectStorageAvailable 检查对象存储服务是否可用
-func (s *ProfileService) IsObjectStorageAvailable() bool {
-	return s.objectStorageService != nil && s.objectStorageService.IsAvailable()
-} 
\ No newline at end of file
+// removeFromFeed 取消关注后从关注者的关注流中移除该作者的笔记
+func (s *ProfileService) removeFromFeed(ctx context.Context, userID primitive.ObjectID, authorID primitive.ObjectID) {
+	if s.feedService == nil {
+		return
+	}
+	s.tasks.Go(ctx, func(ctx context.Context) {
+		if err := s.feedService.RemoveAuthor(ctx, userID, authorID); err != nil {
+			slog.ErrorContext(ctx, "从关注流移除作者失败", "user_id", userID.Hex(), "author_id", authorID.Hex(), "error", err)
+		}
+	})
+}
+
+// hiddenUserIDsFrom 返回对 viewerID 不可见的用户ID：双向拉黑的用户，以及 includeMuted 为 true 时被屏蔽的用户
+func hiddenUserIDsFrom(ctx context.Context, follows repository.FollowRepo, viewerID string, includeMuted bool) ([]primitive.ObjectID, error) {
+	if viewerID == "" {
+		return nil, nil
+	}
+
+	viewerObjectID, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		return nil, nil
+	}
+
+	return follows.HiddenUserIDs(ctx, viewerObjectID, includeMuted)
+}
+
+// hiddenUserIDs 同 hiddenUserIDsFrom，供直接访问数据库的服务使用
+func hiddenUserIDs(ctx context.Context, db *mongo.Database, viewerID string, includeMuted bool) ([]primitive.ObjectID, error) {
+	return hiddenUserIDsFrom(ctx, repository.NewMongoFollowRepo(db), viewerID, includeMuted)
+}
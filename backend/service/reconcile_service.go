@@ -0,0 +1,381 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/health"
+	"blue-note/model"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrReconcileRunning 已有对账任务正在运行
+var ErrReconcileRunning = apperr.ErrReconcileRunning
+
+// maxReportedDiscrepancies 对账报告中最多保存的不一致记录数
+const maxReportedDiscrepancies = 1000
+
+// ReconcileService 计数对账服务，根据明细记录重新计算冗余计数并修正
+type ReconcileService struct {
+	db       *mongo.Database
+	cache    *cache.Group
+	mu       sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	heartbeat health.Heartbeat
+}
+
+// NewReconcileService 创建计数对账服务实例
+func NewReconcileService(db *mongo.Database, cache *cache.Group) *ReconcileService {
+	return &ReconcileService{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// counterCheck 一项计数检查：collection 中的 field 应等于 count 统计出的实际值（缺省为 0）
+type counterCheck struct {
+	collection string
+	field      string
+	// count 统计实际值，ids 为空时统计所有文档，否则只统计指定的文档
+	count func(ctx context.Context, ids ...primitive.ObjectID) (map[primitive.ObjectID]int, error)
+}
+
+// Start 启动定时对账任务，间隔为 0 时不启动
+func (s *ReconcileService) Start() {
+	cfg := config.GetConfig().Reconcile
+	if cfg.Interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	interval := time.Duration(cfg.Interval) * time.Minute
+	s.heartbeat.Start(interval)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report, err := s.Run(ctx, "schedule", cfg.DryRun)
+				if errors.Is(err, ErrReconcileRunning) {
+					// 管理员手动触发的对账正在运行，本轮跳过
+					s.heartbeat.Beat(nil)
+					continue
+				}
+				s.heartbeat.Beat(err)
+				if err != nil {
+					slog.ErrorContext(ctx, "定时对账失败", "error", err)
+					continue
+				}
+				slog.InfoContext(ctx, "定时对账完成", "checked", report.Checked, "discrepancies", report.DiscrepancyCount)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时对账任务，取消正在进行的对账并等待其退出
+func (s *ReconcileService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.heartbeat.Stop()
+}
+
+// Health 返回定时对账任务的运行状态，未开启定时对账时为正常
+func (s *ReconcileService) Health(ctx context.Context) health.Result {
+	return s.heartbeat.Check(ctx)
+}
+
+// Run 执行一次对账，dryRun 为 true 时只报告不修正；同一时间只允许一个对账任务
+func (s *ReconcileService) Run(ctx context.Context, trigger string, dryRun bool) (*model.ReconcileReport, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrReconcileRunning
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	report := &model.ReconcileReport{
+		Trigger:       trigger,
+		DryRun:        dryRun,
+		Discrepancies: []model.ReconcileDiscrepancy{},
+		StartedAt:     time.Now(),
+	}
+
+	for _, check := range s.checks() {
+		if err := s.runCheck(ctx, check, dryRun, report); err != nil {
+			report.Error = err.Error()
+			break
+		}
+	}
+	report.FinishedAt = time.Now()
+
+	result, err := s.db.Collection("reconcile_reports").InsertOne(ctx, report)
+	if err != nil {
+		return nil, fmt.Errorf("保存对账报告失败: %w", err)
+	}
+	report.ID = result.InsertedID.(primitive.ObjectID)
+
+	if report.Error != "" {
+		return report, errors.New(report.Error)
+	}
+	return report, nil
+}
+
+// checks 返回所有计数检查项
+func (s *ReconcileService) checks() []counterCheck {
+	return []counterCheck{
+		{collection: "posts", field: "likes", count: s.countGrouped("post_likes", "post_id", nil)},
+		{collection: "posts", field: "comments", count: s.countGrouped("comments", "post_id", nil)},
+		{collection: "comments", field: "likes", count: s.countGrouped("comment_likes", "comment_id", nil)},
+		{collection: "users", field: "follow_count", count: s.countGrouped("user_follows", "user_id", nil)},
+		{collection: "users", field: "fans_count", count: s.countGrouped("user_follows", "following_id", nil)},
+		{collection: "users", field: "post_count", count: s.countGrouped("posts", "user_id", bson.M{"status": "approved"})},
+		{collection: "users", field: "like_count", count: s.sumGrouped("posts", "user_id", "likes", bson.M{"status": "approved"})},
+		{collection: "users", field: "collect_count", count: s.sumGrouped("posts", "user_id", "collections", bson.M{"status": "approved"})},
+	}
+}
+
+// runCheck 执行一项检查，逐条比较存储值与实际值
+func (s *ReconcileService) runCheck(ctx context.Context, check counterCheck, dryRun bool, report *model.ReconcileReport) error {
+	actual, err := check.count(ctx)
+	if err != nil {
+		return fmt.Errorf("计算 %s.%s 失败: %w", check.collection, check.field, err)
+	}
+
+	cursor, err := s.db.Collection(check.collection).Find(
+		ctx,
+		bson.M{},
+		options.Find().SetProjection(bson.M{check.field: 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("查询 %s 失败: %w", check.collection, err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		id, ok := doc["_id"].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		report.Checked++
+
+		stored := toInt(doc[check.field])
+		if stored == actual[id] {
+			continue
+		}
+
+		// 以读到的存储值作为条件修正，避免覆盖重新统计之后发生的并发更新
+		fix := func(value int) (bool, error) {
+			result, err := s.db.Collection(check.collection).UpdateOne(
+				ctx,
+				bson.M{"_id": id, check.field: doc[check.field]},
+				bson.M{"$set": bson.M{check.field: value}},
+			)
+			if err != nil {
+				return false, err
+			}
+			return result.ModifiedCount > 0, nil
+		}
+
+		discrepancy, err := checkDocument(ctx, check, id, stored, dryRun, fix)
+		if err != nil {
+			return err
+		}
+		if discrepancy == nil {
+			continue
+		}
+		if discrepancy.Fixed {
+			s.invalidate(ctx, check.collection, id)
+		}
+
+		report.DiscrepancyCount++
+		if len(report.Discrepancies) < maxReportedDiscrepancies {
+			report.Discrepancies = append(report.Discrepancies, *discrepancy)
+		}
+	}
+
+	return cursor.Err()
+}
+
+// checkDocument 处理与批量统计结果不一致的文档。批量统计在扫描开始前完成，
+// 扫描到该文档时计数可能已被正常的点赞、评论、关注更新，因此先单独重新统计，
+// 仍不一致时才记录并按最新的实际值修正；返回 nil 表示计数正确
+func checkDocument(ctx context.Context, check counterCheck, id primitive.ObjectID, stored int, dryRun bool, fix func(value int) (bool, error)) (*model.ReconcileDiscrepancy, error) {
+	fresh, err := check.count(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("重新计算 %s.%s 失败: %w", check.collection, check.field, err)
+	}
+	if stored == fresh[id] {
+		return nil, nil
+	}
+
+	discrepancy := &model.ReconcileDiscrepancy{
+		Collection: check.collection,
+		DocumentID: id,
+		Field:      check.field,
+		Stored:     stored,
+		Actual:     fresh[id],
+	}
+	if dryRun {
+		return discrepancy, nil
+	}
+
+	fixed, err := fix(fresh[id])
+	if err != nil {
+		slog.ErrorContext(ctx, "修正计数失败", "collection", check.collection, "field", check.field, "id", id.Hex(), "error", err)
+	}
+	discrepancy.Fixed = fixed
+	return discrepancy, nil
+}
+
+// invalidate 删除修正过计数的笔记或用户的缓存
+func (s *ReconcileService) invalidate(ctx context.Context, collection string, id primitive.ObjectID) {
+	switch collection {
+	case "posts":
+		s.cache.Delete(ctx, postCacheKey(id))
+	case "users":
+		s.cache.Delete(ctx, profileCacheKey(id))
+	}
+}
+
+// countGrouped 按字段分组统计记录数
+func (s *ReconcileService) countGrouped(collection string, groupField string, match bson.M) func(ctx context.Context, ids ...primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	return s.grouped(collection, groupField, bson.M{"$sum": 1}, match)
+}
+
+// sumGrouped 按字段分组对数值字段求和
+func (s *ReconcileService) sumGrouped(collection string, groupField string, sumField string, match bson.M) func(ctx context.Context, ids ...primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	return s.grouped(collection, groupField, bson.M{"$sum": "$" + sumField}, match)
+}
+
+// grouped 返回分组统计函数，指定 ids 时只统计分组字段为这些值的记录
+func (s *ReconcileService) grouped(collection string, groupField string, accumulator bson.M, match bson.M) func(ctx context.Context, ids ...primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	return func(ctx context.Context, ids ...primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+		filter := bson.M{}
+		for key, value := range match {
+			filter[key] = value
+		}
+		if len(ids) > 0 {
+			filter[groupField] = bson.M{"$in": ids}
+		}
+		return s.aggregateGrouped(ctx, collection, groupField, accumulator, filter)
+	}
+}
+
+func (s *ReconcileService) aggregateGrouped(ctx context.Context, collection string, groupField string, accumulator bson.M, match bson.M) (map[primitive.ObjectID]int, error) {
+	pipeline := []bson.M{}
+	if len(match) > 0 {
+		pipeline = append(pipeline, bson.M{"$match": match})
+	}
+	pipeline = append(pipeline, bson.M{"$group": bson.M{
+		"_id":   "$" + groupField,
+		"count": accumulator,
+	}})
+
+	cursor, err := s.db.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	result := make(map[primitive.ObjectID]int, len(rows))
+	for _, row := range rows {
+		result[row.ID] = row.Count
+	}
+	return result, nil
+}
+
+// toInt 将 BSON 数值转换为 int，字段缺失时为 0
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// GetReports 获取对账报告列表，按时间倒序
+func (s *ReconcileService) GetReports(ctx context.Context, query *model.ReconcileReportQuery) (*model.ReconcileReportListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = 10
+	}
+
+	total, err := s.db.Collection("reconcile_reports").CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("查询对账报告总数失败: %w", err)
+	}
+
+	cursor, err := s.db.Collection("reconcile_reports").Find(
+		ctx,
+		bson.M{},
+		options.Find().
+			SetSort(bson.D{{Key: "started_at", Value: -1}}).
+			SetSkip(int64((query.Page-1)*query.Limit)).
+			SetLimit(int64(query.Limit)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询对账报告失败: %w", err)
+	}
+
+	reports := []*model.ReconcileReport{}
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+
+	return &model.ReconcileReportListResponse{
+		Total: total,
+		List:  reports,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckDocumentRecountsBeforeFix(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		stored     int
+		recount    int
+		dryRun     bool
+		wantReport bool
+		wantFix    int
+	}{
+		// 批量统计时实际值为 3，扫描到该文档前新增一个点赞，存储值和实际值都变为 4
+		{name: "统计后计数已变化", stored: 4, recount: 4},
+		// 存储值本身有偏差，修正为重新统计的值而不是批量统计时的旧值
+		{name: "存储值有偏差", stored: 6, recount: 4, wantReport: true, wantFix: 4},
+		{name: "只检查不修正", stored: 6, recount: 4, dryRun: true, wantReport: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := map[primitive.ObjectID]int{id: 3}
+			check := counterCheck{
+				collection: "posts",
+				field:      "likes",
+				count: func(ctx context.Context, ids ...primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+					return counts, nil
+				},
+			}
+
+			actual, _ := check.count(context.Background())
+			if actual[id] == tt.stored {
+				t.Fatal("批量统计结果应与存储值不一致")
+			}
+			counts = map[primitive.ObjectID]int{id: tt.recount}
+
+			fixed := -1
+			fix := func(value int) (bool, error) {
+				fixed = value
+				return true, nil
+			}
+			discrepancy, err := checkDocument(context.Background(), check, id, tt.stored, tt.dryRun, fix)
+			if err != nil {
+				t.Fatalf("checkDocument 失败: %v", err)
+			}
+
+			if (discrepancy != nil) != tt.wantReport {
+				t.Fatalf("不一致记录 = %+v, 期望记录: %v", discrepancy, tt.wantReport)
+			}
+			if discrepancy != nil && discrepancy.Actual != tt.recount {
+				t.Errorf("实际值 = %d, 期望 %d", discrepancy.Actual, tt.recount)
+			}
+			switch {
+			case tt.wantFix > 0 && fixed != tt.wantFix:
+				t.Errorf("修正值 = %d, 期望 %d", fixed, tt.wantFix)
+			case tt.wantFix == 0 && fixed != -1:
+				t.Errorf("不应修正, 修正值 = %d", fixed)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/model"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReportService 举报服务
+type ReportService struct {
+	db    *mongo.Database
+	cache *cache.Group
+}
+
+// NewReportService 创建举报服务实例
+func NewReportService(db *mongo.Database, cache *cache.Group) *ReportService {
+	return &ReportService{db: db, cache: cache}
+}
+
+// reportTarget 被举报对象的基本信息
+type reportTarget struct {
+	owner  primitive.ObjectID
+	postID primitive.ObjectID
+}
+
+// targetCollection 返回举报对象所在的集合
+func targetCollection(targetType model.ReportTargetType) (string, error) {
+	switch targetType {
+	case model.ReportTargetPost:
+		return "posts", nil
+	case model.ReportTargetComment:
+		return "comments", nil
+	case model.ReportTargetUser:
+		return "users", nil
+	}
+	return "", apperr.ErrUnsupportedReportType
+}
+
+// invalidateTarget 举报对象的隐藏、审核或封禁状态变化后删除其缓存，评论没有缓存
+func (s *ReportService) invalidateTarget(ctx context.Context, targetType model.ReportTargetType, targetID primitive.ObjectID) {
+	switch targetType {
+	case model.ReportTargetPost:
+		s.cache.Delete(ctx, postCacheKey(targetID))
+	case model.ReportTargetUser:
+		s.cache.Delete(ctx, profileCacheKey(targetID))
+	}
+}
+
+// ReportPost 举报笔记
+func (s *ReportService) ReportPost(ctx context.Context, reporterID string, postID string, req *model.CreateReportRequest) (*model.Report, error) {
+	return s.createReport(ctx, model.ReportTargetPost, postID, reporterID, req)
+}
+
+// ReportComment 举报评论
+func (s *ReportService) ReportComment(ctx context.Context, reporterID string, commentID string, req *model.CreateReportRequest) (*model.Report, error) {
+	return s.createReport(ctx, model.ReportTargetComment, commentID, reporterID, req)
+}
+
+// ReportUser 举报用户
+func (s *ReportService) ReportUser(ctx context.Context, reporterID string, userID string, req *model.CreateReportRequest) (*model.Report, error) {
+	return s.createReport(ctx, model.ReportTargetUser, userID, reporterID, req)
+}
+
+// findTarget 查询被举报对象，确认其存在并获取作者信息
+func (s *ReportService) findTarget(ctx context.Context, targetType model.ReportTargetType, targetID primitive.ObjectID) (*reportTarget, error) {
+	collection, err := targetCollection(targetType)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		ID     primitive.ObjectID `bson:"_id"`
+		UserID primitive.ObjectID `bson:"user_id"`
+		PostID primitive.ObjectID `bson:"post_id"`
+	}
+	err = s.db.Collection(collection).FindOne(ctx, bson.M{"_id": targetID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, apperr.ErrReportTargetNotFound
+		}
+		return nil, fmt.Errorf("查询举报对象失败: %w", err)
+	}
+
+	target := &reportTarget{owner: doc.UserID, postID: doc.PostID}
+	if targetType == model.ReportTargetUser {
+		target.owner = doc.ID
+	}
+	return target, nil
+}
+
+// createReport 创建举报记录，同一举报人对同一对象只能举报一次
+func (s *ReportService) createReport(ctx context.Context, targetType model.ReportTargetType, targetID string, reporterID string, req *model.CreateReportRequest) (*model.Report, error) {
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的举报对象ID: %w", err)
+	}
+
+	reporterObjID, err := primitive.ObjectIDFromHex(reporterID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	target, err := s.findTarget(ctx, targetType, targetObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.owner == reporterObjID {
+		return nil, apperr.ErrReportSelf
+	}
+
+	now := time.Now()
+	report := &model.Report{
+		TargetType:  targetType,
+		TargetID:    targetObjID,
+		TargetOwner: target.owner,
+		PostID:      target.postID,
+		ReporterID:  reporterObjID,
+		Reason:      req.Reason,
+		Description: req.Description,
+		Status:      model.ReportStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	// 由 (reporter_id, target_type, target_id) 唯一索引保证重复举报（包括并发请求）只有一条能写入
+	result, err := s.db.Collection("reports").InsertOne(ctx, report)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, apperr.ErrAlreadyReported
+		}
+		return nil, fmt.Errorf("创建举报记录失败: %w", err)
+	}
+	report.ID = result.InsertedID.(primitive.ObjectID)
+
+	// 累加待处理举报数，达到阈值后自动隐藏
+	if err := s.incrReportCount(ctx, targetType, targetObjID); err != nil {
+		slog.ErrorContext(ctx, "更新举报计数失败", "error", err)
+	}
+
+	return report, nil
+}
+
+// incrReportCount 累加举报对象的待处理举报数，达到阈值时自动隐藏
+func (s *ReportService) incrReportCount(ctx context.Context, targetType model.ReportTargetType, targetID primitive.ObjectID) error {
+	collection, err := targetCollection(targetType)
+	if err != nil {
+		return err
+	}
+
+	var updated struct {
+		ReportCount int  `bson:"report_count"`
+		Hidden      bool `bson:"hidden"`
+	}
+	err = s.db.Collection(collection).FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": targetID},
+		bson.M{"$inc": bson.M{"report_count": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return err
+	}
+
+	threshold := config.GetConfig().Report.HideThreshold
+	if threshold <= 0 || updated.Hidden || updated.ReportCount < threshold {
+		return nil
+	}
+
+	_, err = s.db.Collection(collection).UpdateOne(
+		ctx,
+		bson.M{"_id": targetID},
+		bson.M{"$set": bson.M{"hidden": true, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	s.invalidateTarget(ctx, targetType, targetID)
+
+	slog.InfoContext(ctx, "举报数达到阈值，已自动隐藏", "target_type", targetType, "target_id", targetID.Hex(), "report_count", updated.ReportCount)
+	return nil
+}
+
+// GetReports 获取举报列表（默认只返回待处理的举报）
+func (s *ReportService) GetReports(ctx context.Context, query *model.ReportQuery) (*model.ReportListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+	if query.Status == "" {
+		query.Status = string(model.ReportStatusPending)
+	}
+
+	filter := bson.M{"status": query.Status}
+	if query.TargetType != "" {
+		filter["target_type"] = query.TargetType
+	}
+
+	total, err := s.db.Collection("reports").CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSkip(int64((query.Page - 1) * query.Limit)).
+		SetLimit(int64(query.Limit)).
+		SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := s.db.Collection("reports").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports := []model.Report{}
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+
+	return &model.ReportListResponse{
+		Total: int(total),
+		List:  reports,
+	}, nil
+}
+
+// HandleReport 处理举报，同一对象的所有待处理举报一并结案，并同步对象的审核状态
+func (s *ReportService) HandleReport(ctx context.Context, reportID string, handlerID string, req *model.HandleReportRequest) (*model.Report, error) {
+	reportObjID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的举报ID: %w", err)
+	}
+
+	handlerObjID, err := primitive.ObjectIDFromHex(handlerID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	var report model.Report
+	err = s.db.Collection("reports").FindOne(ctx, bson.M{"_id": reportObjID}).Decode(&report)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, apperr.ErrReportNotFound
+		}
+		return nil, fmt.Errorf("查询举报失败: %w", err)
+	}
+
+	if report.Status != model.ReportStatusPending {
+		return nil, apperr.ErrReportHandled
+	}
+
+	status := model.ReportStatusDismissed
+	if req.Action == "resolve" {
+		status = model.ReportStatusResolved
+	}
+
+	now := time.Now()
+	_, err = s.db.Collection("reports").UpdateMany(
+		ctx,
+		bson.M{
+			"target_type": report.TargetType,
+			"target_id":   report.TargetID,
+			"status":      model.ReportStatusPending,
+		},
+		bson.M{"$set": bson.M{
+			"status":      status,
+			"handler_id":  handlerObjID,
+			"handle_note": req.Note,
+			"handled_at":  now,
+			"updated_at":  now,
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("更新举报状态失败: %w", err)
+	}
+
+	if err := s.applyOutcome(ctx, &report, status, req.Note); err != nil {
+		return nil, err
+	}
+
+	report.Status = status
+	report.HandlerID = &handlerObjID
+	report.HandleNote = req.Note
+	report.HandledAt = &now
+	report.UpdatedAt = now
+
+	return &report, nil
+}
+
+// applyOutcome 根据处理结果更新举报对象的审核状态
+// 举报成立：笔记改为审核拒绝，评论保持隐藏，用户被封禁
+// 举报驳回：取消隐藏
+func (s *ReportService) applyOutcome(ctx context.Context, report *model.Report, status model.ReportStatus, note string) error {
+	collection, err := targetCollection(report.TargetType)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"report_count": 0,
+		"updated_at":   time.Now(),
+	}
+
+	if status == model.ReportStatusDismissed {
+		update["hidden"] = false
+	} else {
+		update["hidden"] = true
+		switch report.TargetType {
+		case model.ReportTargetPost:
+			reason := note
+			if reason == "" {
+				reason = "内容被举报并核实违规"
+			}
+			update["status"] = "rejected"
+			update["reject_reason"] = reason
+		case model.ReportTargetUser:
+			update["banned"] = true
+		}
+	}
+
+	_, err = s.db.Collection(collection).UpdateOne(
+		ctx,
+		bson.M{"_id": report.TargetID},
+		bson.M{"$set": update},
+	)
+	if err != nil {
+		return fmt.Errorf("更新举报对象状态失败: %w", err)
+	}
+	s.invalidateTarget(ctx, report.TargetType, report.TargetID)
+
+	// 笔记被下架后更新作者的笔记数和获赞数
+	if report.TargetType == model.ReportTargetPost && status == model.ReportStatusResolved {
+		if err := recomputeCreatorStats(ctx, s.db, report.TargetOwner); err != nil {
+			slog.ErrorContext(ctx, "更新创作者数据失败", "error", err)
+		}
+	}
+
+	return nil
+}
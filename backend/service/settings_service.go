@@ -0,0 +1,399 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/config"
+	"blue-note/health"
+	"blue-note/i18n"
+	"blue-note/model"
+	"blue-note/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidSettings 运行时配置校验失败，details 中返回每一项问题
+	ErrInvalidSettings = apperr.ErrInvalidSettings
+	// ErrSettingsConflict 配置已被其他管理员修改
+	ErrSettingsConflict = apperr.ErrSettingsConflict
+)
+
+// extensionPattern 合法的文件扩展名
+var extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// SettingsService 运行时配置服务：缓存当前配置，修改后通知订阅者，使修改无需重启即可生效
+type SettingsService struct {
+	settings repository.SettingsRepo
+
+	mu          sync.RWMutex
+	current     model.RuntimeSettings
+	subscribers []func(model.RuntimeSettings)
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	heartbeat health.Heartbeat
+}
+
+// NewSettingsService 创建运行时配置服务实例，加载数据库中的配置前使用默认配置
+func NewSettingsService(repos *repository.Repositories) *SettingsService {
+	return &SettingsService{
+		settings: repos.Settings,
+		current:  model.DefaultRuntimeSettings(),
+	}
+}
+
+// Current 返回当前配置的副本
+func (s *SettingsService) Current() model.RuntimeSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current.Clone()
+}
+
+// Subscribe 订阅配置变化，订阅时立即以当前配置调用一次 fn
+func (s *SettingsService) Subscribe(fn func(model.RuntimeSettings)) {
+	s.mu.Lock()
+	s.subscribers = append(s.subscribers, fn)
+	current := s.current.Clone()
+	s.mu.Unlock()
+
+	fn(current)
+}
+
+// apply 更新缓存的配置并通知订阅者
+func (s *SettingsService) apply(settings model.RuntimeSettings) {
+	settings = settings.Clone()
+	settings.FillDefaults()
+
+	s.mu.Lock()
+	s.current = settings.Clone()
+	subscribers := make([]func(model.RuntimeSettings), len(s.subscribers))
+	copy(subscribers, s.subscribers)
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(settings.Clone())
+	}
+}
+
+// Load 从数据库加载配置，数据库中还没有配置时保存默认配置
+func (s *SettingsService) Load(ctx context.Context) error {
+	settings, err := s.settings.Get(ctx)
+	if err == repository.ErrNotFound {
+		defaults := model.DefaultRuntimeSettings()
+		defaults.Version = 1
+		defaults.UpdatedAt = time.Now()
+
+		err = s.settings.Save(ctx, &defaults, 0)
+		if err == repository.ErrConflict {
+			// 其他实例已经保存了默认配置
+			return s.Load(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("保存默认配置失败: %w", err)
+		}
+		settings = &defaults
+	} else if err != nil {
+		return fmt.Errorf("读取运行时配置失败: %w", err)
+	}
+
+	s.apply(*settings)
+	return nil
+}
+
+// Latest 从数据库读取最新配置，比缓存的版本新时更新缓存
+func (s *SettingsService) Latest(ctx context.Context) (model.RuntimeSettings, error) {
+	settings, err := s.settings.Get(ctx)
+	if err == repository.ErrNotFound {
+		return s.Current(), nil
+	}
+	if err != nil {
+		return model.RuntimeSettings{}, err
+	}
+
+	if settings.Version > s.Current().Version {
+		s.apply(*settings)
+	}
+	return s.Current(), nil
+}
+
+// Start 定时从数据库重新加载配置，使其他实例上的修改在本实例生效
+func (s *SettingsService) Start() {
+	interval := time.Duration(config.GetConfig().Settings.ReloadInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	s.heartbeat.Start(interval)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_, err := s.Latest(ctx)
+				if err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "重新加载运行时配置失败", "error", err)
+				}
+				s.heartbeat.Beat(err)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时加载
+func (s *SettingsService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.heartbeat.Stop()
+}
+
+// Health 返回定时加载任务的运行状态
+func (s *SettingsService) Health(ctx context.Context) health.Result {
+	return s.heartbeat.Check(ctx)
+}
+
+// Update 校验并保存配置，记录修改历史后通知订阅者
+func (s *SettingsService) Update(ctx context.Context, adminID string, req *model.UpdateSettingsRequest) (*model.RuntimeSettings, error) {
+	latest, err := s.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != latest.Version {
+		return nil, ErrSettingsConflict
+	}
+
+	updated := model.RuntimeSettings{
+		Upload:     req.Upload,
+		RateLimits: req.RateLimits,
+		CORS:       req.CORS,
+	}
+	// 请求中的限流规则会整条替换，未提交的算法和计数维度沿用当前值
+	for name, rule := range updated.RateLimits {
+		if current, ok := latest.RateLimits[name]; ok {
+			if rule.Algorithm == "" {
+				rule.Algorithm = current.Algorithm
+			}
+			if rule.Key == "" {
+				rule.Key = current.Key
+			}
+			updated.RateLimits[name] = rule
+		}
+	}
+	if err := validateSettings(&updated); err != nil {
+		return nil, err
+	}
+
+	changes := diffSettings(latest, updated)
+	if len(changes) == 0 {
+		return &latest, nil
+	}
+
+	now := time.Now()
+	updated.Version = latest.Version + 1
+	updated.UpdatedBy = adminID
+	updated.UpdatedAt = now
+
+	if err := s.settings.Save(ctx, &updated, latest.Version); err != nil {
+		if err == repository.ErrConflict {
+			return nil, ErrSettingsConflict
+		}
+		return nil, err
+	}
+
+	// 配置已经保存，修改记录写入失败不影响本次修改
+	if err := s.settings.AddHistory(ctx, &model.SettingsChange{
+		Version:   updated.Version,
+		Changes:   changes,
+		Settings:  updated,
+		UpdatedBy: adminID,
+		Reason:    req.Reason,
+		CreatedAt: now,
+	}); err != nil {
+		slog.ErrorContext(ctx, "保存配置修改记录失败", "error", err)
+	}
+
+	s.apply(updated)
+	return &updated, nil
+}
+
+// GetHistory 获取配置修改记录
+func (s *SettingsService) GetHistory(ctx context.Context, query *model.SettingsHistoryQuery) (*model.SettingsHistoryResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+
+	changes, total, err := s.settings.ListHistory(ctx, (query.Page-1)*query.Limit, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SettingsHistoryResponse{Total: total, List: changes}, nil
+}
+
+// validateSettings 校验配置，同时规范化扩展名和跨域来源
+func validateSettings(settings *model.RuntimeSettings) error {
+	var problems i18n.Messages
+
+	upload := &settings.Upload
+	if upload.MaxImageSizeMB < 1 || upload.MaxImageSizeMB > 100 {
+		problems = append(problems, i18n.M("settings.range", "upload.maxImageSizeMB", 1, 100))
+	}
+	if upload.MaxVideoSizeMB < 1 || upload.MaxVideoSizeMB > 2048 {
+		problems = append(problems, i18n.M("settings.range", "upload.maxVideoSizeMB", 1, 2048))
+	}
+	upload.ImageExtensions, problems = normalizeExtensions("upload.imageExtensions", upload.ImageExtensions, problems)
+	upload.VideoExtensions, problems = normalizeExtensions("upload.videoExtensions", upload.VideoExtensions, problems)
+
+	for _, name := range model.RateLimitNames {
+		if _, ok := settings.RateLimits[name]; !ok {
+			problems = append(problems, i18n.M("settings.empty", "rateLimits."+name))
+		}
+	}
+	for name, rule := range settings.RateLimits {
+		if !containsString(model.RateLimitNames, name) {
+			problems = append(problems, i18n.M("settings.unknown_rate_limit", "rateLimits."+name))
+			continue
+		}
+		if rule.Requests < 1 || rule.Requests > 10000 {
+			problems = append(problems, i18n.M("settings.range", "rateLimits."+name+".requests", 1, 10000))
+		}
+		if rule.Window < 1 || rule.Window > 86400 {
+			problems = append(problems, i18n.M("settings.range_seconds", "rateLimits."+name+".window", 1, 86400))
+		}
+		if rule.Algorithm != model.RateLimitTokenBucket && rule.Algorithm != model.RateLimitSlidingWindow {
+			problems = append(problems, i18n.M("settings.one_of", "rateLimits."+name+".algorithm", "token_bucket, sliding_window"))
+		}
+		if rule.Key != model.RateLimitByIP && rule.Key != model.RateLimitByUser && rule.Key != model.RateLimitByRoute {
+			problems = append(problems, i18n.M("settings.one_of", "rateLimits."+name+".key", "ip, user, route"))
+		}
+	}
+
+	origins := make([]string, 0, len(settings.CORS.AllowOrigins))
+	for _, origin := range settings.CORS.AllowOrigins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			problems = append(problems, i18n.M("settings.invalid_origin", "cors.allowOrigins", origin))
+			continue
+		}
+		if !containsString(origins, origin) {
+			origins = append(origins, origin)
+		}
+	}
+	if len(settings.CORS.AllowOrigins) == 0 {
+		problems = append(problems, i18n.M("settings.empty", "cors.allowOrigins"))
+	}
+	settings.CORS.AllowOrigins = origins
+
+	if len(problems) > 0 {
+		sort.Slice(problems, func(i, j int) bool {
+			return problems[i].String() < problems[j].String()
+		})
+		return ErrInvalidSettings.WithDetails(problems)
+	}
+	return nil
+}
+
+// normalizeExtensions 将扩展名转为小写并补全 . 前缀，去除重复项
+func normalizeExtensions(field string, exts []string, problems i18n.Messages) ([]string, i18n.Messages) {
+	normalized := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !extensionPattern.MatchString(ext) {
+			problems = append(problems, i18n.M("settings.invalid_extension", field, ext))
+			continue
+		}
+		if !containsString(normalized, ext) {
+			normalized = append(normalized, ext)
+		}
+	}
+	if len(exts) == 0 {
+		problems = append(problems, i18n.M("settings.empty", field))
+	}
+	return normalized, problems
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// diffSettings 比较两份配置，返回变化的字段，字段名与接口中的 JSON 字段一致
+func diffSettings(before, after model.RuntimeSettings) []model.SettingsFieldChange {
+	old, updated := flattenSettings(before), flattenSettings(after)
+
+	fields := make([]string, 0, len(updated))
+	for field := range updated {
+		fields = append(fields, field)
+	}
+	for field := range old {
+		if _, ok := updated[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var changes []model.SettingsFieldChange
+	for _, field := range fields {
+		if !reflect.DeepEqual(old[field], updated[field]) {
+			changes = append(changes, model.SettingsFieldChange{Field: field, Old: old[field], New: updated[field]})
+		}
+	}
+	return changes
+}
+
+// flattenSettings 将配置展开为 "upload.maxImageSizeMB" 形式的键值对
+func flattenSettings(settings model.RuntimeSettings) map[string]interface{} {
+	data, _ := json.Marshal(struct {
+		Upload     model.UploadSettings           `json:"upload"`
+		RateLimits map[string]model.RateLimitRule `json:"rateLimits"`
+		CORS       model.CORSSettings             `json:"cors"`
+	}{settings.Upload, settings.RateLimits, settings.CORS})
+
+	var tree map[string]interface{}
+	json.Unmarshal(data, &tree)
+
+	result := make(map[string]interface{})
+	var flatten func(prefix string, value interface{})
+	flatten = func(prefix string, value interface{}) {
+		if children, ok := value.(map[string]interface{}); ok {
+			for key, child := range children {
+				flatten(prefix+"."+key, child)
+			}
+			return
+		}
+		result[strings.TrimPrefix(prefix, ".")] = value
+	}
+	flatten("", tree)
+	return result
+}
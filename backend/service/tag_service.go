@@ -0,0 +1,624 @@
+package service
+
+import (
+	"blue-note/apperr"
+	"blue-note/cache"
+	"blue-note/model"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TagService 标签服务
+type TagService struct {
+	db    *mongo.Database
+	cache *cache.Group
+}
+
+// NewTagService 创建标签服务实例
+func NewTagService(db *mongo.Database, cache *cache.Group) *TagService {
+	return &TagService{db: db, cache: cache}
+}
+
+// NormalizeTagName 归一化标签名：去除首尾空白和 # 前缀，合并连续空白，英文转小写
+func NormalizeTagName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimLeft(name, "#＃")
+	name = strings.Join(strings.Fields(name), " ")
+	return strings.ToLower(name)
+}
+
+// normalizeTagNames 归一化并去重，忽略空标签
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized := NormalizeTagName(name)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	return result
+}
+
+// findTag 按规范名称或同义词查找标签，不存在时返回 nil
+func (s *TagService) findTag(ctx context.Context, name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := s.db.Collection("tags").FindOne(
+		ctx,
+		bson.M{"$or": []bson.M{
+			{"name": name},
+			{"aliases": name},
+		}},
+	).Decode(&tag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	return &tag, nil
+}
+
+// findTagByID 按ID查找标签
+func (s *TagService) findTagByID(ctx context.Context, tagID string) (*model.Tag, error) {
+	objectID, err := primitive.ObjectIDFromHex(tagID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的标签ID: %w", err)
+	}
+
+	var tag model.Tag
+	err = s.db.Collection("tags").FindOne(ctx, bson.M{"_id": objectID}).Decode(&tag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, apperr.ErrTagNotFound
+		}
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	return &tag, nil
+}
+
+// CanonicalName 返回标签的规范名称，标签不存在时返回归一化后的名称
+func (s *TagService) CanonicalName(ctx context.Context, name string) (string, error) {
+	normalized := NormalizeTagName(name)
+	tag, err := s.findTag(ctx, normalized)
+	if err != nil {
+		return "", err
+	}
+	if tag == nil {
+		return normalized, nil
+	}
+	return tag.Name, nil
+}
+
+// ResolveTags 将用户输入的标签转换为规范名称，不存在的标签会自动创建
+func (s *TagService) ResolveTags(ctx context.Context, names []string) ([]string, error) {
+	normalized := normalizeTagNames(names)
+
+	seen := make(map[string]bool, len(normalized))
+	result := make([]string, 0, len(normalized))
+	for _, name := range normalized {
+		tag, err := s.findTag(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		canonical := name
+		if tag != nil {
+			canonical = tag.Name
+		} else {
+			now := time.Now()
+			_, err = s.db.Collection("tags").UpdateOne(
+				ctx,
+				bson.M{"name": name},
+				bson.M{"$setOnInsert": bson.M{
+					"aliases":        []string{},
+					"description":    "",
+					"post_count":     0,
+					"follower_count": 0,
+					"created_at":     now,
+					"updated_at":     now,
+				}},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("创建标签失败: %w", err)
+			}
+		}
+
+		if !seen[canonical] {
+			seen[canonical] = true
+			result = append(result, canonical)
+		}
+	}
+
+	return result, nil
+}
+
+// RecountTags 重新统计标签下审核通过的笔记数
+func (s *TagService) RecountTags(ctx context.Context, names []string) error {
+	for _, name := range names {
+		count, err := s.db.Collection("posts").CountDocuments(
+			ctx,
+			bson.M{"tags": name, "status": "approved"},
+		)
+		if err != nil {
+			return fmt.Errorf("统计标签笔记数失败: %w", err)
+		}
+
+		_, err = s.db.Collection("tags").UpdateOne(
+			ctx,
+			bson.M{"name": name},
+			bson.M{"$set": bson.M{"post_count": count}},
+		)
+		if err != nil {
+			return fmt.Errorf("更新标签笔记数失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListTags 获取标签列表，按笔记数降序
+func (s *TagService) ListTags(ctx context.Context, query *model.TagQuery) (*model.TagListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+
+	filter := bson.M{}
+	if keyword := NormalizeTagName(query.Keyword); keyword != "" {
+		pattern := regexp.QuoteMeta(keyword)
+		filter["$or"] = []bson.M{
+			{"name": bson.M{"$regex": pattern}},
+			{"aliases": bson.M{"$regex": pattern}},
+		}
+	}
+
+	total, err := s.db.Collection("tags").CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签总数失败: %w", err)
+	}
+
+	cursor, err := s.db.Collection("tags").Find(
+		ctx,
+		filter,
+		options.Find().
+			SetSort(bson.D{{Key: "post_count", Value: -1}, {Key: "_id", Value: 1}}).
+			SetSkip(int64((query.Page-1)*query.Limit)).
+			SetLimit(int64(query.Limit)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签列表失败: %w", err)
+	}
+
+	tags := []*model.Tag{}
+	if err = cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+
+	return &model.TagListResponse{
+		Total: total,
+		List:  tags,
+	}, nil
+}
+
+// GetTag 获取标签页信息，支持通过同义词访问
+func (s *TagService) GetTag(ctx context.Context, name string, viewerID string) (*model.TagDetailResponse, error) {
+	tag, err := s.findTag(ctx, NormalizeTagName(name))
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, apperr.ErrTagNotFound
+	}
+
+	response := &model.TagDetailResponse{Tag: tag}
+	if userID, err := primitive.ObjectIDFromHex(viewerID); err == nil {
+		count, err := s.db.Collection("tag_follows").CountDocuments(
+			ctx,
+			bson.M{"user_id": userID, "tag_id": tag.ID},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询关注状态失败: %w", err)
+		}
+		response.IsFollowing = count > 0
+	}
+
+	return response, nil
+}
+
+// FollowTag 关注标签
+func (s *TagService) FollowTag(ctx context.Context, userID string, name string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	tag, err := s.findTag(ctx, NormalizeTagName(name))
+	if err != nil {
+		return err
+	}
+	if tag == nil {
+		return apperr.ErrTagNotFound
+	}
+
+	result, err := s.db.Collection("tag_follows").UpdateOne(
+		ctx,
+		bson.M{"user_id": userObjectID, "tag_id": tag.ID},
+		bson.M{"$setOnInsert": bson.M{"created_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("关注标签失败: %w", err)
+	}
+	if result.UpsertedCount == 0 {
+		return apperr.ErrTagFollowed
+	}
+
+	_, err = s.db.Collection("tags").UpdateOne(
+		ctx,
+		bson.M{"_id": tag.ID},
+		bson.M{"$inc": bson.M{"follower_count": 1}},
+	)
+	return err
+}
+
+// UnfollowTag 取消关注标签
+func (s *TagService) UnfollowTag(ctx context.Context, userID string, name string) error {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	tag, err := s.findTag(ctx, NormalizeTagName(name))
+	if err != nil {
+		return err
+	}
+	if tag == nil {
+		return apperr.ErrTagNotFound
+	}
+
+	result, err := s.db.Collection("tag_follows").DeleteOne(
+		ctx,
+		bson.M{"user_id": userObjectID, "tag_id": tag.ID},
+	)
+	if err != nil {
+		return fmt.Errorf("取消关注标签失败: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return apperr.ErrTagNotFollowed
+	}
+
+	_, err = s.db.Collection("tags").UpdateOne(
+		ctx,
+		bson.M{"_id": tag.ID},
+		bson.M{"$inc": bson.M{"follower_count": -1}},
+	)
+	return err
+}
+
+// GetFollowedTags 获取用户关注的标签
+func (s *TagService) GetFollowedTags(ctx context.Context, userID string) ([]*model.Tag, error) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的用户ID: %w", err)
+	}
+
+	return followedTags(ctx, s.db, userObjectID)
+}
+
+// followedTags 查询用户关注的标签
+func followedTags(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) ([]*model.Tag, error) {
+	cursor, err := db.Collection("tag_follows").Find(
+		ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询关注的标签失败: %w", err)
+	}
+
+	var follows []model.TagFollow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	if len(follows) == 0 {
+		return []*model.Tag{}, nil
+	}
+
+	tagIDs := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		tagIDs = append(tagIDs, follow.TagID)
+	}
+
+	tagCursor, err := db.Collection("tags").Find(ctx, bson.M{"_id": bson.M{"$in": tagIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+
+	tags := []*model.Tag{}
+	if err = tagCursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// checkNamesAvailable 检查名称是否已被其他标签占用（作为规范名称或同义词）
+func (s *TagService) checkNamesAvailable(ctx context.Context, names []string, excludeID primitive.ObjectID) error {
+	for _, name := range names {
+		tag, err := s.findTag(ctx, name)
+		if err != nil {
+			return err
+		}
+		if tag != nil && tag.ID != excludeID {
+			return apperr.ErrTagNameTaken.WithArgs(name, tag.Name)
+		}
+	}
+	return nil
+}
+
+// rewritePostTags 将笔记中的旧标签替换为规范名称
+func (s *TagService) rewritePostTags(ctx context.Context, from []string, to string) error {
+	if len(from) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"tags": bson.M{"$in": from}}
+
+	// 记录受影响的笔记，改写后删除它们的详情缓存
+	cursor, err := s.db.Collection("posts").Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return fmt.Errorf("查询笔记失败: %w", err)
+	}
+	var posts []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &posts); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(posts))
+	for _, post := range posts {
+		keys = append(keys, postCacheKey(post.ID))
+	}
+	defer s.cache.Delete(ctx, keys...)
+
+	_, err = s.db.Collection("posts").UpdateMany(
+		ctx,
+		filter,
+		bson.M{"$addToSet": bson.M{"tags": to}},
+	)
+	if err != nil {
+		return fmt.Errorf("更新笔记标签失败: %w", err)
+	}
+
+	_, err = s.db.Collection("posts").UpdateMany(
+		ctx,
+		filter,
+		bson.M{"$pull": bson.M{"tags": bson.M{"$in": from}}},
+	)
+	if err != nil {
+		return fmt.Errorf("更新笔记标签失败: %w", err)
+	}
+	return nil
+}
+
+// CreateTag 管理员创建标签
+func (s *TagService) CreateTag(ctx context.Context, req *model.CreateTagRequest) (*model.Tag, error) {
+	name := NormalizeTagName(req.Name)
+	if name == "" {
+		return nil, apperr.ErrEmptyTagName
+	}
+
+	aliases := removeName(normalizeTagNames(req.Aliases), name)
+	if err := s.checkNamesAvailable(ctx, append([]string{name}, aliases...), primitive.NilObjectID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tag := &model.Tag{
+		ID:          primitive.NewObjectID(),
+		Name:        name,
+		Aliases:     aliases,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := s.db.Collection("tags").InsertOne(ctx, tag); err != nil {
+		return nil, fmt.Errorf("创建标签失败: %w", err)
+	}
+
+	// 已使用同义词的笔记统一改为规范名称
+	if err := s.rewritePostTags(ctx, aliases, name); err != nil {
+		return nil, err
+	}
+	if err := s.RecountTags(ctx, []string{name}); err != nil {
+		return nil, err
+	}
+
+	return s.findTagByID(ctx, tag.ID.Hex())
+}
+
+// UpdateTag 管理员更新标签：重命名时旧名称自动成为同义词
+func (s *TagService) UpdateTag(ctx context.Context, tagID string, req *model.UpdateTagRequest) (*model.Tag, error) {
+	tag, err := s.findTagByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := tag.Name
+	if req.Name != "" {
+		name = NormalizeTagName(req.Name)
+	}
+
+	aliases := tag.Aliases
+	if req.Aliases != nil {
+		aliases = normalizeTagNames(req.Aliases)
+	}
+	if name != tag.Name {
+		aliases = append(aliases, tag.Name)
+	}
+	aliases = removeName(normalizeTagNames(aliases), name)
+
+	if err := s.checkNamesAvailable(ctx, append([]string{name}, aliases...), tag.ID); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"name":       name,
+		"aliases":    aliases,
+		"updated_at": time.Now(),
+	}
+	if req.Description != "" {
+		update["description"] = req.Description
+	}
+
+	_, err = s.db.Collection("tags").UpdateOne(
+		ctx,
+		bson.M{"_id": tag.ID},
+		bson.M{"$set": update},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("更新标签失败: %w", err)
+	}
+
+	if err := s.rewritePostTags(ctx, aliases, name); err != nil {
+		return nil, err
+	}
+	if err := s.RecountTags(ctx, []string{name}); err != nil {
+		return nil, err
+	}
+
+	return s.findTagByID(ctx, tagID)
+}
+
+// MergeTags 管理员合并标签：源标签的名称和同义词并入目标标签，笔记和关注关系迁移到目标标签，源标签删除
+func (s *TagService) MergeTags(ctx context.Context, targetID string, req *model.MergeTagsRequest) (*model.Tag, error) {
+	target, err := s.findTagByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]bool, len(req.SourceIDs))
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == targetID {
+			return nil, apperr.ErrMergeIntoSelf
+		}
+		if merged[sourceID] {
+			continue
+		}
+		merged[sourceID] = true
+
+		source, err := s.findTagByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 先将源标签的名称加入目标标签的同义词再迁移和删除，中途失败时这些名称仍能解析到目标标签
+		names := removeName(normalizeTagNames(append([]string{source.Name}, source.Aliases...)), target.Name)
+		_, err = s.db.Collection("tags").UpdateOne(
+			ctx,
+			bson.M{"_id": target.ID},
+			bson.M{"$addToSet": bson.M{"aliases": bson.M{"$each": names}}},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("更新目标标签失败: %w", err)
+		}
+
+		if err := s.rewritePostTags(ctx, names, target.Name); err != nil {
+			return nil, err
+		}
+		if err := s.moveTagFollows(ctx, source.ID, target.ID); err != nil {
+			return nil, err
+		}
+
+		if _, err := s.db.Collection("tags").DeleteOne(ctx, bson.M{"_id": source.ID}); err != nil {
+			return nil, fmt.Errorf("删除源标签失败: %w", err)
+		}
+	}
+
+	followerCount, err := s.db.Collection("tag_follows").CountDocuments(
+		ctx,
+		bson.M{"tag_id": target.ID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("统计关注人数失败: %w", err)
+	}
+
+	_, err = s.db.Collection("tags").UpdateOne(
+		ctx,
+		bson.M{"_id": target.ID},
+		bson.M{"$set": bson.M{
+			"follower_count": followerCount,
+			"updated_at":     time.Now(),
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("更新目标标签失败: %w", err)
+	}
+
+	if err := s.RecountTags(ctx, []string{target.Name}); err != nil {
+		return nil, err
+	}
+
+	return s.findTagByID(ctx, targetID)
+}
+
+// moveTagFollows 将源标签的关注记录迁移到目标标签，已关注目标标签的记录直接删除
+func (s *TagService) moveTagFollows(ctx context.Context, sourceID, targetID primitive.ObjectID) error {
+	cursor, err := s.db.Collection("tag_follows").Find(ctx, bson.M{"tag_id": sourceID})
+	if err != nil {
+		return fmt.Errorf("查询标签关注记录失败: %w", err)
+	}
+
+	var follows []model.TagFollow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return err
+	}
+
+	for _, follow := range follows {
+		count, err := s.db.Collection("tag_follows").CountDocuments(
+			ctx,
+			bson.M{"user_id": follow.UserID, "tag_id": targetID},
+		)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			_, err = s.db.Collection("tag_follows").DeleteOne(ctx, bson.M{"_id": follow.ID})
+		} else {
+			_, err = s.db.Collection("tag_follows").UpdateOne(
+				ctx,
+				bson.M{"_id": follow.ID},
+				bson.M{"$set": bson.M{"tag_id": targetID}},
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("迁移标签关注记录失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeName 从列表中移除指定名称
+func removeName(names []string, name string) []string {
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"blue-note/model"
+	"blue-note/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// abortingTransactor 模拟 MongoDB 事务：事务中任一写入返回错误后整个事务中止，
+// 即使 fn 吞掉了错误，提交时也会失败
+type abortingTransactor struct {
+	aborted bool
+}
+
+var errTransactionAborted = errors.New("事务已中止")
+
+func (t *abortingTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.aborted = false
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	if t.aborted {
+		return errTransactionAborted
+	}
+	return nil
+}
+
+// fail 记录事务中的写入错误
+func (t *abortingTransactor) fail(err error) {
+	if err != nil {
+		t.aborted = true
+	}
+}
+
+type abortingLikeRepo struct {
+	repository.LikeRepo
+	tx *abortingTransactor
+}
+
+func (r *abortingLikeRepo) LikePost(ctx context.Context, like *model.PostLike) (bool, error) {
+	created, err := r.LikeRepo.LikePost(ctx, like)
+	r.tx.fail(err)
+	return created, err
+}
+
+type abortingFollowRepo struct {
+	repository.FollowRepo
+	tx *abortingTransactor
+}
+
+func (r *abortingFollowRepo) Follow(ctx context.Context, follow *model.UserFollow) (bool, error) {
+	created, err := r.FollowRepo.Follow(ctx, follow)
+	r.tx.fail(err)
+	return created, err
+}
+
+func newAbortingRepos() *repository.Repositories {
+	repos := repository.NewMemory()
+	tx := &abortingTransactor{}
+	repos.Tx = tx
+	repos.Likes = &abortingLikeRepo{LikeRepo: repos.Likes, tx: tx}
+	repos.Follows = &abortingFollowRepo{FollowRepo: repos.Follows, tx: tx}
+	return repos
+}
+
+func TestRepeatLikeInTransaction(t *testing.T) {
+	ctx := context.Background()
+	repos := newAbortingRepos()
+
+	author := &model.User{Username: "alice"}
+	reader := &model.User{Username: "bob"}
+	for _, user := range []*model.User{author, reader} {
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+	}
+	post := &model.Post{ID: primitive.NewObjectID(), UserID: author.ID, Status: "approved", CreatedAt: time.Now()}
+	if err := repos.Posts.Create(ctx, post); err != nil {
+		t.Fatalf("创建笔记失败: %v", err)
+	}
+
+	s := NewPostService(repos, nil, nil, nil, nil, nil)
+	for i := 0; i < 2; i++ {
+		state, err := s.LikePost(ctx, post.ID.Hex(), reader.ID.Hex())
+		if err != nil {
+			t.Fatalf("第 %d 次点赞失败: %v", i+1, err)
+		}
+		if !state.Liked || state.Likes != 1 {
+			t.Errorf("第 %d 次点赞状态 = %+v, 期望 liked=true likes=1", i+1, state)
+		}
+	}
+
+	user, err := repos.Users.FindByID(ctx, author.ID)
+	if err != nil {
+		t.Fatalf("查询作者失败: %v", err)
+	}
+	if user.LikeCount != 1 {
+		t.Errorf("作者获赞数 = %d, 期望 1", user.LikeCount)
+	}
+}
+
+func TestRepeatFollowInTransaction(t *testing.T) {
+	ctx := context.Background()
+	repos := newAbortingRepos()
+
+	alice := &model.User{Username: "alice"}
+	bob := &model.User{Username: "bob"}
+	for _, user := range []*model.User{alice, bob} {
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+	}
+
+	s := NewProfileService(repos, nil, nil, nil, nil)
+	first, created, err := s.addFollow(ctx, alice.ID, bob.ID)
+	if err != nil || !created {
+		t.Fatalf("关注失败: created=%v err=%v", created, err)
+	}
+	again, created, err := s.addFollow(ctx, alice.ID, bob.ID)
+	if err != nil || created {
+		t.Fatalf("重复关注 created=%v err=%v, 期望不报错且不新建", created, err)
+	}
+	if again != first {
+		t.Errorf("重复关注返回的记录ID = %s, 期望 %s", again.Hex(), first.Hex())
+	}
+
+	user, err := repos.Users.FindByID(ctx, bob.ID)
+	if err != nil {
+		t.Fatalf("查询用户失败: %v", err)
+	}
+	if user.FansCount != 1 {
+		t.Errorf("粉丝数 = %d, 期望 1", user.FansCount)
+	}
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"blue-note/config"
+	"blue-note/health"
+	"blue-note/model"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 各类互动在热度计算中的权重
+const (
+	trendingLikeWeight    = 1.0
+	trendingCommentWeight = 2.0
+	trendingCollectWeight = 3.0
+	trendingPublishWeight = 2.0 // 新发布笔记对其标签的贡献
+)
+
+// TrendingService 热门标签和热门笔记服务
+type TrendingService struct {
+	db          *mongo.Database
+	fileService *FileService
+	cancel      context.CancelFunc
+	done        chan struct{}
+	heartbeat   health.Heartbeat
+}
+
+// NewTrendingService 创建热门榜单服务实例
+func NewTrendingService(db *mongo.Database, fileService *FileService) *TrendingService {
+	return &TrendingService{
+		db:          db,
+		fileService: fileService,
+	}
+}
+
+// Start 启动定时计算任务，启动时立即计算一次
+func (s *TrendingService) Start() {
+	interval := time.Duration(config.GetConfig().Trending.RefreshInterval) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	s.heartbeat.Start(interval)
+
+	go func() {
+		defer close(s.done)
+
+		s.heartbeat.Beat(s.RefreshAll(ctx))
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.heartbeat.Beat(s.RefreshAll(ctx))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时计算任务，取消正在进行的计算并等待其退出
+func (s *TrendingService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.heartbeat.Stop()
+}
+
+// Health 返回定时计算任务的运行状态
+func (s *TrendingService) Health(ctx context.Context) health.Result {
+	return s.heartbeat.Check(ctx)
+}
+
+// RefreshAll 重新计算所有窗口的榜单，返回计算失败的窗口的错误
+func (s *TrendingService) RefreshAll(ctx context.Context) error {
+	var errs []error
+	for _, window := range model.TrendingWindows {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.Refresh(ctx, window); err != nil {
+			slog.ErrorContext(ctx, "计算热门榜单失败", "window", window, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", window, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Refresh 计算指定窗口的热门标签和热门笔记
+// 热度 = 当前窗口加权互动量 + 0.5 * 相比上一个窗口的增量（仅计正增长）
+func (s *TrendingService) Refresh(ctx context.Context, window model.TrendingWindow) error {
+	now := time.Now()
+	duration := window.Duration()
+	curStart := now.Add(-duration)
+	prevStart := curStart.Add(-duration)
+
+	cur, err := s.getEngagement(ctx, curStart, now)
+	if err != nil {
+		return err
+	}
+	prev, err := s.getEngagement(ctx, prevStart, curStart)
+	if err != nil {
+		return err
+	}
+
+	// 查询涉及的笔记以及两个窗口内新发布的笔记
+	postIDs := make([]primitive.ObjectID, 0, len(cur)+len(prev))
+	for id := range cur {
+		postIDs = append(postIDs, id)
+	}
+	for id := range prev {
+		if _, ok := cur[id]; !ok {
+			postIDs = append(postIDs, id)
+		}
+	}
+
+	cursor, err := s.db.Collection("posts").Find(
+		ctx,
+		bson.M{
+			"status": "approved",
+			"hidden": bson.M{"$ne": true},
+			"$or": []bson.M{
+				{"_id": bson.M{"$in": postIDs}},
+				{"created_at": bson.M{"$gte": prevStart, "$lt": now}},
+			},
+		},
+		options.Find().SetProjection(bson.M{"tags": 1, "created_at": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("查询笔记失败: %w", err)
+	}
+
+	var posts []model.Post
+	if err = cursor.All(ctx, &posts); err != nil {
+		return err
+	}
+
+	hours := duration.Hours()
+	postCur := make(map[string]float64)
+	postPrev := make(map[string]float64)
+	tagCur := make(map[string]float64)
+	tagPrev := make(map[string]float64)
+
+	for _, post := range posts {
+		c, p := cur[post.ID], prev[post.ID]
+		if !post.CreatedAt.Before(curStart) {
+			c += trendingPublishWeight
+		} else if !post.CreatedAt.Before(prevStart) {
+			p += trendingPublishWeight
+		}
+
+		if cur[post.ID] > 0 {
+			postCur[post.ID.Hex()] = cur[post.ID]
+			postPrev[post.ID.Hex()] = prev[post.ID]
+		}
+
+		for _, tag := range post.Tags {
+			tagCur[tag] += c
+			tagPrev[tag] += p
+		}
+	}
+
+	topN := config.GetConfig().Trending.TopN
+	if topN <= 0 {
+		topN = 50
+	}
+
+	if err := s.saveSnapshot(ctx, window, model.TrendingKindPost, rankTrending(postCur, postPrev, hours, topN), now); err != nil {
+		return err
+	}
+	return s.saveSnapshot(ctx, window, model.TrendingKindTag, rankTrending(tagCur, tagPrev, hours, topN), now)
+}
+
+// getEngagement 统计时间范围内每篇笔记的加权互动量
+func (s *TrendingService) getEngagement(ctx context.Context, start, end time.Time) (map[primitive.ObjectID]float64, error) {
+	weights := map[string]float64{
+		"post_likes":       trendingLikeWeight,
+		"comments":         trendingCommentWeight,
+		"post_collections": trendingCollectWeight,
+	}
+
+	result := make(map[primitive.ObjectID]float64)
+	for collection, weight := range weights {
+		pipeline := []bson.M{
+			{"$match": bson.M{"created_at": bson.M{"$gte": start, "$lt": end}}},
+			{"$group": bson.M{
+				"_id":   "$post_id",
+				"count": bson.M{"$sum": 1},
+			}},
+		}
+
+		cursor, err := s.db.Collection(collection).Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, fmt.Errorf("统计%s失败: %w", collection, err)
+		}
+
+		var rows []struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Count int                `bson:"count"`
+		}
+		if err = cursor.All(ctx, &rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			result[row.ID] += float64(row.Count) * weight
+		}
+	}
+
+	return result, nil
+}
+
+// rankTrending 根据两个窗口的互动量计算热度并排序
+func rankTrending(cur, prev map[string]float64, hours float64, topN int) []model.TrendingItem {
+	items := make([]model.TrendingItem, 0, len(cur))
+	for key, c := range cur {
+		if c <= 0 {
+			continue
+		}
+		p := prev[key]
+		growth := c - p
+		if growth < 0 {
+			growth = 0
+		}
+		items = append(items, model.TrendingItem{
+			Key:       key,
+			Score:     c + growth*0.5,
+			Count:     c,
+			PrevCount: p,
+			Velocity:  (c - p) / hours,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Key < items[j].Key
+	})
+
+	if len(items) > topN {
+		items = items[:topN]
+	}
+	return items
+}
+
+// saveSnapshot 覆盖保存榜单快照
+func (s *TrendingService) saveSnapshot(ctx context.Context, window model.TrendingWindow, kind model.TrendingKind, items []model.TrendingItem, computedAt time.Time) error {
+	_, err := s.db.Collection("trending").UpdateOne(
+		ctx,
+		bson.M{"window": window, "kind": kind},
+		bson.M{"$set": bson.M{
+			"items":       items,
+			"computed_at": computedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("保存热门榜单失败: %w", err)
+	}
+	return nil
+}
+
+// getSnapshot 读取榜单快照，尚未计算时返回 nil
+func (s *TrendingService) getSnapshot(ctx context.Context, window model.TrendingWindow, kind model.TrendingKind) (*model.TrendingSnapshot, error) {
+	var snapshot model.TrendingSnapshot
+	err := s.db.Collection("trending").FindOne(
+		ctx,
+		bson.M{"window": window, "kind": kind},
+	).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetTrending 获取指定窗口的热门标签和热门笔记
+func (s *TrendingService) GetTrending(ctx context.Context, viewerID string, query *model.TrendingQuery) (*model.TrendingResponse, error) {
+	window := model.TrendingWindow(query.Window)
+	if window == "" {
+		window = model.TrendingWindowDay
+	}
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+
+	response := &model.TrendingResponse{
+		Window: window,
+		Tags:   []model.TrendingTag{},
+		Posts:  []model.PostItem{},
+	}
+
+	tagSnapshot, err := s.getSnapshot(ctx, window, model.TrendingKindTag)
+	if err != nil {
+		return nil, fmt.Errorf("查询热门标签失败: %w", err)
+	}
+	if tagSnapshot != nil {
+		response.ComputedAt = &tagSnapshot.ComputedAt
+		for i, item := range tagSnapshot.Items {
+			if i >= query.Limit {
+				break
+			}
+			response.Tags = append(response.Tags, model.TrendingTag{
+				Tag:      item.Key,
+				Score:    item.Score,
+				Velocity: item.Velocity,
+			})
+		}
+	}
+
+	postSnapshot, err := s.getSnapshot(ctx, window, model.TrendingKindPost)
+	if err != nil {
+		return nil, fmt.Errorf("查询热门笔记失败: %w", err)
+	}
+	if postSnapshot == nil {
+		return response, nil
+	}
+
+	postIDs := make([]primitive.ObjectID, 0, len(postSnapshot.Items))
+	for _, item := range postSnapshot.Items {
+		if id, err := primitive.ObjectIDFromHex(item.Key); err == nil {
+			postIDs = append(postIDs, id)
+		}
+	}
+
+	hiddenIDs, err := hiddenUserIDs(ctx, s.db, viewerID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := findVisiblePostsInOrder(ctx, s.db, postIDs, hiddenIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询热门笔记失败: %w", err)
+	}
+	if len(posts) > query.Limit {
+		posts = posts[:query.Limit]
+	}
+	response.Posts = buildPostItems(ctx, s.fileService, posts)
+
+	return response, nil
+}
+
+// SuggestTags 标签联想，优先返回近期热门的标签，不足时从已发布笔记的标签中补充
+func (s *TrendingService) SuggestTags(ctx context.Context, query *model.TagSuggestQuery) ([]string, error) {
+	if query.Limit < 1 {
+		query.Limit = 10
+	}
+	keyword := strings.ToLower(strings.TrimSpace(query.Keyword))
+
+	seen := make(map[string]bool)
+	suggestions := make([]string, 0, query.Limit)
+	add := func(tag string) bool {
+		if seen[tag] || !strings.Contains(strings.ToLower(tag), keyword) {
+			return len(suggestions) < query.Limit
+		}
+		seen[tag] = true
+		suggestions = append(suggestions, tag)
+		return len(suggestions) < query.Limit
+	}
+
+	for _, window := range []model.TrendingWindow{model.TrendingWindowDay, model.TrendingWindowWeek, model.TrendingWindowHour} {
+		snapshot, err := s.getSnapshot(ctx, window, model.TrendingKindTag)
+		if err != nil {
+			return nil, fmt.Errorf("查询热门标签失败: %w", err)
+		}
+		if snapshot == nil {
+			continue
+		}
+		for _, item := range snapshot.Items {
+			if !add(item.Key) {
+				return suggestions, nil
+			}
+		}
+	}
+
+	if keyword == "" {
+		return suggestions, nil
+	}
+
+	tags, err := s.db.Collection("posts").Distinct(
+		ctx,
+		"tags",
+		bson.M{
+			"status": "approved",
+			"tags":   bson.M{"$regex": regexp.QuoteMeta(keyword), "$options": "i"},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	for _, tag := range tags {
+		if name, ok := tag.(string); ok && !add(name) {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
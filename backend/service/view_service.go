@@ -0,0 +1,240 @@
+package service
+
+import (
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/health"
+	"blue-note/model"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pendingView 尚未写入数据库的浏览数，笔记和每日统计分开记录，写入失败时只重试失败的部分
+type pendingView struct {
+	authorID primitive.ObjectID
+	views    int // 待写入笔记的浏览数
+	stats    int // 待写入每日统计的浏览数
+}
+
+// ViewService 笔记浏览计数服务
+// 同一访客在去重窗口内重复浏览只计一次，浏览数先在内存中累积，再定时批量写入
+type ViewService struct {
+	db       *mongo.Database
+	cache    *cache.Group
+	mu       sync.Mutex
+	seen     map[string]time.Time // 访客+笔记 -> 去重窗口结束时间
+	pending  map[primitive.ObjectID]*pendingView
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	heartbeat health.Heartbeat
+}
+
+// NewViewService 创建浏览计数服务实例
+func NewViewService(db *mongo.Database, cache *cache.Group) *ViewService {
+	return &ViewService{
+		db:      db,
+		cache:   cache,
+		seen:    make(map[string]time.Time),
+		pending: make(map[primitive.ObjectID]*pendingView),
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// ViewerFingerprint 生成访客标识：登录用户使用用户ID，匿名访客使用 IP 和 User-Agent 的摘要
+func ViewerFingerprint(userID string, clientIP string, userAgent string) string {
+	if userID != "" {
+		return "u:" + userID
+	}
+	sum := sha256.Sum256([]byte(clientIP + "|" + userAgent))
+	return "a:" + hex.EncodeToString(sum[:8])
+}
+
+// RecordView 记录一次浏览，作者本人浏览和未审核通过的笔记不计数
+func (s *ViewService) RecordView(post *model.Post, viewerKey string) {
+	if post.Status != "approved" || viewerKey == "u:"+post.UserID.Hex() {
+		return
+	}
+
+	window := time.Duration(config.GetConfig().Views.DedupWindow) * time.Minute
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+
+	key := post.ID.Hex() + "|" + viewerKey
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if expireAt, ok := s.seen[key]; ok && now.Before(expireAt) {
+		return
+	}
+	s.seen[key] = now.Add(window)
+
+	s.addPending(post.ID, post.UserID, 1, 1)
+}
+
+// addPending 累加待写入的浏览数，调用方需持有锁
+func (s *ViewService) addPending(postID, authorID primitive.ObjectID, views, stats int) {
+	if p, ok := s.pending[postID]; ok {
+		p.views += views
+		p.stats += stats
+		return
+	}
+	s.pending[postID] = &pendingView{authorID: authorID, views: views, stats: stats}
+}
+
+// Start 启动定时写入任务
+func (s *ViewService) Start() {
+	interval := time.Duration(config.GetConfig().Views.FlushInterval) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	s.heartbeat.Start(interval)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := s.Flush(context.Background())
+				if err != nil {
+					slog.Error("写入浏览数失败", "error", err)
+				}
+				s.heartbeat.Beat(err)
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时任务，并在 ctx 的期限内写入剩余的浏览数
+func (s *ViewService) Stop(ctx context.Context) error {
+	var err error
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.done
+		s.heartbeat.Stop()
+		err = s.Flush(ctx)
+	})
+	return err
+}
+
+// Health 返回定时写入任务的运行状态
+func (s *ViewService) Health(ctx context.Context) health.Result {
+	return s.heartbeat.Check(ctx)
+}
+
+// Flush 将累积的浏览数写入笔记和每日统计，并清理过期的去重记录
+func (s *ViewService) Flush(ctx context.Context) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[primitive.ObjectID]*pendingView)
+	for key, expireAt := range s.seen {
+		if now.After(expireAt) {
+			delete(s.seen, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	date := now.Format("2006-01-02")
+	var postIDs, statIDs []primitive.ObjectID
+	var postModels, statModels []mongo.WriteModel
+	for postID, p := range pending {
+		if p.views > 0 {
+			postIDs = append(postIDs, postID)
+			postModels = append(postModels, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": postID}).
+				SetUpdate(bson.M{"$inc": bson.M{"views": p.views}}))
+		}
+		if p.stats > 0 {
+			statIDs = append(statIDs, postID)
+			statModels = append(statModels, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"post_id": postID, "date": date}).
+				SetUpdate(bson.M{
+					"$inc":         bson.M{"views": p.stats},
+					"$setOnInsert": bson.M{"author_id": p.authorID},
+				}).
+				SetUpsert(true))
+		}
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	var postErr, statErr error
+	if len(postModels) > 0 {
+		_, postErr = s.db.Collection("posts").BulkWrite(ctx, postModels, opts)
+	}
+	if len(statModels) > 0 {
+		_, statErr = s.db.Collection("post_daily_stats").BulkWrite(ctx, statModels, opts)
+	}
+
+	// 写入失败的浏览数放回待写入队列，下次继续写入；写入成功的笔记删除详情缓存
+	failed := make(map[primitive.ObjectID]bool)
+	s.mu.Lock()
+	for _, i := range failedWrites(postErr, len(postIDs)) {
+		p := pending[postIDs[i]]
+		s.addPending(postIDs[i], p.authorID, p.views, 0)
+		failed[postIDs[i]] = true
+	}
+	for _, i := range failedWrites(statErr, len(statIDs)) {
+		p := pending[statIDs[i]]
+		s.addPending(statIDs[i], p.authorID, 0, p.stats)
+	}
+	s.mu.Unlock()
+
+	keys := make([]string, 0, len(postIDs))
+	for _, postID := range postIDs {
+		if !failed[postID] {
+			keys = append(keys, postCacheKey(postID))
+		}
+	}
+	s.cache.Delete(ctx, keys...)
+
+	return errors.Join(postErr, statErr)
+}
+
+// failedWrites 返回批量写入中失败的操作下标。只有部分操作失败时按错误中的下标返回，其他错误视为全部失败
+func failedWrites(err error, n int) []int {
+	if err == nil {
+		return nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		indexes := make([]int, 0, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			indexes = append(indexes, writeErr.Index)
+		}
+		return indexes
+	}
+
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"blue-note/model"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestFlushKeepsPendingOnError(t *testing.T) {
+	// 连接不可用的地址，写入会在服务器选择超时后失败
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	s := NewViewService(client.Database("test"), nil)
+	post := &model.Post{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: "approved"}
+	s.RecordView(post, "u:a")
+	s.RecordView(post, "u:b")
+
+	if err := s.Flush(context.Background()); err == nil {
+		t.Fatal("写入应失败")
+	}
+	s.RecordView(post, "u:c")
+
+	p := s.pending[post.ID]
+	if p == nil || p.views != 3 || p.stats != 3 {
+		t.Fatalf("待写入 = %+v, 期望 views=3 stats=3", p)
+	}
+}
+
+func TestFailedWrites(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []int
+	}{
+		{name: "成功", err: nil, want: nil},
+		{name: "部分失败", err: mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+			{WriteError: mongo.WriteError{Index: 1}},
+			{WriteError: mongo.WriteError{Index: 3}},
+		}}, want: []int{1, 3}},
+		{name: "全部失败", err: errors.New("connection refused"), want: []int{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedWrites(tt.err, 4); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failedWrites() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
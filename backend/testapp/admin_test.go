@@ -0,0 +1,147 @@
+package testapp_test
+
+import (
+	"blue-note/testapp"
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAdminRequiresRole(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "未登录", wantStatus: http.StatusUnauthorized},
+		{name: "普通用户", token: user.Token, wantStatus: http.StatusForbidden},
+		{name: "管理员", token: admin.Token, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		for _, path := range []string{"/api/v1/admin/stats", "/api/v1/admin/posts/pending"} {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				resp := app.Do(http.MethodGet, path, nil, tt.token)
+				if resp.Code != tt.wantStatus {
+					t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+				}
+			})
+		}
+	}
+}
+
+func TestAdminStatistics(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+
+	approved := createPost(t, app, alice, "已通过")
+	approvePost(t, app, approved)
+	createPost(t, app, alice, "待审核")
+	createComment(t, app, admin, approved, "评论")
+
+	resp := app.Do(http.MethodGet, "/api/v1/admin/stats", nil, admin.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取统计失败: %d %s", resp.Code, resp.Body)
+	}
+	data := resp.Data()
+
+	want := map[string]int{
+		"totalUsers":    2,
+		"totalPosts":    2,
+		"pendingPosts":  1,
+		"totalComments": 1,
+	}
+	for key, value := range want {
+		if got := number(t, data, key); got != value {
+			t.Errorf("%s = %d, 期望 %d", key, got, value)
+		}
+	}
+
+	daily := data["dailyStats"].([]interface{})
+	if len(daily) != 7 {
+		t.Fatalf("dailyStats 长度 = %d, 期望 7", len(daily))
+	}
+	today := daily[6].(map[string]interface{})
+	if number(t, today, "newPosts") != 2 || number(t, today, "newUsers") != 2 {
+		t.Errorf("今日统计不正确: %v", today)
+	}
+
+	tags := data["tagStats"].([]interface{})
+	if len(tags) != 1 || tags[0].(map[string]interface{})["tag"] != "旅行" {
+		t.Errorf("标签统计不正确: %v", tags)
+	}
+}
+
+func TestReviewPost(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+
+	first := createPost(t, app, alice, "第一篇")
+	second := createPost(t, app, alice, "第二篇")
+
+	tests := []struct {
+		name        string
+		postID      string
+		body        map[string]string
+		wantStatus  int
+		wantPending int
+	}{
+		{name: "状态无效", postID: first, body: map[string]string{"status": "draft"}, wantStatus: http.StatusBadRequest, wantPending: 2},
+		{name: "通过", postID: first, body: map[string]string{"status": "approved"}, wantStatus: http.StatusOK, wantPending: 1},
+		{name: "拒绝", postID: second, body: map[string]string{"status": "rejected", "reason": "内容违规"}, wantStatus: http.StatusOK, wantPending: 0},
+		{name: "笔记不存在", postID: "000000000000000000000000", body: map[string]string{"status": "approved"}, wantStatus: http.StatusNotFound, wantPending: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPut, "/api/v1/admin/posts/"+tt.postID+"/review", tt.body, admin.Token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+
+			pending := app.Do(http.MethodGet, "/api/v1/admin/posts/pending", nil, admin.Token)
+			if total := number(t, pending.Data(), "total"); total != tt.wantPending {
+				t.Errorf("待审核数 = %d, 期望 %d", total, tt.wantPending)
+			}
+		})
+	}
+
+	post, err := app.Repos.Posts.FindByID(context.Background(), objectID(t, second))
+	if err != nil {
+		t.Fatalf("查询笔记失败: %v", err)
+	}
+	if post.Status != "rejected" || post.RejectReason != "内容违规" {
+		t.Errorf("拒绝结果不正确: status=%s reason=%s", post.Status, post.RejectReason)
+	}
+
+	// 审核通过后作者的笔记数随之更新
+	app.Eventually(func() bool {
+		user, err := app.Repos.Users.FindByID(context.Background(), alice.ID)
+		return err == nil && user.PostCount == 1
+	}, "作者笔记数更新")
+}
+
+func TestAdminConfigHidesSecrets(t *testing.T) {
+	app := testapp.New(t)
+	admin := app.CreateUser("root", "secret1", "admin")
+
+	resp := app.Do(http.MethodGet, "/api/v1/admin/config", nil, admin.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取配置失败: %d %s", resp.Code, resp.Body)
+	}
+	if bytes.Contains(resp.Body, []byte("test-secret")) {
+		t.Errorf("配置中包含 JWT 密钥: %s", resp.Body)
+	}
+
+	jwt := resp.Data()["jwt"].(map[string]interface{})
+	if jwt["secret"] != "******" || number(t, jwt, "expire") != 1 {
+		t.Errorf("jwt 配置不正确: %v", jwt)
+	}
+}
@@ -0,0 +1,193 @@
+package testapp_test
+
+import (
+	"blue-note/repository"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestLogin(t *testing.T) {
+	app := testapp.New(t)
+	app.CreateUser("alice", "secret1", "")
+	banned := app.CreateUser("mallory", "secret1", "")
+	isBanned := true
+	if err := app.Repos.Users.Update(context.Background(), banned.ID, repository.UserUpdate{Banned: &isBanned}); err != nil {
+		t.Fatalf("封禁用户失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		username    string
+		password    string
+		badCaptcha  bool
+		omitCaptcha bool
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "新用户自动注册", username: "bob", password: "secret1", wantStatus: http.StatusCreated, wantMessage: "注册成功"},
+		{name: "已有用户登录", username: "alice", password: "secret1", wantStatus: http.StatusOK, wantMessage: "登录成功"},
+		{name: "密码错误", username: "alice", password: "wrong", wantStatus: http.StatusUnauthorized, wantMessage: "用户名或密码错误"},
+		{name: "验证码错误", username: "alice", password: "secret1", badCaptcha: true, wantStatus: http.StatusUnauthorized, wantMessage: "用户名或密码错误"},
+		{name: "缺少验证码", username: "alice", password: "secret1", omitCaptcha: true, wantStatus: http.StatusBadRequest, wantMessage: "请求参数错误"},
+		{name: "封禁用户", username: "mallory", password: "secret1", wantStatus: http.StatusUnauthorized, wantMessage: "用户名或密码错误"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]string{"username": tt.username, "password": tt.password}
+			if !tt.omitCaptcha {
+				id, code := app.Captcha()
+				if tt.badCaptcha {
+					code = "000000"
+				}
+				body["captchaId"] = id
+				body["captchaCode"] = code
+			}
+
+			resp := app.Do(http.MethodPost, "/api/v1/auth/login", body, "")
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if msg := resp.JSON()["message"]; msg != tt.wantMessage {
+				t.Errorf("message = %v, 期望 %v", msg, tt.wantMessage)
+			}
+			if resp.Code < 300 {
+				data := resp.Data()
+				if data["token"] == "" || data["username"] != tt.username {
+					t.Errorf("登录结果不正确: %v", data)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginTokenAuthorizesRequests(t *testing.T) {
+	app := testapp.New(t)
+
+	id, code := app.Captcha()
+	resp := app.Do(http.MethodPost, "/api/v1/auth/login", map[string]string{
+		"username": "carol", "password": "secret1", "captchaId": id, "captchaCode": code,
+	}, "")
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("注册失败: %d %s", resp.Code, resp.Body)
+	}
+	data := resp.Data()
+
+	profile := app.Do(http.MethodGet, "/api/v1/users/profile/"+data["user_id"].(string), nil, data["token"].(string))
+	if profile.Code != http.StatusOK {
+		t.Fatalf("获取资料失败: %d %s", profile.Code, profile.Body)
+	}
+	if got := profile.Data()["username"]; got != "carol" {
+		t.Errorf("username = %v, 期望 carol", got)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "未提供token", header: "", wantStatus: http.StatusUnauthorized},
+		{name: "格式错误", header: "Token " + user.Token, wantStatus: http.StatusUnauthorized},
+		{name: "无效token", header: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+		{name: "有效token", header: "Bearer " + user.Token, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.DoWithHeader(http.MethodGet, "/api/v1/posts/drafts", "Authorization", tt.header)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	app := testapp.New(t)
+	app.CreateUser("alice", "secret1", "")
+
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantStatus  int
+	}{
+		{name: "原密码错误", oldPassword: "wrong", newPassword: "secret2", wantStatus: http.StatusBadRequest},
+		{name: "新密码过短", oldPassword: "secret1", newPassword: "123", wantStatus: http.StatusBadRequest},
+		{name: "修改成功", oldPassword: "secret1", newPassword: "secret2", wantStatus: http.StatusOK},
+		{name: "旧密码失效", oldPassword: "secret1", newPassword: "secret3", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, code := app.Captcha()
+			resp := app.Do(http.MethodPost, "/api/v1/auth/change-password", map[string]string{
+				"username":    "alice",
+				"oldPassword": tt.oldPassword,
+				"newPassword": tt.newPassword,
+				"captchaId":   id,
+				"captchaCode": code,
+			}, "")
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+
+	// 新密码可以登录
+	id, code := app.Captcha()
+	resp := app.Do(http.MethodPost, "/api/v1/auth/login", map[string]string{
+		"username": "alice", "password": "secret2", "captchaId": id, "captchaCode": code,
+	}, "")
+	if resp.Code != http.StatusOK {
+		t.Errorf("使用新密码登录失败: %d %s", resp.Code, resp.Body)
+	}
+}
+
+func TestBannedAccount(t *testing.T) {
+	app := testapp.New(t)
+	banned := app.CreateUser("mallory", "secret1", "")
+	user := app.CreateUser("alice", "secret1", "")
+	postID := createPost(t, app, user, "封禁")
+	approvePost(t, app, postID)
+
+	// 封禁前签发的 token 不再有效
+	isBanned := true
+	if err := app.Repos.Users.Update(context.Background(), banned.ID, repository.UserUpdate{Banned: &isBanned}); err != nil {
+		t.Fatalf("封禁用户失败: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       interface{}
+		token      string
+		wantStatus int
+	}{
+		{name: "需要登录的接口", method: http.MethodGet, path: "/api/v1/posts/drafts", token: banned.Token, wantStatus: http.StatusForbidden},
+		{name: "修改状态不能解封", method: http.MethodPut, path: "/api/v1/users/profile", body: map[string]string{"status": "happy"}, token: banned.Token, wantStatus: http.StatusForbidden},
+		{name: "可选登录的接口按未登录处理", method: http.MethodGet, path: "/api/v1/posts/" + postID, token: banned.Token, wantStatus: http.StatusOK},
+		{name: "心情状态不影响账号", method: http.MethodPut, path: "/api/v1/users/profile", body: map[string]string{"status": "banned"}, token: user.Token, wantStatus: http.StatusOK},
+		{name: "设置心情状态后仍可访问", method: http.MethodGet, path: "/api/v1/posts/drafts", token: user.Token, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, tt.path, tt.body, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantStatus == http.StatusForbidden && number(t, resp.JSON(), "code") != 40307 {
+				t.Errorf("code = %v, 期望 40307", resp.JSON()["code"])
+			}
+		})
+	}
+}
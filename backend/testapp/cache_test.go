@@ -0,0 +1,119 @@
+package testapp_test
+
+import (
+	"blue-note/repository"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestPostDetailCache(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	postID := createPost(t, app, author, "原标题")
+	approvePost(t, app, postID)
+	path := "/api/v1/posts/" + postID
+
+	// 修改后笔记重新进入待审核，以作者身份查看
+	detail := func() map[string]interface{} {
+		t.Helper()
+		resp := app.Do(http.MethodGet, path, nil, author.Token)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("获取笔记失败: %d %s", resp.Code, resp.Body)
+		}
+		return resp.Data()
+	}
+	detail()
+
+	// 绕过服务直接修改仓储，缓存未过期前读到的仍是旧数据
+	title := "仓储标题"
+	if err := app.Repos.Posts.Update(context.Background(), objectID(t, postID), repository.PostUpdate{Title: &title}); err != nil {
+		t.Fatalf("修改笔记失败: %v", err)
+	}
+	if got := detail()["title"]; got != "原标题" {
+		t.Fatalf("title = %v, 期望命中缓存", got)
+	}
+
+	// 通过接口修改时删除缓存
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      interface{}
+		token     string
+		wantTitle string
+		wantLikes int
+	}{
+		{name: "点赞", method: http.MethodPost, path: path + "/like", token: reader.Token, wantTitle: "仓储标题", wantLikes: 1},
+		{name: "修改", method: http.MethodPut, path: path, body: map[string]string{"title": "新标题"}, token: author.Token, wantTitle: "新标题", wantLikes: 1},
+		{name: "取消点赞", method: http.MethodDelete, path: path + "/like", token: reader.Token, wantTitle: "新标题", wantLikes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := app.Do(tt.method, tt.path, tt.body, tt.token); resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			data := detail()
+			if data["title"] != tt.wantTitle || number(t, data, "likes") != tt.wantLikes {
+				t.Errorf("详情 = title:%v likes:%v, 期望 title:%s likes:%d", data["title"], data["likes"], tt.wantTitle, tt.wantLikes)
+			}
+		})
+	}
+
+	if resp := app.Do(http.MethodDelete, path, nil, author.Token); resp.Code != http.StatusOK {
+		t.Fatalf("删除笔记失败: %d %s", resp.Code, resp.Body)
+	}
+	if resp := app.Do(http.MethodGet, path, nil, ""); resp.Code != http.StatusNotFound {
+		t.Errorf("删除后仍能获取笔记: %d", resp.Code)
+	}
+}
+
+func TestProfileCache(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+	carol := app.CreateUser("carol", "secret1", "")
+	path := "/api/v1/users/profile/" + bob.ID.Hex()
+
+	profile := func(token string) map[string]interface{} {
+		t.Helper()
+		resp := app.Do(http.MethodGet, path, nil, token)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("获取用户资料失败: %d %s", resp.Code, resp.Body)
+		}
+		return resp.Data()
+	}
+	if got := number(t, profile(bob.Token), "fansCount"); got != 0 {
+		t.Fatalf("fansCount = %d, 期望 0", got)
+	}
+
+	if resp := app.Do(http.MethodPost, "/api/v1/users/follow/"+bob.ID.Hex(), nil, alice.Token); resp.Code != http.StatusOK {
+		t.Fatalf("关注失败: %d %s", resp.Code, resp.Body)
+	}
+
+	// 缓存中不保存查看者相关的状态
+	tests := []struct {
+		name          string
+		token         string
+		wantFollowing bool
+	}{
+		{name: "其他用户", token: carol.Token, wantFollowing: false},
+		{name: "粉丝", token: alice.Token, wantFollowing: true},
+		{name: "本人", token: bob.Token, wantFollowing: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := profile(tt.token)
+			if got := number(t, data, "fansCount"); got != 1 {
+				t.Errorf("fansCount = %d, 期望 1", got)
+			}
+			if data["isFollowing"] != tt.wantFollowing {
+				t.Errorf("isFollowing = %v, 期望 %v", data["isFollowing"], tt.wantFollowing)
+			}
+		})
+	}
+}
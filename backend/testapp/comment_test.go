@@ -0,0 +1,165 @@
+package testapp_test
+
+import (
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+)
+
+type commentList struct {
+	Data struct {
+		Comments []struct {
+			ID       string `json:"id"`
+			UserID   string `json:"user_id"`
+			Content  string `json:"content"`
+			Likes    int    `json:"likes"`
+			IsAuthor bool   `json:"is_author"`
+		} `json:"comments"`
+		Total int `json:"total"`
+	} `json:"data"`
+}
+
+func createComment(t *testing.T, app *testapp.App, user *testapp.User, postID, content string) string {
+	t.Helper()
+
+	resp := app.Do(http.MethodPost, "/api/v1/posts/"+postID+"/comments", map[string]string{"content": content}, user.Token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("发表评论失败: %d %s", resp.Code, resp.Body)
+	}
+	return resp.Data()["id"].(string)
+}
+
+func TestCreateComment(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	blocked := app.CreateUser("carol", "secret1", "")
+	postID := createPost(t, app, author, "评论测试")
+	approvePost(t, app, postID)
+	pendingID := createPost(t, app, author, "待审核")
+	app.Do(http.MethodPost, "/api/v1/users/block/"+blocked.ID.Hex(), nil, author.Token)
+
+	tests := []struct {
+		name       string
+		postID     string
+		body       interface{}
+		user       *testapp.User
+		wantStatus int
+		wantAuthor bool
+	}{
+		{name: "读者评论", postID: postID, body: map[string]string{"content": "写得好"}, user: reader, wantStatus: http.StatusCreated},
+		{name: "作者评论", postID: postID, body: map[string]string{"content": "谢谢"}, user: author, wantStatus: http.StatusCreated, wantAuthor: true},
+		{name: "内容为空", postID: postID, body: map[string]string{}, user: reader, wantStatus: http.StatusBadRequest},
+		{name: "无效笔记ID", postID: "invalid", body: map[string]string{"content": "内容"}, user: reader, wantStatus: http.StatusBadRequest},
+		{name: "被作者拉黑", postID: postID, body: map[string]string{"content": "内容"}, user: blocked, wantStatus: http.StatusForbidden},
+		{name: "待审核笔记", postID: pendingID, body: map[string]string{"content": "内容"}, user: reader, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPost, "/api/v1/posts/"+tt.postID+"/comments", tt.body, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantStatus == http.StatusCreated {
+				if isAuthor := resp.Data()["is_author"]; isAuthor != tt.wantAuthor {
+					t.Errorf("is_author = %v, 期望 %v", isAuthor, tt.wantAuthor)
+				}
+			}
+		})
+	}
+
+	post, err := app.Repos.Posts.FindByID(context.Background(), objectID(t, postID))
+	if err != nil {
+		t.Fatalf("查询笔记失败: %v", err)
+	}
+	if post.Comments != 2 {
+		t.Errorf("评论数 = %d, 期望 2", post.Comments)
+	}
+}
+
+func TestGetPostComments(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+	carol := app.CreateUser("carol", "secret1", "")
+	postID := createPost(t, app, author, "评论列表")
+	approvePost(t, app, postID)
+
+	createComment(t, app, bob, postID, "第一条")
+	popular := createComment(t, app, carol, postID, "第二条")
+	createComment(t, app, bob, postID, "第三条")
+	app.Do(http.MethodPost, "/api/v1/posts/"+postID+"/comments/"+popular+"/like", nil, author.Token)
+
+	tests := []struct {
+		name      string
+		query     string
+		user      *testapp.User
+		wantTotal int
+		wantFirst string
+	}{
+		{name: "按时间升序", query: "?sortBy=time&order=asc", user: author, wantTotal: 3, wantFirst: "第一条"},
+		{name: "按时间降序", query: "?sortBy=time&order=desc", user: author, wantTotal: 3, wantFirst: "第三条"},
+		{name: "按点赞数", query: "?sortBy=likes", user: author, wantTotal: 3, wantFirst: "第二条"},
+		{name: "分页", query: "?sortBy=time&order=asc&page=2&pageSize=2", user: author, wantTotal: 3, wantFirst: "第三条"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodGet, "/api/v1/posts/"+postID+"/comments"+tt.query, nil, tt.user.Token)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			var list commentList
+			resp.Decode(&list)
+			if list.Data.Total != tt.wantTotal {
+				t.Errorf("total = %d, 期望 %d", list.Data.Total, tt.wantTotal)
+			}
+			if len(list.Data.Comments) == 0 || list.Data.Comments[0].Content != tt.wantFirst {
+				t.Errorf("第一条评论不正确: %+v", list.Data.Comments)
+			}
+		})
+	}
+
+	// 屏蔽的用户的评论不可见
+	app.Do(http.MethodPost, "/api/v1/users/mute/"+bob.ID.Hex(), nil, carol.Token)
+	var list commentList
+	app.Do(http.MethodGet, "/api/v1/posts/"+postID+"/comments", nil, carol.Token).Decode(&list)
+	if list.Data.Total != 1 {
+		t.Errorf("屏蔽后 total = %d, 期望 1", list.Data.Total)
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	commenter := app.CreateUser("bob", "secret1", "")
+	postID := createPost(t, app, author, "删除评论")
+	approvePost(t, app, postID)
+	commentID := createComment(t, app, commenter, postID, "待删除")
+
+	tests := []struct {
+		name       string
+		user       *testapp.User
+		wantStatus int
+	}{
+		{name: "他人删除", user: author, wantStatus: http.StatusForbidden},
+		{name: "本人删除", user: commenter, wantStatus: http.StatusOK},
+		{name: "重复删除", user: commenter, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodDelete, "/api/v1/posts/"+postID+"/comments/"+commentID, nil, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+
+	post, _ := app.Repos.Posts.FindByID(context.Background(), objectID(t, postID))
+	if post.Comments != 0 {
+		t.Errorf("评论数 = %d, 期望 0", post.Comments)
+	}
+}
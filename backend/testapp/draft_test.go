@@ -0,0 +1,113 @@
+package testapp_test
+
+import (
+	"blue-note/testapp"
+	"net/http"
+	"testing"
+)
+
+func saveDraft(t *testing.T, app *testapp.App, user *testapp.User, draftID string, title string) *testapp.Response {
+	t.Helper()
+
+	path := "/api/v1/posts/draft"
+	if draftID != "" {
+		path += "?draftId=" + draftID
+	}
+	return app.Do(http.MethodPost, path, map[string]interface{}{
+		"title":   title,
+		"content": "草稿内容",
+		"type":    "image",
+		"tags":    []string{"美食"},
+		"files":   []string{"image/draft.jpg"},
+	}, user.Token)
+}
+
+func TestDraftLifecycle(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	// 创建草稿
+	resp := saveDraft(t, app, user, "", "第一版")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("保存草稿失败: %d %s", resp.Code, resp.Body)
+	}
+	draft := resp.Data()
+	draftID := draft["postId"].(string)
+	if draft["status"] != "draft" || draft["coverImage"] != "image/draft.jpg" {
+		t.Errorf("草稿内容不正确: %v", draft)
+	}
+
+	// 更新草稿
+	resp = saveDraft(t, app, user, draftID, "第二版")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("更新草稿失败: %d %s", resp.Code, resp.Body)
+	}
+	if title := resp.Data()["title"]; title != "第二版" {
+		t.Errorf("title = %v, 期望 第二版", title)
+	}
+
+	// 草稿列表和详情
+	resp = app.Do(http.MethodGet, "/api/v1/posts/drafts", nil, user.Token)
+	if total := number(t, resp.Data(), "total"); total != 1 {
+		t.Errorf("草稿数 = %d, 期望 1", total)
+	}
+	resp = app.Do(http.MethodGet, "/api/v1/posts/draft/"+draftID, nil, user.Token)
+	if resp.Code != http.StatusOK || resp.Data()["title"] != "第二版" {
+		t.Errorf("获取草稿详情失败: %d %s", resp.Code, resp.Body)
+	}
+
+	// 发布草稿
+	resp = app.Do(http.MethodPost, "/api/v1/posts/draft/"+draftID+"/publish", map[string]string{"title": "正式版"}, user.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("发布草稿失败: %d %s", resp.Code, resp.Body)
+	}
+	published := resp.Data()
+	if published["status"] != "pending" || published["title"] != "正式版" {
+		t.Errorf("发布结果不正确: %v", published)
+	}
+
+	// 发布后不再是草稿
+	resp = app.Do(http.MethodGet, "/api/v1/posts/drafts", nil, user.Token)
+	if total := number(t, resp.Data(), "total"); total != 0 {
+		t.Errorf("发布后草稿数 = %d, 期望 0", total)
+	}
+}
+
+func TestDraftOwnership(t *testing.T) {
+	app := testapp.New(t)
+	owner := app.CreateUser("alice", "secret1", "")
+	other := app.CreateUser("bob", "secret1", "")
+
+	draftID := saveDraft(t, app, owner, "", "草稿").Data()["postId"].(string)
+	postID := createPost(t, app, owner, "已发布")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "他人查看", method: http.MethodGet, path: "/api/v1/posts/draft/" + draftID, token: other.Token, wantStatus: http.StatusNotFound},
+		{name: "他人删除", method: http.MethodDelete, path: "/api/v1/posts/draft/" + draftID, token: other.Token, wantStatus: http.StatusNotFound},
+		{name: "他人发布", method: http.MethodPost, path: "/api/v1/posts/draft/" + draftID + "/publish", token: other.Token, wantStatus: http.StatusNotFound},
+		{name: "非草稿笔记", method: http.MethodGet, path: "/api/v1/posts/draft/" + postID, token: owner.Token, wantStatus: http.StatusNotFound},
+		{name: "无效ID", method: http.MethodGet, path: "/api/v1/posts/draft/invalid", token: owner.Token, wantStatus: http.StatusBadRequest},
+		{name: "作者删除", method: http.MethodDelete, path: "/api/v1/posts/draft/" + draftID, token: owner.Token, wantStatus: http.StatusOK},
+		{name: "重复删除", method: http.MethodDelete, path: "/api/v1/posts/draft/" + draftID, token: owner.Token, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, tt.path, nil, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+
+	// 更新他人的草稿失败
+	if resp := saveDraft(t, app, other, postID, "篡改"); resp.Code != http.StatusNotFound {
+		t.Errorf("更新他人草稿 状态码 = %d, 期望 404", resp.Code)
+	}
+}
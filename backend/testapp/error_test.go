@@ -0,0 +1,103 @@
+package testapp_test
+
+import (
+	"blue-note/testapp"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorResponse(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	postID := createPost(t, app, user, "错误响应")
+	app.Router.GET("/test/panic", func(ctx *gin.Context) {
+		panic("数据库连接串 mongodb://secret")
+	})
+
+	tests := []struct {
+		name        string
+		resp        *testapp.Response
+		wantStatus  int
+		wantCode    float64
+		wantMessage string
+	}{
+		{
+			name:        "参数校验失败",
+			resp:        app.Do(http.MethodPost, "/api/v1/posts/"+postID+"/comments", map[string]string{}, user.Token),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    40003,
+			wantMessage: "请求参数错误",
+		},
+		{
+			name:        "英文错误信息",
+			resp:        app.DoWithHeader(http.MethodGet, "/api/v1/posts/invalid", "Accept-Language", "en-US,en;q=0.9"),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    40001,
+			wantMessage: "Invalid ID",
+		},
+		{
+			name:        "笔记不存在",
+			resp:        app.Do(http.MethodGet, "/api/v1/posts/000000000000000000000000", nil, ""),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    40402,
+			wantMessage: "笔记不存在",
+		},
+		{
+			name:        "路由不存在",
+			resp:        app.Do(http.MethodGet, "/api/v1/unknown", nil, ""),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    40400,
+			wantMessage: "记录不存在",
+		},
+		{
+			name:        "未登录",
+			resp:        app.Do(http.MethodGet, "/api/v1/posts/drafts", nil, ""),
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    40102,
+			wantMessage: "未提供认证信息",
+		},
+		{
+			name:        "处理函数panic",
+			resp:        app.Do(http.MethodGet, "/test/panic", nil, ""),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    50001,
+			wantMessage: "服务器内部错误",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", tt.resp.Code, tt.wantStatus, tt.resp.Body)
+			}
+			body := tt.resp.JSON()
+			if body["code"] != tt.wantCode || body["message"] != tt.wantMessage {
+				t.Errorf("code = %v, message = %v, 期望 %v %s", body["code"], body["message"], tt.wantCode, tt.wantMessage)
+			}
+			if id, _ := body["request_id"].(string); id == "" || id != tt.resp.Header.Get("X-Request-ID") {
+				t.Errorf("request_id = %v, 响应头 = %s", body["request_id"], tt.resp.Header.Get("X-Request-ID"))
+			}
+		})
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	postID := createPost(t, app, user, "参数校验")
+
+	resp := app.Do(http.MethodPost, "/api/v1/posts/"+postID+"/comments", map[string]string{}, user.Token)
+	var body struct {
+		Details []struct {
+			Field   string `json:"field"`
+			Rule    string `json:"rule"`
+			Message string `json:"message"`
+		} `json:"details"`
+	}
+	resp.Decode(&body)
+	if len(body.Details) != 1 || body.Details[0].Field != "content" || body.Details[0].Rule != "required" || body.Details[0].Message != "content 为必填字段" {
+		t.Errorf("details = %+v, body=%s", body.Details, resp.Body)
+	}
+}
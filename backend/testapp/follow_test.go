@@ -0,0 +1,175 @@
+package testapp_test
+
+import (
+	"blue-note/testapp"
+	"net/http"
+	"testing"
+)
+
+func TestFollowUser(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+	path := "/api/v1/users/follow/" + bob.ID.Hex()
+
+	// 关注和取消关注都是幂等的，计数不会重复增减
+	tests := []struct {
+		name            string
+		method          string
+		wantFollowing   bool
+		wantFollowCount int
+		wantFansCount   int
+	}{
+		{name: "关注", method: http.MethodPost, wantFollowing: true, wantFollowCount: 1, wantFansCount: 1},
+		{name: "重复关注", method: http.MethodPost, wantFollowing: true, wantFollowCount: 1, wantFansCount: 1},
+		{name: "取消关注", method: http.MethodDelete, wantFollowing: false, wantFollowCount: 0, wantFansCount: 0},
+		{name: "重复取消关注", method: http.MethodDelete, wantFollowing: false, wantFollowCount: 0, wantFansCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, path, nil, alice.Token)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			data := resp.Data()
+			if data["isFollowing"] != tt.wantFollowing {
+				t.Errorf("isFollowing = %v, 期望 %v", data["isFollowing"], tt.wantFollowing)
+			}
+			if got := number(t, data, "followCount"); got != tt.wantFollowCount {
+				t.Errorf("followCount = %d, 期望 %d", got, tt.wantFollowCount)
+			}
+			if got := number(t, data, "fansCount"); got != tt.wantFansCount {
+				t.Errorf("fansCount = %d, 期望 %d", got, tt.wantFansCount)
+			}
+
+			check := app.Do(http.MethodGet, "/api/v1/users/follow/check/"+bob.ID.Hex(), nil, alice.Token)
+			if check.Code != http.StatusOK {
+				t.Fatalf("检查关注状态失败: %d %s", check.Code, check.Body)
+			}
+		})
+	}
+}
+
+func TestFollowLists(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+	carol := app.CreateUser("carol", "secret1", "")
+
+	app.Do(http.MethodPost, "/api/v1/users/follow/"+bob.ID.Hex(), nil, alice.Token)
+	app.Do(http.MethodPost, "/api/v1/users/follow/"+carol.ID.Hex(), nil, alice.Token)
+	app.Do(http.MethodPost, "/api/v1/users/follow/"+bob.ID.Hex(), nil, carol.Token)
+
+	tests := []struct {
+		name      string
+		path      string
+		user      *testapp.User
+		wantTotal int
+	}{
+		{name: "alice 的关注", path: "/api/v1/users/" + alice.ID.Hex() + "/following", user: alice, wantTotal: 2},
+		{name: "bob 的粉丝", path: "/api/v1/users/" + bob.ID.Hex() + "/fans", user: alice, wantTotal: 2},
+		{name: "carol 的粉丝", path: "/api/v1/users/" + carol.ID.Hex() + "/fans", user: bob, wantTotal: 1},
+		{name: "bob 的关注", path: "/api/v1/users/" + bob.ID.Hex() + "/following", user: bob, wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodGet, tt.path, nil, tt.user.Token)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			if total := number(t, resp.Data(), "total"); total != tt.wantTotal {
+				t.Errorf("total = %d, 期望 %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestBlockUser(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+
+	app.Do(http.MethodPost, "/api/v1/users/follow/"+bob.ID.Hex(), nil, alice.Token)
+	app.Do(http.MethodPost, "/api/v1/users/follow/"+alice.ID.Hex(), nil, bob.Token)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		user       *testapp.User
+		wantStatus int
+		wantError  string
+	}{
+		{name: "拉黑自己", method: http.MethodPost, path: "/api/v1/users/block/" + alice.ID.Hex(), user: alice, wantStatus: http.StatusBadRequest, wantError: "不能拉黑自己"},
+		{name: "用户不存在", method: http.MethodPost, path: "/api/v1/users/block/000000000000000000000000", user: alice, wantStatus: http.StatusNotFound, wantError: "用户不存在"},
+		{name: "拉黑", method: http.MethodPost, path: "/api/v1/users/block/" + bob.ID.Hex(), user: alice, wantStatus: http.StatusOK},
+		{name: "重复拉黑", method: http.MethodPost, path: "/api/v1/users/block/" + bob.ID.Hex(), user: alice, wantStatus: http.StatusConflict, wantError: "已经拉黑该用户"},
+		{name: "被拉黑者关注", method: http.MethodPost, path: "/api/v1/users/follow/" + alice.ID.Hex(), user: bob, wantStatus: http.StatusForbidden, wantError: "对方已将你拉黑，无法关注"},
+		{name: "拉黑者关注", method: http.MethodPost, path: "/api/v1/users/follow/" + bob.ID.Hex(), user: alice, wantStatus: http.StatusConflict, wantError: "已拉黑该用户，请先取消拉黑"},
+		{name: "取消拉黑", method: http.MethodDelete, path: "/api/v1/users/block/" + bob.ID.Hex(), user: alice, wantStatus: http.StatusOK},
+		{name: "重复取消拉黑", method: http.MethodDelete, path: "/api/v1/users/block/" + bob.ID.Hex(), user: alice, wantStatus: http.StatusConflict, wantError: "未拉黑该用户"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, tt.path, nil, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantError != "" && resp.JSON()["message"] != tt.wantError {
+				t.Errorf("message = %v, 期望 %s", resp.JSON()["message"], tt.wantError)
+			}
+
+			if tt.name == "拉黑" {
+				// 拉黑后双方的关注关系都被解除
+				for _, u := range []*testapp.User{alice, bob} {
+					profile := app.Do(http.MethodGet, "/api/v1/users/profile/"+u.ID.Hex(), nil, u.Token).Data()
+					if number(t, profile, "followCount") != 0 || number(t, profile, "fansCount") != 0 {
+						t.Errorf("%s 的关注数据未清零: %v", u.Username, profile)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMuteUser(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+	path := "/api/v1/users/mute/" + bob.ID.Hex()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantError  string
+		wantTotal  int
+	}{
+		{name: "屏蔽自己", method: http.MethodPost, path: "/api/v1/users/mute/" + alice.ID.Hex(), wantStatus: http.StatusBadRequest, wantError: "不能屏蔽自己", wantTotal: 0},
+		{name: "屏蔽", method: http.MethodPost, path: path, wantStatus: http.StatusOK, wantTotal: 1},
+		{name: "重复屏蔽", method: http.MethodPost, path: path, wantStatus: http.StatusConflict, wantError: "已经屏蔽该用户", wantTotal: 1},
+		{name: "取消屏蔽", method: http.MethodDelete, path: path, wantStatus: http.StatusOK, wantTotal: 0},
+		{name: "重复取消屏蔽", method: http.MethodDelete, path: path, wantStatus: http.StatusConflict, wantError: "未屏蔽该用户", wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, tt.path, nil, alice.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantError != "" && resp.JSON()["message"] != tt.wantError {
+				t.Errorf("message = %v, 期望 %s", resp.JSON()["message"], tt.wantError)
+			}
+
+			list := app.Do(http.MethodGet, "/api/v1/users/mutes", nil, alice.Token)
+			if total := number(t, list.Data(), "total"); total != tt.wantTotal {
+				t.Errorf("屏蔽列表 total = %d, 期望 %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package testapp_test
+
+import (
+	"blue-note/health"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestHealthLive(t *testing.T) {
+	app := testapp.New(t)
+	app.Health.Register("mongodb", true, func(ctx context.Context) health.Result {
+		return health.Down("连接失败")
+	})
+
+	// 存活检查不检查外部依赖
+	resp := app.Do(http.MethodGet, "/health/live", nil, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d, 期望 200, body=%s", resp.Code, resp.Body)
+	}
+}
+
+func TestHealthReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		mongo      health.Result
+		drain      bool
+		wantStatus int
+		want       health.Status
+	}{
+		// 测试应用只使用本地存储，对象存储为降级状态
+		{name: "存储降级", mongo: health.Up(""), wantStatus: http.StatusOK, want: health.StatusDegraded},
+		{name: "数据库不可用", mongo: health.Down("连接失败"), wantStatus: http.StatusServiceUnavailable, want: health.StatusDown},
+		{name: "正在关闭", mongo: health.Up(""), drain: true, wantStatus: http.StatusServiceUnavailable, want: health.StatusDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := testapp.New(t)
+			app.Health.Register("mongodb", true, func(ctx context.Context) health.Result { return tt.mongo })
+			if tt.drain {
+				app.Health.Drain()
+			}
+
+			for _, path := range []string{"/health/ready", "/health"} {
+				resp := app.Do(http.MethodGet, path, nil, "")
+				if resp.Code != tt.wantStatus {
+					t.Fatalf("%s 状态码 = %d, 期望 %d, body=%s", path, resp.Code, tt.wantStatus, resp.Body)
+				}
+
+				var report health.Report
+				resp.Decode(&report)
+				if report.Status != tt.want || report.Draining != tt.drain {
+					t.Errorf("%s status = %s draining = %v, 期望 %s %v", path, report.Status, report.Draining, tt.want, tt.drain)
+				}
+				if got := report.Components["storage"].Status; got != health.StatusDegraded {
+					t.Errorf("storage = %s, 期望 degraded", got)
+				}
+				if got := report.Components["mongodb"]; got.Status != tt.mongo.Status || got.Message != tt.mongo.Message {
+					t.Errorf("mongodb = %+v, 期望 %+v", got, tt.mongo)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package testapp_test
+
+import (
+	"blue-note/repository"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// createPost 通过接口发布一篇图文笔记，返回笔记ID
+func createPost(t *testing.T, app *testapp.App, user *testapp.User, title string) string {
+	t.Helper()
+
+	resp := app.Do(http.MethodPost, "/api/v1/posts", map[string]interface{}{
+		"title":   title,
+		"content": title + " 的内容",
+		"type":    "image",
+		"tags":    []string{"旅行"},
+		"files":   []string{"image/a.jpg"},
+	}, user.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("发布笔记失败: %d %s", resp.Code, resp.Body)
+	}
+	return resp.Data()["postId"].(string)
+}
+
+// approvePost 直接在仓储中将笔记设为审核通过
+func approvePost(t *testing.T, app *testapp.App, postID string) {
+	t.Helper()
+
+	status := "approved"
+	if err := app.Repos.Posts.Update(context.Background(), objectID(t, postID), repository.PostUpdate{Status: &status}); err != nil {
+		t.Fatalf("审核笔记失败: %v", err)
+	}
+}
+
+func objectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatalf("无效的ID %q: %v", hex, err)
+	}
+	return id
+}
+
+// number 读取 JSON 中的数字字段
+func number(t *testing.T, data map[string]interface{}, key string) int {
+	t.Helper()
+
+	v, ok := data[key].(float64)
+	if !ok {
+		t.Fatalf("字段 %s 不是数字: %v", key, data[key])
+	}
+	return int(v)
+}
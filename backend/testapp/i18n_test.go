@@ -0,0 +1,143 @@
+package testapp_test
+
+import (
+	"blue-note/middleware"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLocalizedResponse(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+	postID := createPost(t, app, user, "多语言")
+	approvePost(t, app, postID)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           interface{}
+		token          string
+		acceptLanguage string
+		wantStatus     int
+		wantLanguage   string
+		wantMessage    string
+		wantDetails    []interface{}
+	}{
+		{
+			name:   "默认中文",
+			method: http.MethodGet, path: "/api/v1/posts/" + postID,
+			wantStatus: http.StatusOK, wantLanguage: "zh-CN", wantMessage: "成功",
+		},
+		{
+			name:   "英文成功提示",
+			method: http.MethodGet, path: "/api/v1/posts/" + postID, acceptLanguage: "en-GB,en;q=0.9,zh;q=0.8",
+			wantStatus: http.StatusOK, wantLanguage: "en-US", wantMessage: "Success",
+		},
+		{
+			name:   "英文错误信息",
+			method: http.MethodPost, path: "/api/v1/users/block/" + user.ID.Hex(), token: user.Token, acceptLanguage: "en",
+			wantStatus: http.StatusBadRequest, wantLanguage: "en-US", wantMessage: "You cannot block yourself",
+		},
+		{
+			name:   "英文参数校验",
+			method: http.MethodPost, path: "/api/v1/posts/" + postID + "/comments", body: map[string]string{}, token: user.Token, acceptLanguage: "en-US",
+			wantStatus: http.StatusBadRequest, wantLanguage: "en-US", wantMessage: "Invalid request parameters",
+			wantDetails: []interface{}{map[string]interface{}{"field": "content", "rule": "required", "param": "", "message": "content is required"}},
+		},
+		{
+			name:   "英文配置校验",
+			method: http.MethodPut, path: "/api/v1/admin/settings", token: admin.Token, acceptLanguage: "en-US",
+			body:       map[string]interface{}{"version": 1, "upload": map[string]interface{}{"maxImageSizeMB": 500}},
+			wantStatus: http.StatusBadRequest, wantLanguage: "en-US", wantMessage: "Invalid settings",
+			wantDetails: []interface{}{"upload.maxImageSizeMB must be between 1 and 100"},
+		},
+		{
+			name:   "不支持的语言",
+			method: http.MethodGet, path: "/api/v1/posts/" + postID, acceptLanguage: "fr-FR",
+			wantStatus: http.StatusOK, wantLanguage: "zh-CN", wantMessage: "成功",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.DoWithLanguage(tt.method, tt.path, tt.body, tt.token, tt.acceptLanguage)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if lang := resp.Header.Get("Content-Language"); lang != tt.wantLanguage {
+				t.Errorf("Content-Language = %s, 期望 %s", lang, tt.wantLanguage)
+			}
+			body := resp.JSON()
+			if body["message"] != tt.wantMessage {
+				t.Errorf("message = %v, 期望 %s", body["message"], tt.wantMessage)
+			}
+			if tt.wantDetails != nil {
+				if !reflect.DeepEqual(body["details"], tt.wantDetails) {
+					t.Errorf("details = %v, 期望 %v", body["details"], tt.wantDetails)
+				}
+			}
+		})
+	}
+}
+
+func TestUserLanguagePreference(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	other := app.CreateUser("bob", "secret1", "")
+
+	resp := app.Do(http.MethodPut, "/api/v1/users/profile", map[string]string{"language": "ja-JP"}, user.Token)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("不支持的语言 状态码 = %d, body=%s", resp.Code, resp.Body)
+	}
+	resp = app.Do(http.MethodPut, "/api/v1/users/profile", map[string]string{"language": "en-US"}, user.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("设置语言偏好失败: %d %s", resp.Code, resp.Body)
+	}
+	if resp.Data()["language"] != "en-US" {
+		t.Errorf("language = %v, 期望 en-US", resp.Data()["language"])
+	}
+
+	// 语言偏好仅本人可见
+	resp = app.Do(http.MethodGet, "/api/v1/users/profile/"+user.ID.Hex(), nil, other.Token)
+	if _, ok := resp.Data()["language"]; ok {
+		t.Errorf("其他用户不应看到语言偏好: %s", resp.Body)
+	}
+
+	// 重新签发的 token 带有语言偏好，优先于 Accept-Language
+	saved, err := app.Repos.Users.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("查询用户失败: %v", err)
+	}
+	token, err := middleware.GenerateToken(saved)
+	if err != nil {
+		t.Fatalf("生成token失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		token       string
+		wantMessage string
+	}{
+		{name: "需要登录的接口", path: "/api/v1/posts/drafts", token: token, wantMessage: "Success"},
+		{name: "可选登录的接口", path: "/api/v1/posts", token: token, wantMessage: "Success"},
+		{name: "旧token", path: "/api/v1/posts/drafts", token: user.Token, wantMessage: "成功"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.DoWithLanguage(http.MethodGet, tt.path, nil, tt.token, "zh-CN")
+			if resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			if msg := resp.JSON()["message"]; msg != tt.wantMessage {
+				t.Errorf("message = %v, 期望 %s", msg, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package testapp_test
+
+import (
+	"blue-note/repository"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestLikePost(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	postID := createPost(t, app, author, "点赞测试")
+	approvePost(t, app, postID)
+	path := "/api/v1/posts/" + postID + "/like"
+
+	// 点赞和取消点赞都是幂等的
+	tests := []struct {
+		name      string
+		method    string
+		wantLiked bool
+		wantLikes int
+	}{
+		{name: "点赞", method: http.MethodPost, wantLiked: true, wantLikes: 1},
+		{name: "重复点赞", method: http.MethodPost, wantLiked: true, wantLikes: 1},
+		{name: "取消点赞", method: http.MethodDelete, wantLiked: false, wantLikes: 0},
+		{name: "重复取消点赞", method: http.MethodDelete, wantLiked: false, wantLikes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, path, nil, reader.Token)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			data := resp.Data()
+			if data["liked"] != tt.wantLiked || number(t, data, "likes") != tt.wantLikes {
+				t.Errorf("点赞状态 = %v, 期望 liked=%v likes=%d", data, tt.wantLiked, tt.wantLikes)
+			}
+
+			status := app.Do(http.MethodGet, path, nil, reader.Token).Data()
+			if status["hasLiked"] != tt.wantLiked {
+				t.Errorf("hasLiked = %v, 期望 %v", status["hasLiked"], tt.wantLiked)
+			}
+
+			user, err := app.Repos.Users.FindByID(context.Background(), author.ID)
+			if err != nil {
+				t.Fatalf("查询作者失败: %v", err)
+			}
+			if user.LikeCount != tt.wantLikes {
+				t.Errorf("作者获赞数 = %d, 期望 %d", user.LikeCount, tt.wantLikes)
+			}
+		})
+	}
+}
+
+func TestLikeUnapprovedPost(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+	postID := createPost(t, app, author, "待审核")
+	path := "/api/v1/posts/" + postID + "/like"
+
+	likeCount := func() int {
+		t.Helper()
+		user, err := app.Repos.Users.FindByID(context.Background(), author.ID)
+		if err != nil {
+			t.Fatalf("查询作者失败: %v", err)
+		}
+		return user.LikeCount
+	}
+	review := func(status string) {
+		t.Helper()
+		resp := app.Do(http.MethodPut, "/api/v1/admin/posts/"+postID+"/review", map[string]string{"status": status}, admin.Token)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("审核失败: %d %s", resp.Code, resp.Body)
+		}
+	}
+
+	// 待审核笔记不能点赞
+	if resp := app.Do(http.MethodPost, path, nil, reader.Token); resp.Code != http.StatusNotFound {
+		t.Fatalf("点赞待审核笔记状态码 = %d, 期望 404, body=%s", resp.Code, resp.Body)
+	}
+
+	review("approved")
+	if resp := app.Do(http.MethodPost, path, nil, reader.Token); resp.Code != http.StatusOK {
+		t.Fatalf("点赞失败: %d %s", resp.Code, resp.Body)
+	}
+	app.Eventually(func() bool { return likeCount() == 1 }, "审核通过后作者获赞数应为 1")
+
+	// 审核拒绝后重新计算，不再计入作者获赞数，取消点赞也不会再扣减
+	review("rejected")
+	app.Eventually(func() bool { return likeCount() == 0 }, "审核拒绝后作者获赞数应为 0")
+
+	if resp := app.Do(http.MethodDelete, path, nil, reader.Token); resp.Code != http.StatusOK {
+		t.Fatalf("取消点赞失败: %d %s", resp.Code, resp.Body)
+	}
+	if got := likeCount(); got != 0 {
+		t.Errorf("作者获赞数 = %d, 期望 0", got)
+	}
+}
+
+func TestLikePostErrors(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	blocked := app.CreateUser("bob", "secret1", "")
+	postID := createPost(t, app, author, "点赞失败")
+	approvePost(t, app, postID)
+	pendingID := createPost(t, app, author, "待审核")
+	hiddenID := createPost(t, app, author, "已隐藏")
+	approvePost(t, app, hiddenID)
+	hidden := true
+	if err := app.Repos.Posts.Update(context.Background(), objectID(t, hiddenID), repository.PostUpdate{Hidden: &hidden}); err != nil {
+		t.Fatalf("隐藏笔记失败: %v", err)
+	}
+	app.Do(http.MethodPost, "/api/v1/users/block/"+blocked.ID.Hex(), nil, author.Token)
+
+	tests := []struct {
+		name       string
+		postID     string
+		user       *testapp.User
+		wantStatus int
+	}{
+		{name: "笔记不存在", postID: "000000000000000000000000", user: author, wantStatus: http.StatusNotFound},
+		{name: "无效ID", postID: "invalid", user: author, wantStatus: http.StatusBadRequest},
+		{name: "被作者拉黑", postID: postID, user: blocked, wantStatus: http.StatusForbidden},
+		{name: "待审核笔记", postID: pendingID, user: author, wantStatus: http.StatusNotFound},
+		{name: "隐藏的笔记", postID: hiddenID, user: author, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPost, "/api/v1/posts/"+tt.postID+"/like", nil, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+}
+
+func TestLikedPostsList(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	first := createPost(t, app, author, "第一篇")
+	approvePost(t, app, first)
+	second := createPost(t, app, author, "第二篇")
+	approvePost(t, app, second)
+
+	app.Do(http.MethodPost, "/api/v1/posts/"+first+"/like", nil, reader.Token)
+	app.Do(http.MethodPost, "/api/v1/posts/"+second+"/like", nil, reader.Token)
+
+	resp := app.Do(http.MethodGet, "/api/v1/users/"+reader.ID.Hex()+"/likes", nil, reader.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取喜欢列表失败: %d %s", resp.Code, resp.Body)
+	}
+	if total := number(t, resp.Data(), "total"); total != 2 {
+		t.Errorf("total = %d, 期望 2", total)
+	}
+}
+
+func TestLikeComment(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	postID := createPost(t, app, author, "评论点赞")
+	approvePost(t, app, postID)
+	commentID := createComment(t, app, author, postID, "评论")
+	path := "/api/v1/posts/" + postID + "/comments/" + commentID + "/like"
+
+	tests := []struct {
+		name       string
+		method     string
+		wantStatus int
+		wantLikes  int
+	}{
+		{name: "点赞", method: http.MethodPost, wantStatus: http.StatusOK, wantLikes: 1},
+		{name: "重复点赞", method: http.MethodPost, wantStatus: http.StatusConflict, wantLikes: 1},
+		{name: "取消点赞", method: http.MethodDelete, wantStatus: http.StatusOK, wantLikes: 0},
+		{name: "重复取消点赞", method: http.MethodDelete, wantStatus: http.StatusNotFound, wantLikes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, path, nil, reader.Token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+
+			comment, err := app.Repos.Comments.FindByID(context.Background(), objectID(t, commentID))
+			if err != nil {
+				t.Fatalf("查询评论失败: %v", err)
+			}
+			if comment.Likes != tt.wantLikes {
+				t.Errorf("评论点赞数 = %d, 期望 %d", comment.Likes, tt.wantLikes)
+			}
+		})
+	}
+}
+
+func TestLikeCommentErrors(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	reader := app.CreateUser("bob", "secret1", "")
+	blocked := app.CreateUser("carol", "secret1", "")
+	postID := createPost(t, app, author, "评论点赞")
+	approvePost(t, app, postID)
+	commentID := createComment(t, app, reader, postID, "评论")
+	app.Do(http.MethodPost, "/api/v1/users/block/"+blocked.ID.Hex(), nil, author.Token)
+
+	path := func(commentID string) string {
+		return "/api/v1/posts/" + postID + "/comments/" + commentID + "/like"
+	}
+
+	tests := []struct {
+		name       string
+		commentID  string
+		user       *testapp.User
+		wantStatus int
+	}{
+		{name: "评论不存在", commentID: "000000000000000000000000", user: reader, wantStatus: http.StatusNotFound},
+		{name: "被作者拉黑", commentID: commentID, user: blocked, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPost, path(tt.commentID), nil, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+
+	// 笔记被隐藏后，其下的评论不能点赞
+	hidden := true
+	if err := app.Repos.Posts.Update(context.Background(), objectID(t, postID), repository.PostUpdate{Hidden: &hidden}); err != nil {
+		t.Fatalf("隐藏笔记失败: %v", err)
+	}
+	if resp := app.Do(http.MethodPost, path(commentID), nil, author.Token); resp.Code != http.StatusNotFound {
+		t.Errorf("隐藏笔记下的评论点赞状态码 = %d, 期望 404, body=%s", resp.Code, resp.Body)
+	}
+}
@@ -0,0 +1,95 @@
+package testapp_test
+
+import (
+	"blue-note/logger"
+	"blue-note/testapp"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDHeader(t *testing.T) {
+	app := testapp.New(t)
+
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{name: "未传入时生成", header: ""},
+		{name: "沿用合法的ID", header: "gateway-123.abc", wantSame: true},
+		{name: "包含非法字符", header: "abc def\n伪造日志"},
+		{name: "超过长度", header: strings.Repeat("a", 65)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.DoWithHeader(http.MethodGet, "/api/v1/posts", logger.HeaderRequestID, tt.header)
+			got := resp.Header.Get(logger.HeaderRequestID)
+			if got == "" {
+				t.Fatal("响应中没有 X-Request-ID")
+			}
+			if tt.wantSame && got != tt.header {
+				t.Errorf("X-Request-ID = %q, 期望 %q", got, tt.header)
+			}
+			if !tt.wantSame && got == tt.header {
+				t.Errorf("X-Request-ID = %q, 期望重新生成", got)
+			}
+		})
+	}
+}
+
+func TestRequestIDInServiceLogs(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, "debug", "json")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(l)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "photo.png")
+	part.Write(pngImage(t, 2, 2))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+user.Token)
+	req.Header.Set(logger.HeaderRequestID, "upload-1")
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("上传失败: %d %s", w.Code, w.Body)
+	}
+
+	// 控制器、服务和访问日志都带有同一个请求ID
+	seen := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("日志不是 JSON: %s", line)
+		}
+		if entry["request_id"] != "upload-1" {
+			t.Errorf("日志缺少请求ID: %s", line)
+		}
+		seen[entry["msg"].(string)] = true
+	}
+	for _, msg := range []string{"开始上传文件", "使用本地存储上传文件", "请求完成"} {
+		if !seen[msg] {
+			t.Errorf("没有记录 %q, 日志: %s", msg, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), user.Token) {
+		t.Error("日志中包含 token")
+	}
+}
@@ -0,0 +1,104 @@
+package testapp_test
+
+import (
+	"blue-note/config"
+	"blue-note/testapp"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// metricValue 从 /metrics 的文本输出中读取某个序列的值，序列不存在时返回 0
+func metricValue(t *testing.T, app *testapp.App, series string) float64 {
+	t.Helper()
+
+	resp := app.Do(http.MethodGet, "/metrics", nil, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取指标失败: %d %s", resp.Code, resp.Body)
+	}
+	for _, line := range strings.Split(string(resp.Body), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("解析指标 %s 失败: %v", line, err)
+			}
+			return v
+		}
+	}
+	return 0
+}
+
+func TestMetrics(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+
+	// 指标注册在全局 Registry 中，其他测试也会计数，这里比较前后的差值
+	tests := []struct {
+		name   string
+		series string
+		action func()
+	}{
+		{
+			name:   "HTTP 请求按路由模板统计",
+			series: `bluenote_http_requests_total{method="GET",route="/api/v1/posts/:postId",status="404"}`,
+			action: func() { app.Do(http.MethodGet, "/api/v1/posts/000000000000000000000000", nil, "") },
+		},
+		{
+			name:   "未匹配的路由",
+			series: `bluenote_http_requests_total{method="GET",route="unmatched",status="404"}`,
+			action: func() { app.Do(http.MethodGet, "/not-found/123", nil, "") },
+		},
+		{
+			name:   "登录密码错误",
+			series: `bluenote_logins_total{result="invalid_password"}`,
+			action: func() {
+				id, code := app.Captcha()
+				app.Do(http.MethodPost, "/api/v1/auth/login", map[string]string{
+					"username": "alice", "password": "wrong-password", "captchaId": id, "captchaCode": code,
+				}, "")
+			},
+		},
+		{
+			name:   "发布笔记",
+			series: `bluenote_posts_created_total{source="post"}`,
+			action: func() { createPost(t, app, alice, "指标") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := metricValue(t, app, tt.series)
+			tt.action()
+			if after := metricValue(t, app, tt.series); after != before+1 {
+				t.Errorf("%s = %v, 期望 %v", tt.series, after, before+1)
+			}
+		})
+	}
+}
+
+func TestMetricsToken(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.Metrics.Token = "scrape-token"
+	defer func() { cfg.Metrics.Token = "" }()
+	app := testapp.New(t)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "未携带 token", wantStatus: http.StatusUnauthorized},
+		{name: "token 错误", token: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "token 正确", token: "scrape-token", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodGet, "/metrics", nil, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d", resp.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package testapp_test
+
+import (
+	"blue-note/repository"
+	"blue-note/testapp"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCreatePost(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	tests := []struct {
+		name           string
+		body           map[string]interface{}
+		token          string
+		wantStatus     int
+		wantPostStatus string
+	}{
+		{
+			name:           "图文笔记",
+			body:           map[string]interface{}{"title": "标题", "content": "内容", "type": "image", "tags": []string{"旅行"}, "files": []string{"image/a.jpg", "image/b.jpg"}},
+			token:          user.Token,
+			wantStatus:     http.StatusOK,
+			wantPostStatus: "pending",
+		},
+		{
+			name:           "保存为草稿",
+			body:           map[string]interface{}{"title": "草稿", "content": "内容", "type": "video", "tags": []string{}, "files": []string{"video/a.mp4"}, "isDraft": true},
+			token:          user.Token,
+			wantStatus:     http.StatusOK,
+			wantPostStatus: "draft",
+		},
+		{
+			name:       "缺少标题",
+			body:       map[string]interface{}{"content": "内容", "type": "image", "tags": []string{}, "files": []string{}},
+			token:      user.Token,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "类型无效",
+			body:       map[string]interface{}{"title": "标题", "content": "内容", "type": "audio", "tags": []string{}, "files": []string{}},
+			token:      user.Token,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "未登录",
+			body:       map[string]interface{}{"title": "标题", "content": "内容", "type": "image", "tags": []string{}, "files": []string{}},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPost, "/api/v1/posts", tt.body, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			data := resp.Data()
+			if data["status"] != tt.wantPostStatus {
+				t.Errorf("status = %v, 期望 %s", data["status"], tt.wantPostStatus)
+			}
+			if data["userId"] != user.ID.Hex() {
+				t.Errorf("userId = %v, 期望 %s", data["userId"], user.ID.Hex())
+			}
+		})
+	}
+}
+
+func TestCreatePostSetsCover(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	postID := createPost(t, app, user, "封面")
+	resp := app.Do(http.MethodGet, "/api/v1/posts/"+postID, nil, user.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取笔记失败: %d %s", resp.Code, resp.Body)
+	}
+	if cover := resp.Data()["coverImage"]; cover != "image/a.jpg" {
+		t.Errorf("coverImage = %v, 期望第一张图片", cover)
+	}
+}
+
+func TestGetPostDetail(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	blocked := app.CreateUser("bob", "secret1", "")
+	other := app.CreateUser("carol", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+	postID := createPost(t, app, author, "详情")
+	approvePost(t, app, postID)
+	pendingID := createPost(t, app, author, "待审核")
+	hiddenID := createPost(t, app, author, "被隐藏")
+	approvePost(t, app, hiddenID)
+	hidden := true
+	if err := app.Repos.Posts.Update(context.Background(), objectID(t, hiddenID), repository.PostUpdate{Hidden: &hidden}); err != nil {
+		t.Fatalf("隐藏笔记失败: %v", err)
+	}
+
+	if resp := app.Do(http.MethodPost, "/api/v1/users/block/"+blocked.ID.Hex(), nil, author.Token); resp.Code != http.StatusOK {
+		t.Fatalf("拉黑失败: %d %s", resp.Code, resp.Body)
+	}
+
+	tests := []struct {
+		name       string
+		postID     string
+		token      string
+		wantStatus int
+	}{
+		{name: "未登录", postID: postID, wantStatus: http.StatusOK},
+		{name: "其他用户", postID: postID, token: other.Token, wantStatus: http.StatusOK},
+		{name: "被作者拉黑", postID: postID, token: blocked.Token, wantStatus: http.StatusNotFound},
+		{name: "待审核笔记未登录", postID: pendingID, wantStatus: http.StatusNotFound},
+		{name: "待审核笔记其他用户", postID: pendingID, token: other.Token, wantStatus: http.StatusNotFound},
+		{name: "待审核笔记作者", postID: pendingID, token: author.Token, wantStatus: http.StatusOK},
+		{name: "待审核笔记管理员", postID: pendingID, token: admin.Token, wantStatus: http.StatusOK},
+		{name: "隐藏笔记其他用户", postID: hiddenID, token: other.Token, wantStatus: http.StatusNotFound},
+		{name: "隐藏笔记作者", postID: hiddenID, token: author.Token, wantStatus: http.StatusOK},
+		{name: "笔记不存在", postID: "000000000000000000000000", wantStatus: http.StatusNotFound},
+		{name: "无效ID", postID: "invalid", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodGet, "/api/v1/posts/"+tt.postID, nil, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+}
+
+func TestGetPostList(t *testing.T) {
+	app := testapp.New(t)
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+
+	approved := createPost(t, app, alice, "已通过")
+	approvePost(t, app, approved)
+	createPost(t, app, alice, "待审核")
+	createPost(t, app, bob, "bob 的笔记")
+
+	tests := []struct {
+		name      string
+		query     string
+		token     string
+		wantTotal int
+	}{
+		{name: "全部", query: "", wantTotal: 3},
+		{name: "按状态", query: "?status=approved", wantTotal: 1},
+		{name: "按作者", query: "?userId=" + bob.ID.Hex(), wantTotal: 1},
+		{name: "分页", query: "?page=2&limit=2", wantTotal: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodGet, "/api/v1/posts"+tt.query, nil, tt.token)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, body=%s", resp.Code, resp.Body)
+			}
+			if total := number(t, resp.Data(), "total"); total != tt.wantTotal {
+				t.Errorf("total = %d, 期望 %d", total, tt.wantTotal)
+			}
+		})
+	}
+
+	// 屏蔽作者后信息流中不再出现其笔记
+	if resp := app.Do(http.MethodPost, "/api/v1/users/mute/"+bob.ID.Hex(), nil, alice.Token); resp.Code != http.StatusOK {
+		t.Fatalf("屏蔽失败: %d %s", resp.Code, resp.Body)
+	}
+	resp := app.Do(http.MethodGet, "/api/v1/posts", nil, alice.Token)
+	if total := number(t, resp.Data(), "total"); total != 2 {
+		t.Errorf("屏蔽后 total = %d, 期望 2", total)
+	}
+}
+
+func TestUpdateAndDeletePost(t *testing.T) {
+	app := testapp.New(t)
+	author := app.CreateUser("alice", "secret1", "")
+	other := app.CreateUser("bob", "secret1", "")
+
+	tests := []struct {
+		name       string
+		method     string
+		body       interface{}
+		token      string
+		wantStatus int
+	}{
+		{name: "他人修改", method: http.MethodPut, body: map[string]string{"title": "新标题"}, token: other.Token, wantStatus: http.StatusForbidden},
+		{name: "作者修改", method: http.MethodPut, body: map[string]string{"title": "新标题"}, token: author.Token, wantStatus: http.StatusOK},
+		{name: "他人删除", method: http.MethodDelete, token: other.Token, wantStatus: http.StatusForbidden},
+		{name: "作者删除", method: http.MethodDelete, token: author.Token, wantStatus: http.StatusOK},
+	}
+
+	postID := createPost(t, app, author, "原标题")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(tt.method, "/api/v1/posts/"+postID, tt.body, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+
+	if resp := app.Do(http.MethodGet, "/api/v1/posts/"+postID, nil, ""); resp.Code != http.StatusNotFound {
+		t.Errorf("删除后仍能获取笔记: %d", resp.Code)
+	}
+}
@@ -0,0 +1,130 @@
+package testapp_test
+
+import (
+	"blue-note/config"
+	"blue-note/testapp"
+	"net/http"
+	"testing"
+)
+
+// setRateLimit 通过管理员接口修改限流规则
+func setRateLimit(t *testing.T, app *testapp.App, admin *testapp.User, name string, rule map[string]interface{}) {
+	t.Helper()
+
+	body := map[string]interface{}{
+		"version":    app.Settings.Current().Version,
+		"rateLimits": map[string]interface{}{name: rule},
+	}
+	if resp := app.Do(http.MethodPut, "/api/v1/admin/settings", body, admin.Token); resp.Code != http.StatusOK {
+		t.Fatalf("修改限流规则失败: %d %s", resp.Code, resp.Body)
+	}
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	app := testapp.New(t)
+	admin := app.CreateUser("root", "secret1", "admin")
+	app.CreateUser("alice", "secret1", "")
+	setRateLimit(t, app, admin, "login", map[string]interface{}{"requests": 2, "window": 60})
+
+	login := func() *testapp.Response {
+		id, code := app.Captcha()
+		body := map[string]string{"username": "alice", "password": "wrong", "captchaId": id, "captchaCode": code}
+		return app.Do(http.MethodPost, "/api/v1/auth/login", body, "")
+	}
+
+	tests := []struct {
+		wantStatus    int
+		wantRemaining string
+	}{
+		{wantStatus: http.StatusUnauthorized, wantRemaining: "1"},
+		{wantStatus: http.StatusUnauthorized, wantRemaining: "0"},
+		{wantStatus: http.StatusTooManyRequests, wantRemaining: "0"},
+	}
+
+	for i, tt := range tests {
+		resp := login()
+		if resp.Code != tt.wantStatus {
+			t.Fatalf("第 %d 次登录状态码 = %d, 期望 %d, body=%s", i+1, resp.Code, tt.wantStatus, resp.Body)
+		}
+		if got := resp.Header.Get("RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("第 %d 次登录 RateLimit-Remaining = %q, 期望 %q", i+1, got, tt.wantRemaining)
+		}
+		if resp.Header.Get("RateLimit-Limit") != "2" || resp.Header.Get("RateLimit-Policy") != "2;w=60" {
+			t.Errorf("第 %d 次登录限流响应头不正确: %v", i+1, resp.Header)
+		}
+		if tt.wantStatus == http.StatusTooManyRequests {
+			if number(t, resp.JSON(), "code") != 42900 || resp.Header.Get("Retry-After") == "" {
+				t.Errorf("限流响应不正确: %v %s", resp.Header, resp.Body)
+			}
+		}
+	}
+}
+
+func TestLoginRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantStatus     int
+	}{
+		// 未配置信任的代理时，伪造的 X-Forwarded-For 不影响按 IP 计数
+		{name: "不信任代理", trustedProxies: nil, wantStatus: http.StatusTooManyRequests},
+		// 请求来自信任的代理时，按 X-Forwarded-For 中的客户端 IP 分别计数
+		{name: "信任代理", trustedProxies: []string{"192.0.2.1"}, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptest 请求的连接地址为 192.0.2.1
+			config.GetConfig().Server.TrustedProxies = tt.trustedProxies
+			t.Cleanup(func() { config.GetConfig().Server.TrustedProxies = nil })
+
+			app := testapp.New(t)
+			admin := app.CreateUser("root", "secret1", "admin")
+			app.CreateUser("alice", "secret1", "")
+			setRateLimit(t, app, admin, "login", map[string]interface{}{"requests": 2, "window": 60})
+
+			var resp *testapp.Response
+			for _, ip := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+				id, code := app.Captcha()
+				body := map[string]string{"username": "alice", "password": "wrong", "captchaId": id, "captchaCode": code}
+				resp = app.DoWithForwardedFor(http.MethodPost, "/api/v1/auth/login", body, "", ip)
+			}
+			if resp.Code != tt.wantStatus {
+				t.Errorf("第 3 次登录状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+}
+
+func TestLikeRateLimitByUser(t *testing.T) {
+	app := testapp.New(t)
+	admin := app.CreateUser("root", "secret1", "admin")
+	alice := app.CreateUser("alice", "secret1", "")
+	bob := app.CreateUser("bob", "secret1", "")
+	setRateLimit(t, app, admin, "like", map[string]interface{}{"requests": 1, "window": 60, "algorithm": "token_bucket", "key": "user"})
+
+	first := createPost(t, app, alice, "第一篇")
+	approvePost(t, app, first)
+	second := createPost(t, app, alice, "第二篇")
+	approvePost(t, app, second)
+
+	tests := []struct {
+		name       string
+		user       *testapp.User
+		postID     string
+		wantStatus int
+	}{
+		{name: "alice 第一次点赞", user: alice, postID: first, wantStatus: http.StatusOK},
+		{name: "alice 超出限制", user: alice, postID: second, wantStatus: http.StatusTooManyRequests},
+		{name: "bob 不受 alice 影响", user: bob, postID: first, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPost, "/api/v1/posts/"+tt.postID+"/like", nil, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+}
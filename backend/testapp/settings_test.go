@@ -0,0 +1,109 @@
+package testapp_test
+
+import (
+	"blue-note/testapp"
+	"net/http"
+	"testing"
+)
+
+func TestUpdateSettings(t *testing.T) {
+	app := testapp.New(t)
+	admin := app.CreateUser("root", "secret1", "admin")
+
+	resp := app.Do(http.MethodGet, "/api/v1/admin/settings", nil, admin.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取配置失败: %d %s", resp.Code, resp.Body)
+	}
+	if number(t, resp.Data(), "version") != 1 {
+		t.Fatalf("初始版本号不正确: %v", resp.Data())
+	}
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus int
+		wantCode   int
+	}{
+		{name: "缺少版本号", body: map[string]interface{}{"upload": map[string]interface{}{"maxImageSizeMB": 5}}, wantStatus: http.StatusBadRequest, wantCode: 40003},
+		{name: "超出范围", body: map[string]interface{}{"version": 1, "upload": map[string]interface{}{"maxImageSizeMB": 500}}, wantStatus: http.StatusBadRequest, wantCode: 40020},
+		{name: "无效来源", body: map[string]interface{}{"version": 1, "cors": map[string]interface{}{"allowOrigins": []string{"*"}}}, wantStatus: http.StatusBadRequest, wantCode: 40020},
+		{name: "未知限流规则", body: map[string]interface{}{"version": 1, "rateLimits": map[string]interface{}{"unknown": map[string]int{"requests": 1, "window": 1}}}, wantStatus: http.StatusBadRequest, wantCode: 40020},
+		{name: "修改成功", body: map[string]interface{}{"version": 1, "reason": "缩小图片限制", "upload": map[string]interface{}{"maxImageSizeMB": 1, "imageExtensions": []string{"PNG"}}}, wantStatus: http.StatusOK},
+		{name: "版本冲突", body: map[string]interface{}{"version": 1, "upload": map[string]interface{}{"maxImageSizeMB": 2}}, wantStatus: http.StatusConflict, wantCode: 40901},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPut, "/api/v1/admin/settings", tt.body, admin.Token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantCode != 0 && number(t, resp.JSON(), "code") != tt.wantCode {
+				t.Errorf("code = %v, 期望 %d", resp.JSON()["code"], tt.wantCode)
+			}
+		})
+	}
+
+	current := app.Settings.Current()
+	if current.Version != 2 || current.Upload.MaxImageSizeMB != 1 || current.Upload.MaxVideoSizeMB != 100 {
+		t.Errorf("修改后的配置不正确: %+v", current.Upload)
+	}
+	if len(current.Upload.ImageExtensions) != 1 || current.Upload.ImageExtensions[0] != ".png" {
+		t.Errorf("扩展名未规范化: %v", current.Upload.ImageExtensions)
+	}
+
+	resp = app.Do(http.MethodGet, "/api/v1/admin/settings/history", nil, admin.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("获取修改记录失败: %d %s", resp.Code, resp.Body)
+	}
+	list := resp.Data()["list"].([]interface{})
+	if len(list) != 1 {
+		t.Fatalf("修改记录数 = %d, 期望 1", len(list))
+	}
+	change := list[0].(map[string]interface{})
+	if change["reason"] != "缩小图片限制" || len(change["changes"].([]interface{})) != 2 {
+		t.Errorf("修改记录不正确: %v", change)
+	}
+}
+
+func TestSettingsApplyWithoutRestart(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	admin := app.CreateUser("root", "secret1", "admin")
+
+	image := pngImage(t, 600, 600)
+	if resp := app.Upload("/api/v1/upload", "photo.png", image, "", user.Token); resp.Code != http.StatusOK {
+		t.Fatalf("修改配置前上传失败: %d %s", resp.Code, resp.Body)
+	}
+
+	body := map[string]interface{}{
+		"version": 1,
+		"upload":  map[string]interface{}{"imageExtensions": []string{".jpg"}},
+		"cors":    map[string]interface{}{"allowOrigins": []string{"https://admin.blue-note.test"}},
+	}
+	if resp := app.Do(http.MethodPut, "/api/v1/admin/settings", body, admin.Token); resp.Code != http.StatusOK {
+		t.Fatalf("修改配置失败: %d %s", resp.Code, resp.Body)
+	}
+
+	resp := app.Upload("/api/v1/upload", "photo.png", image, "", user.Token)
+	if resp.Code != http.StatusBadRequest || number(t, resp.JSON(), "code") != 40005 {
+		t.Errorf("修改配置后应拒绝 PNG: %d %s", resp.Code, resp.Body)
+	}
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{origin: "https://admin.blue-note.test", allowed: true},
+		{origin: "http://localhost:3000", allowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			resp := app.DoWithHeader(http.MethodGet, "/api/v1/posts", "Origin", tt.origin)
+			allowed := resp.Header.Get("Access-Control-Allow-Origin") == tt.origin
+			if allowed != tt.allowed {
+				t.Errorf("跨域 %s allowed = %v, 期望 %v", tt.origin, allowed, tt.allowed)
+			}
+		})
+	}
+}
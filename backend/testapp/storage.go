@@ -0,0 +1,58 @@
+package testapp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemoryStorage 内存文件存储，替代本地磁盘保存上传的文件
+type MemoryStorage struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryStorage 创建内存文件存储
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{files: make(map[string][]byte)}
+}
+
+func (s *MemoryStorage) Save(objectName string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[objectName] = data
+	return nil
+}
+
+func (s *MemoryStorage) Open(objectName string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[objectName]
+	if !ok {
+		return nil, fmt.Errorf("文件不存在: %s", objectName)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Get 返回已保存的文件内容
+func (s *MemoryStorage) Get(objectName string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[objectName]
+	return data, ok
+}
+
+// Len 返回已保存的文件数
+func (s *MemoryStorage) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.files)
+}
@@ -0,0 +1,312 @@
+// Package testapp 用内存仓储和内存文件存储组装完整的 HTTP 路由，供测试在不依赖
+// MongoDB 和对象存储的情况下调用接口。
+//
+// 认证、用户资料、笔记、草稿、评论、点赞、关注、上传和管理员统计/审核接口均可用；
+// 举报、关注流、发现页、热门榜单、标签、数据分析和对账等仍直接访问数据库的服务没有接入，
+// 对应的路由在测试中不可调用。
+package testapp
+
+import (
+	"blue-note/cache"
+	"blue-note/config"
+	"blue-note/controller"
+	"blue-note/health"
+	"blue-note/lifecycle"
+	"blue-note/middleware"
+	"blue-note/model"
+	"blue-note/ratelimit"
+	"blue-note/repository"
+	"blue-note/router"
+	"blue-note/service"
+	"blue-note/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mojocn/base64Captcha"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// App 测试用的应用实例
+type App struct {
+	Router  *gin.Engine
+	Repos   *repository.Repositories
+	Storage *MemoryStorage
+
+	Posts    *service.PostService
+	Profiles *service.ProfileService
+	Files    *service.FileService
+	Settings *service.SettingsService
+	Health   *health.Registry
+
+	t testing.TB
+}
+
+// New 创建测试应用，每次调用都使用全新的内存数据
+func New(t testing.TB) *App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.GetConfig()
+	cfg.Environment = "test"
+	cfg.JWT.Secret = "test-secret"
+	cfg.JWT.Expire = 1
+	cfg.Timeout.Default = 5
+	cfg.Timeout.Routes = nil
+	cfg.Cache.PostTTL = 60
+	cfg.Cache.ProfileTTL = 60
+	cfg.Cache.DimensionsTTL = 60
+	cfg.Cache.FeedTTL = 60
+	cfg.Metrics.Enabled = true
+	cfg.Tracing.Exporter = "none"
+
+	// 只设置链路信息的传递方式，测试需要记录 span 时自行替换 TracerProvider
+	if _, err := tracing.Init(context.Background(), cfg); err != nil {
+		t.Fatalf("初始化链路追踪失败: %v", err)
+	}
+
+	repos := repository.NewMemory()
+	storage := NewMemoryStorage()
+	objectStorageService := service.NewLocalObjectStorageService(storage)
+
+	cacheGroup := cache.NewGroup(cache.NewLRU(1000))
+
+	// 测试结束前等待后台任务完成
+	tasks := lifecycle.NewTasks()
+	t.Cleanup(func() { tasks.Stop(context.Background()) })
+
+	fileService := service.NewFileService(repos, objectStorageService, cacheGroup)
+	profileService := service.NewProfileService(repos, objectStorageService, nil, cacheGroup, tasks)
+	authService := service.NewAuthService(repos, profileService)
+	postService := service.NewPostService(repos, fileService, nil, nil, cacheGroup, tasks)
+	adminService := service.NewAdminService(repos, postService)
+	settingsService := service.NewSettingsService(repos)
+	if err := settingsService.Load(context.Background()); err != nil {
+		t.Fatalf("加载运行时配置失败: %v", err)
+	}
+
+	// 测试中不缓存检查结果，注册的检查立即生效
+	healthRegistry := health.NewRegistry(time.Second, 0)
+	healthRegistry.Register("storage", false, objectStorageService.Health)
+	healthRegistry.Register("settings", false, settingsService.Health)
+
+	r := router.SetupRouter(
+		controller.NewAuthController(authService),
+		controller.NewProfileController(profileService),
+		controller.NewPostController(postService, nil),
+		controller.NewAdminController(adminService, objectStorageService),
+		controller.NewUploadController(objectStorageService, fileService, settingsService),
+		controller.NewFileController(fileService),
+		controller.NewReportController(nil),
+		controller.NewFeedController(nil, nil),
+		controller.NewTrendingController(nil),
+		controller.NewTagController(nil),
+		controller.NewAnalyticsController(nil, nil),
+		controller.NewReconcileController(nil),
+		controller.NewSettingsController(settingsService),
+		controller.NewHealthController(healthRegistry),
+		settingsService,
+		profileService,
+		ratelimit.NewMemoryStore(),
+	)
+
+	return &App{
+		Router:   r,
+		Repos:    repos,
+		Storage:  storage,
+		Posts:    postService,
+		Profiles: profileService,
+		Files:    fileService,
+		Settings: settingsService,
+		Health:   healthRegistry,
+		t:        t,
+	}
+}
+
+// User 测试用户及其 token
+type User struct {
+	*model.User
+	Token string
+}
+
+// CreateUser 直接在仓储中创建用户并签发 token，role 为空时为普通用户
+func (a *App) CreateUser(username, password, role string) *User {
+	a.t.Helper()
+
+	if role == "" {
+		role = "user"
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		a.t.Fatalf("加密密码失败: %v", err)
+	}
+
+	user := &model.User{
+		Username:  username,
+		Password:  string(hashed),
+		Nickname:  username,
+		Status:    "happy",
+		Role:      role,
+		IsAdmin:   role == "admin",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := a.Repos.Users.Create(context.Background(), user); err != nil {
+		a.t.Fatalf("创建用户失败: %v", err)
+	}
+
+	token, err := middleware.GenerateToken(user)
+	if err != nil {
+		a.t.Fatalf("生成token失败: %v", err)
+	}
+	return &User{User: user, Token: token}
+}
+
+// Captcha 生成一个已知答案的验证码，返回验证码ID和答案
+func (a *App) Captcha() (string, string) {
+	id := primitive.NewObjectID().Hex()
+	code := "123456"
+	if err := base64Captcha.DefaultMemStore.Set(id, code); err != nil {
+		a.t.Fatalf("保存验证码失败: %v", err)
+	}
+	return id, code
+}
+
+// Response 接口响应
+type Response struct {
+	Code   int
+	Body   []byte
+	Header http.Header
+	t      testing.TB
+}
+
+// JSON 将响应体解析为 map
+func (r *Response) JSON() map[string]interface{} {
+	r.t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		r.t.Fatalf("解析响应失败: %v, body=%s", err, r.Body)
+	}
+	return body
+}
+
+// Data 返回响应中的 data 字段
+func (r *Response) Data() map[string]interface{} {
+	r.t.Helper()
+	data, ok := r.JSON()["data"].(map[string]interface{})
+	if !ok {
+		r.t.Fatalf("响应中没有 data 字段: %s", r.Body)
+	}
+	return data
+}
+
+// Decode 将响应体解析到 v
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("解析响应失败: %v, body=%s", err, r.Body)
+	}
+}
+
+// Do 发送 JSON 请求，body 为 nil 时不带请求体，token 为空时不带认证信息
+func (a *App) Do(method, path string, body interface{}, token string) *Response {
+	a.t.Helper()
+	return a.serve(a.jsonRequest(method, path, body), token)
+}
+
+// DoWithLanguage 发送 JSON 请求，并通过 Accept-Language 指定语言
+func (a *App) DoWithLanguage(method, path string, body interface{}, token, acceptLanguage string) *Response {
+	a.t.Helper()
+
+	req := a.jsonRequest(method, path, body)
+	req.Header.Set("Accept-Language", acceptLanguage)
+	return a.serve(req, token)
+}
+
+// DoWithForwardedFor 发送 JSON 请求，并通过 X-Forwarded-For 声明客户端 IP
+func (a *App) DoWithForwardedFor(method, path string, body interface{}, token, forwardedFor string) *Response {
+	a.t.Helper()
+
+	req := a.jsonRequest(method, path, body)
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	return a.serve(req, token)
+}
+
+func (a *App) jsonRequest(method, path string, body interface{}) *http.Request {
+	a.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatalf("序列化请求失败: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// DoWithHeader 发送不带请求体的请求，并设置指定的请求头
+func (a *App) DoWithHeader(method, path, key, value string) *Response {
+	a.t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(key, value)
+	return a.serve(req, "")
+}
+
+// Upload 以 multipart 表单上传文件
+func (a *App) Upload(path, filename string, content []byte, fileType string, token string) *Response {
+	a.t.Helper()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if fileType != "" {
+		writer.WriteField("type", fileType)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		a.t.Fatalf("创建表单失败: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return a.serve(req, token)
+}
+
+func (a *App) serve(req *http.Request, token string) *Response {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.Router.ServeHTTP(w, req)
+	return &Response{Code: w.Code, Body: w.Body.Bytes(), Header: w.Header(), t: a.t}
+}
+
+// Eventually 等待后台任务完成，超时后测试失败
+func (a *App) Eventually(cond func() bool, msg string) {
+	a.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	a.t.Fatalf("等待超时: %s", msg)
+}
@@ -0,0 +1,69 @@
+package testapp_test
+
+import (
+	"blue-note/config"
+	"blue-note/testapp"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	app := testapp.New(t)
+	config.GetConfig().Timeout.Routes = map[string]int{
+		"get /test/slow":    1,
+		"get /test/handled": 1,
+	}
+
+	// 等待请求上下文结束，模拟被取消的数据库查询
+	wait := func(ctx *gin.Context) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-time.After(3 * time.Second):
+			return true
+		}
+	}
+	app.Router.GET("/test/slow", func(ctx *gin.Context) {
+		if wait(ctx) {
+			ctx.JSON(http.StatusOK, gin.H{"code": 0})
+		}
+	})
+	app.Router.GET("/test/handled", func(ctx *gin.Context) {
+		if !wait(ctx) {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": ctx.Request.Context().Err().Error()})
+		}
+	})
+	app.Router.GET("/test/fast", func(ctx *gin.Context) {
+		if _, ok := ctx.Request.Context().Deadline(); !ok {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "未设置超时"})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"code": 0})
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "超时未响应", path: "/test/slow", wantStatus: http.StatusGatewayTimeout},
+		{name: "超时后自行响应", path: "/test/handled", wantStatus: http.StatusInternalServerError},
+		{name: "默认超时", path: "/test/fast", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			resp := app.Do(http.MethodGet, tt.path, nil, "")
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("请求耗时 %v，超时未生效", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package testapp_test
+
+import (
+	"blue-note/logger"
+	"blue-note/testapp"
+	"blue-note/tracing"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans 将全局 TracerProvider 替换为记录 span 的 provider，测试结束后恢复
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestTracingSpans(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+	recorder := recordSpans(t)
+
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, "debug", "json")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(l)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "photo.png")
+	part.Write(pngImage(t, 2, 2))
+	writer.Close()
+
+	// 沿用上游网关传入的链路
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+user.Token)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("上传失败: %d %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get(tracing.HeaderTraceID); got != traceID {
+		t.Errorf("X-Trace-ID = %q, 期望 %q", got, traceID)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	server, ok := spans["POST /api/v1/upload"]
+	if !ok {
+		t.Fatalf("没有记录请求的 span: %v", spans)
+	}
+	if server.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("请求 span 的父 span = %s, 期望上游的 span", server.Parent().SpanID())
+	}
+	upload, ok := spans["storage.upload"]
+	if !ok {
+		t.Fatalf("没有记录文件存储的 span: %v", spans)
+	}
+	if upload.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("文件存储的 span 没有关联到请求的 span")
+	}
+	for _, attr := range upload.Attributes() {
+		if attr.Key == "storage.backend" && attr.Value.AsString() != "local" {
+			t.Errorf("storage.backend = %s, 期望 local", attr.Value.AsString())
+		}
+	}
+
+	// 请求中的日志都带有链路ID
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("日志不是 JSON: %s", line)
+		}
+		if entry["trace_id"] != traceID {
+			t.Errorf("日志缺少链路ID: %s", line)
+		}
+	}
+}
+
+func TestTraceIDHeader(t *testing.T) {
+	app := testapp.New(t)
+	recorder := recordSpans(t)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantSpan string
+	}{
+		{name: "按路由模板命名", path: "/api/v1/posts/000000000000000000000000", wantSpan: "GET /api/v1/posts/:postId"},
+		{name: "未匹配的路由", path: "/not-found/123", wantSpan: "GET unmatched"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodGet, tt.path, nil, "")
+			traceID := resp.Header.Get(tracing.HeaderTraceID)
+			if traceID == "" {
+				t.Fatal("错误响应中没有 X-Trace-ID")
+			}
+
+			spans := recorder.Ended()
+			last := spans[len(spans)-1]
+			if last.Name() != tt.wantSpan {
+				t.Errorf("span 名称 = %q, 期望 %q", last.Name(), tt.wantSpan)
+			}
+			if last.SpanContext().TraceID().String() != traceID {
+				t.Errorf("X-Trace-ID = %s, 期望 %s", traceID, last.SpanContext().TraceID())
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package testapp_test
+
+import (
+	"blue-note/model"
+	"blue-note/testapp"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// pngImage 生成指定尺寸的 PNG 图片
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("生成图片失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadFile(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	tests := []struct {
+		name       string
+		filename   string
+		content    []byte
+		fileType   string
+		token      string
+		wantStatus int
+		wantCode   int
+	}{
+		{name: "上传图片", filename: "photo.png", content: pngImage(t, 32, 16), token: user.Token, wantStatus: http.StatusOK},
+		{name: "上传视频", filename: "clip.mp4", content: []byte("video"), fileType: "video", token: user.Token, wantStatus: http.StatusOK},
+		{name: "图片格式错误", filename: "doc.pdf", content: []byte("pdf"), token: user.Token, wantStatus: http.StatusBadRequest, wantCode: 40005},
+		{name: "视频格式错误", filename: "clip.png", content: []byte("png"), fileType: "video", token: user.Token, wantStatus: http.StatusBadRequest, wantCode: 40005},
+		{name: "未登录", filename: "photo.png", content: pngImage(t, 1, 1), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Upload("/api/v1/upload", tt.filename, tt.content, tt.fileType, tt.token)
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if tt.wantCode != 0 && number(t, resp.JSON(), "code") != tt.wantCode {
+				t.Errorf("code = %v, 期望 %d", resp.JSON()["code"], tt.wantCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			url := resp.Data()["url"].(string)
+			objectName := strings.TrimPrefix(url, "/uploads/")
+			if _, ok := app.Storage.Get(objectName); !ok {
+				t.Errorf("文件未保存到存储: %s", objectName)
+			}
+
+			record, err := app.Repos.Files.FindByURL(context.Background(), url)
+			if err != nil {
+				t.Fatalf("未创建文件记录: %v", err)
+			}
+			if record.Status != model.FileStatusTemporary || record.UserID != user.ID {
+				t.Errorf("文件记录不正确: %+v", record)
+			}
+		})
+	}
+
+	if n := app.Storage.Len(); n != 2 {
+		t.Errorf("保存的文件数 = %d, 期望 2", n)
+	}
+}
+
+func TestUploadRecordsImageDimensions(t *testing.T) {
+	app := testapp.New(t)
+	user := app.CreateUser("alice", "secret1", "")
+
+	resp := app.Upload("/api/v1/upload", "wide.png", pngImage(t, 64, 48), "", user.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("上传失败: %d %s", resp.Code, resp.Body)
+	}
+
+	record, err := app.Repos.Files.FindByURL(context.Background(), resp.Data()["url"].(string))
+	if err != nil {
+		t.Fatalf("未创建文件记录: %v", err)
+	}
+	if record.Width != 64 || record.Height != 48 {
+		t.Errorf("尺寸 = %dx%d, 期望 64x48", record.Width, record.Height)
+	}
+}
+
+func TestUploadedFileLifecycle(t *testing.T) {
+	app := testapp.New(t)
+	owner := app.CreateUser("alice", "secret1", "")
+	other := app.CreateUser("bob", "secret1", "")
+
+	url := app.Upload("/api/v1/upload", "photo.png", pngImage(t, 8, 8), "", owner.Token).Data()["url"].(string)
+	filePath := strings.TrimPrefix(url, "/uploads/")
+
+	// 发布笔记后文件标记为已使用
+	resp := app.Do(http.MethodPost, "/api/v1/posts", map[string]interface{}{
+		"title": "带图笔记", "content": "内容", "type": "image", "tags": []string{}, "files": []string{filePath},
+	}, owner.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("发布笔记失败: %d %s", resp.Code, resp.Body)
+	}
+	app.Eventually(func() bool {
+		record, err := app.Repos.Files.FindByURL(context.Background(), url)
+		return err == nil && record.Status == model.FileStatusUsed
+	}, "文件标记为已使用")
+
+	tests := []struct {
+		name       string
+		user       *testapp.User
+		wantStatus int
+	}{
+		{name: "他人删除", user: other, wantStatus: http.StatusNotFound},
+		{name: "本人删除", user: owner, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.Do(http.MethodPost, "/api/v1/file/delete", map[string]string{"filePath": filePath}, tt.user.Token)
+			if resp.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d, body=%s", resp.Code, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+
+	record, _ := app.Repos.Files.FindByURL(context.Background(), url)
+	if record.Status != model.FileStatusDeleted {
+		t.Errorf("文件状态 = %s, 期望 deleted", record.Status)
+	}
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MongoMonitor 返回为每条 MongoDB 命令创建 span 的命令监听器，在创建客户端时通过 SetMonitor 设置。
+// span 只记录命令名、数据库和集合，不记录命令内容
+func MongoMonitor() *event.CommandMonitor {
+	// 命令开始时创建的 span，按请求ID在结束事件中取出
+	var spans sync.Map
+
+	finish := func(requestID int64, err error) {
+		if v, ok := spans.LoadAndDelete(requestID); ok {
+			End(v.(trace.Span), err)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collection := ""
+			if element, err := e.Command.IndexErr(0); err == nil {
+				if value, err := element.ValueErr(); err == nil {
+					collection, _ = value.StringValueOK()
+				}
+			}
+
+			name := e.CommandName
+			if collection != "" {
+				name += " " + collection
+			}
+			_, span := Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBOperationName(e.CommandName),
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBCollectionName(collection),
+				),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, errors.New(e.Failure))
+		},
+	}
+}
@@ -0,0 +1,102 @@
+// Package tracing 基于 OpenTelemetry 的链路追踪。
+//
+// Init 按配置设置全局的 TracerProvider，业务代码通过 Start 创建 span，
+// HTTP 请求、MongoDB 命令、文件存储和远程文件请求都会记录为同一条链路上的 span。
+package tracing
+
+import (
+	"blue-note/config"
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderTraceID 返回链路ID的响应头
+const HeaderTraceID = "X-Trace-ID"
+
+// instrumentationName 创建 Tracer 时使用的名称
+const instrumentationName = "blue-note"
+
+// Init 按配置创建 TracerProvider 并设为全局，返回退出时调用的关闭函数。
+// exporter 为 none 时不创建 TracerProvider，span 不会被记录，但仍然传递上游的链路信息
+func Init(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Tracing.Endpoint)}
+		if cfg.Tracing.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("无效的链路导出方式: %s", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("创建链路导出器失败: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(cfg.Tracing.ServiceName), semconv.DeploymentEnvironment(cfg.Environment)),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建链路资源信息失败: %w", err)
+	}
+
+	// 上游已经决定采样时沿用上游的决定，否则按比例采样
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer 返回全局 TracerProvider 中的 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 创建一个 span，调用方需要在结束时调用 End
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End 结束 span，err 不为空时记录错误并把 span 标记为失败
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID 返回上下文中的链路ID，没有链路信息时返回空字符串
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
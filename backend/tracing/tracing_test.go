@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"blue-note/config"
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useRecorder 将全局 TracerProvider 替换为记录 span 的 provider，测试结束后恢复
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{name: "不导出", exporter: "none"},
+		{name: "未设置", exporter: ""},
+		{name: "无效", exporter: "jaeger", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config.Config
+			cfg.Tracing.Exporter = tt.exporter
+			shutdown, err := Init(context.Background(), &cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err := shutdown(context.Background()); err != nil {
+					t.Errorf("shutdown() = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestStartAndEnd(t *testing.T) {
+	recorder := useRecorder(t)
+
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("没有链路时 TraceID() = %q, 期望为空", got)
+	}
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("失败"))
+	End(parent, nil)
+
+	if got := TraceID(ctx); got != parent.SpanContext().TraceID().String() {
+		t.Errorf("TraceID() = %q, 期望 %q", got, parent.SpanContext().TraceID())
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("记录了 %d 个 span, 期望 2 个", len(spans))
+	}
+	if spans[0].Name() != "child" || spans[0].Status().Code != codes.Error || len(spans[0].Events()) != 1 {
+		t.Errorf("child span = %s %v, 期望记录错误", spans[0].Name(), spans[0].Status())
+	}
+	if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("child span 的父 span 不正确")
+	}
+	if spans[1].Status().Code != codes.Unset {
+		t.Errorf("parent span 状态 = %v, 期望未设置", spans[1].Status())
+	}
+}
+
+func TestMongoMonitor(t *testing.T) {
+	recorder := useRecorder(t)
+	monitor := MongoMonitor()
+
+	ctx, parent := Start(context.Background(), "request")
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "posts"}, {Key: "filter", Value: bson.D{{Key: "password", Value: "secret"}}}})
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      command,
+		DatabaseName: "bluenote",
+		CommandName:  "find",
+		RequestID:    1,
+	})
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      command,
+		DatabaseName: "bluenote",
+		CommandName:  "find",
+		RequestID:    2,
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2}, Failure: "超时"})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("记录了 %d 个 span, 期望 3 个", len(spans))
+	}
+	for i, span := range spans[:2] {
+		if span.Name() != "find posts" {
+			t.Errorf("span 名称 = %q, 期望 find posts", span.Name())
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("第 %d 个 span 没有关联到请求的 span", i+1)
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Value.Emit() == "secret" {
+				t.Errorf("span 中包含命令内容: %v", attr)
+			}
+		}
+	}
+	if spans[0].Status().Code != codes.Unset || spans[1].Status().Code != codes.Error {
+		t.Errorf("span 状态 = %v %v, 期望成功和失败", spans[0].Status(), spans[1].Status())
+	}
+}
@@ -1 +1 @@
-./main
\ No newline at end of file
+exec ./main
\ No newline at end of file